@@ -0,0 +1,21 @@
+#!/bin/bash
+
+# Requires protoc, protoc-gen-go and protoc-gen-go-grpc:
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.10
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+
+echo "Generating gRPC code for the internal service APIs..."
+
+protos=("product/v1/product.proto" "order/v1/order.proto" "user/v1/user.proto")
+
+for proto in "${protos[@]}"; do
+    echo "Generating code for $proto..."
+    # go_package points into pkg/pb, module= strips the module path so the files land there
+    protoc -I proto \
+        --go_out=. --go_opt=module=rearatrox/go-ecommerce-backend \
+        --go-grpc_out=. --go-grpc_opt=module=rearatrox/go-ecommerce-backend \
+        "$proto"
+    echo "✅ $proto done"
+done
+
+echo "🎉 All gRPC code generated!"
@@ -7,7 +7,8 @@ services=("user-service" "product-service" "cart-service" "order-service" "payme
 for service in "${services[@]}"; do
     echo "Generating Swagger docs for $service..."
     cd "services/$service"
-    swag init
+    # parse dependencies so shared types from /pkg (e.g. pkg/shipping) are resolved
+    swag init --parseDependency --parseInternal
     cd "../.."
     echo "✅ $service done"
 done
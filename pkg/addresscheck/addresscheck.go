@@ -0,0 +1,258 @@
+package addresscheck
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Issue codes of a failed address check
+const (
+	IssueRequired          = "required"            // field is empty
+	IssueTooLong           = "too_long"            // field exceeds MaxFieldLength
+	IssueUnknownCountry    = "unknown_country"     // not an ISO 3166-1 country
+	IssueInvalidPostalCode = "invalid_postal_code" // does not match the postal code format of the country
+	IssueUndeliverable     = "undeliverable"       // rejected by the verifier
+)
+
+// MaxFieldLength is the maximum length of each address field in characters
+const MaxFieldLength = 200
+
+// Address is a postal address as entered by a customer
+type Address struct {
+	FullName   string `json:"fullName" example:"Max Mustermann"`
+	Street     string `json:"street" example:"Musterstraße 123"`
+	PostalCode string `json:"postalCode" example:"12345"`
+	City       string `json:"city" example:"Berlin"`
+	Country    string `json:"country" example:"DE"`
+}
+
+// Issue describes why a field of an address is invalid
+type Issue struct {
+	Field   string `json:"field" example:"postalCode"`
+	Code    string `json:"code" example:"invalid_postal_code"`
+	Message string `json:"message" example:"postal code does not match the format of DE, e.g. 12345"`
+}
+
+// Result is the outcome of validating an address
+type Result struct {
+	Valid bool `json:"valid" example:"true"`
+	// Normalized address; store this instead of the input
+	Address Address `json:"address"`
+	// Fields changed by normalization, e.g. country "Germany" became "DE"
+	Changed []string `json:"changed,omitempty" example:"country"`
+	Issues  []Issue  `json:"issues,omitempty"`
+	// Corrected addresses the customer may choose from
+	Suggestions []Address `json:"suggestions,omitempty"`
+}
+
+// LookupCountry resolves an ISO 3166-1 alpha-2 or alpha-3 code, an English short name or a common alias
+func LookupCountry(s string) (Country, bool) {
+	key := strings.ToLower(collapseSpaces(s))
+	if alpha2, ok := countryAliases[key]; ok {
+		key = strings.ToLower(alpha2)
+	}
+	for _, c := range countries {
+		if strings.ToLower(c.Alpha2) == key || strings.ToLower(c.Alpha3) == key || strings.ToLower(c.Name) == key {
+			return c, true
+		}
+	}
+	return Country{}, false
+}
+
+// Normalize trims and collapses whitespace, fixes all upper or all lower case names, resolves the country to its
+// alpha-2 code and formats the postal code the way the country writes it
+func Normalize(a Address) Address {
+	n := Address{
+		FullName:   fixCase(collapseSpaces(a.FullName)),
+		Street:     fixCase(collapseSpaces(a.Street)),
+		PostalCode: strings.ToUpper(collapseSpaces(a.PostalCode)),
+		City:       fixCase(collapseSpaces(a.City)),
+		Country:    strings.ToUpper(collapseSpaces(a.Country)),
+	}
+	if c, ok := LookupCountry(a.Country); ok {
+		n.Country = c.Alpha2
+	}
+	n.PostalCode = formatPostalCode(n.Country, n.PostalCode)
+	return n
+}
+
+// Check normalizes and validates an address against the rules of its country
+func Check(a Address) Result {
+	r := Result{Address: Normalize(a)}
+	n := r.Address
+
+	fields := []struct{ name, in, out string }{
+		{"fullName", a.FullName, n.FullName},
+		{"street", a.Street, n.Street},
+		{"postalCode", a.PostalCode, n.PostalCode},
+		{"city", a.City, n.City},
+		{"country", a.Country, n.Country},
+	}
+	for _, f := range fields {
+		if f.in != f.out {
+			r.Changed = append(r.Changed, f.name)
+		}
+		if f.name == "postalCode" {
+			continue // checked per country below
+		}
+		if f.out == "" {
+			r.Issues = append(r.Issues, Issue{Field: f.name, Code: IssueRequired, Message: f.name + " is required"})
+		} else if len([]rune(f.out)) > MaxFieldLength {
+			r.Issues = append(r.Issues, Issue{Field: f.name, Code: IssueTooLong, Message: fmt.Sprintf("%s exceeds %d characters", f.name, MaxFieldLength)})
+		}
+	}
+
+	if _, ok := LookupCountry(n.Country); n.Country != "" && !ok {
+		r.Issues = append(r.Issues, Issue{Field: "country", Code: IssueUnknownCountry, Message: fmt.Sprintf("unknown country %q, use an ISO 3166-1 code such as DE", n.Country)})
+		for _, c := range similarCountries(a.Country) {
+			s := n
+			s.Country = c.Alpha2
+			s.PostalCode = formatPostalCode(s.Country, s.PostalCode)
+			if checkPostalCode(s.Country, s.PostalCode) == nil {
+				r.Suggestions = append(r.Suggestions, s)
+			}
+		}
+	} else if issue := checkPostalCode(n.Country, n.PostalCode); issue != nil {
+		r.Issues = append(r.Issues, *issue)
+	}
+
+	r.Valid = len(r.Issues) == 0
+	return r
+}
+
+// checkPostalCode validates a normalized postal code for a known country
+func checkPostalCode(country, code string) *Issue {
+	if code == "" {
+		if slices.Contains(noPostalCodes, country) {
+			return nil
+		}
+		return &Issue{Field: "postalCode", Code: IssueRequired, Message: "postalCode is required"}
+	}
+	if len(code) > 12 {
+		return &Issue{Field: "postalCode", Code: IssueTooLong, Message: "postalCode exceeds 12 characters"}
+	}
+
+	layouts, ok := postalLayouts[country]
+	if !ok {
+		// no known format: accept letters, digits, spaces and hyphens
+		for _, r := range code {
+			if !isAlnum(r) && r != ' ' && r != '-' {
+				return &Issue{Field: "postalCode", Code: IssueInvalidPostalCode, Message: "postal code may only contain letters, digits, spaces and hyphens"}
+			}
+		}
+		return nil
+	}
+	for _, layout := range layouts {
+		if matchesLayout(code, layout) {
+			return nil
+		}
+	}
+	return &Issue{
+		Field:   "postalCode",
+		Code:    IssueInvalidPostalCode,
+		Message: fmt.Sprintf("postal code does not match the format of %s, e.g. %s", country, layoutExample(layouts[0])),
+	}
+}
+
+// similarCountries returns up to three countries whose code, name or alias is within two edits of s
+func similarCountries(s string) []Country {
+	key := strings.ToLower(collapseSpaces(s))
+	if len([]rune(key)) < 4 {
+		return nil
+	}
+
+	type candidate struct {
+		country  Country
+		distance int
+	}
+	var candidates []candidate
+	add := func(alpha2 string, d int) {
+		for i, c := range candidates {
+			if c.country.Alpha2 == alpha2 {
+				candidates[i].distance = min(c.distance, d)
+				return
+			}
+		}
+		c, _ := LookupCountry(alpha2)
+		candidates = append(candidates, candidate{c, d})
+	}
+	for _, c := range countries {
+		if d := levenshtein(key, strings.ToLower(c.Name)); d <= 2 {
+			add(c.Alpha2, d)
+		}
+	}
+	for alias, alpha2 := range countryAliases {
+		if d := levenshtein(key, alias); d <= 2 {
+			add(alpha2, d)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.country.Name, b.country.Name)
+	})
+	var out []Country
+	for i := 0; i < len(candidates) && i < 3; i++ {
+		out = append(out, candidates[i].country)
+	}
+	return out
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// fixCase title-cases text typed entirely in upper or lower case and keeps mixed case (e.g. McDonald) as entered.
+// Words containing digits, such as house numbers like 12a, are left alone.
+func fixCase(s string) string {
+	hasUpper, hasLower := false, false
+	for _, r := range s {
+		hasUpper = hasUpper || unicode.IsUpper(r)
+		hasLower = hasLower || unicode.IsLower(r)
+	}
+	if hasUpper == hasLower {
+		return s
+	}
+
+	words := strings.Split(s, " ")
+	for i, w := range words {
+		if strings.ContainsFunc(w, unicode.IsDigit) {
+			continue
+		}
+		runes := []rune(strings.ToLower(w))
+		start := true
+		for j, r := range runes {
+			if start && unicode.IsLetter(r) {
+				runes[j] = unicode.ToUpper(r)
+			}
+			start = r == '-' || r == '/' || r == '('
+		}
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
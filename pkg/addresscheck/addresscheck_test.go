@@ -0,0 +1,157 @@
+package addresscheck
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLookupCountry(t *testing.T) {
+	tests := map[string]string{
+		"DE":              "DE",
+		"de":              "DE",
+		"DEU":             "DE",
+		"Germany":         "DE",
+		" deutschland":    "DE",
+		"USA":             "US",
+		"United  Kingdom": "GB",
+		"Österreich":      "AT",
+	}
+	for in, want := range tests {
+		c, ok := LookupCountry(in)
+		if !ok || c.Alpha2 != want {
+			t.Errorf("LookupCountry(%q) = %q, %v, want %q", in, c.Alpha2, ok, want)
+		}
+	}
+	if _, ok := LookupCountry("Atlantis"); ok {
+		t.Error("LookupCountry(Atlantis) found a country")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize(Address{
+		FullName:   "  max   MUSTERMANN ",
+		Street:     "MUSTERSTRASSE 12A",
+		PostalCode: "d-10115",
+		City:       "berlin",
+		Country:    "germany",
+	})
+	// mixed case is kept as entered, house numbers keep their letter
+	want := Address{FullName: "max MUSTERMANN", Street: "Musterstrasse 12A", PostalCode: "10115", City: "Berlin", Country: "DE"}
+	if got != want {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+
+	if got := fixCase("JEAN-PIERRE O'NEILL"); got != "Jean-Pierre O'neill" {
+		t.Errorf("fixCase() = %q", got)
+	}
+	if got := fixCase("McDonald"); got != "McDonald" {
+		t.Errorf("fixCase() changed mixed case: %q", got)
+	}
+}
+
+func TestFormatPostalCode(t *testing.T) {
+	tests := []struct{ country, in, want string }{
+		{"NL", "1234AB", "1234 AB"},
+		{"GB", "SW1A1AA", "SW1A 1AA"},
+		{"GB", "M11AE", "M1 1AE"},
+		{"CA", "K1A0B1", "K1A 0B1"},
+		{"PL", "00950", "00-950"},
+		{"SE", "11455", "114 55"},
+		{"US", "123456789", "12345-6789"},
+		{"IE", "D02X285", "D02 X285"},
+		{"LU", "L-1234", "1234"},
+		{"DE", "1011", "1011"}, // fits no layout, left for the check to reject
+	}
+	for _, tt := range tests {
+		if got := formatPostalCode(tt.country, tt.in); got != tt.want {
+			t.Errorf("formatPostalCode(%s, %q) = %q, want %q", tt.country, tt.in, got, tt.want)
+		}
+	}
+}
+
+func codes(r Result) []string {
+	var out []string
+	for _, i := range r.Issues {
+		out = append(out, i.Field+":"+i.Code)
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	valid := Address{FullName: "Max Mustermann", Street: "Musterstraße 123", PostalCode: "12345", City: "Berlin", Country: "DE"}
+
+	r := Check(valid)
+	if !r.Valid || len(r.Changed) != 0 {
+		t.Fatalf("Check(valid) = %+v, want valid and unchanged", r)
+	}
+
+	a := valid
+	a.Country, a.PostalCode = "Netherlands", "1234ab"
+	r = Check(a)
+	if !r.Valid || r.Address.PostalCode != "1234 AB" || !slices.Equal(r.Changed, []string{"postalCode", "country"}) {
+		t.Errorf("Check(NL) = %+v", r)
+	}
+
+	a = valid
+	a.PostalCode = "1234"
+	r = Check(a)
+	if r.Valid || !slices.Equal(codes(r), []string{"postalCode:" + IssueInvalidPostalCode}) {
+		t.Errorf("Check(short DE postal code) issues = %v", codes(r))
+	}
+
+	a = valid
+	a.City, a.PostalCode = " ", ""
+	r = Check(a)
+	if !slices.Equal(codes(r), []string{"city:" + IssueRequired, "postalCode:" + IssueRequired}) {
+		t.Errorf("Check(missing fields) issues = %v", codes(r))
+	}
+
+	a = valid
+	a.Country, a.PostalCode = "HK", ""
+	if r = Check(a); !r.Valid {
+		t.Errorf("Check(HK without postal code) issues = %v", codes(r))
+	}
+
+	a = valid
+	a.Country = "Germny"
+	r = Check(a)
+	if r.Valid || !slices.Equal(codes(r), []string{"country:" + IssueUnknownCountry}) {
+		t.Errorf("Check(misspelled country) issues = %v", codes(r))
+	}
+	if len(r.Suggestions) != 1 || r.Suggestions[0].Country != "DE" {
+		t.Errorf("Check(misspelled country) suggestions = %+v, want DE", r.Suggestions)
+	}
+}
+
+type stubVerifier struct {
+	v   Verification
+	err error
+}
+
+func (s stubVerifier) Verify(ctx context.Context, a Address) (Verification, error) {
+	return s.v, s.err
+}
+
+func TestValidator(t *testing.T) {
+	a := Address{FullName: "Max Mustermann", Street: "Musterstr. 1", PostalCode: "10115", City: "Berlin", Country: "DE"}
+	suggestion := Address{FullName: "Max Mustermann", Street: "Musterstraße 1", PostalCode: "10115", City: "Berlin", Country: "DE"}
+
+	r, err := (&Validator{Verifier: LocalVerifier{}}).Validate(context.Background(), a)
+	if err != nil || !r.Valid {
+		t.Errorf("Validate(local) = %+v, %v", r, err)
+	}
+
+	v := &Validator{Verifier: stubVerifier{v: Verification{Deliverable: false, Suggestions: []Address{suggestion}}}}
+	r, err = v.Validate(context.Background(), a)
+	if err != nil || r.Valid || !slices.Equal(codes(r), []string{"address:" + IssueUndeliverable}) || len(r.Suggestions) != 1 {
+		t.Errorf("Validate(undeliverable) = %+v, %v", r, err)
+	}
+
+	v = &Validator{Verifier: stubVerifier{err: errors.New("timeout")}}
+	r, err = v.Validate(context.Background(), a)
+	if err == nil || !r.Valid {
+		t.Errorf("Validate(verifier down) = %+v, %v, want local result and error", r, err)
+	}
+}
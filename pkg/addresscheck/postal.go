@@ -0,0 +1,164 @@
+package addresscheck
+
+import "strings"
+
+// postalLayouts are the postal code formats per country: 9 is a digit, A a letter, X a letter or digit; spaces and
+// hyphens are written as shown. Countries without an entry accept any code of letters, digits, spaces and hyphens.
+var postalLayouts = map[string][]string{
+	"AT": {"9999"},
+	"AU": {"9999"},
+	"BE": {"9999"},
+	"BG": {"9999"},
+	"BR": {"99999-999"},
+	"CA": {"A9A 9A9"},
+	"CH": {"9999"},
+	"CN": {"999999"},
+	"CY": {"9999"},
+	"CZ": {"999 99"},
+	"DE": {"99999"},
+	"DK": {"9999"},
+	"EE": {"99999"},
+	"ES": {"99999"},
+	"FI": {"99999"},
+	"FR": {"99999"},
+	"GB": {"A9 9AA", "A99 9AA", "AA9 9AA", "AA99 9AA", "A9A 9AA", "AA9A 9AA"},
+	"GR": {"999 99"},
+	"HR": {"99999"},
+	"HU": {"9999"},
+	"IE": {"A9X XXXX"},
+	"IN": {"999999"},
+	"IS": {"999"},
+	"IT": {"99999"},
+	"JP": {"999-9999"},
+	"KR": {"99999"},
+	"LI": {"9999"},
+	"LU": {"9999"},
+	"MC": {"99999"},
+	"MT": {"AAA 9999"},
+	"MX": {"99999"},
+	"NL": {"9999 AA"},
+	"NO": {"9999"},
+	"NZ": {"9999"},
+	"PL": {"99-999"},
+	"PT": {"9999-999"},
+	"RO": {"999999"},
+	"RU": {"999999"},
+	"SE": {"999 99"},
+	"SG": {"999999"},
+	"SI": {"9999"},
+	"SK": {"999 99"},
+	"SM": {"99999"},
+	"TR": {"99999"},
+	"UA": {"99999"},
+	"US": {"99999", "99999-9999"},
+	"ZA": {"9999"},
+}
+
+// noPostalCodes lists countries without a postal code system, where the postal code may be left empty
+var noPostalCodes = []string{
+	"AE", "AG", "AO", "AW", "BF", "BI", "BJ", "BO", "BS", "BW", "BZ", "CF", "CG", "CI", "CM", "CW", "DJ", "DM", "ER",
+	"FJ", "GA", "GD", "GM", "GQ", "GY", "HK", "KI", "KM", "KN", "KP", "LC", "ML", "MO", "MR", "NR", "NU", "QA", "RW",
+	"SB", "SC", "SL", "SR", "ST", "SY", "TD", "TG", "TK", "TL", "TO", "TV", "UG", "VU", "YE", "ZW",
+}
+
+// legacyPrefixes are country prefixes customers still put in front of postal codes, e.g. D-10115
+var legacyPrefixes = map[string][]string{
+	"AT": {"A-", "AT-"},
+	"BE": {"B-", "BE-"},
+	"CH": {"CH-"},
+	"DE": {"D-", "DE-"},
+	"FR": {"F-", "FR-"},
+	"LI": {"FL-", "LI-"},
+	"LU": {"L-", "LU-"},
+}
+
+// formatPostalCode rewrites an upper case postal code into the layout of the country, e.g. 1234ab becomes 1234 AB
+// in the Netherlands. Codes that fit no layout are returned unchanged.
+func formatPostalCode(country, code string) string {
+	for _, prefix := range legacyPrefixes[country] {
+		code = strings.TrimPrefix(code, prefix)
+	}
+
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(code)
+	for _, layout := range postalLayouts[country] {
+		if formatted, ok := applyLayout(compact, layout); ok {
+			return formatted
+		}
+	}
+	return code
+}
+
+// applyLayout fills the characters of a compact code into a layout
+func applyLayout(compact, layout string) (string, bool) {
+	runes := []rune(compact)
+	var b strings.Builder
+	i := 0
+	for _, l := range layout {
+		if l == ' ' || l == '-' {
+			b.WriteRune(l)
+			continue
+		}
+		if i >= len(runes) || !matchesClass(runes[i], l) {
+			return "", false
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	if i != len(runes) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// matchesLayout reports whether a formatted code is written exactly in a layout
+func matchesLayout(code, layout string) bool {
+	runes, lay := []rune(code), []rune(layout)
+	if len(runes) != len(lay) {
+		return false
+	}
+	for i, l := range lay {
+		if l == ' ' || l == '-' {
+			if runes[i] != l {
+				return false
+			}
+		} else if !matchesClass(runes[i], l) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesClass(r, class rune) bool {
+	switch class {
+	case '9':
+		return r >= '0' && r <= '9'
+	case 'A':
+		return r >= 'A' && r <= 'Z'
+	default:
+		return isAlnum(r)
+	}
+}
+
+func isAlnum(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+}
+
+// layoutExample turns a layout into a readable example, e.g. 9999 AA becomes 1234 AB
+func layoutExample(layout string) string {
+	digits, letters := "1234567890", "ABCDEFGHIJ"
+	var b strings.Builder
+	d, l := 0, 0
+	for _, r := range layout {
+		switch r {
+		case '9':
+			b.WriteByte(digits[d%len(digits)])
+			d++
+		case 'A', 'X':
+			b.WriteByte(letters[l%len(letters)])
+			l++
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
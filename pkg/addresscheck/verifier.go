@@ -0,0 +1,68 @@
+package addresscheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Verification is the answer of a verifier for a locally valid address
+type Verification struct {
+	Deliverable bool
+	Suggestions []Address
+}
+
+// Verifier checks addresses against an external source such as a postal address database. Implementations must be
+// safe for concurrent use.
+type Verifier interface {
+	Verify(ctx context.Context, a Address) (Verification, error)
+}
+
+// LocalVerifier is the stub used when no external verifier is configured: it accepts every address that passes the
+// local checks
+type LocalVerifier struct{}
+
+func (LocalVerifier) Verify(ctx context.Context, a Address) (Verification, error) {
+	return Verification{Deliverable: true}, nil
+}
+
+// VerifierFromEnv returns the verifier configured by ADDRESS_VERIFIER. Only the local stub ("local", the default)
+// ships with the backend; external verifiers implement Verifier and are added here.
+func VerifierFromEnv() (Verifier, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("ADDRESS_VERIFIER"))) {
+	case "", "local":
+		return LocalVerifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown address verifier %q", os.Getenv("ADDRESS_VERIFIER"))
+	}
+}
+
+// Validator runs the local checks and then asks the verifier
+type Validator struct {
+	Verifier Verifier
+}
+
+// Validate normalizes and checks an address. If the verifier fails, the result of the local checks is returned
+// together with the error, so callers can log it and carry on.
+func (v *Validator) Validate(ctx context.Context, a Address) (Result, error) {
+	r := Check(a)
+	if !r.Valid || v.Verifier == nil {
+		return r, nil
+	}
+
+	verification, err := v.Verifier.Verify(ctx, r.Address)
+	if err != nil {
+		return r, fmt.Errorf("address verification failed: %w", err)
+	}
+	for _, s := range verification.Suggestions {
+		if s = Normalize(s); s != r.Address {
+			r.Suggestions = append(r.Suggestions, s)
+		}
+	}
+	if !verification.Deliverable {
+		r.Valid = false
+		r.Issues = append(r.Issues, Issue{Field: "address", Code: IssueUndeliverable, Message: "address could not be verified as deliverable"})
+	}
+	return r, nil
+}
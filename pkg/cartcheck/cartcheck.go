@@ -0,0 +1,80 @@
+package cartcheck
+
+import "fmt"
+
+// Warning types returned when a cart line no longer matches the catalog
+const (
+	WarningPriceChanged      = "price_changed"      // product was repriced since it was added
+	WarningProductInactive   = "product_inactive"   // product was deactivated or removed from sale
+	WarningInsufficientStock = "insufficient_stock" // less stock available than in the cart
+)
+
+// Line is a cart line with its price snapshot and the current product state
+type Line struct {
+	ProductID         int64
+	ProductName       string
+	Quantity          int
+	PriceCents        int // snapshot taken when the item was added
+	CurrentPriceCents int
+	Status            string
+	StockQty          int
+}
+
+// Warning describes one change of a cart line the customer has to acknowledge before checkout
+type Warning struct {
+	ProductID     int64  `json:"productId" example:"1"`
+	ProductName   string `json:"productName" example:"Gaming Laptop"`
+	Type          string `json:"type" example:"price_changed"`
+	Message       string `json:"message" example:"price changed from 2999 to 3499 cents"`
+	OldPriceCents *int   `json:"oldPriceCents,omitempty" example:"2999"`
+	NewPriceCents *int   `json:"newPriceCents,omitempty" example:"3499"`
+	Requested     *int   `json:"requested,omitempty" example:"3"`
+	Available     *int   `json:"available,omitempty" example:"1"`
+}
+
+// Check compares a cart line against the current product state.
+// An inactive product only yields the inactive warning since the line will be removed anyway.
+func Check(line Line) []Warning {
+	base := Warning{ProductID: line.ProductID, ProductName: line.ProductName}
+
+	if line.Status != "active" {
+		w := base
+		w.Type = WarningProductInactive
+		w.Message = "product is no longer available"
+		return []Warning{w}
+	}
+
+	var warnings []Warning
+	if line.StockQty < line.Quantity {
+		w := base
+		w.Type = WarningInsufficientStock
+		requested, available := line.Quantity, max(line.StockQty, 0)
+		w.Requested, w.Available = &requested, &available
+		if available == 0 {
+			w.Message = "product is out of stock"
+		} else {
+			w.Message = fmt.Sprintf("only %d of %d available", available, requested)
+		}
+		warnings = append(warnings, w)
+	}
+
+	if line.CurrentPriceCents != line.PriceCents {
+		w := base
+		w.Type = WarningPriceChanged
+		oldPrice, newPrice := line.PriceCents, line.CurrentPriceCents
+		w.OldPriceCents, w.NewPriceCents = &oldPrice, &newPrice
+		w.Message = fmt.Sprintf("price changed from %d to %d cents", oldPrice, newPrice)
+		warnings = append(warnings, w)
+	}
+
+	return warnings
+}
+
+// CheckAll checks all lines of a cart
+func CheckAll(lines []Line) []Warning {
+	warnings := []Warning{}
+	for _, line := range lines {
+		warnings = append(warnings, Check(line)...)
+	}
+	return warnings
+}
@@ -0,0 +1,37 @@
+package cartcheck
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		line Line
+		want []string
+	}{
+		{"unchanged", Line{Quantity: 2, PriceCents: 1000, CurrentPriceCents: 1000, Status: "active", StockQty: 5}, nil},
+		{"repriced", Line{Quantity: 2, PriceCents: 1000, CurrentPriceCents: 1200, Status: "active", StockQty: 5}, []string{WarningPriceChanged}},
+		{"inactive", Line{Quantity: 2, PriceCents: 1000, CurrentPriceCents: 1200, Status: "inactive", StockQty: 0}, []string{WarningProductInactive}},
+		{"stock shortfall", Line{Quantity: 3, PriceCents: 1000, CurrentPriceCents: 1000, Status: "active", StockQty: 1}, []string{WarningInsufficientStock}},
+		{"shortfall and repriced", Line{Quantity: 3, PriceCents: 1000, CurrentPriceCents: 900, Status: "active", StockQty: 0}, []string{WarningInsufficientStock, WarningPriceChanged}},
+	}
+
+	for _, tt := range tests {
+		got := Check(tt.line)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d warnings, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i, w := range got {
+			if w.Type != tt.want[i] {
+				t.Errorf("%s: warning %d type = %s, want %s", tt.name, i, w.Type, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCheckStockDetails(t *testing.T) {
+	got := Check(Line{Quantity: 3, PriceCents: 1000, CurrentPriceCents: 1000, Status: "active", StockQty: 1})
+	if len(got) != 1 || *got[0].Requested != 3 || *got[0].Available != 1 {
+		t.Fatalf("unexpected warning: %+v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package cartcheck
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const linesQuery = `SELECT ci.product_id, p.name, ci.quantity, ci.price_cents, p.price_cents, p.status, p.stock_qty
+	FROM cart_items ci
+	JOIN products p ON ci.product_id = p.id
+	WHERE ci.cart_id=$1
+	ORDER BY ci.created_at DESC`
+
+func loadLines(ctx context.Context, q querier, query string, cartID int64) ([]Line, error) {
+	rows, err := q.Query(ctx, query, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []Line{}
+	for rows.Next() {
+		var line Line
+		err := rows.Scan(&line.ProductID, &line.ProductName, &line.Quantity, &line.PriceCents,
+			&line.CurrentPriceCents, &line.Status, &line.StockQty)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// Validate revalidates all lines of a cart against current price, status and stock
+// used in: cart-service models.Cart, order-service handlers.CreateOrder, handlers.CreateGuestOrder
+func Validate(ctx context.Context, cartID int64) ([]Warning, error) {
+	lines, err := loadLines(ctx, db.DB, linesQuery, cartID)
+	if err != nil {
+		return nil, err
+	}
+	return CheckAll(lines), nil
+}
+
+// Acknowledge applies all pending changes to the cart: prices are updated to the current price,
+// quantities are reduced to the available stock and inactive or sold out products are removed.
+// Returns the warnings that were applied.
+// used in: cart-service handlers.AcknowledgeCartChanges
+func Acknowledge(ctx context.Context, cartID int64) ([]Warning, error) {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the cart lines so concurrent updates cannot interleave with the adjustment
+	lines, err := loadLines(ctx, tx, linesQuery+` FOR UPDATE OF ci`, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Warning{}
+	for _, line := range lines {
+		warnings := Check(line)
+		if len(warnings) == 0 {
+			continue
+		}
+		applied = append(applied, warnings...)
+
+		if line.Status != "active" || line.StockQty <= 0 {
+			_, err = tx.Exec(ctx, `DELETE FROM cart_items WHERE cart_id=$1 AND product_id=$2`, cartID, line.ProductID)
+		} else {
+			_, err = tx.Exec(ctx, `
+				UPDATE cart_items SET quantity=$1, price_cents=$2, updated_at=now()
+				WHERE cart_id=$3 AND product_id=$4
+			`, min(line.Quantity, line.StockQty), line.CurrentPriceCents, cartID, line.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(applied) > 0 {
+		if _, err = tx.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, cartID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
@@ -0,0 +1,41 @@
+package clients
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+)
+
+// CartClient calls the cart-service
+type CartClient struct {
+	http *httpclient.Client
+}
+
+// NewCartClient creates a cart-service client on top of an HTTP client
+func NewCartClient(c *httpclient.Client) *CartClient {
+	return &CartClient{http: c}
+}
+
+// NewCartClientFromEnv creates a cart-service client configured by httpclient.ConfigFromEnv
+func NewCartClientFromEnv() *CartClient {
+	return NewCartClient(httpclient.NewFromEnv("cart-service"))
+}
+
+// MergeGuestCart moves the guest cart identified by cartToken into the user's cart (internal)
+func (c *CartClient) MergeGuestCart(ctx context.Context, userID int64, cartToken string) (*models.MergeCartResponse, error) {
+	var resp models.MergeCartResponse
+	if err := c.http.PostJSON(ctx, "/internal/cart/merge", models.MergeCartRequest{UserID: userID, CartToken: cartToken}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddItem adds a product to the cart of the user identified by token and returns the updated cart
+func (c *CartClient) AddItem(ctx context.Context, token string, item models.AddItemRequest) (*models.Cart, error) {
+	var cart models.Cart
+	if err := c.http.PostJSON(ctx, "/cart/items", item, &cart, bearer(token)); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
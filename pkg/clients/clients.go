@@ -0,0 +1,14 @@
+// Package clients provides typed clients for the HTTP APIs of the services. Requests and responses use the
+// services' own model types, so callers cannot drift from the JSON the services actually speak. All calls go
+// through httpclient and return its *httpclient.StatusError for non-2xx responses.
+//
+// The internal operations are also served over gRPC (proto/, pkg/pb). The *GRPCClient types call those and convert
+// the messages into the same model types; their errors are gRPC status errors (status.Code).
+package clients
+
+import "rearatrox/go-ecommerce-backend/pkg/httpclient"
+
+// bearer forwards a user's token; token is the full header value as returned by the login ("Bearer ...")
+func bearer(token string) httpclient.Option {
+	return httpclient.WithHeader("Authorization", token)
+}
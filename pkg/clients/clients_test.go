@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	ordermodels "rearatrox/go-ecommerce-backend/services/order-service/models"
+	productmodels "rearatrox/go-ecommerce-backend/services/product-service/models"
+)
+
+func newTestHTTPClient(url string) *httpclient.Client {
+	return httpclient.New(httpclient.Config{Service: "test-service", BaseURL: url, Timeout: time.Second,
+		Signer: serviceauth.NewSigner("test-service", []byte("key"))})
+}
+
+func TestProductCheckStock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/products/stock/check" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req productmodels.CheckStockRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(productmodels.CheckStockResponse{
+			Available: req.Quantity <= 3, RequestedQty: req.Quantity, AvailableQty: 3, ProductID: req.ProductID,
+		})
+	}))
+	defer srv.Close()
+
+	resp, err := NewProductClient(newTestHTTPClient(srv.URL)).CheckStock(context.Background(), 7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Available || resp.AvailableQty != 3 || resp.ProductID != 7 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestOrderUpdateStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/internal/orders/3/status" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get(serviceauth.CallerHeader) != "test-service" || r.Header.Get(serviceauth.SignatureHeader) == "" {
+			t.Error("request not signed")
+		}
+		var req ordermodels.UpdateStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Status != "cancelled" {
+			t.Errorf("status = %q", req.Status)
+		}
+		http.Error(w, `{"message":"order cannot be cancelled in current state"}`, http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	err := NewOrderClient(newTestHTTPClient(srv.URL)).UpdateStatus(context.Background(), 3, "cancelled")
+	if httpclient.StatusCode(err) != http.StatusConflict {
+		t.Errorf("err = %v, want status 409", err)
+	}
+}
+
+func TestUserLogin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["email"] != "user@example.com" || body["password"] != "pw" {
+			t.Errorf("body = %v", body)
+		}
+		w.Write([]byte(`{"message":"Login successful","token":"Bearer abc"}`))
+	}))
+	defer srv.Close()
+
+	token, err := NewUserClient(newTestHTTPClient(srv.URL)).Login(context.Background(), "user@example.com", "pw")
+	if err != nil || token != "Bearer abc" {
+		t.Errorf("Login = %q, %v", token, err)
+	}
+}
@@ -0,0 +1,123 @@
+package clients
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"rearatrox/go-ecommerce-backend/pkg/pb/orderpb"
+	"rearatrox/go-ecommerce-backend/pkg/pb/productpb"
+	productmodels "rearatrox/go-ecommerce-backend/services/product-service/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serveGRPC starts srv on an in-memory listener and returns a connection to it
+func serveGRPC(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+type fakeProductServer struct {
+	productpb.UnimplementedProductInternalServer
+	orderID  int64
+	reserved []*productpb.StockItem
+}
+
+func (s *fakeProductServer) CheckStock(_ context.Context, req *productpb.CheckStockRequest) (*productpb.CheckStockResponse, error) {
+	return &productpb.CheckStockResponse{
+		ProductId: req.ProductId, Available: req.Quantity <= 3, RequestedQty: req.Quantity, AvailableQty: 3,
+	}, nil
+}
+
+func (s *fakeProductServer) ReserveStock(_ context.Context, req *productpb.ReserveStockRequest) (*productpb.ReserveStockResponse, error) {
+	for _, item := range req.Items {
+		if item.Quantity > 3 {
+			return nil, status.Error(codes.FailedPrecondition, "insufficient stock")
+		}
+	}
+	s.orderID = req.OrderId
+	s.reserved = append(s.reserved, req.Items...)
+	return &productpb.ReserveStockResponse{}, nil
+}
+
+func TestProductGRPCClient(t *testing.T) {
+	fake := &fakeProductServer{}
+	c := NewProductGRPCClient(serveGRPC(t, func(s *grpc.Server) { productpb.RegisterProductInternalServer(s, fake) }))
+
+	resp, err := c.CheckStock(context.Background(), 7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Available || resp.AvailableQty != 3 || resp.RequestedQty != 5 || resp.ProductID != 7 {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	items := []productmodels.ReduceStockRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}
+	if err := c.ReserveStock(context.Background(), 9, items); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.reserved) != 2 || fake.reserved[0].ProductId != 1 || fake.reserved[0].Quantity != 2 {
+		t.Errorf("reserved = %v", fake.reserved)
+	}
+	if fake.orderID != 9 {
+		t.Errorf("order id = %d, want 9", fake.orderID)
+	}
+
+	err = c.ReserveStock(context.Background(), 9, []productmodels.ReduceStockRequest{{ProductID: 1, Quantity: 4}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("err = %v, want FailedPrecondition", err)
+	}
+}
+
+type fakeOrderServer struct {
+	orderpb.UnimplementedOrderInternalServer
+}
+
+func (fakeOrderServer) GetOrder(_ context.Context, req *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	if req.Id != 1 {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	userID := int64(9)
+	return &orderpb.Order{
+		Id: 1, UserId: &userID, Status: "pending", TotalCents: 5999, ShippingCents: 495,
+		Items: []*orderpb.OrderItem{{ProductId: 3, Quantity: 2, PriceCents: 2752}},
+	}, nil
+}
+
+func TestOrderGRPCClient(t *testing.T) {
+	c := NewOrderGRPCClient(serveGRPC(t, func(s *grpc.Server) { orderpb.RegisterOrderInternalServer(s, fakeOrderServer{}) }))
+
+	order, err := c.GetOrder(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.UserID == nil || *order.UserID != 9 || order.TotalCents != 5999 || order.GuestEmail != nil {
+		t.Errorf("order = %+v", order)
+	}
+	if len(order.Items) != 1 || order.Items[0].ProductID != 3 || order.Items[0].OrderID != 1 {
+		t.Errorf("items = %+v", order.Items)
+	}
+
+	if _, err := c.GetOrder(context.Background(), 2); status.Code(err) != codes.NotFound {
+		t.Errorf("err = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+)
+
+// OrderClient calls the order-service
+type OrderClient struct {
+	http *httpclient.Client
+}
+
+// NewOrderClient creates an order-service client on top of an HTTP client
+func NewOrderClient(c *httpclient.Client) *OrderClient {
+	return &OrderClient{http: c}
+}
+
+// NewOrderClientFromEnv creates an order-service client configured by httpclient.ConfigFromEnv
+func NewOrderClientFromEnv() *OrderClient {
+	return NewOrderClient(httpclient.NewFromEnv("order-service"))
+}
+
+// GetOrder returns an order of the user identified by token
+func (c *OrderClient) GetOrder(ctx context.Context, token string, orderID int64) (*models.Order, error) {
+	var order models.Order
+	if err := c.http.GetJSON(ctx, fmt.Sprintf("/orders/%d", orderID), &order, bearer(token)); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderInternal returns any order without user context, e.g. guest orders (internal)
+func (c *OrderClient) GetOrderInternal(ctx context.Context, orderID int64) (*models.Order, error) {
+	var order models.Order
+	if err := c.http.GetJSON(ctx, fmt.Sprintf("/internal/orders/%d", orderID), &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateStatus sets the status of an order (internal). Setting a status the order already has is a no-op
+// there, so the call is retried.
+func (c *OrderClient) UpdateStatus(ctx context.Context, orderID int64, status string) error {
+	path := fmt.Sprintf("/internal/orders/%d/status", orderID)
+	return c.http.DoJSON(ctx, http.MethodPatch, path, models.UpdateStatusRequest{Status: status}, nil, httpclient.Idempotent())
+}
@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/pb/orderpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+
+	"google.golang.org/grpc"
+)
+
+// OrderGRPCClient calls the internal gRPC API of the order-service
+type OrderGRPCClient struct {
+	rpc orderpb.OrderInternalClient
+}
+
+// NewOrderGRPCClient creates an order-service client on top of a gRPC connection
+func NewOrderGRPCClient(conn grpc.ClientConnInterface) *OrderGRPCClient {
+	return &OrderGRPCClient{rpc: orderpb.NewOrderInternalClient(conn)}
+}
+
+// NewOrderGRPCClientFromEnv creates an order-service client connected by rpc.Dial. Both calls are retried: setting
+// the same status twice has no further effect.
+func NewOrderGRPCClientFromEnv() *OrderGRPCClient {
+	return NewOrderGRPCClient(rpc.MustDial("order-service", rpc.WithRetries("order.v1.OrderInternal", "GetOrder", "UpdateOrderStatus")))
+}
+
+// GetOrder returns any order without user context; callers check order.UserID themselves. The order carries its
+// amounts, status and items only.
+func (c *OrderGRPCClient) GetOrder(ctx context.Context, orderID int64) (*models.Order, error) {
+	resp, err := c.rpc.GetOrder(ctx, &orderpb.GetOrderRequest{Id: orderID})
+	if err != nil {
+		return nil, err
+	}
+	return orderFromProto(resp), nil
+}
+
+// UpdateStatus confirms or cancels an order
+func (c *OrderGRPCClient) UpdateStatus(ctx context.Context, orderID int64, status string) error {
+	_, err := c.rpc.UpdateOrderStatus(ctx, &orderpb.UpdateOrderStatusRequest{Id: orderID, Status: status})
+	return err
+}
+
+func orderFromProto(o *orderpb.Order) *models.Order {
+	order := &models.Order{
+		ID:            o.GetId(),
+		UserID:        o.UserId,
+		GuestEmail:    o.GuestEmail,
+		Status:        o.GetStatus(),
+		SubtotalCents: int(o.GetSubtotalCents()),
+		ShippingCents: int(o.GetShippingCents()),
+		DiscountCents: int(o.GetDiscountCents()),
+		TotalCents:    int(o.GetTotalCents()),
+	}
+	for _, item := range o.GetItems() {
+		order.Items = append(order.Items, models.OrderItem{
+			OrderID:     o.GetId(),
+			ProductID:   item.GetProductId(),
+			Quantity:    int(item.GetQuantity()),
+			PriceCents:  int(item.GetPriceCents()),
+			ProductName: item.GetProductName(),
+		})
+	}
+	return order
+}
@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+)
+
+// PaymentClient calls the payment-service
+type PaymentClient struct {
+	http *httpclient.Client
+}
+
+// NewPaymentClient creates a payment-service client on top of an HTTP client
+func NewPaymentClient(c *httpclient.Client) *PaymentClient {
+	return &PaymentClient{http: c}
+}
+
+// NewPaymentClientFromEnv creates a payment-service client configured by httpclient.ConfigFromEnv
+func NewPaymentClientFromEnv() *PaymentClient {
+	return NewPaymentClient(httpclient.NewFromEnv("payment-service"))
+}
+
+// CreateRefund refunds part of the order's payment (internal). Refunds for a return are created only once
+// by the payment-service, so only those calls are retried.
+func (c *PaymentClient) CreateRefund(ctx context.Context, req models.CreateRefundRequest) (*models.Refund, error) {
+	var opts []httpclient.Option
+	if req.ReturnID != nil {
+		opts = append(opts, httpclient.Idempotent())
+	}
+
+	var refund models.Refund
+	if err := c.http.PostJSON(ctx, "/internal/refunds", req, &refund, opts...); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// CancelOrderPayment cancels the open payment intent of a cancelled order, if it has one (internal). Cancelling twice
+// has no further effect, so the call is retried.
+func (c *PaymentClient) CancelOrderPayment(ctx context.Context, orderID int64) error {
+	return c.http.PostJSON(ctx, fmt.Sprintf("/internal/orders/%d/payment/cancel", orderID), nil, nil, httpclient.Idempotent())
+}
@@ -0,0 +1,53 @@
+package clients
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/services/product-service/models"
+)
+
+// ProductClient calls the product-service
+type ProductClient struct {
+	http *httpclient.Client
+}
+
+// NewProductClient creates a product-service client on top of an HTTP client
+func NewProductClient(c *httpclient.Client) *ProductClient {
+	return &ProductClient{http: c}
+}
+
+// NewProductClientFromEnv creates a product-service client configured by httpclient.ConfigFromEnv
+func NewProductClientFromEnv() *ProductClient {
+	return NewProductClient(httpclient.NewFromEnv("product-service"))
+}
+
+// CheckStock reports whether quantity items of a product are in stock. The check only reads, so it is retried.
+func (c *ProductClient) CheckStock(ctx context.Context, productID int64, quantity int) (*models.CheckStockResponse, error) {
+	var resp models.CheckStockResponse
+	req := models.CheckStockRequest{ProductID: productID, Quantity: quantity}
+	if err := c.http.PostJSON(ctx, "/products/stock/check", req, &resp, httpclient.Idempotent()); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReduceStock takes sold items out of stock (internal). Not retried, a repeated call would reduce the stock twice.
+func (c *ProductClient) ReduceStock(ctx context.Context, productID int64, quantity int) error {
+	return c.http.PostJSON(ctx, "/internal/products/stock/reduce", models.ReduceStockRequest{ProductID: productID, Quantity: quantity}, nil)
+}
+
+// Restock puts items back into stock (internal). Not retried, like ReduceStock.
+func (c *ProductClient) Restock(ctx context.Context, productID int64, quantity int) error {
+	return c.http.PostJSON(ctx, "/internal/products/stock/restock", models.RestockRequest{ProductID: productID, Quantity: quantity}, nil)
+}
+
+// CreateCategory creates a category; token must belong to an admin
+func (c *ProductClient) CreateCategory(ctx context.Context, token string, category *models.Category) error {
+	return c.http.PostJSON(ctx, "/admin/categories/create", category, nil, bearer(token))
+}
+
+// CreateProduct creates a product with its categories; token must belong to an admin
+func (c *ProductClient) CreateProduct(ctx context.Context, token string, product *models.CreateProductRequest) error {
+	return c.http.PostJSON(ctx, "/admin/products/create", product, nil, bearer(token))
+}
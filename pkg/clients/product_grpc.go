@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/pb/productpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/services/product-service/models"
+
+	"google.golang.org/grpc"
+)
+
+// ProductGRPCClient calls the internal gRPC API of the product-service
+type ProductGRPCClient struct {
+	rpc productpb.ProductInternalClient
+}
+
+// NewProductGRPCClient creates a product-service client on top of a gRPC connection
+func NewProductGRPCClient(conn grpc.ClientConnInterface) *ProductGRPCClient {
+	return &ProductGRPCClient{rpc: productpb.NewProductInternalClient(conn)}
+}
+
+// NewProductGRPCClientFromEnv creates a product-service client connected by rpc.Dial. Only the stock check is
+// retried, the other calls change the stock.
+func NewProductGRPCClientFromEnv() *ProductGRPCClient {
+	return NewProductGRPCClient(rpc.MustDial("product-service", rpc.WithRetries("product.v1.ProductInternal", "CheckStock")))
+}
+
+// CheckStock reports whether quantity items of a product are in stock
+func (c *ProductGRPCClient) CheckStock(ctx context.Context, productID int64, quantity int) (*models.CheckStockResponse, error) {
+	resp, err := c.rpc.CheckStock(ctx, &productpb.CheckStockRequest{ProductId: productID, Quantity: int32(quantity)})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CheckStockResponse{
+		Available:    resp.GetAvailable(),
+		RequestedQty: int(resp.GetRequestedQty()),
+		AvailableQty: int(resp.GetAvailableQty()),
+		ProductID:    resp.GetProductId(),
+	}, nil
+}
+
+// ReserveStock takes all items of an order out of stock, or none of them if one is short (codes.FailedPrecondition).
+// A repeated call for the same order takes nothing, so it is safe to retry.
+func (c *ProductGRPCClient) ReserveStock(ctx context.Context, orderID int64, items []models.ReduceStockRequest) error {
+	req := &productpb.ReserveStockRequest{OrderId: orderID}
+	for _, item := range items {
+		req.Items = append(req.Items, &productpb.StockItem{ProductId: item.ProductID, Quantity: int32(item.Quantity)})
+	}
+	_, err := c.rpc.ReserveStock(ctx, req)
+	return err
+}
+
+// ReduceStock takes sold items of a single product out of stock
+func (c *ProductGRPCClient) ReduceStock(ctx context.Context, productID int64, quantity int) error {
+	_, err := c.rpc.ReduceStock(ctx, &productpb.StockItem{ProductId: productID, Quantity: int32(quantity)})
+	return err
+}
+
+// Restock puts items back into stock
+func (c *ProductGRPCClient) Restock(ctx context.Context, productID int64, quantity int) error {
+	_, err := c.rpc.Restock(ctx, &productpb.StockItem{ProductId: productID, Quantity: int32(quantity)})
+	return err
+}
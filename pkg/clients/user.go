@@ -0,0 +1,60 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	"rearatrox/go-ecommerce-backend/services/user-service/models"
+)
+
+// UserClient calls the user-service
+type UserClient struct {
+	http *httpclient.Client
+}
+
+// NewUserClient creates a user-service client on top of an HTTP client
+func NewUserClient(c *httpclient.Client) *UserClient {
+	return &UserClient{http: c}
+}
+
+// NewUserClientFromEnv creates a user-service client configured by httpclient.ConfigFromEnv
+func NewUserClientFromEnv() *UserClient {
+	return NewUserClient(httpclient.NewFromEnv("user-service"))
+}
+
+// Signup registers a user
+func (c *UserClient) Signup(ctx context.Context, user *models.User) error {
+	return c.http.PostJSON(ctx, "/auth/signup", user, nil)
+}
+
+// Login returns the Authorization header value ("Bearer ...") for the credentials
+func (c *UserClient) Login(ctx context.Context, email, password string) (string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.http.PostJSON(ctx, "/auth/login", models.User{Email: email, Password: password}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// CreateAddress saves an address of the user identified by token and returns it as stored (normalized)
+func (c *UserClient) CreateAddress(ctx context.Context, token string, address *models.Address) (*models.Address, error) {
+	var resp struct {
+		Address models.Address `json:"address"`
+	}
+	if err := c.http.PostJSON(ctx, "/users/me/addresses", address, &resp, bearer(token)); err != nil {
+		return nil, err
+	}
+	return &resp.Address, nil
+}
+
+// GetAddress returns an address of the user identified by token
+func (c *UserClient) GetAddress(ctx context.Context, token string, addressID int64) (*models.Address, error) {
+	var address models.Address
+	if err := c.http.GetJSON(ctx, fmt.Sprintf("/users/me/addresses/%d", addressID), &address, bearer(token)); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
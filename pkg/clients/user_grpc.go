@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/pb/userpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/services/user-service/models"
+
+	"google.golang.org/grpc"
+)
+
+// UserGRPCClient calls the internal gRPC API of the user-service
+type UserGRPCClient struct {
+	rpc userpb.UserInternalClient
+}
+
+// NewUserGRPCClient creates a user-service client on top of a gRPC connection
+func NewUserGRPCClient(conn grpc.ClientConnInterface) *UserGRPCClient {
+	return &UserGRPCClient{rpc: userpb.NewUserInternalClient(conn)}
+}
+
+// NewUserGRPCClientFromEnv creates a user-service client connected by rpc.Dial; the address lookup is retried
+func NewUserGRPCClientFromEnv() *UserGRPCClient {
+	return NewUserGRPCClient(rpc.MustDial("user-service", rpc.WithRetries("user.v1.UserInternal", "GetAddress")))
+}
+
+// GetAddress returns a saved address of the user; addresses of other users are not found (codes.NotFound)
+func (c *UserGRPCClient) GetAddress(ctx context.Context, userID, addressID int64) (*models.Address, error) {
+	resp, err := c.rpc.GetAddress(ctx, &userpb.GetAddressRequest{UserId: userID, AddressId: addressID})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Address{
+		ID:         resp.GetId(),
+		UserID:     resp.GetUserId(),
+		FullName:   resp.GetFullName(),
+		Street:     resp.GetStreet(),
+		PostalCode: resp.GetPostalCode(),
+		City:       resp.GetCity(),
+		Country:    resp.GetCountry(),
+		Type:       resp.GetType(),
+		IsDefault:  resp.GetIsDefault(),
+	}, nil
+}
@@ -2,28 +2,72 @@ package db
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
+	"rearatrox/go-ecommerce-backend/pkg/env"
+
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var DB *pgxpool.Pool
-var Ctx = context.Background()
 
-func InitDB() {
+const (
+	defaultConnectTimeout = time.Minute
+	maxConnectBackoff     = 5 * time.Second
+)
 
-	var err error
+// Connect opens DB for DATABASE_URL. The database is pinged until it answers, waiting longer after every failed
+// attempt, for up to DB_CONNECT_TIMEOUT (Go duration, default 1m), so a service can start before Postgres accepts
+// connections.
+func Connect(ctx context.Context) error {
+	config, err := pgxpool.ParseConfig(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+	// every query gets a span in the trace of the request running it
+	config.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
 
-	DB, err = pgxpool.New(Ctx, os.Getenv("DATABASE_URL"))
+	timeout, err := env.Duration("DB_CONNECT_TIMEOUT", defaultConnectTimeout)
+	if err != nil {
+		return err
+	}
 
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		panic("could not connect to the database")
+		return fmt.Errorf("could not create database pool: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err = pool.Ping(ctx)
+		if err == nil {
+			break
+		}
+		slog.Warn("database not reachable, retrying", "attempt", attempt, "wait", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			pool.Close()
+			return fmt.Errorf("database not reachable after %s: %w", timeout, errors.Join(err, ctx.Err()))
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxConnectBackoff)
 	}
 
-	// Verify the connection
-	if err := DB.Ping(Ctx); err != nil {
-		log.Fatal("Unable to ping database:", err)
+	DB = pool
+	slog.Info("successfully connected to database")
+	return nil
+}
+
+// Close closes all connections of DB; queries still running are waited for
+func Close() {
+	if DB != nil {
+		DB.Close()
 	}
-	log.Println("successfully connected to database")
 }
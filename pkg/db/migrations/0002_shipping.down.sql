@@ -0,0 +1,11 @@
+-- Rollback: Remove shipping zones, methods and order shipping columns
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_cents;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_name;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal_cents;
+
+DROP TABLE IF EXISTS shipping_methods CASCADE;
+DROP TABLE IF EXISTS shipping_zones CASCADE;
+
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
@@ -0,0 +1,68 @@
+-- Shipping zones, methods and shipping cost on orders
+
+-- =====================================================
+-- PRODUCTS: weight for weight-based shipping rates
+-- =====================================================
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+
+-- =====================================================
+-- SHIPPING_ZONES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS shipping_zones (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL UNIQUE,
+  -- Country codes or names matched case-insensitively against the address country
+  countries TEXT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+-- =====================================================
+-- SHIPPING_METHODS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS shipping_methods (
+  id BIGSERIAL PRIMARY KEY,
+  zone_id BIGINT NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+  code VARCHAR(50) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  type VARCHAR(20) NOT NULL CHECK (type IN ('flat', 'weight', 'free_threshold')),
+  rate_cents INTEGER NOT NULL DEFAULT 0 CHECK (rate_cents >= 0),
+  per_kg_cents INTEGER NOT NULL DEFAULT 0 CHECK (per_kg_cents >= 0),
+  free_above_cents INTEGER CHECK (free_above_cents >= 0),
+  max_weight_grams INTEGER CHECK (max_weight_grams > 0),
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ,
+  CHECK (type <> 'free_threshold' OR free_above_cents IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_shipping_methods_zone_code ON shipping_methods(zone_id, code);
+CREATE INDEX idx_shipping_methods_active ON shipping_methods(active);
+
+-- Seed default zones and methods
+INSERT INTO shipping_zones (name, countries)
+VALUES
+  ('Germany', ARRAY['DE', 'Germany', 'Deutschland']),
+  ('European Union', ARRAY['AT', 'Austria', 'BE', 'Belgium', 'FR', 'France', 'IT', 'Italy', 'LU', 'Luxembourg', 'NL', 'Netherlands', 'PL', 'Poland', 'ES', 'Spain'])
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO shipping_methods (zone_id, code, name, type, rate_cents, per_kg_cents, free_above_cents)
+SELECT z.id, m.code, m.name, m.type, m.rate_cents, m.per_kg_cents, m.free_above_cents
+FROM shipping_zones z
+JOIN (VALUES
+  ('Germany', 'standard', 'Standard Shipping', 'free_threshold', 495, 0, 5000),
+  ('Germany', 'express', 'Express Shipping', 'weight', 995, 150, NULL),
+  ('European Union', 'standard', 'EU Standard Shipping', 'weight', 995, 200, NULL)
+) AS m(zone_name, code, name, type, rate_cents, per_kg_cents, free_above_cents) ON m.zone_name = z.name
+ON CONFLICT (zone_id, code) DO NOTHING;
+
+-- =====================================================
+-- ORDERS: chosen shipping method and cost
+-- =====================================================
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal_cents INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method_id BIGINT REFERENCES shipping_methods(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method_name VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_cents INTEGER NOT NULL DEFAULT 0 CHECK (shipping_cents >= 0);
+
+-- Existing orders had no shipping cost, so their total is the subtotal
+UPDATE orders SET subtotal_cents = total_cents WHERE subtotal_cents = 0;
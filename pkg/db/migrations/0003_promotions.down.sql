@@ -0,0 +1,9 @@
+-- Rollback: Remove coupons, redemptions and discounts
+
+DROP TABLE IF EXISTS coupon_redemptions CASCADE;
+DROP TABLE IF EXISTS order_discounts CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_cents;
+ALTER TABLE carts DROP COLUMN IF EXISTS coupon_id;
+DROP TABLE IF EXISTS coupon_categories CASCADE;
+DROP TABLE IF EXISTS coupon_products CASCADE;
+DROP TABLE IF EXISTS coupons CASCADE;
@@ -0,0 +1,81 @@
+-- Coupons, promotion scoping, redemptions and discounts on carts and orders
+
+-- =====================================================
+-- COUPONS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS coupons (
+  id BIGSERIAL PRIMARY KEY,
+  code VARCHAR(50) NOT NULL,
+  description TEXT,
+  type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'free_shipping')),
+  -- percent (1-100) for percentage coupons, cents for fixed coupons, unused for free_shipping
+  value INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0),
+  min_order_cents INTEGER NOT NULL DEFAULT 0 CHECK (min_order_cents >= 0),
+  max_uses INTEGER CHECK (max_uses > 0),
+  max_uses_per_user INTEGER CHECK (max_uses_per_user > 0),
+  starts_at TIMESTAMPTZ,
+  ends_at TIMESTAMPTZ,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ,
+  CHECK (type <> 'percentage' OR value BETWEEN 1 AND 100),
+  CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+-- Codes are case-insensitive
+CREATE UNIQUE INDEX idx_coupons_code ON coupons(upper(code));
+
+-- =====================================================
+-- COUPON SCOPING (empty = applies to all products)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS coupon_products (
+  coupon_id BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+  product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (coupon_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS coupon_categories (
+  coupon_id BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+  category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (coupon_id, category_id)
+);
+
+-- =====================================================
+-- CARTS: applied coupon
+-- =====================================================
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS coupon_id BIGINT REFERENCES coupons(id) ON DELETE SET NULL;
+
+-- =====================================================
+-- ORDERS: discount total and discount lines
+-- =====================================================
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_cents INTEGER NOT NULL DEFAULT 0 CHECK (discount_cents >= 0);
+
+CREATE TABLE IF NOT EXISTS order_discounts (
+  id BIGSERIAL PRIMARY KEY,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  coupon_id BIGINT REFERENCES coupons(id) ON DELETE SET NULL,
+  code VARCHAR(50) NOT NULL,
+  type VARCHAR(20) NOT NULL,
+  description TEXT,
+  amount_cents INTEGER NOT NULL CHECK (amount_cents >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
+
+-- =====================================================
+-- COUPON_REDEMPTIONS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+  id BIGSERIAL PRIMARY KEY,
+  coupon_id BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  discount_cents INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  -- set when the order is cancelled so the usage counts again
+  released_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_coupon_redemptions_coupon_order ON coupon_redemptions(coupon_id, order_id);
+CREATE INDEX idx_coupon_redemptions_active ON coupon_redemptions(coupon_id, user_id) WHERE released_at IS NULL;
@@ -0,0 +1,24 @@
+-- Rollback: Remove guest checkout (guest carts, orders and payments are deleted)
+
+DELETE FROM payments WHERE user_id IS NULL;
+DELETE FROM coupon_redemptions WHERE user_id IS NULL;
+DELETE FROM orders WHERE user_id IS NULL;
+DELETE FROM carts WHERE user_id IS NULL;
+
+DROP INDEX IF EXISTS idx_coupon_redemptions_guest_email;
+ALTER TABLE coupon_redemptions DROP COLUMN IF EXISTS guest_email;
+ALTER TABLE coupon_redemptions ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE payments ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_customer_check;
+ALTER TABLE orders DROP COLUMN IF EXISTS billing_address;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_address;
+ALTER TABLE orders DROP COLUMN IF EXISTS guest_email;
+ALTER TABLE orders ALTER COLUMN user_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_carts_guest_active;
+UPDATE carts SET status = 'abandoned' WHERE status = 'merged';
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_status_check;
+ALTER TABLE carts ADD CONSTRAINT carts_status_check CHECK (status IN ('active', 'ordered', 'abandoned'));
+ALTER TABLE carts ALTER COLUMN user_id SET NOT NULL;
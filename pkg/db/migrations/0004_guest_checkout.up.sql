@@ -0,0 +1,28 @@
+-- Guest checkout: anonymous carts, guest orders with inline addresses and guest payments
+
+-- =====================================================
+-- CARTS: anonymous carts (identified by a signed cart token) and merged status
+-- =====================================================
+ALTER TABLE carts ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_status_check;
+ALTER TABLE carts ADD CONSTRAINT carts_status_check CHECK (status IN ('active', 'ordered', 'abandoned', 'merged'));
+
+CREATE INDEX IF NOT EXISTS idx_carts_guest_active ON carts(id) WHERE user_id IS NULL AND status = 'active';
+
+-- =====================================================
+-- ORDERS: guest email and inline address snapshots
+-- =====================================================
+ALTER TABLE orders ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS guest_email TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS billing_address JSONB;
+ALTER TABLE orders ADD CONSTRAINT orders_customer_check CHECK (user_id IS NOT NULL OR guest_email IS NOT NULL);
+
+-- =====================================================
+-- PAYMENTS / COUPON_REDEMPTIONS: guest customers
+-- =====================================================
+ALTER TABLE payments ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE coupon_redemptions ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE coupon_redemptions ADD COLUMN IF NOT EXISTS guest_email TEXT;
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_guest_email ON coupon_redemptions(coupon_id, lower(guest_email)) WHERE released_at IS NULL;
@@ -0,0 +1,7 @@
+-- Rollback: Remove abandoned cart tracking
+
+DROP INDEX IF EXISTS idx_carts_abandoned_at;
+DROP INDEX IF EXISTS idx_carts_active_idle;
+ALTER TABLE carts DROP COLUMN IF EXISTS recovered_at;
+ALTER TABLE carts DROP COLUMN IF EXISTS notified_at;
+ALTER TABLE carts DROP COLUMN IF EXISTS abandoned_at;
@@ -0,0 +1,14 @@
+-- Abandoned cart detection, recovery notifications and restore tracking
+
+-- =====================================================
+-- CARTS: abandonment lifecycle
+-- =====================================================
+-- set by the cart-service job when an active cart was idle for longer than ABANDONED_CART_AFTER
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS abandoned_at TIMESTAMPTZ;
+-- set once a recovery notification was sent for the cart
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS notified_at TIMESTAMPTZ;
+-- set when an abandoned cart is reactivated (restore link or returning customer)
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS recovered_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_carts_active_idle ON carts(COALESCE(updated_at, created_at)) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_carts_abandoned_at ON carts(abandoned_at) WHERE abandoned_at IS NOT NULL;
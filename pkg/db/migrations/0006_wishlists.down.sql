@@ -0,0 +1,4 @@
+-- Rollback: Remove wishlists
+
+DROP TABLE IF EXISTS wishlist_items CASCADE;
+DROP TABLE IF EXISTS wishlists CASCADE;
@@ -0,0 +1,38 @@
+-- Named wishlists, save-for-later list, shareable links and price/stock alerts
+
+-- =====================================================
+-- WISHLISTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS wishlists (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  -- 'saved_for_later' holds items moved out of the cart, one per user
+  kind VARCHAR(20) NOT NULL DEFAULT 'wishlist' CHECK (kind IN ('wishlist', 'saved_for_later')),
+  -- random token for the public read-only link, NULL when not shared
+  share_token TEXT UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_wishlists_user_id ON wishlists(user_id);
+CREATE UNIQUE INDEX idx_wishlists_user_name ON wishlists(user_id, lower(name)) WHERE kind = 'wishlist';
+CREATE UNIQUE INDEX idx_wishlists_user_saved ON wishlists(user_id) WHERE kind = 'saved_for_later';
+
+-- =====================================================
+-- WISHLIST_ITEMS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS wishlist_items (
+  id BIGSERIAL PRIMARY KEY,
+  wishlist_id BIGINT NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+  product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+  -- last price and availability the customer was told about, baseline for price drop / back in stock alerts
+  alert_price_cents INTEGER NOT NULL,
+  alert_in_stock BOOLEAN NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_wishlist_items_list_product ON wishlist_items(wishlist_id, product_id);
+CREATE INDEX idx_wishlist_items_product_id ON wishlist_items(product_id);
@@ -0,0 +1,8 @@
+-- Rollback: Remove shipments
+
+DROP TABLE IF EXISTS shipment_items CASCADE;
+DROP TABLE IF EXISTS shipments CASCADE;
+
+UPDATE orders SET status = 'shipped' WHERE status = 'partially_shipped';
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'confirmed', 'shipped', 'delivered', 'cancelled'));
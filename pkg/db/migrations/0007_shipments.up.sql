@@ -0,0 +1,38 @@
+-- Order fulfilment: shipments with carrier and tracking, shipped line items, partially shipped orders
+
+-- =====================================================
+-- ORDERS: partially shipped status
+-- =====================================================
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'confirmed', 'partially_shipped', 'shipped', 'delivered', 'cancelled'));
+
+-- =====================================================
+-- SHIPMENTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS shipments (
+  id BIGSERIAL PRIMARY KEY,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  carrier VARCHAR(50) NOT NULL,
+  tracking_number TEXT,
+  tracking_url TEXT,
+  status VARCHAR(20) NOT NULL DEFAULT 'shipped' CHECK (status IN ('shipped', 'in_transit', 'delivered')),
+  shipped_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_shipments_order_id ON shipments(order_id);
+
+-- =====================================================
+-- SHIPMENT_ITEMS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS shipment_items (
+  id BIGSERIAL PRIMARY KEY,
+  shipment_id BIGINT NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+  order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE UNIQUE INDEX idx_shipment_items_shipment_item ON shipment_items(shipment_id, order_item_id);
+CREATE INDEX idx_shipment_items_order_item_id ON shipment_items(order_item_id);
@@ -0,0 +1,6 @@
+-- Rollback: Remove returns and refunds
+
+DROP TABLE IF EXISTS refunds CASCADE;
+DROP TABLE IF EXISTS return_status_history CASCADE;
+DROP TABLE IF EXISTS return_items CASCADE;
+DROP TABLE IF EXISTS returns CASCADE;
@@ -0,0 +1,77 @@
+-- Returns (RMA): customer return requests for shipped order lines, inspection, restocking and refunds
+
+-- =====================================================
+-- RETURNS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS returns (
+  id BIGSERIAL PRIMARY KEY,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(20) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'approved', 'rejected', 'received', 'refunded', 'closed')),
+  refund_cents INTEGER NOT NULL DEFAULT 0 CHECK (refund_cents >= 0),
+  refund_id BIGINT, -- refunds.id of the payment-service refund
+  note TEXT,        -- latest admin note shown to the customer
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_returns_order_id ON returns(order_id);
+CREATE INDEX idx_returns_user_id ON returns(user_id);
+CREATE INDEX idx_returns_status ON returns(status);
+
+-- =====================================================
+-- RETURN_ITEMS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS return_items (
+  id BIGSERIAL PRIMARY KEY,
+  return_id BIGINT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+  order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  reason VARCHAR(30) NOT NULL CHECK (reason IN ('damaged', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other')),
+  comment TEXT,
+  -- inspection results, set when the items are received
+  accepted_quantity INTEGER CHECK (accepted_quantity >= 0 AND accepted_quantity <= quantity),
+  condition VARCHAR(20) CHECK (condition IN ('resellable', 'opened', 'damaged')),
+  restock BOOLEAN NOT NULL DEFAULT FALSE,
+  restocked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_return_items_return_item ON return_items(return_id, order_item_id);
+CREATE INDEX idx_return_items_order_item_id ON return_items(order_item_id);
+
+-- =====================================================
+-- RETURN_STATUS_HISTORY TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS return_status_history (
+  id BIGSERIAL PRIMARY KEY,
+  return_id BIGINT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+  from_status VARCHAR(20),
+  to_status VARCHAR(20) NOT NULL,
+  note TEXT,
+  actor_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_return_status_history_return_id ON return_status_history(return_id);
+
+-- =====================================================
+-- REFUNDS TABLE (payment-service)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS refunds (
+  id BIGSERIAL PRIMARY KEY,
+  payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  return_id BIGINT REFERENCES returns(id) ON DELETE SET NULL,
+  amount_cents INTEGER NOT NULL CHECK (amount_cents > 0),
+  currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+  status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+  reason TEXT,
+  stripe_refund_id VARCHAR(255) UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+-- at most one active refund per return
+CREATE UNIQUE INDEX idx_refunds_return_id ON refunds(return_id) WHERE status <> 'failed';
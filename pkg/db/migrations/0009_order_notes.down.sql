@@ -0,0 +1,5 @@
+-- Rollback: Remove order notes and search indexes
+
+DROP INDEX IF EXISTS idx_orders_total_cents;
+DROP INDEX IF EXISTS idx_orders_created_at;
+DROP TABLE IF EXISTS order_notes CASCADE;
@@ -0,0 +1,20 @@
+-- Admin order console: internal staff notes on orders, indexes for order search
+
+-- =====================================================
+-- ORDER_NOTES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS order_notes (
+  id BIGSERIAL PRIMARY KEY,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  author_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_notes_order_id ON order_notes(order_id);
+
+-- =====================================================
+-- ORDERS: search by date and total
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_total_cents ON orders(total_cents);
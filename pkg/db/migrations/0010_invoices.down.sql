@@ -0,0 +1,4 @@
+-- Rollback: Remove invoices
+
+DROP TABLE IF EXISTS invoices CASCADE;
+DROP TABLE IF EXISTS invoice_sequences CASCADE;
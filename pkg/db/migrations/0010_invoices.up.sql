@@ -0,0 +1,36 @@
+-- Invoices and credit notes: gapless yearly numbering, rendered PDF and e-invoice XML
+
+-- =====================================================
+-- INVOICE_SEQUENCES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+  kind VARCHAR(20) NOT NULL,
+  year INTEGER NOT NULL,
+  last_number BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (kind, year)
+);
+
+-- =====================================================
+-- INVOICES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS invoices (
+  id BIGSERIAL PRIMARY KEY,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  kind VARCHAR(20) NOT NULL CHECK (kind IN ('invoice', 'credit_note')),
+  number VARCHAR(30) NOT NULL UNIQUE,
+  invoice_id BIGINT REFERENCES invoices(id) ON DELETE CASCADE, -- invoice corrected by a credit note
+  refund_id BIGINT REFERENCES refunds(id) ON DELETE SET NULL,  -- refund documented by a credit note
+  currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+  vat_rate INTEGER NOT NULL,
+  net_cents INTEGER NOT NULL,
+  tax_cents INTEGER NOT NULL,
+  total_cents INTEGER NOT NULL,
+  pdf BYTEA NOT NULL,
+  xml BYTEA, -- CII e-invoice, NULL if e-invoicing is disabled
+  issued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_invoices_order_id ON invoices(order_id);
+-- one invoice per order, one credit note per refund
+CREATE UNIQUE INDEX idx_invoices_order_invoice ON invoices(order_id) WHERE kind = 'invoice';
+CREATE UNIQUE INDEX idx_invoices_refund_id ON invoices(refund_id) WHERE refund_id IS NOT NULL;
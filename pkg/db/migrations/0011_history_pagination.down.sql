@@ -0,0 +1,4 @@
+-- Rollback: Remove history pagination indexes
+
+DROP INDEX IF EXISTS idx_payments_user_created;
+DROP INDEX IF EXISTS idx_orders_user_created;
@@ -0,0 +1,4 @@
+-- Cursor pagination of order and payment history: newest first per user, id breaks ties
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_created ON orders(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_payments_user_created ON payments(user_id, created_at DESC, id DESC);
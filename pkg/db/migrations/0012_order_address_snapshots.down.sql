@@ -0,0 +1,25 @@
+-- Rollback: Customer orders read their saved addresses again, guest snapshots get their zipCode key back
+-- Not fully reversible: the state, id and isDefault keys dropped from guest snapshots cannot be restored,
+-- and customer snapshots are kept where the saved address has been deleted since
+
+-- =====================================================
+-- ORDERS: drop the snapshots of customer orders whose saved address still exists
+-- =====================================================
+UPDATE orders
+SET shipping_address = NULL
+WHERE guest_email IS NULL AND shipping_address_id IS NOT NULL;
+
+UPDATE orders
+SET billing_address = NULL
+WHERE guest_email IS NULL AND billing_address_id IS NOT NULL;
+
+-- =====================================================
+-- ORDERS: guest snapshots use the zipCode key again
+-- =====================================================
+UPDATE orders
+SET shipping_address = (shipping_address - 'postalCode') || jsonb_build_object('zipCode', shipping_address->>'postalCode')
+WHERE guest_email IS NOT NULL AND shipping_address ? 'postalCode';
+
+UPDATE orders
+SET billing_address = (billing_address - 'postalCode') || jsonb_build_object('zipCode', billing_address->>'postalCode')
+WHERE guest_email IS NOT NULL AND billing_address ? 'postalCode';
@@ -0,0 +1,29 @@
+-- Immutable address snapshots on all orders: order history no longer depends on the saved addresses
+
+-- =====================================================
+-- ORDERS: guest snapshots use the postalCode key of the saved addresses
+-- =====================================================
+UPDATE orders
+SET shipping_address = (shipping_address - 'zipCode' - 'state' - 'id' - 'isDefault')
+                       || jsonb_build_object('postalCode', shipping_address->>'zipCode')
+WHERE shipping_address ? 'zipCode';
+
+UPDATE orders
+SET billing_address = (billing_address - 'zipCode' - 'state' - 'id' - 'isDefault')
+                      || jsonb_build_object('postalCode', billing_address->>'zipCode')
+WHERE billing_address ? 'zipCode';
+
+-- =====================================================
+-- ORDERS: snapshot the saved addresses of existing customer orders
+-- =====================================================
+UPDATE orders o
+SET shipping_address = jsonb_build_object(
+      'fullName', a.full_name, 'street', a.street, 'postalCode', a.postal_code, 'city', a.city, 'country', a.country)
+FROM addresses a
+WHERE a.id = o.shipping_address_id AND o.shipping_address IS NULL;
+
+UPDATE orders o
+SET billing_address = jsonb_build_object(
+      'fullName', a.full_name, 'street', a.street, 'postalCode', a.postal_code, 'city', a.city, 'country', a.country)
+FROM addresses a
+WHERE a.id = o.billing_address_id AND o.billing_address IS NULL;
@@ -0,0 +1,3 @@
+-- Rollback: Remove pending payment index
+
+DROP INDEX IF EXISTS idx_payments_pending;
@@ -0,0 +1,3 @@
+-- Expiry of stale payments: the expiry job pages through pending payments by id
+
+CREATE INDEX IF NOT EXISTS idx_payments_pending ON payments(id) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- Rollback: Remove payment reconciliation report
+
+DROP TABLE IF EXISTS payment_discrepancies;
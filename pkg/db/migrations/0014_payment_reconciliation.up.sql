@@ -0,0 +1,16 @@
+-- Payment reconciliation: differences between local payment state and Stripe found by the reconciliation job
+
+CREATE TABLE IF NOT EXISTS payment_discrepancies (
+  id BIGSERIAL PRIMARY KEY,
+  payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+  order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  stripe_payment_intent_id VARCHAR(255) NOT NULL,
+  local_status VARCHAR(20) NOT NULL,    -- payments.status before the correction
+  provider_status VARCHAR(40) NOT NULL, -- payment intent status at Stripe
+  corrected_status VARCHAR(20),         -- payments.status after the correction, NULL if it failed
+  error TEXT,                           -- why the correction or the order update failed
+  detected_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_payment_discrepancies_detected ON payment_discrepancies(detected_at DESC, id DESC);
+CREATE INDEX idx_payment_discrepancies_payment_id ON payment_discrepancies(payment_id);
@@ -0,0 +1,15 @@
+-- Idempotency keys: stored responses of POST requests sent with an Idempotency-Key header, replayed on retries
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  scope VARCHAR(100) NOT NULL,  -- caller the key belongs to: user, guest token hash or client IP
+  key VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL, -- SHA-256 of method, path and body of the first request
+  response_status INTEGER,
+  response_headers JSONB,
+  response_body BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ,      -- NULL while the first request is running
+  PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);
@@ -0,0 +1,3 @@
+-- Rollback: Remove rate limit buckets
+
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,10 @@
+-- Rate limit buckets: token buckets shared by all instances of a service (RATE_LIMIT_STORE=postgres)
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+  key VARCHAR(255) PRIMARY KEY,     -- policy and caller, e.g. login|ip:203.0.113.7
+  tokens DOUBLE PRECISION NOT NULL, -- requests left at updated_at
+  updated_at TIMESTAMPTZ NOT NULL,
+  full_at TIMESTAMPTZ NOT NULL      -- bucket is full again and can be deleted
+);
+
+CREATE INDEX idx_rate_limit_buckets_full ON rate_limit_buckets(full_at);
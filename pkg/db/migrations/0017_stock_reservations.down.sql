@@ -0,0 +1,5 @@
+-- Rollback: Remove stock reservations
+
+ALTER TABLE orders DROP COLUMN IF EXISTS stock_returned;
+
+DROP TABLE IF EXISTS stock_reservations;
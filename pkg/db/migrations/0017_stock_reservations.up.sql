@@ -0,0 +1,10 @@
+-- Stock reservations: order confirmation takes stock outside the order row lock, so reservations are keyed by order
+-- and a retried confirmation takes nothing twice
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+  order_id BIGINT PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- set once the stock reserved for a cancelled order is put back, so only one caller returns it
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS stock_returned BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+-- Rollback: Remove idempotency leases
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,3 @@
+-- Idempotency leases: a request in progress holds its key only until locked_until, then a retry can take it over
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ; -- NULL once completed
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Duration reads a positive Go duration (e.g. 15m) from the environment variable key. An unset or empty variable
+// yields fallback.
+func Duration(key string, fallback time.Duration) (time.Duration, error) {
+	return parseDuration(key, fallback, false)
+}
+
+// NonNegativeDuration is Duration accepting 0 as well, for settings where 0 turns something off
+func NonNegativeDuration(key string, fallback time.Duration) (time.Duration, error) {
+	return parseDuration(key, fallback, true)
+}
+
+func parseDuration(key string, fallback time.Duration, allowZero bool) (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		if allowZero {
+			return 0, fmt.Errorf("invalid %s %q: must be a non-negative duration", key, v)
+		}
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration", key, v)
+	}
+	return d, nil
+}
@@ -0,0 +1,40 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", time.Minute, false},
+		{" 15m ", 15 * time.Minute, false},
+		{"0", 0, true},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATION", tt.value)
+		got, err := Duration("TEST_DURATION", time.Minute)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Duration(%q) = %v, %v, want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNonNegativeDuration(t *testing.T) {
+	t.Setenv("TEST_DURATION", "0")
+	if got, err := NonNegativeDuration("TEST_DURATION", time.Minute); err != nil || got != 0 {
+		t.Errorf("NonNegativeDuration(0) = %v, %v, want 0", got, err)
+	}
+
+	t.Setenv("TEST_DURATION", "-1s")
+	if _, err := NonNegativeDuration("TEST_DURATION", time.Minute); err == nil {
+		t.Error("expected negative duration to be rejected")
+	}
+}
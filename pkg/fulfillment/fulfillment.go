@@ -0,0 +1,124 @@
+package fulfillment
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// Shipment statuses
+const (
+	StatusShipped   = "shipped"    // handed over to the carrier
+	StatusInTransit = "in_transit" // carrier reported progress
+	StatusDelivered = "delivered"  // carrier reported delivery
+)
+
+// Order statuses derived from shipments
+const (
+	OrderPartiallyShipped = "partially_shipped"
+	OrderShipped          = "shipped"
+	OrderDelivered        = "delivered"
+)
+
+// Carrier is a shipping carrier with its public tracking page
+type Carrier struct {
+	Code        string `json:"code" example:"dhl"`
+	Name        string `json:"name" example:"DHL"`
+	TrackingURL string `json:"-"` // fmt template receiving the escaped tracking number
+}
+
+// CarrierOther is used for carriers without a known tracking page; a tracking URL can be passed explicitly
+const CarrierOther = "other"
+
+var carriers = map[string]Carrier{
+	"dhl":        {Code: "dhl", Name: "DHL", TrackingURL: "https://www.dhl.com/de-en/home/tracking/tracking-parcel.html?tracking-id=%s"},
+	"dpd":        {Code: "dpd", Name: "DPD", TrackingURL: "https://tracking.dpd.de/status/en_US/parcel/%s"},
+	"hermes":     {Code: "hermes", Name: "Hermes", TrackingURL: "https://www.myhermes.de/empfangen/sendungsverfolgung/sendungsinformation/#%s"},
+	"gls":        {Code: "gls", Name: "GLS", TrackingURL: "https://gls-group.com/DE/en/parcel-tracking?match=%s"},
+	"ups":        {Code: "ups", Name: "UPS", TrackingURL: "https://www.ups.com/track?tracknum=%s"},
+	"fedex":      {Code: "fedex", Name: "FedEx", TrackingURL: "https://www.fedex.com/fedextrack/?trknbr=%s"},
+	CarrierOther: {Code: CarrierOther, Name: "Other"},
+}
+
+// Carriers returns all known carriers sorted by code
+func Carriers() []Carrier {
+	list := make([]Carrier, 0, len(carriers))
+	for _, c := range carriers {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// LookupCarrier returns the carrier for a code
+func LookupCarrier(code string) (Carrier, bool) {
+	c, ok := carriers[code]
+	return c, ok
+}
+
+// TrackingLink returns the public tracking page for a parcel, empty if the carrier has none
+func (c Carrier) TrackingLink(trackingNumber string) string {
+	if c.TrackingURL == "" || trackingNumber == "" {
+		return ""
+	}
+	return fmt.Sprintf(c.TrackingURL, url.QueryEscape(trackingNumber))
+}
+
+// ShipmentError explains why a shipment cannot be created
+type ShipmentError struct {
+	Reason string
+}
+
+func (e *ShipmentError) Error() string {
+	return "invalid shipment: " + e.Reason
+}
+
+// Remaining returns the quantity per order item that has not been shipped yet (zero entries are omitted)
+func Remaining(ordered, shipped map[int64]int) map[int64]int {
+	remaining := make(map[int64]int, len(ordered))
+	for itemID, qty := range ordered {
+		if left := qty - shipped[itemID]; left > 0 {
+			remaining[itemID] = left
+		}
+	}
+	return remaining
+}
+
+// CheckShipment validates the requested quantities per order item against what is left to ship.
+// ordered and shipped hold the quantities per order item across all previous shipments.
+func CheckShipment(ordered, shipped, requested map[int64]int) error {
+	if len(requested) == 0 {
+		return &ShipmentError{Reason: "nothing left to ship"}
+	}
+	remaining := Remaining(ordered, shipped)
+	for itemID, qty := range requested {
+		if _, ok := ordered[itemID]; !ok {
+			return &ShipmentError{Reason: fmt.Sprintf("order item %d does not belong to the order", itemID)}
+		}
+		if qty <= 0 {
+			return &ShipmentError{Reason: fmt.Sprintf("quantity of order item %d must be positive", itemID)}
+		}
+		if qty > remaining[itemID] {
+			return &ShipmentError{Reason: fmt.Sprintf("order item %d has only %d left to ship", itemID, remaining[itemID])}
+		}
+	}
+	return nil
+}
+
+// OrderStatus derives the fulfilment status of an order from its shipments: delivered once everything
+// is shipped and every shipment is delivered, shipped once everything is shipped, partially_shipped while
+// items are missing. Returns an empty string if nothing has been shipped yet.
+func OrderStatus(ordered, shipped map[int64]int, shipmentStatuses []string) string {
+	if len(shipmentStatuses) == 0 {
+		return ""
+	}
+	if len(Remaining(ordered, shipped)) > 0 {
+		return OrderPartiallyShipped
+	}
+	for _, s := range shipmentStatuses {
+		if s != StatusDelivered {
+			return OrderShipped
+		}
+	}
+	return OrderDelivered
+}
@@ -0,0 +1,75 @@
+package fulfillment
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckShipment(t *testing.T) {
+	ordered := map[int64]int{1: 2, 2: 1}
+	shipped := map[int64]int{1: 1}
+
+	tests := []struct {
+		name      string
+		requested map[int64]int
+		wantErr   bool
+	}{
+		{"remaining quantities", map[int64]int{1: 1, 2: 1}, false},
+		{"partial", map[int64]int{2: 1}, false},
+		{"empty", map[int64]int{}, true},
+		{"foreign item", map[int64]int{3: 1}, true},
+		{"more than remaining", map[int64]int{1: 2}, true},
+		{"zero quantity", map[int64]int{2: 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckShipment(ordered, shipped, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckShipment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var shipmentErr *ShipmentError
+			if err != nil && !errors.As(err, &shipmentErr) {
+				t.Errorf("expected *ShipmentError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestOrderStatus(t *testing.T) {
+	ordered := map[int64]int{1: 2, 2: 1}
+
+	tests := []struct {
+		name     string
+		shipped  map[int64]int
+		statuses []string
+		want     string
+	}{
+		{"nothing shipped", map[int64]int{}, nil, ""},
+		{"partial", map[int64]int{1: 2}, []string{StatusShipped}, OrderPartiallyShipped},
+		{"partial delivered", map[int64]int{1: 1}, []string{StatusDelivered}, OrderPartiallyShipped},
+		{"split shipped", map[int64]int{1: 2, 2: 1}, []string{StatusDelivered, StatusInTransit}, OrderShipped},
+		{"all delivered", map[int64]int{1: 2, 2: 1}, []string{StatusDelivered, StatusDelivered}, OrderDelivered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrderStatus(ordered, tt.shipped, tt.statuses); got != tt.want {
+				t.Errorf("OrderStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackingLink(t *testing.T) {
+	dhl, ok := LookupCarrier("dhl")
+	if !ok {
+		t.Fatal("dhl carrier missing")
+	}
+	if got := dhl.TrackingLink("AB 12"); got != "https://www.dhl.com/de-en/home/tracking/tracking-parcel.html?tracking-id=AB+12" {
+		t.Errorf("unexpected tracking link %q", got)
+	}
+
+	other, _ := LookupCarrier(CarrierOther)
+	if got := other.TrackingLink("123"); got != "" {
+		t.Errorf("expected no tracking link for other carrier, got %q", got)
+	}
+}
@@ -0,0 +1,110 @@
+package guesttoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of resources a guest token can grant access to
+const (
+	KindCart        = "cart"
+	KindOrder       = "order"
+	KindCartRestore = "cart_restore" // restore links in abandoned cart notifications
+)
+
+// Headers used to pass guest tokens between client and services
+const (
+	CartHeader  = "X-Cart-Token"
+	OrderHeader = "X-Order-Token"
+)
+
+// DefaultTTL is used when GUEST_TOKEN_TTL is not set
+const DefaultTTL = 30 * 24 * time.Hour
+
+var (
+	ErrMalformed = errors.New("malformed guest token")
+	ErrSignature = errors.New("invalid guest token signature")
+	ErrExpired   = errors.New("guest token has expired")
+	ErrNoSecret  = errors.New("GUEST_TOKEN_SECRET or JWT_SECRET must be set to sign guest tokens")
+)
+
+// secret returns GUEST_TOKEN_SECRET and falls back to JWT_SECRET
+func secret() []byte {
+	if s := os.Getenv("GUEST_TOKEN_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// CheckSecret returns ErrNoSecret if neither GUEST_TOKEN_SECRET nor JWT_SECRET is set; anyone could forge tokens
+// signed with an empty key. Services handling guest tokens call it at startup.
+func CheckSecret() error {
+	if len(secret()) == 0 {
+		return ErrNoSecret
+	}
+	return nil
+}
+
+// ttl reads GUEST_TOKEN_TTL as a Go duration (e.g. 720h)
+func ttl() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("GUEST_TOKEN_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTTL
+}
+
+func signature(key []byte, kind, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Sign creates a token of the form <id>.<expiresUnix>.<signature> for a resource id
+func Sign(kind string, id int64) string {
+	return sign(secret(), kind, id, time.Now().Add(ttl()))
+}
+
+func sign(key []byte, kind string, id int64, expires time.Time) string {
+	payload := strconv.FormatInt(id, 10) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + signature(key, kind, payload)
+}
+
+// Verify checks signature and expiry of a token and returns the resource id
+func Verify(kind, token string) (int64, error) {
+	return verify(secret(), kind, token, time.Now())
+}
+
+func verify(key []byte, kind, token string, now time.Time) (int64, error) {
+	if len(key) == 0 {
+		return 0, ErrNoSecret
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrMalformed
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signature(key, kind, payload))) {
+		return 0, ErrSignature
+	}
+
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	if now.Unix() >= expires {
+		return 0, ErrExpired
+	}
+
+	return id, nil
+}
@@ -0,0 +1,62 @@
+package guesttoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	key := []byte("test-secret")
+	now := time.Now()
+	token := sign(key, KindCart, 42, now.Add(time.Hour))
+
+	id, err := verify(key, KindCart, token, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("id = %d, want 42", id)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key := []byte("test-secret")
+	now := time.Now()
+	token := sign(key, KindCart, 42, now.Add(time.Hour))
+
+	tests := []struct {
+		name  string
+		key   []byte
+		kind  string
+		token string
+		now   time.Time
+		want  error
+	}{
+		{"other kind", key, KindOrder, token, now, ErrSignature},
+		{"other key", []byte("other"), KindCart, token, now, ErrSignature},
+		{"tampered id", key, KindCart, "43" + token[2:], now, ErrSignature},
+		{"expired", key, KindCart, token, now.Add(2 * time.Hour), ErrExpired},
+		{"malformed", key, KindCart, "garbage", now, ErrMalformed},
+		{"empty key", nil, KindCart, sign(nil, KindCart, 42, now.Add(time.Hour)), now, ErrNoSecret},
+	}
+
+	for _, tt := range tests {
+		if _, err := verify(tt.key, tt.kind, tt.token, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestCheckSecret(t *testing.T) {
+	t.Setenv("GUEST_TOKEN_SECRET", "")
+	t.Setenv("JWT_SECRET", "")
+	if err := CheckSecret(); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("err = %v, want %v", err, ErrNoSecret)
+	}
+
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	if err := CheckSecret(); err != nil {
+		t.Errorf("unexpected error with JWT_SECRET fallback: %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+)
+
+var (
+	ErrCircuitOpen     = errors.New("circuit breaker open")
+	errRetryableStatus = errors.New("retryable status")
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultRetries          = 2
+	defaultRetryBackoff     = 200 * time.Millisecond
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	maxErrorBodyLength      = 512
+	idempotencyKeyHeader    = "Idempotency-Key"
+	internalPathPrefix      = "/internal/"
+)
+
+// Config configures a client for one downstream service
+type Config struct {
+	Service          string              // e.g. product-service; used in errors and logs
+	BaseURL          string              // scheme, host and API prefix, e.g. http://product-service:8080/api/v1
+	Timeout          time.Duration       // per attempt
+	Retries          int                 // additional attempts for idempotent requests
+	RetryBackoff     time.Duration       // wait before the first retry, doubled for every further retry
+	BreakerThreshold int                 // consecutive failures that open the circuit; 0 disables the breaker
+	BreakerCooldown  time.Duration       // time the circuit stays open before a trial request is let through
+	Signer           *serviceauth.Signer // signs requests to /internal/ paths; nil if the service makes no internal calls
+}
+
+// ConfigFromEnv builds the config for a service from the environment. The base URL is read from
+// <SERVICE>_URL (e.g. PRODUCT_SERVICE_URL for product-service) and defaults to the docker compose host
+// http://<service>:8080; API_PREFIX is appended in both cases. Timeouts, retries and the breaker are shared
+// settings of all clients: HTTP_CLIENT_TIMEOUT, HTTP_CLIENT_RETRIES, HTTP_CLIENT_RETRY_BACKOFF,
+// HTTP_CLIENT_BREAKER_THRESHOLD and HTTP_CLIENT_BREAKER_COOLDOWN. Internal requests are signed with
+// serviceauth.SignerFromEnv. Invalid values are reported in the error and replaced by their defaults, so the
+// returned config is always usable.
+func ConfigFromEnv(service string) (Config, error) {
+	cfg := Config{
+		Service:          service,
+		Timeout:          defaultTimeout,
+		Retries:          defaultRetries,
+		RetryBackoff:     defaultRetryBackoff,
+		BreakerThreshold: defaultBreakerThreshold,
+		BreakerCooldown:  defaultBreakerCooldown,
+	}
+
+	apiPrefix := strings.TrimSpace(os.Getenv("API_PREFIX"))
+	if apiPrefix == "" {
+		apiPrefix = "/api/v1"
+	}
+	cfg.BaseURL = ServiceURL(service) + apiPrefix
+
+	var errs []error
+	signer, err := serviceauth.SignerFromEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	cfg.Signer = signer
+	cfg.Timeout = durationFromEnv("HTTP_CLIENT_TIMEOUT", cfg.Timeout, &errs)
+	cfg.Retries = intFromEnv("HTTP_CLIENT_RETRIES", cfg.Retries, &errs)
+	cfg.RetryBackoff = durationFromEnv("HTTP_CLIENT_RETRY_BACKOFF", cfg.RetryBackoff, &errs)
+	cfg.BreakerThreshold = intFromEnv("HTTP_CLIENT_BREAKER_THRESHOLD", cfg.BreakerThreshold, &errs)
+	cfg.BreakerCooldown = durationFromEnv("HTTP_CLIENT_BREAKER_COOLDOWN", cfg.BreakerCooldown, &errs)
+	return cfg, errors.Join(errs...)
+}
+
+// EnvName returns the environment variable holding the base URL of a service, e.g. PRODUCT_SERVICE_URL
+func EnvName(service string) string {
+	return strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_URL"
+}
+
+// ServiceURL returns the base URL of a service without API_PREFIX, read from <SERVICE>_URL and defaulting to the
+// docker compose host http://<service>:8080
+func ServiceURL(service string) string {
+	baseURL := os.Getenv(EnvName(service))
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:8080", service)
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+func durationFromEnv(key string, fallback time.Duration, errs *[]error) time.Duration {
+	d, err := env.NonNegativeDuration(key, fallback)
+	if err != nil {
+		*errs = append(*errs, err)
+		return fallback
+	}
+	return d
+}
+
+func intFromEnv(key string, fallback int, errs *[]error) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		*errs = append(*errs, fmt.Errorf("invalid %s %q", key, v))
+		return fallback
+	}
+	return n
+}
+
+// Client calls one downstream service. It is safe for concurrent use.
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a client from a config
+func New(cfg Config) *Client {
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		sleep:   sleepContext,
+	}
+}
+
+// NewFromEnv creates a client for a service configured by ConfigFromEnv. Invalid settings are logged and
+// replaced by their defaults, so a typo does not take down a service at startup.
+func NewFromEnv(service string) *Client {
+	cfg, err := ConfigFromEnv(service)
+	if err != nil {
+		logger.WithAttrs("service", service).Error("invalid http client config, using defaults", "error", err)
+	}
+	return New(cfg)
+}
+
+// Service returns the name of the downstream service
+func (c *Client) Service() string {
+	return c.cfg.Service
+}
+
+// Option adjusts a single request
+type Option func(*request)
+
+type request struct {
+	header     http.Header
+	idempotent bool
+	signer     *serviceauth.Signer
+}
+
+// WithHeader sets a request header, e.g. Authorization to forward the caller's JWT
+func WithHeader(key, value string) Option {
+	return func(r *request) { r.header.Set(key, value) }
+}
+
+// Idempotent marks a request with a non-idempotent method as safe to retry, e.g. a POST that only reads
+// or one the downstream service deduplicates
+func Idempotent() Option {
+	return func(r *request) { r.idempotent = true }
+}
+
+// Response is a completed response with its body already read
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode unmarshals the JSON body into v
+func (r *Response) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// StatusError is returned by the JSON helpers for responses outside 2xx
+type StatusError struct {
+	Service    string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Service, e.StatusCode, e.Body)
+}
+
+// StatusCode returns the status of a StatusError in err's chain, or 0
+func StatusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// Do sends a request to path (relative to the base URL) with body encoded as JSON when not nil.
+// Requests to /internal/ paths are signed by the service's serviceauth.Signer, and the request id and
+// trace context of ctx are forwarded.
+// GET, HEAD, PUT, DELETE and OPTIONS requests, requests with an Idempotency-Key and requests marked
+// Idempotent are retried with exponential backoff on network errors and 502, 503 and 504 responses.
+// Any response is returned without error; use the JSON helpers to treat non-2xx statuses as errors.
+func (c *Client) Do(ctx context.Context, method, path string, body any, opts ...Option) (*Response, error) {
+	r := &request{header: http.Header{}}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		r.header.Set("Content-Type", "application/json")
+	}
+	if strings.HasPrefix(path, internalPathPrefix) {
+		if c.cfg.Signer == nil {
+			return nil, serviceauth.ErrNoServiceKey
+		}
+		r.signer = c.cfg.Signer
+	}
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		r.header.Set(logger.RequestIDHeader(), id)
+	}
+
+	attempts := 1
+	if r.idempotent || isIdempotent(method) || r.header.Get(idempotencyKeyHeader) != "" {
+		attempts += c.cfg.Retries
+	}
+
+	l := logger.FromContext(ctx)
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			wait := backoff(c.cfg.RetryBackoff, attempt)
+			l.Warn("retrying service call", "service", c.cfg.Service, "method", method, "path", path, "attempt", attempt+1, "wait", wait, "error", lastErr)
+			if err := c.sleep(ctx, wait); err != nil {
+				return nil, fmt.Errorf("failed to call %s: %w", c.cfg.Service, lastErr)
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, payload, r)
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, fmt.Errorf("failed to call %s: %w", c.cfg.Service, err)
+		}
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err == nil {
+			// keep the last response so callers see the real status once retries are exhausted
+			if attempt == attempts-1 {
+				return resp, nil
+			}
+			err = fmt.Errorf("%w %d", errRetryableStatus, resp.StatusCode)
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("failed to call %s: %w", c.cfg.Service, lastErr)
+}
+
+// send performs a single attempt through the circuit breaker
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, r *request) (*Response, error) {
+	if !c.breaker.allow() {
+		metrics.ObserveCall(c.cfg.Service, method, metrics.ResultCircuitOpen, true, 0)
+		return nil, ErrCircuitOpen
+	}
+
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, bodyReader)
+	if err != nil {
+		// a request that was never sent says nothing about the downstream service
+		c.breaker.release()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	// signed per attempt, a nonce is accepted only once
+	if r.signer != nil {
+		r.signer.SignRequest(req, payload)
+	}
+
+	start := time.Now()
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		metrics.ObserveCall(c.cfg.Service, method, metrics.ResultError, true, time.Since(start))
+		// a cancelled caller says nothing about the health of the downstream service
+		if ctx.Err() != nil {
+			c.breaker.release()
+		} else {
+			c.breaker.record(true)
+		}
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		metrics.ObserveCall(c.cfg.Service, method, metrics.ResultError, true, time.Since(start))
+		c.breaker.record(true)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	failed := httpResp.StatusCode >= http.StatusInternalServerError
+	metrics.ObserveCall(c.cfg.Service, method, strconv.Itoa(httpResp.StatusCode), failed, time.Since(start))
+	c.breaker.record(failed)
+
+	return &Response{StatusCode: httpResp.StatusCode, Header: httpResp.Header, Body: respBody}, nil
+}
+
+// GetJSON sends a GET request and decodes a 2xx JSON response into out
+func (c *Client) GetJSON(ctx context.Context, path string, out any, opts ...Option) error {
+	return c.DoJSON(ctx, http.MethodGet, path, nil, out, opts...)
+}
+
+// PostJSON sends body as a POST request and decodes a 2xx JSON response into out (skipped if out is nil)
+func (c *Client) PostJSON(ctx context.Context, path string, body, out any, opts ...Option) error {
+	return c.DoJSON(ctx, http.MethodPost, path, body, out, opts...)
+}
+
+// DoJSON is Do for JSON APIs: non-2xx responses are returned as *StatusError and a 2xx body is decoded
+// into out unless out is nil
+func (c *Client) DoJSON(ctx context.Context, method, path string, body, out any, opts ...Option) error {
+	resp, err := c.Do(ctx, method, path, body, opts...)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := string(resp.Body)
+		if len(msg) > maxErrorBodyLength {
+			msg = msg[:maxErrorBodyLength]
+		}
+		return &StatusError{Service: c.cfg.Service, StatusCode: resp.StatusCode, Body: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := resp.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.cfg.Service, err)
+	}
+	return nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// backoff doubles the base wait per retry and adds up to 50% jitter so callers do not retry in lockstep
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	wait := base << (attempt - 1)
+	return wait + time.Duration(rand.Int63n(int64(wait)/2+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
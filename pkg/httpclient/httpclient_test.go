@@ -0,0 +1,239 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+)
+
+func newTestClient(url string, retries, threshold int) *Client {
+	c := New(Config{
+		Service:          "test-service",
+		BaseURL:          url,
+		Timeout:          time.Second,
+		Retries:          retries,
+		BreakerThreshold: threshold,
+		BreakerCooldown:  time.Minute,
+		Signer:           serviceauth.NewSigner("order-service", []byte("key")),
+	})
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+	return c
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PRODUCT_SERVICE_URL", "http://localhost:8082/")
+	t.Setenv("API_PREFIX", "/api/v2")
+	t.Setenv("HTTP_CLIENT_TIMEOUT", "3s")
+	t.Setenv("HTTP_CLIENT_RETRIES", "nope")
+
+	cfg, err := ConfigFromEnv("product-service")
+	if err == nil {
+		t.Fatal("expected error for invalid HTTP_CLIENT_RETRIES")
+	}
+	if cfg.BaseURL != "http://localhost:8082/api/v2" {
+		t.Errorf("BaseURL = %q", cfg.BaseURL)
+	}
+	if cfg.Timeout != 3*time.Second || cfg.Retries != defaultRetries {
+		t.Errorf("Timeout = %v, Retries = %d", cfg.Timeout, cfg.Retries)
+	}
+
+	t.Setenv("HTTP_CLIENT_RETRIES", "")
+	cfg, err = ConfigFromEnv("user-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "http://user-service:8080/api/v2" {
+		t.Errorf("default BaseURL = %q", cfg.BaseURL)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	verifier := serviceauth.NewVerifier(map[string][]byte{"order-service": []byte("key")}, time.Minute)
+	var got http.Header
+	var verifyErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = verifier.Verify(got.Get(serviceauth.CallerHeader), got.Get(serviceauth.TimestampHeader),
+			got.Get(serviceauth.NonceHeader), got.Get(serviceauth.SignatureHeader), r.Method, r.URL.RequestURI(), "", "", body)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 0, 0)
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	if err := c.PostJSON(ctx, "/internal/things", map[string]int{"a": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if verifyErr != nil {
+		t.Errorf("signature: %v", verifyErr)
+	}
+	if got.Get(logger.RequestIDHeader()) != "req-1" {
+		t.Errorf("request id = %q", got.Get(logger.RequestIDHeader()))
+	}
+	if got.Get("Content-Type") != "application/json" {
+		t.Errorf("content type = %q", got.Get("Content-Type"))
+	}
+
+	if err := c.GetJSON(context.Background(), "/things", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get(serviceauth.SignatureHeader) != "" {
+		t.Error("signature sent to public path")
+	}
+
+	c.cfg.Signer = nil
+	if err := c.GetJSON(context.Background(), "/internal/things", nil); !errors.Is(err, serviceauth.ErrNoServiceKey) {
+		t.Errorf("err = %v, want ErrNoServiceKey", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 2, 0)
+
+	var out struct{ OK bool }
+	if err := c.GetJSON(context.Background(), "/things", &out); err != nil || !out.OK {
+		t.Fatalf("GetJSON = %v, %+v", err, out)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+
+	// POST without Idempotency-Key is not retried
+	calls.Store(0)
+	err := c.PostJSON(context.Background(), "/things", nil, nil)
+	if StatusCode(err) != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("POST: err = %v, calls = %d", err, calls.Load())
+	}
+
+	calls.Store(0)
+	if err := c.PostJSON(context.Background(), "/things", nil, nil, WithHeader("Idempotency-Key", "k")); err != nil {
+		t.Errorf("POST with Idempotency-Key: %v", err)
+	}
+	calls.Store(0)
+	if err := c.PostJSON(context.Background(), "/things", nil, nil, Idempotent()); err != nil {
+		t.Errorf("POST marked idempotent: %v", err)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "missing", http.StatusNotFound)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 2, 1)
+
+	err := c.GetJSON(context.Background(), "/things", nil)
+	if StatusCode(err) != http.StatusNotFound || calls.Load() != 1 {
+		t.Errorf("err = %v, calls = %d", err, calls.Load())
+	}
+	// 4xx responses do not count against the breaker
+	if !c.breaker.allow() {
+		t.Error("breaker opened on 404")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	healthy := atomic.Bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 0, 2)
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := c.GetJSON(context.Background(), "/things", nil); StatusCode(err) != http.StatusInternalServerError {
+			t.Fatalf("call %d: err = %v", i, err)
+		}
+	}
+	if err := c.GetJSON(context.Background(), "/things", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+
+	// after the cooldown a successful trial call closes the circuit
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+	if err := c.GetJSON(context.Background(), "/things", nil); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if err := c.GetJSON(context.Background(), "/things", nil); err != nil {
+		t.Fatalf("after close: %v", err)
+	}
+}
+
+func TestBreakerSingleTrial(t *testing.T) {
+	b := newBreaker(1, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.record(true)
+	if b.allow() {
+		t.Fatal("allowed while open")
+	}
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("trial call rejected after cooldown")
+	}
+	if b.allow() {
+		t.Fatal("second call allowed while trial is in flight")
+	}
+	b.record(true)
+	if b.allow() {
+		t.Fatal("allowed after failed trial")
+	}
+}
+
+func TestBreakerReleaseKeepsFailures(t *testing.T) {
+	b := newBreaker(2, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.record(true)
+	// a cancelled call neither resets nor adds to the failure count
+	b.allow()
+	b.release()
+	b.record(true)
+	if b.allow() {
+		t.Fatal("allowed after threshold failures around a cancelled call")
+	}
+
+	// a cancelled trial call frees the slot for the next trial
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("trial call rejected after cooldown")
+	}
+	b.release()
+	if !b.allow() {
+		t.Fatal("trial call rejected after a cancelled trial")
+	}
+}
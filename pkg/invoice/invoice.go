@@ -0,0 +1,181 @@
+package invoice
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Document kinds
+const (
+	KindInvoice    = "invoice"
+	KindCreditNote = "credit_note"
+)
+
+// DefaultVATRate is the VAT rate in percent contained in all prices unless INVOICE_VAT_RATE is set
+const DefaultVATRate = 19
+
+// Party is the seller or buyer of an invoice
+type Party struct {
+	Name       string
+	Street     string
+	PostalCode string
+	City       string
+	Country    string // ISO 3166-1 alpha-2
+	VATID      string
+	Email      string
+}
+
+// Line is an invoiced item at its gross (VAT inclusive) price
+type Line struct {
+	Description string
+	Quantity    int
+	UnitCents   int
+}
+
+// TotalCents returns the gross line amount
+func (l Line) TotalCents() int {
+	return l.Quantity * l.UnitCents
+}
+
+// Document is an invoice or credit note. All amounts are gross prices in cents as charged in the shop;
+// credit notes carry positive amounts, the kind says they are credited.
+type Document struct {
+	Kind          string
+	Number        string
+	IssueDate     time.Time
+	OrderID       int64
+	RefersTo      string // invoice number a credit note corrects
+	Currency      string
+	VATRate       int // percent
+	Seller        Party
+	Buyer         Party
+	Lines         []Line
+	ShippingCents int
+	DiscountCents int
+}
+
+// SubtotalCents returns the sum of all gross line amounts
+func (d *Document) SubtotalCents() int {
+	total := 0
+	for _, l := range d.Lines {
+		total += l.TotalCents()
+	}
+	return total
+}
+
+// TotalCents returns the gross amount payable (or credited): lines plus shipping minus discounts
+func (d *Document) TotalCents() int {
+	return d.SubtotalCents() + d.ShippingCents - d.DiscountCents
+}
+
+// Title returns the document title printed on the PDF
+func (d *Document) Title() string {
+	if d.Kind == KindCreditNote {
+		return "Credit Note"
+	}
+	return "Invoice"
+}
+
+// Totals are the net amounts and VAT of a document as required by e-invoicing
+type Totals struct {
+	LineNetCents  []int // net amount per line
+	LinesNetCents int
+	ShippingNet   int
+	DiscountNet   int
+	NetCents      int // tax basis
+	TaxCents      int // VAT on the tax basis
+	RoundingCents int // difference between net plus VAT and the gross total, caused by rounding
+	GrossCents    int
+}
+
+// Net returns the net part of a gross amount for a VAT rate in percent, rounded half up
+func Net(grossCents, vatRate int) int {
+	return (grossCents*100*2 + (100 + vatRate)) / ((100 + vatRate) * 2)
+}
+
+// Totals splits the gross amounts of the document into net amounts and VAT
+func (d *Document) Totals() Totals {
+	t := Totals{GrossCents: d.TotalCents()}
+	for _, l := range d.Lines {
+		net := Net(l.TotalCents(), d.VATRate)
+		t.LineNetCents = append(t.LineNetCents, net)
+		t.LinesNetCents += net
+	}
+	t.ShippingNet = Net(d.ShippingCents, d.VATRate)
+	t.DiscountNet = Net(d.DiscountCents, d.VATRate)
+	t.NetCents = t.LinesNetCents + t.ShippingNet - t.DiscountNet
+	t.TaxCents = (t.NetCents*d.VATRate*2 + 100) / 200
+	t.RoundingCents = t.GrossCents - t.NetCents - t.TaxCents
+	return t
+}
+
+// FormatNumber returns the document number for a yearly sequence, e.g. INV-2026-000042 or CN-2026-000003
+func FormatNumber(kind string, year int, seq int64) string {
+	prefix := "INV"
+	if kind == KindCreditNote {
+		prefix = "CN"
+	}
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, seq)
+}
+
+// formatAmount renders cents as decimal amount, e.g. 5999 -> "59.99"
+func formatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Config holds the seller details and invoice options
+type Config struct {
+	Seller   Party
+	VATRate  int
+	EInvoice bool // also emit a structured e-invoice (CII XML)
+}
+
+// ConfigFromEnv reads the seller from INVOICE_SELLER_NAME, INVOICE_SELLER_STREET, INVOICE_SELLER_POSTAL_CODE,
+// INVOICE_SELLER_CITY, INVOICE_SELLER_COUNTRY, INVOICE_SELLER_VAT_ID and INVOICE_SELLER_EMAIL, the VAT rate from
+// INVOICE_VAT_RATE (default 19) and EINVOICE_ENABLED (default true)
+func ConfigFromEnv() (Config, error) {
+	c := Config{
+		Seller: Party{
+			Name:       envOr("INVOICE_SELLER_NAME", "go-ecommerce-backend Shop"),
+			Street:     os.Getenv("INVOICE_SELLER_STREET"),
+			PostalCode: os.Getenv("INVOICE_SELLER_POSTAL_CODE"),
+			City:       os.Getenv("INVOICE_SELLER_CITY"),
+			Country:    envOr("INVOICE_SELLER_COUNTRY", "DE"),
+			VATID:      os.Getenv("INVOICE_SELLER_VAT_ID"),
+			Email:      os.Getenv("INVOICE_SELLER_EMAIL"),
+		},
+		VATRate:  DefaultVATRate,
+		EInvoice: true,
+	}
+
+	if v := strings.TrimSpace(os.Getenv("INVOICE_VAT_RATE")); v != "" {
+		rate, err := strconv.Atoi(v)
+		if err != nil || rate < 0 || rate > 100 {
+			return c, fmt.Errorf("invalid INVOICE_VAT_RATE %q", v)
+		}
+		c.VATRate = rate
+	}
+	if v := strings.TrimSpace(os.Getenv("EINVOICE_ENABLED")); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("invalid EINVOICE_ENABLED %q", v)
+		}
+		c.EInvoice = enabled
+	}
+	return c, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
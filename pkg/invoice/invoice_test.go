@@ -0,0 +1,137 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDocument() *Document {
+	return &Document{
+		Kind:      KindInvoice,
+		Number:    FormatNumber(KindInvoice, 2026, 42),
+		IssueDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		OrderID:   7,
+		Currency:  "EUR",
+		VATRate:   19,
+		Seller:    Party{Name: "Shop (Test)", Country: "DE", VATID: "DE123456789"},
+		Buyer:     Party{Name: "Jörg Müller", Street: "Main Street 1", PostalCode: "10115", City: "Berlin", Country: "DE"},
+		Lines: []Line{
+			{Description: "Gaming Laptop", Quantity: 1, UnitCents: 149999},
+			{Description: "Mouse", Quantity: 2, UnitCents: 2999},
+		},
+		ShippingCents: 495,
+		DiscountCents: 1000,
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	if got := FormatNumber(KindInvoice, 2026, 42); got != "INV-2026-000042" {
+		t.Errorf("FormatNumber(invoice) = %q", got)
+	}
+	if got := FormatNumber(KindCreditNote, 2026, 3); got != "CN-2026-000003" {
+		t.Errorf("FormatNumber(credit note) = %q", got)
+	}
+}
+
+func TestNet(t *testing.T) {
+	tests := []struct{ gross, rate, want int }{
+		{119, 19, 100},
+		{5999, 19, 5041}, // 50.411...
+		{495, 19, 416},   // 4.159...
+		{1000, 0, 1000},
+		{107, 7, 100},
+	}
+	for _, tt := range tests {
+		if got := Net(tt.gross, tt.rate); got != tt.want {
+			t.Errorf("Net(%d, %d) = %d, want %d", tt.gross, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestTotals(t *testing.T) {
+	d := testDocument()
+	totals := d.Totals()
+
+	if want := 149999 + 2*2999 + 495 - 1000; totals.GrossCents != want {
+		t.Fatalf("GrossCents = %d, want %d", totals.GrossCents, want)
+	}
+	if totals.NetCents != totals.LinesNetCents+totals.ShippingNet-totals.DiscountNet {
+		t.Errorf("NetCents %d does not add up", totals.NetCents)
+	}
+	if totals.NetCents+totals.TaxCents+totals.RoundingCents != totals.GrossCents {
+		t.Errorf("net %d + tax %d + rounding %d != gross %d", totals.NetCents, totals.TaxCents, totals.RoundingCents, totals.GrossCents)
+	}
+	if totals.RoundingCents < -2 || totals.RoundingCents > 2 {
+		t.Errorf("RoundingCents = %d, expected a few cents at most", totals.RoundingCents)
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	d := testDocument()
+	for i := 0; i < 80; i++ {
+		d.Lines = append(d.Lines, Line{Description: "Cable", Quantity: 1, UnitCents: 999})
+	}
+
+	pdf, err := RenderPDF(d)
+	if err != nil {
+		t.Fatalf("RenderPDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("output is not a PDF file")
+	}
+	if bytes.Contains(pdf, []byte("/Count 1 ")) || !bytes.Contains(pdf, []byte("(Page 2 of ")) {
+		t.Error("expected the line items to continue on further pages")
+	}
+	if !bytes.Contains(pdf, []byte(`(Shop \(Test\))`)) {
+		t.Error("expected escaped seller name")
+	}
+	if !bytes.Contains(pdf, []byte(`J\366rg M\374ller`)) {
+		t.Error("expected WinAnsi encoded buyer name")
+	}
+}
+
+func TestRenderXML(t *testing.T) {
+	d := testDocument()
+	d.Kind = KindCreditNote
+	d.Number = FormatNumber(KindCreditNote, 2026, 1)
+	d.RefersTo = "INV-2026-000042"
+
+	out, err := RenderXML(d)
+	if err != nil {
+		t.Fatalf("RenderXML() error = %v", err)
+	}
+
+	var parsed struct {
+		ID       string `xml:"ExchangedDocument>ID"`
+		TypeCode string `xml:"ExchangedDocument>TypeCode"`
+		Lines    []struct {
+			Amount string `xml:"SpecifiedLineTradeSettlement>SpecifiedTradeSettlementLineMonetarySummation>LineTotalAmount"`
+		} `xml:"SupplyChainTradeTransaction>IncludedSupplyChainTradeLineItem"`
+		Prepaid  string `xml:"SupplyChainTradeTransaction>ApplicableHeaderTradeSettlement>SpecifiedTradeSettlementHeaderMonetarySummation>TotalPrepaidAmount"`
+		RefersTo string `xml:"SupplyChainTradeTransaction>ApplicableHeaderTradeSettlement>InvoiceReferencedDocument>IssuerAssignedID"`
+	}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if parsed.ID != "CN-2026-000001" || parsed.TypeCode != "381" {
+		t.Errorf("document = %q/%q, want CN-2026-000001/381", parsed.ID, parsed.TypeCode)
+	}
+	if len(parsed.Lines) != 2 {
+		t.Errorf("got %d lines, want 2", len(parsed.Lines))
+	}
+	if parsed.Prepaid != formatAmount(d.TotalCents()) {
+		t.Errorf("TotalPrepaidAmount = %q, want %q", parsed.Prepaid, formatAmount(d.TotalCents()))
+	}
+	if parsed.RefersTo != d.RefersTo {
+		t.Errorf("InvoiceReferencedDocument = %q, want %q", parsed.RefersTo, d.RefersTo)
+	}
+
+	d.Kind, d.RefersTo = KindInvoice, ""
+	out, _ = RenderXML(d)
+	if strings.Contains(string(out), "InvoiceReferencedDocument") {
+		t.Error("invoice must not reference another invoice")
+	}
+}
@@ -0,0 +1,259 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page layout in PDF points
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 50
+	marginRight  = 545
+	marginTop    = 790
+	marginBottom = 60
+)
+
+// pdfPage collects the content stream of one page
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (p *pdfPage) text(x, y float64, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (p *pdfPage) textRight(x, y float64, size float64, bold bool, s string) {
+	p.text(x-textWidth(s, size), y, size, bold, s)
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// pdfLayout writes text top to bottom and starts a new page when the current one is full
+type pdfLayout struct {
+	pages []*pdfPage
+	y     float64
+	onNew func(*pdfLayout)
+}
+
+func (l *pdfLayout) page() *pdfPage {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &pdfPage{})
+	l.y = marginTop
+	if l.onNew != nil {
+		l.onNew(l)
+	}
+}
+
+// advance moves down by height, breaking the page if needed, and returns the baseline to write at
+func (l *pdfLayout) advance(height float64) float64 {
+	if l.y-height < marginBottom {
+		l.newPage()
+	}
+	l.y -= height
+	return l.y
+}
+
+// RenderPDF renders the document as a single or multi page A4 PDF using the standard Helvetica fonts
+func RenderPDF(d *Document) ([]byte, error) {
+	t := d.Totals()
+	layout := &pdfLayout{}
+	layout.newPage()
+	p := layout.page()
+
+	// seller and document header
+	y := layout.advance(18)
+	p.text(marginLeft, y, 16, true, d.Seller.Name)
+	p.textRight(marginRight, y, 16, true, d.Title())
+	for _, s := range []string{d.Seller.Street, strings.TrimSpace(d.Seller.PostalCode + " " + d.Seller.City), d.Seller.Country} {
+		if s != "" {
+			p.text(marginLeft, layout.advance(12), 9, false, s)
+		}
+	}
+	layout.advance(16)
+
+	// buyer and document details side by side
+	top := layout.y
+	details := [][2]string{
+		{"Number", d.Number},
+		{"Date", d.IssueDate.Format("2006-01-02")},
+		{"Order", fmt.Sprintf("#%d", d.OrderID)},
+	}
+	if d.RefersTo != "" {
+		details = append(details, [2]string{"Invoice", d.RefersTo})
+	}
+	for i, kv := range details {
+		dy := top - float64(i+1)*13
+		p.text(360, dy, 10, true, kv[0])
+		p.textRight(marginRight, dy, 10, false, kv[1])
+	}
+	for _, s := range []string{d.Buyer.Name, d.Buyer.Street, strings.TrimSpace(d.Buyer.PostalCode + " " + d.Buyer.City), d.Buyer.Country, d.Buyer.Email} {
+		if s != "" {
+			p.text(marginLeft, layout.advance(13), 10, false, s)
+		}
+	}
+	layout.y = min(layout.y, top-float64(len(details))*13)
+	layout.advance(28)
+
+	// line items
+	tableHeader := func(l *pdfLayout) {
+		y := l.advance(14)
+		pg := l.page()
+		pg.text(marginLeft, y, 10, true, "Description")
+		pg.textRight(360, y, 10, true, "Qty")
+		pg.textRight(450, y, 10, true, "Unit price")
+		pg.textRight(marginRight, y, 10, true, "Amount")
+		pg.line(marginLeft, y-4, marginRight, y-4)
+		l.advance(4)
+	}
+	tableHeader(layout)
+	layout.onNew = tableHeader
+
+	for _, line := range d.Lines {
+		y := layout.advance(15)
+		pg := layout.page()
+		pg.text(marginLeft, y, 10, false, truncate(line.Description, 55))
+		pg.textRight(360, y, 10, false, fmt.Sprint(line.Quantity))
+		pg.textRight(450, y, 10, false, formatAmount(line.UnitCents))
+		pg.textRight(marginRight, y, 10, false, formatAmount(line.TotalCents()))
+	}
+	layout.onNew = nil
+
+	// totals
+	y = layout.advance(10)
+	layout.page().line(330, y, marginRight, y)
+	totals := [][2]string{{"Subtotal", formatAmount(d.SubtotalCents())}}
+	if d.ShippingCents != 0 {
+		totals = append(totals, [2]string{"Shipping", formatAmount(d.ShippingCents)})
+	}
+	if d.DiscountCents != 0 {
+		totals = append(totals, [2]string{"Discount", formatAmount(-d.DiscountCents)})
+	}
+	totals = append(totals,
+		[2]string{"Net amount", formatAmount(t.NetCents)},
+		[2]string{fmt.Sprintf("VAT %d%%", d.VATRate), formatAmount(t.TaxCents)},
+	)
+	if t.RoundingCents != 0 {
+		totals = append(totals, [2]string{"Rounding", formatAmount(t.RoundingCents)})
+	}
+	for _, kv := range totals {
+		y := layout.advance(14)
+		layout.page().text(340, y, 10, false, kv[0])
+		layout.page().textRight(marginRight, y, 10, false, kv[1])
+	}
+	y = layout.advance(18)
+	totalLabel := "Total"
+	if d.Kind == KindCreditNote {
+		totalLabel = "Total credited"
+	}
+	layout.page().text(340, y, 11, true, totalLabel)
+	layout.page().textRight(marginRight, y, 11, true, formatAmount(t.GrossCents)+" "+d.Currency)
+
+	// footer on every page
+	for i, pg := range layout.pages {
+		footer := fmt.Sprintf("%s - %s", d.Seller.Name, d.Number)
+		if d.Seller.VATID != "" {
+			footer += " - VAT ID " + d.Seller.VATID
+		}
+		pg.text(marginLeft, 30, 8, false, footer)
+		pg.textRight(marginRight, 30, 8, false, fmt.Sprintf("Page %d of %d", i+1, len(layout.pages)))
+	}
+
+	return writePDF(layout.pages), nil
+}
+
+// writePDF assembles the pages into a PDF file with cross-reference table
+func writePDF(pages []*pdfPage) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3/4 fonts, then page and content stream objects per page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfString encodes text for a PDF string literal in WinAnsiEncoding
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString("\\200")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths holds glyph widths (1/1000 em) for characters used in right aligned columns
+var helveticaWidths = map[rune]float64{
+	' ': 278, '.': 278, ',': 278, '-': 333, '#': 556, '%': 889, '/': 278, ':': 278,
+	'0': 556, '1': 556, '2': 556, '3': 556, '4': 556, '5': 556, '6': 556, '7': 556, '8': 556, '9': 556,
+	'E': 667, 'U': 722, 'R': 722, 'I': 278, 'N': 722, 'V': 667, 'C': 722, 'P': 667,
+	'a': 556, 'e': 556, 'f': 278, 'g': 556, 'i': 222, 'l': 222, 'o': 556, 't': 278, 'y': 500,
+}
+
+// textWidth approximates the rendered width of s in points
+func textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		if gw, ok := helveticaWidths[r]; ok {
+			w += gw
+		} else {
+			w += 556
+		}
+	}
+	return w * size / 1000
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
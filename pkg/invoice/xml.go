@@ -0,0 +1,235 @@
+package invoice
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// EN 16931 guideline of the UN/CEFACT Cross Industry Invoice (ZUGFeRD/Factur-X EN 16931 profile, XRechnung CII)
+const ciiGuideline = "urn:cen.eu:en16931:2017"
+
+// UNTDID 1001 document type codes
+const (
+	typeCodeInvoice    = "380"
+	typeCodeCreditNote = "381"
+)
+
+type ciiInvoice struct {
+	XMLName     xml.Name       `xml:"rsm:CrossIndustryInvoice"`
+	RSM         string         `xml:"xmlns:rsm,attr"`
+	RAM         string         `xml:"xmlns:ram,attr"`
+	UDT         string         `xml:"xmlns:udt,attr"`
+	Context     ciiContext     `xml:"rsm:ExchangedDocumentContext"`
+	Document    ciiDocument    `xml:"rsm:ExchangedDocument"`
+	Transaction ciiTransaction `xml:"rsm:SupplyChainTradeTransaction"`
+}
+
+type ciiContext struct {
+	Guideline string `xml:"ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+}
+
+type ciiDate struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ciiDocument struct {
+	ID        string  `xml:"ram:ID"`
+	TypeCode  string  `xml:"ram:TypeCode"`
+	IssueDate ciiDate `xml:"ram:IssueDateTime>udt:DateTimeString"`
+}
+
+type ciiTransaction struct {
+	Lines      []ciiLine     `xml:"ram:IncludedSupplyChainTradeLineItem"`
+	Agreement  ciiAgreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+	Delivery   struct{}      `xml:"ram:ApplicableHeaderTradeDelivery"`
+	Settlement ciiSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+}
+
+type ciiQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    int    `xml:",chardata"`
+}
+
+type ciiTax struct {
+	CalculatedAmount string `xml:"ram:CalculatedAmount,omitempty"`
+	TypeCode         string `xml:"ram:TypeCode"`
+	BasisAmount      string `xml:"ram:BasisAmount,omitempty"`
+	CategoryCode     string `xml:"ram:CategoryCode"`
+	RatePercent      int    `xml:"ram:RateApplicablePercent"`
+}
+
+type ciiLine struct {
+	LineID     string      `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+	Name       string      `xml:"ram:SpecifiedTradeProduct>ram:Name"`
+	NetPrice   string      `xml:"ram:SpecifiedLineTradeAgreement>ram:NetPriceProductTradePrice>ram:ChargeAmount"`
+	Quantity   ciiQuantity `xml:"ram:SpecifiedLineTradeDelivery>ram:BilledQuantity"`
+	Tax        ciiTax      `xml:"ram:SpecifiedLineTradeSettlement>ram:ApplicableTradeTax"`
+	LineAmount string      `xml:"ram:SpecifiedLineTradeSettlement>ram:SpecifiedTradeSettlementLineMonetarySummation>ram:LineTotalAmount"`
+}
+
+type ciiID struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// ciiParty lists its elements in the order required by the CII schema
+type ciiParty struct {
+	Name            string              `xml:"ram:Name"`
+	PostalCode      string              `xml:"ram:PostalTradeAddress>ram:PostcodeCode,omitempty"`
+	Street          string              `xml:"ram:PostalTradeAddress>ram:LineOne,omitempty"`
+	City            string              `xml:"ram:PostalTradeAddress>ram:CityName,omitempty"`
+	Country         string              `xml:"ram:PostalTradeAddress>ram:CountryID"`
+	Email           *ciiCommunication   `xml:"ram:URIUniversalCommunication,omitempty"`
+	TaxRegistration *ciiTaxRegistration `xml:"ram:SpecifiedTaxRegistration,omitempty"`
+}
+
+type ciiCommunication struct {
+	URI ciiID `xml:"ram:URIID"`
+}
+
+type ciiTaxRegistration struct {
+	ID ciiID `xml:"ram:ID"`
+}
+
+type ciiReferencedDocument struct {
+	ID string `xml:"ram:IssuerAssignedID"`
+}
+
+type ciiAgreement struct {
+	BuyerReference string   `xml:"ram:BuyerReference"`
+	Seller         ciiParty `xml:"ram:SellerTradeParty"`
+	Buyer          ciiParty `xml:"ram:BuyerTradeParty"`
+}
+
+type ciiIndicator struct {
+	Value bool `xml:"udt:Indicator"`
+}
+
+type ciiAllowanceCharge struct {
+	ChargeIndicator ciiIndicator `xml:"ram:ChargeIndicator"`
+	Amount          string       `xml:"ram:ActualAmount"`
+	Reason          string       `xml:"ram:Reason"`
+	Tax             ciiTax       `xml:"ram:CategoryTradeTax"`
+}
+
+type ciiCurrencyAmount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type ciiSummation struct {
+	LineTotal      string            `xml:"ram:LineTotalAmount"`
+	ChargeTotal    string            `xml:"ram:ChargeTotalAmount"`
+	AllowanceTotal string            `xml:"ram:AllowanceTotalAmount"`
+	TaxBasisTotal  string            `xml:"ram:TaxBasisTotalAmount"`
+	TaxTotal       ciiCurrencyAmount `xml:"ram:TaxTotalAmount"`
+	Rounding       string            `xml:"ram:RoundingAmount,omitempty"`
+	GrandTotal     string            `xml:"ram:GrandTotalAmount"`
+	Prepaid        string            `xml:"ram:TotalPrepaidAmount"`
+	DuePayable     string            `xml:"ram:DuePayableAmount"`
+}
+
+type ciiSettlement struct {
+	Currency          string                 `xml:"ram:InvoiceCurrencyCode"`
+	Tax               ciiTax                 `xml:"ram:ApplicableTradeTax"`
+	AllowanceCharges  []ciiAllowanceCharge   `xml:"ram:SpecifiedTradeAllowanceCharge"`
+	Summation         ciiSummation           `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+	ReferencedInvoice *ciiReferencedDocument `xml:"ram:InvoiceReferencedDocument,omitempty"`
+}
+
+func toCIIParty(p Party) ciiParty {
+	party := ciiParty{Name: p.Name, PostalCode: p.PostalCode, Street: p.Street, City: p.City, Country: p.Country}
+	if p.VATID != "" {
+		party.TaxRegistration = &ciiTaxRegistration{ID: ciiID{SchemeID: "VA", Value: p.VATID}}
+	}
+	if p.Email != "" {
+		party.Email = &ciiCommunication{URI: ciiID{SchemeID: "EM", Value: p.Email}}
+	}
+	return party
+}
+
+// RenderXML renders the document as EN 16931 compliant UN/CEFACT Cross Industry Invoice XML, the syntax used by
+// ZUGFeRD/Factur-X and XRechnung (CII). The shop charges card payments at checkout, so the total is declared prepaid.
+func RenderXML(d *Document) ([]byte, error) {
+	t := d.Totals()
+	vat := ciiTax{TypeCode: "VAT", CategoryCode: "S", RatePercent: d.VATRate}
+
+	inv := ciiInvoice{
+		RSM:     "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100",
+		RAM:     "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100",
+		UDT:     "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100",
+		Context: ciiContext{Guideline: ciiGuideline},
+		Document: ciiDocument{
+			ID:        d.Number,
+			TypeCode:  typeCodeInvoice,
+			IssueDate: ciiDate{Format: "102", Value: d.IssueDate.Format("20060102")},
+		},
+	}
+	if d.Kind == KindCreditNote {
+		inv.Document.TypeCode = typeCodeCreditNote
+	}
+
+	for i, line := range d.Lines {
+		unitNet := "0.00"
+		if line.Quantity != 0 {
+			unitNet = fmt.Sprintf("%.4f", float64(t.LineNetCents[i])/float64(line.Quantity)/100)
+		}
+		inv.Transaction.Lines = append(inv.Transaction.Lines, ciiLine{
+			LineID:     fmt.Sprint(i + 1),
+			Name:       line.Description,
+			NetPrice:   unitNet,
+			Quantity:   ciiQuantity{UnitCode: "H87", Value: line.Quantity},
+			Tax:        vat,
+			LineAmount: formatAmount(t.LineNetCents[i]),
+		})
+	}
+
+	inv.Transaction.Agreement = ciiAgreement{
+		BuyerReference: fmt.Sprintf("Order %d", d.OrderID),
+		Seller:         toCIIParty(d.Seller),
+		Buyer:          toCIIParty(d.Buyer),
+	}
+
+	s := &inv.Transaction.Settlement
+	s.Currency = d.Currency
+	s.Tax = ciiTax{
+		CalculatedAmount: formatAmount(t.TaxCents),
+		TypeCode:         "VAT",
+		BasisAmount:      formatAmount(t.NetCents),
+		CategoryCode:     "S",
+		RatePercent:      d.VATRate,
+	}
+	if t.ShippingNet != 0 {
+		s.AllowanceCharges = append(s.AllowanceCharges, ciiAllowanceCharge{
+			ChargeIndicator: ciiIndicator{Value: true}, Amount: formatAmount(t.ShippingNet), Reason: "Shipping", Tax: vat,
+		})
+	}
+	if t.DiscountNet != 0 {
+		s.AllowanceCharges = append(s.AllowanceCharges, ciiAllowanceCharge{
+			ChargeIndicator: ciiIndicator{Value: false}, Amount: formatAmount(t.DiscountNet), Reason: "Discount", Tax: vat,
+		})
+	}
+	s.Summation = ciiSummation{
+		LineTotal:      formatAmount(t.LinesNetCents),
+		ChargeTotal:    formatAmount(t.ShippingNet),
+		AllowanceTotal: formatAmount(t.DiscountNet),
+		TaxBasisTotal:  formatAmount(t.NetCents),
+		TaxTotal:       ciiCurrencyAmount{CurrencyID: d.Currency, Value: formatAmount(t.TaxCents)},
+		GrandTotal:     formatAmount(t.NetCents + t.TaxCents),
+		Prepaid:        formatAmount(t.GrossCents),
+		DuePayable:     "0.00",
+	}
+	if t.RoundingCents != 0 {
+		s.Summation.Rounding = formatAmount(t.RoundingCents)
+	}
+	if d.RefersTo != "" {
+		s.ReferencedInvoice = &ciiReferencedDocument{ID: d.RefersTo}
+	}
+
+	out, err := xml.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -125,14 +127,42 @@ func NewContext(ctx context.Context, l *slog.Logger) context.Context {
 }
 
 // FromContext returns the logger from the context if present, otherwise the default logger.
+// If the context carries a trace span, its trace_id and span_id are added, so the logs of a request can be found
+// from its trace.
 func FromContext(ctx context.Context) *slog.Logger {
 	if ctx == nil {
 		return slog.Default()
 	}
-	if v := ctx.Value(ctxKey{}); v != nil {
-		if l, ok := v.(*slog.Logger); ok {
-			return l
-		}
+	l := slog.Default()
+	if v, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		l = v
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		l = l.With(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return l
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context that carries the request id, so outgoing calls can forward it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request id stored by GinMiddleware, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDHeader returns the configured header name for request ids.
+func RequestIDHeader() string {
+	if requestIDHeader == "" {
+		return DefaultConfig().RequestIDHeader
 	}
-	return slog.Default()
+	return requestIDHeader
 }
@@ -1,8 +1,14 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
 	"os"
+	"strings"
 	"testing"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestInitFromEnv(t *testing.T) {
@@ -15,3 +21,24 @@ func TestInitFromEnv(t *testing.T) {
 		t.Fatalf("InitFromEnv failed: %v", err)
 	}
 }
+
+func TestFromContextAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := NewContext(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	FromContext(ctx).Info("without span")
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Fatalf("unexpected trace_id without span: %s", buf.String())
+	}
+
+	buf.Reset()
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	FromContext(trace.ContextWithSpanContext(ctx, sc)).Info("with span")
+	out := buf.String()
+	if !strings.Contains(out, `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) || !strings.Contains(out, `"span_id":"00f067aa0ba902b7"`) {
+		t.Fatalf("trace ids missing: %s", out)
+	}
+}
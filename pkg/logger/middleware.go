@@ -16,11 +16,11 @@ func GinMiddleware() gin.HandlerFunc {
 		start := time.Now()
 
 		// request id
-		reqID := c.GetHeader(requestIDHeader)
+		reqID := c.GetHeader(RequestIDHeader())
 		if reqID == "" {
 			reqID = uuid.NewString()
 			// also set header so downstream services/clients can see it
-			c.Request.Header.Set(requestIDHeader, reqID)
+			c.Request.Header.Set(RequestIDHeader(), reqID)
 		}
 
 		// create request scoped logger
@@ -34,7 +34,8 @@ func GinMiddleware() gin.HandlerFunc {
 		)
 
 		// attach to context
-		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), l))
+		ctx := WithRequestID(NewContext(c.Request.Context(), l), reqID)
+		c.Request = c.Request.WithContext(ctx)
 
 		// proceed
 		c.Next()
@@ -42,7 +43,7 @@ func GinMiddleware() gin.HandlerFunc {
 		// after request
 		duration := time.Since(start)
 		status := c.Writer.Status()
-		l.Info("request completed",
+		FromContext(ctx).Info("request completed",
 			slog.Int("status", status),
 			slog.Duration("duration", time.Duration(duration.Microseconds())),
 			slog.String("handler", c.HandlerName()),
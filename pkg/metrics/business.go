@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Business counters, incremented by the service owning the event
+var (
+	// OrdersCreated counts created orders by checkout ("user" or "guest")
+	// used in: order-service handlers.CreateOrder, handlers.CreateGuestOrder
+	OrdersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "orders_created_total",
+		Help: "Created orders by checkout (user, guest).",
+	}, []string{"checkout"})
+
+	// Payments counts payments reaching a final result ("succeeded" or "failed") by source ("webhook" or
+	// "reconciliation")
+	// used in: payment-service handlers.WebhookHandler, handlers.ReconcilePayment
+	Payments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_total",
+		Help: "Payments that succeeded or failed, by result and source (webhook, reconciliation).",
+	}, []string{"result", "source"})
+
+	// StockReductions counts stock reductions (a single product or all items of an order)
+	// used in: product-service models.ReduceStock, models.ReserveStock
+	StockReductions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "stock_reductions_total",
+		Help: "Successful stock reductions and order reservations.",
+	})
+
+	// StockReducedUnits counts the units taken from stock
+	// used in: product-service models.ReduceStock, models.ReserveStock
+	StockReducedUnits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "stock_reduced_units_total",
+		Help: "Units taken from stock by stock reductions and order reservations.",
+	})
+)
@@ -0,0 +1,84 @@
+// Package metrics exposes Prometheus metrics of a service on /metrics: HTTP requests by route and status, the
+// statistics of the database pool, outbound calls to other services and business counters. All metrics are
+// registered with the default registry, which also carries the Go runtime and process metrics.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RouteKey can be set in the gin context to label requests without a registered route, e.g. by the API gateway
+// for proxied requests
+const RouteKey = "metricsRoute"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	callDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "service_call_duration_seconds",
+		Help:    "Duration of calls to other services (every attempt of HTTP calls) by target, method and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"target", "method", "result"})
+
+	callErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "service_call_errors_total",
+		Help: "Failed calls to other services (transport errors, 5xx statuses, gRPC server errors) by target, method and result.",
+	}, []string{"target", "method", "result"})
+)
+
+// GinMiddleware records every request in http_requests_total and http_request_duration_seconds. Requests are
+// labelled with the route pattern, not the path, so ids do not create new series; requests without a route are
+// labelled with RouteKey from the context or "unmatched".
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.GetString(RouteKey)
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Call results of ObserveCall besides HTTP statuses and gRPC codes
+const (
+	ResultError       = "error"        // transport error, no response
+	ResultCircuitOpen = "circuit_open" // rejected by the circuit breaker without a call
+)
+
+// ObserveCall records a call to target (every attempt of HTTP calls). method is the HTTP method or the full gRPC method, result
+// the HTTP status, the gRPC code or one of the Result constants; failed attempts are also counted as errors.
+// used in: httpclient.Client, rpc client interceptor
+func ObserveCall(target, method, result string, failed bool, d time.Duration) {
+	callDuration.WithLabelValues(target, method, result).Observe(d.Seconds())
+	if failed {
+		callErrors.WithLabelValues(target, method, result).Inc()
+	}
+}
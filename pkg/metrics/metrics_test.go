@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinMiddleware())
+	r.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/metrics", Handler())
+	r.NoRoute(func(c *gin.Context) {
+		if c.Request.URL.Path == "/proxied" {
+			c.Set(RouteKey, "proxy:order-service")
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/unknown", "/proxied"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/orders/:id", "200")); got != 2 {
+		t.Errorf("requests of route = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "proxy:order-service", "404")); got != 1 {
+		t.Errorf("requests labelled by RouteKey = %v, want 1", got)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `http_request_duration_seconds_count{method="GET",route="/orders/:id",status="200"} 2`) {
+		t.Errorf("histogram missing from /metrics output")
+	}
+}
+
+func TestObserveCall(t *testing.T) {
+	ObserveCall("product-service", "POST", "200", false, 10*time.Millisecond)
+	ObserveCall("product-service", "POST", "503", true, 10*time.Millisecond)
+	ObserveCall("product-service", "POST", ResultCircuitOpen, true, 0)
+
+	if got := testutil.ToFloat64(callErrors.WithLabelValues("product-service", "POST", "200")); got != 0 {
+		t.Errorf("successful call counted as error")
+	}
+	if got := testutil.ToFloat64(callErrors.WithLabelValues("product-service", "POST", "503")); got != 1 {
+		t.Errorf("errors = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(callDuration); got != 3 {
+		t.Errorf("duration series = %d, want 3", got)
+	}
+}
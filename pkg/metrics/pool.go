@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterPool exports the statistics of the database pool; call once per pool after it was created
+func RegisterPool(pool *pgxpool.Pool) {
+	prometheus.MustRegister(newPoolCollector(pool))
+}
+
+// poolCollector reads pool.Stat() on every scrape
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired, idle, constructing, total, max                                              *prometheus.Desc
+	acquires, acquireDuration, canceled, empty, created, lifetimeDestroyed, idleDestroyed *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+name, help, nil, nil)
+	}
+	return &poolCollector{
+		pool:              pool,
+		acquired:          desc("acquired_connections", "Connections currently in use."),
+		idle:              desc("idle_connections", "Idle connections in the pool."),
+		constructing:      desc("constructing_connections", "Connections being established."),
+		total:             desc("total_connections", "All connections of the pool."),
+		max:               desc("max_connections", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent waiting for connections."),
+		canceled:          desc("canceled_acquires_total", "Acquires canceled by their context."),
+		empty:             desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		created:           desc("new_connections_total", "Connections opened."),
+		lifetimeDestroyed: desc("max_lifetime_destroys_total", "Connections closed for reaching their maximum lifetime."),
+		idleDestroyed:     desc("max_idle_destroys_total", "Connections closed for being idle too long."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.acquired, c.idle, c.constructing, c.total, c.max, c.acquires,
+		c.acquireDuration, c.canceled, c.empty, c.created, c.lifetimeDestroyed, c.idleDestroyed} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(c.acquired, float64(s.AcquiredConns()))
+	gauge(c.idle, float64(s.IdleConns()))
+	gauge(c.constructing, float64(s.ConstructingConns()))
+	gauge(c.total, float64(s.TotalConns()))
+	gauge(c.max, float64(s.MaxConns()))
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.acquireDuration, s.AcquireDuration().Seconds())
+	counter(c.canceled, float64(s.CanceledAcquireCount()))
+	counter(c.empty, float64(s.EmptyAcquireCount()))
+	counter(c.created, float64(s.NewConnsCount()))
+	counter(c.lifetimeDestroyed, float64(s.MaxLifetimeDestroyCount()))
+	counter(c.idleDestroyed, float64(s.MaxIdleDestroyCount()))
+}
@@ -4,16 +4,31 @@ import (
 	"net/http"
 	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Identity headers set by the API gateway after it validated the token of a request. Services trust them only on
+// requests signed by the gateway (serviceauth), which covers both headers, so clients cannot pass or change them.
+const (
+	UserIDHeader   = serviceauth.UserIDHeader
+	UserRoleHeader = serviceauth.UserRoleHeader
+	GatewayService = "api-gateway"
+)
+
 func Authenticate(context *gin.Context) {
 	var token = context.Request.Header.Get("Authorization")
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("Authentication required for route")
 
+	if context.Request.Header.Get(UserIDHeader) != "" {
+		authenticateGateway(context)
+		return
+	}
+
 	if len(token) > 0 {
 		parts := strings.Split(token, " ")
 		if len(parts) == 2 && parts[0] == "Bearer" {
@@ -29,7 +44,7 @@ func Authenticate(context *gin.Context) {
 		return
 	}
 
-	userId, userRole, err := ValidateToken(token, db.DB, db.Ctx)
+	userId, userRole, err := ValidateToken(token, db.DB, context.Request.Context())
 	if err != nil {
 		l.Error("Not authorized", "error", err)
 		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized", "error": err.Error()})
@@ -42,6 +57,45 @@ func Authenticate(context *gin.Context) {
 	context.Next()
 }
 
+// authenticateGateway takes the user from the identity headers of a request the API gateway has authenticated
+func authenticateGateway(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	caller, err := serviceauth.VerifyRequest(context.Request)
+	if err == nil && caller != GatewayService {
+		err = serviceauth.ErrCallerNotAllowed
+	}
+	if err != nil {
+		l.Error("identity headers without valid gateway signature", "caller", caller, "error", err)
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized", "error": err.Error()})
+		return
+	}
+
+	userId, err := strconv.ParseInt(context.Request.Header.Get(UserIDHeader), 10, 64)
+	userRole := context.Request.Header.Get(UserRoleHeader)
+	if err != nil || userRole == "" {
+		l.Error("invalid identity headers from gateway")
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized"})
+		return
+	}
+
+	l.Debug("Authenticated by gateway")
+	context.Set("userId", userId)
+	context.Set("userRole", userRole)
+	context.Next()
+}
+
+// OptionalAuthenticate authenticates the request if an Authorization header is present
+// and lets anonymous requests (e.g. guest carts) through without a userId in the context
+func OptionalAuthenticate(context *gin.Context) {
+	if context.Request.Header.Get("Authorization") == "" && context.Request.Header.Get(UserIDHeader) == "" {
+		logger.FromContext(context.Request.Context()).Debug("anonymous request")
+		context.Next()
+		return
+	}
+	Authenticate(context)
+}
+
 const (
 	CtxRole = "userRole"
 )
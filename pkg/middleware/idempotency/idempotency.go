@@ -0,0 +1,201 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of idempotent requests
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed" // set to true on replayed responses
+)
+
+// MaxKeyLength is the maximum length of an idempotency key
+const MaxKeyLength = 255
+
+// DefaultTTL is how long a key and its stored response are kept
+const DefaultTTL = 24 * time.Hour
+
+// DefaultLease is how long a request may run before a retry can take over its key, e.g. after the instance
+// running it crashed
+const DefaultLease = time.Minute
+
+// ErrKeyNotClaimed is returned by Complete for keys without a record
+var ErrKeyNotClaimed = errors.New("idempotency key not claimed")
+
+// Record is a request stored under an idempotency key
+type Record struct {
+	Fingerprint string
+	Completed   bool // false while the first request is still running
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// Store keeps idempotency records per scope (the caller) and key. Implementations must be safe for concurrent use.
+type Store interface {
+	// Begin claims a key for a request. If the key is already claimed, the existing record is returned with
+	// created=false, unless the lease of a request still in progress has run out: the key is then claimed anew.
+	Begin(ctx context.Context, scope, key, fingerprint string) (rec *Record, created bool, err error)
+	// Complete stores the response of a claimed key
+	Complete(ctx context.Context, scope, key string, rec *Record) error
+	// Release frees a claimed key so the request can be retried, e.g. after a server error
+	Release(ctx context.Context, scope, key string) error
+}
+
+// skipped response headers, set again when the response is replayed
+var skippedHeaders = map[string]bool{"Content-Length": true, "Date": true, "X-Request-Id": true}
+
+// Middleware makes POST requests carrying an Idempotency-Key header safe to retry. The first request runs and its
+// response is stored; retries with the same key and the same request get the stored response replayed, retries with a
+// different request are rejected with 422 and retries while the first request is still running with 409, until the
+// store's lease of the first request runs out. Responses with a 5xx status are not stored, and neither are panicking
+// handlers, so the request can be retried. Requests without the header are passed through.
+// Must run after authentication: keys are scoped to the user, the guest token or the client IP.
+func Middleware(store Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		l := logger.FromContext(c.Request.Context())
+
+		if len(key) > MaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Idempotency-Key exceeds " + strconv.Itoa(MaxKeyLength) + " characters."})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "could not read request body."})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := Scope(c)
+		fingerprint := Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		rec, created, err := store.Begin(c.Request.Context(), scope, key, fingerprint)
+		if err != nil {
+			l.Error("failed to claim idempotency key", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "could not process idempotency key.", "error": err.Error()})
+			return
+		}
+		if !created {
+			replay(c, rec, fingerprint)
+			return
+		}
+
+		// the request context may be cancelled by now, the outcome is stored regardless
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// release the key unless the response got stored, also when a handler panics, so the request can be retried
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Release(ctx, scope, key); err != nil {
+				l.Error("failed to release idempotency key", "error", err)
+			}
+		}()
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		header := http.Header{}
+		for k, v := range w.Header() {
+			if !skippedHeaders[k] {
+				header[k] = v
+			}
+		}
+		rec = &Record{Fingerprint: fingerprint, Completed: true, StatusCode: status, Header: header, Body: w.body.Bytes()}
+		if err := store.Complete(ctx, scope, key, rec); err != nil {
+			l.Error("failed to store idempotent response", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replay answers a retry from the stored record
+func replay(c *gin.Context, rec *Record, fingerprint string) {
+	l := logger.FromContext(c.Request.Context())
+
+	if rec.Fingerprint != fingerprint {
+		l.Warn("idempotency key reused for a different request")
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"message": "Idempotency-Key was already used for a different request."})
+		return
+	}
+	if !rec.Completed {
+		l.Warn("idempotent request still in progress")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "a request with this Idempotency-Key is still in progress."})
+		return
+	}
+
+	l.Info("replaying idempotent response", "status", rec.StatusCode)
+	for k, v := range rec.Header {
+		c.Writer.Header()[k] = v
+	}
+	c.Writer.Header().Set(ReplayedHeader, "true")
+	c.Writer.WriteHeader(rec.StatusCode)
+	c.Writer.Write(rec.Body)
+	c.Abort()
+}
+
+// Scope identifies the caller a key belongs to: the authenticated user, else the guest cart or order token, else
+// the client IP
+func Scope(c *gin.Context) string {
+	if userId := c.GetInt64("userId"); userId != 0 {
+		return "user:" + strconv.FormatInt(userId, 10)
+	}
+	if token := c.GetHeader(guesttoken.OrderHeader) + c.GetHeader(guesttoken.CartHeader); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "guest:" + hex.EncodeToString(sum[:])
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// Fingerprint identifies a request by method, path and body
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder captures the response body while writing it through
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
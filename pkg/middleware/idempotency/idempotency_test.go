@@ -0,0 +1,164 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore is an in-memory Store for tests
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func (s *memoryStore) Begin(ctx context.Context, scope, key, fingerprint string) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[scope+"|"+key]; ok {
+		return rec, false, nil
+	}
+	s.records[scope+"|"+key] = &Record{Fingerprint: fingerprint}
+	return &Record{Fingerprint: fingerprint}, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, scope, key string, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[scope+"|"+key]; !ok {
+		return ErrKeyNotClaimed
+	}
+	s.records[scope+"|"+key] = rec
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, scope+"|"+key)
+	return nil
+}
+
+func newRouter(store Store, calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/orders", func(c *gin.Context) {
+		c.Set("userId", int64(7))
+	}, Middleware(store), func(c *gin.Context) {
+		*calls++
+		c.Header("X-Order-Token", "token")
+		c.JSON(status, gin.H{"id": *calls})
+	})
+	return r
+}
+
+func post(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(KeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareReplay(t *testing.T) {
+	calls := 0
+	r := newRouter(&memoryStore{records: map[string]*Record{}}, &calls, http.StatusCreated)
+
+	first := post(r, "abc", `{"cartId":1}`)
+	second := post(r, "abc", `{"cartId":1}`)
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(ReplayedHeader) != "true" || second.Header().Get("X-Order-Token") != "token" {
+		t.Errorf("replay headers = %v", second.Header())
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Error("first response must not be marked as replayed")
+	}
+}
+
+func TestMiddlewareMismatch(t *testing.T) {
+	calls := 0
+	r := newRouter(&memoryStore{records: map[string]*Record{}}, &calls, http.StatusCreated)
+
+	post(r, "abc", `{"cartId":1}`)
+	if w := post(r, "abc", `{"cartId":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	store := &memoryStore{records: map[string]*Record{}}
+	store.records["user:7|abc"] = &Record{Fingerprint: Fingerprint(http.MethodPost, "/orders", []byte(`{}`))}
+	calls := 0
+	r := newRouter(store, &calls, http.StatusCreated)
+
+	if w := post(r, "abc", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", w.Code)
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times, want 0", calls)
+	}
+}
+
+func TestMiddlewareServerErrorReleasesKey(t *testing.T) {
+	calls := 0
+	r := newRouter(&memoryStore{records: map[string]*Record{}}, &calls, http.StatusInternalServerError)
+
+	post(r, "abc", `{}`)
+	post(r, "abc", `{}`)
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestMiddlewarePanicReleasesKey(t *testing.T) {
+	store := &memoryStore{records: map[string]*Record{}}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/orders", Middleware(store), func(c *gin.Context) {
+		panic("handler failed")
+	})
+
+	if w := post(r, "abc", `{}`); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if len(store.records) != 0 {
+		t.Errorf("key still claimed after panic: %v", store.records)
+	}
+}
+
+func TestMiddlewareWithoutKey(t *testing.T) {
+	calls := 0
+	r := newRouter(&memoryStore{records: map[string]*Record{}}, &calls, http.StatusCreated)
+
+	post(r, "", `{}`)
+	post(r, "", `{}`)
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestMiddlewareKeyTooLong(t *testing.T) {
+	calls := 0
+	r := newRouter(&memoryStore{records: map[string]*Record{}}, &calls, http.StatusCreated)
+
+	if w := post(r, strings.Repeat("k", MaxKeyLength+1), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxBeginAttempts bounds how often Begin retries a key that is released while it is read
+const maxBeginAttempts = 3
+
+// PostgresStore keeps idempotency records in the idempotency_keys table
+type PostgresStore struct {
+	DB    *pgxpool.Pool
+	TTL   time.Duration // records older than this are discarded, DefaultTTL if zero
+	Lease time.Duration // a request in progress longer than this loses its key to a retry, DefaultLease if zero
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewPostgresStore returns a store on the shared database pool
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{DB: db, TTL: DefaultTTL, Lease: DefaultLease}
+}
+
+func (s *PostgresStore) ttl() time.Duration {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return DefaultTTL
+}
+
+func (s *PostgresStore) lease() time.Duration {
+	if s.Lease > 0 {
+		return s.Lease
+	}
+	return DefaultLease
+}
+
+// prune deletes all expired records, at most once a minute per instance; Begin only frees the key it claims
+func (s *PostgresStore) prune(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastPrune) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.mu.Unlock()
+
+	// best effort, expired records only cost space
+	s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, time.Now().Add(-s.ttl()))
+}
+
+func (s *PostgresStore) Begin(ctx context.Context, scope, key, fingerprint string) (*Record, bool, error) {
+	s.prune(ctx)
+
+	for attempt := 0; attempt < maxBeginAttempts; attempt++ {
+		rec, created, err := s.begin(ctx, scope, key, fingerprint)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return rec, created, err
+		}
+		// released in the meantime, claim it again
+	}
+	return nil, false, fmt.Errorf("idempotency key released %d times while claiming it", maxBeginAttempts)
+}
+
+// begin makes one attempt of Begin; pgx.ErrNoRows means the key was released between the claim and the read
+func (s *PostgresStore) begin(ctx context.Context, scope, key, fingerprint string) (*Record, bool, error) {
+	now := time.Now()
+
+	// an expired record frees its key
+	_, err := s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope=$1 AND key=$2 AND created_at < $3`, scope, key, now.Add(-s.ttl()))
+	if err != nil {
+		return nil, false, err
+	}
+
+	tag, err := s.DB.Exec(ctx,
+		`INSERT INTO idempotency_keys (scope, key, fingerprint, locked_until) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (scope, key) DO NOTHING`,
+		scope, key, fingerprint, now.Add(s.lease()))
+	if err != nil {
+		return nil, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return &Record{Fingerprint: fingerprint}, true, nil
+	}
+
+	// a request in progress past its lease, e.g. on a crashed instance, hands the key over
+	tag, err = s.DB.Exec(ctx,
+		`UPDATE idempotency_keys SET fingerprint=$3, locked_until=$4, created_at=$5
+		 WHERE scope=$1 AND key=$2 AND completed_at IS NULL AND (locked_until IS NULL OR locked_until < $5)`,
+		scope, key, fingerprint, now.Add(s.lease()), now)
+	if err != nil {
+		return nil, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return &Record{Fingerprint: fingerprint}, true, nil
+	}
+
+	rec := &Record{}
+	var status *int
+	var header []byte
+	err = s.DB.QueryRow(ctx,
+		`SELECT fingerprint, completed_at IS NOT NULL, response_status, response_headers, response_body
+		 FROM idempotency_keys WHERE scope=$1 AND key=$2`, scope, key).
+		Scan(&rec.Fingerprint, &rec.Completed, &status, &header, &rec.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	if status != nil {
+		rec.StatusCode = *status
+	}
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &rec.Header); err != nil {
+			return nil, false, fmt.Errorf("invalid stored headers: %w", err)
+		}
+	}
+	return rec, false, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, scope, key string, rec *Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	tag, err := s.DB.Exec(ctx,
+		`UPDATE idempotency_keys
+		 SET response_status=$3, response_headers=$4, response_body=$5, completed_at=now(), locked_until=NULL
+		 WHERE scope=$1 AND key=$2`,
+		scope, key, rec.StatusCode, header, rec.Body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrKeyNotClaimed
+	}
+	return nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, scope, key string) error {
+	_, err := s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope=$1 AND key=$2 AND completed_at IS NULL`, scope, key)
+	return err
+}
+
+var _ Store = (*PostgresStore)(nil)
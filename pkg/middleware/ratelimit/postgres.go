@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps the buckets in the rate_limit_buckets table, so all instances of a service share their limits.
+// Times are taken from the database clock. Any store with an atomic read-modify-write per key (e.g. a Redis script)
+// can implement Store the same way.
+type PostgresStore struct {
+	DB *pgxpool.Pool
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewPostgresStore returns a store on the shared database pool
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.prune(ctx)
+
+	var res Result
+	err := pgx.BeginFunc(ctx, s.DB, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at) VALUES ($1, $2, now(), now())
+			 ON CONFLICT (key) DO NOTHING`, key, float64(limit.burst()))
+		if err != nil {
+			return err
+		}
+
+		var tokens float64
+		var elapsed float64
+		err = tx.QueryRow(ctx,
+			`SELECT tokens, GREATEST(EXTRACT(EPOCH FROM now() - updated_at), 0)::float8
+			 FROM rate_limit_buckets WHERE key=$1 FOR UPDATE`, key).Scan(&tokens, &elapsed)
+		if err != nil {
+			return err
+		}
+
+		tokens, res = take(limit, tokens, time.Duration(elapsed*float64(time.Second)))
+		_, err = tx.Exec(ctx,
+			`UPDATE rate_limit_buckets SET tokens=$2, updated_at=now(), full_at=now() + make_interval(secs => $3)
+			 WHERE key=$1`, key, tokens, res.Reset.Seconds())
+		return err
+	})
+	return res, err
+}
+
+// prune deletes the buckets that have refilled completely, at most once a minute per instance
+func (s *PostgresStore) prune(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastPrune) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.mu.Unlock()
+
+	// best effort, stale buckets only cost space
+	s.DB.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE full_at < now()`)
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// StoreFromEnv returns the store selected by RATE_LIMIT_STORE: memory (default, limits per instance) or postgres
+// (limits shared by all instances through db)
+func StoreFromEnv(db *pgxpool.Pool) (Store, error) {
+	switch v := os.Getenv("RATE_LIMIT_STORE"); v {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		if db == nil {
+			return nil, errors.New("RATE_LIMIT_STORE=postgres needs a database connection")
+		}
+		return NewPostgresStore(db), nil
+	default:
+		return nil, fmt.Errorf("invalid RATE_LIMIT_STORE %q, want memory or postgres", v)
+	}
+}
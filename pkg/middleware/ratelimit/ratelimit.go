@@ -0,0 +1,228 @@
+// Package ratelimit limits requests with token buckets. Every bucket holds up to Limit.Burst requests and is refilled
+// at Limit.Requests per Limit.Period; buckets are kept per policy and key (user, client IP or route) in a Store, so
+// several instances of a service can share their limits through the database.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response headers (IETF draft "RateLimit header fields for HTTP")
+const (
+	LimitHeader     = "RateLimit-Limit"     // requests of a full bucket
+	RemainingHeader = "RateLimit-Remaining" // requests left in the bucket
+	ResetHeader     = "RateLimit-Reset"     // seconds until the bucket is full again
+	PolicyHeader    = "RateLimit-Policy"    // quota and window in seconds, e.g. 5;w=60
+)
+
+// Limit allows Requests per Period with bursts of up to Burst requests (Requests if zero)
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// rate returns the refill rate in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// ParseLimit parses limits like "5/1m" (5 requests per minute) or "20/1s,burst=40"
+func ParseLimit(s string) (Limit, error) {
+	spec, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ",")
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want requests/period", s)
+	}
+
+	var l Limit
+	var err error
+	if l.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || l.Requests < 1 {
+		return Limit{}, fmt.Errorf("invalid requests in rate limit %q", s)
+	}
+	if l.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || l.Period <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in rate limit %q", s)
+	}
+	if hasBurst {
+		value, ok := strings.CutPrefix(strings.TrimSpace(burst), "burst=")
+		if l.Burst, err = strconv.Atoi(value); !ok || err != nil || l.Burst < 1 {
+			return Limit{}, fmt.Errorf("invalid burst in rate limit %q", s)
+		}
+	}
+	return l, nil
+}
+
+// LimitFromEnv reads the limit of a policy from RATE_LIMIT_<NAME> (e.g. RATE_LIMIT_LOGIN=5/1m), fallback if unset.
+// "off" disables the policy and returns ok=false.
+func LimitFromEnv(name string, fallback Limit) (limit Limit, ok bool, err error) {
+	v := strings.TrimSpace(os.Getenv(EnvName(name)))
+	switch v {
+	case "":
+		return fallback, true, nil
+	case "off":
+		return Limit{}, false, nil
+	}
+	limit, err = ParseLimit(v)
+	if err != nil {
+		return fallback, true, fmt.Errorf("%s: %w", EnvName(name), err)
+	}
+	return limit, true, nil
+}
+
+// EnvName returns the environment variable holding the limit of a policy, e.g. RATE_LIMIT_CHECK_STOCK
+func EnvName(name string) string {
+	return "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Result is the state of a bucket after a request was counted
+type Result struct {
+	Allowed    bool
+	Remaining  int           // whole requests left in the bucket
+	RetryAfter time.Duration // until the next request is allowed, zero if allowed
+	Reset      time.Duration // until the bucket is full again
+}
+
+// Store keeps the token buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take removes a token from the bucket of key if it has one left
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// KeyFunc returns the key of the bucket a request counts against
+type KeyFunc func(c *gin.Context) string
+
+// ByIP limits every client IP. Set the proxies allowed to name the client with TrustProxiesFromEnv, otherwise any
+// caller can pick its own bucket through X-Forwarded-For.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// TrustProxiesFromEnv lets the router take client IPs from X-Forwarded-For only on requests from the proxies in the
+// environment variable key (comma separated IPs/CIDRs, e.g. the API gateway). Unset, no proxy is trusted and the
+// client IP is the address of the connection.
+func TrustProxiesFromEnv(router *gin.Engine, key string) error {
+	var trusted []string
+	for _, proxy := range strings.Split(os.Getenv(key), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trusted); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// ByUser limits every authenticated user, anonymous requests per client IP. Must run after authentication.
+func ByUser(c *gin.Context) string {
+	if userId := c.GetInt64("userId"); userId != 0 {
+		return "user:" + strconv.FormatInt(userId, 10)
+	}
+	return ByIP(c)
+}
+
+// ByRoute limits a route for all callers together
+func ByRoute(c *gin.Context) string {
+	return "route:" + c.Request.Method + " " + c.FullPath()
+}
+
+// Policy is a limit of a route group; Name separates its buckets from other policies using the same keys
+type Policy struct {
+	Name  string
+	Limit Limit
+	Key   KeyFunc
+}
+
+// PolicyFromEnv returns a middleware for the policy with its limit overridden by RATE_LIMIT_<NAME>; if the variable
+// is "off", the middleware lets all requests pass. Panics on an invalid limit, like the other route middlewares
+// checking their configuration at startup.
+func PolicyFromEnv(store Store, policy Policy) gin.HandlerFunc {
+	limit, ok, err := LimitFromEnv(policy.Name, policy.Limit)
+	if err != nil {
+		panic(err.Error())
+	}
+	if !ok {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy.Limit = limit
+	return Middleware(store, policy)
+}
+
+// Middleware counts every request against the bucket of its key and rejects requests over the limit with
+// 429 Too Many Requests and a Retry-After header. All responses carry the RateLimit-* headers. If the store fails,
+// the request is let through: an outage of the store must not take down the service.
+func Middleware(store Store, policy Policy) gin.HandlerFunc {
+	window := int(math.Ceil(policy.Limit.Period.Seconds()))
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit.Requests, window)
+	if burst := policy.Limit.burst(); burst != policy.Limit.Requests {
+		policyHeader += fmt.Sprintf(";burst=%d", burst)
+	}
+
+	return func(c *gin.Context) {
+		l := logger.FromContext(c.Request.Context())
+
+		res, err := store.Take(c.Request.Context(), policy.Name+"|"+policy.Key(c), policy.Limit)
+		if err != nil {
+			l.Error("rate limit store failed, request not limited", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(LimitHeader, strconv.Itoa(policy.Limit.burst()))
+		c.Header(RemainingHeader, strconv.Itoa(res.Remaining))
+		c.Header(ResetHeader, strconv.Itoa(seconds(res.Reset)))
+		c.Header(PolicyHeader, policyHeader)
+
+		if !res.Allowed {
+			l.Warn("rate limit exceeded", "policy", policy.Name)
+			c.Header("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// take refills a bucket holding tokens for the time elapsed since its last request and takes a token for the
+// current request if there is one; returns the tokens left
+func take(limit Limit, tokens float64, elapsed time.Duration) (float64, Result) {
+	rate, burst := limit.rate(), float64(limit.burst())
+	tokens = math.Min(burst, tokens+elapsed.Seconds()*rate)
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((burst - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return tokens, res
+}
+
+// seconds rounds up, so clients never retry too early
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
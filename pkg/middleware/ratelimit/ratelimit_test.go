@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("20/1s, burst=40")
+	if err != nil || l != (Limit{Requests: 20, Period: time.Second, Burst: 40}) {
+		t.Errorf("ParseLimit = %+v, %v", l, err)
+	}
+	for _, invalid := range []string{"", "5", "0/1m", "5/soon", "5/1m,40", "5/1m,burst=0"} {
+		if _, err := ParseLimit(invalid); err == nil {
+			t.Errorf("ParseLimit(%q): expected error", invalid)
+		}
+	}
+}
+
+func TestLimitFromEnv(t *testing.T) {
+	fallback := Limit{Requests: 5, Period: time.Minute}
+
+	t.Setenv("RATE_LIMIT_CHECK_STOCK", "")
+	if l, ok, err := LimitFromEnv("check-stock", fallback); !ok || err != nil || l != fallback {
+		t.Errorf("unset: %+v %v %v", l, ok, err)
+	}
+	t.Setenv("RATE_LIMIT_CHECK_STOCK", "10/1s")
+	if l, _, _ := LimitFromEnv("check-stock", fallback); l.Requests != 10 || l.Period != time.Second {
+		t.Errorf("override: %+v", l)
+	}
+	t.Setenv("RATE_LIMIT_CHECK_STOCK", "off")
+	if _, ok, _ := LimitFromEnv("check-stock", fallback); ok {
+		t.Error("off: policy still enabled")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 2}
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		res, _ := s.Take(ctx, "a", limit)
+		if res.Allowed != want {
+			t.Fatalf("request %d: allowed = %v, want %v", i, res.Allowed, want)
+		}
+	}
+	res, _ := s.Take(ctx, "a", limit)
+	if res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Errorf("retry after = %v, reset = %v", res.RetryAfter, res.Reset)
+	}
+	if res, _ := s.Take(ctx, "b", limit); !res.Allowed {
+		t.Error("other key limited")
+	}
+
+	now = now.Add(time.Second)
+	if res, _ := s.Take(ctx, "a", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after refill: %+v", res)
+	}
+
+	now = now.Add(time.Hour)
+	s.Take(ctx, "c", limit)
+	if len(s.buckets) != 1 {
+		t.Errorf("full buckets not pruned: %d left", len(s.buckets))
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("down")
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(store Store) *gin.Engine {
+		r := gin.New()
+		r.POST("/auth/login", Middleware(store, Policy{Name: "login", Limit: Limit{Requests: 2, Period: time.Minute}, Key: ByIP}),
+			func(c *gin.Context) { c.Status(http.StatusOK) })
+		return r
+	}
+	send := func(r *gin.Engine, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := newRouter(NewMemoryStore())
+	w := send(r, "192.0.2.1")
+	if w.Code != http.StatusOK || w.Header().Get(LimitHeader) != "2" || w.Header().Get(RemainingHeader) != "1" ||
+		w.Header().Get(PolicyHeader) != "2;w=60" {
+		t.Errorf("first request: %d %v", w.Code, w.Header())
+	}
+	send(r, "192.0.2.1")
+	w = send(r, "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Errorf("over limit: %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := send(r, "192.0.2.2"); w.Code != http.StatusOK {
+		t.Errorf("other client: %d", w.Code)
+	}
+
+	if w := send(newRouter(failingStore{}), "192.0.2.1"); w.Code != http.StatusOK {
+		t.Errorf("store failure: %d, want request let through", w.Code)
+	}
+}
+
+func TestTrustProxiesFromEnv(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clientIP := func(r *gin.Engine, remote, forwarded string) string {
+		var key string
+		r.GET("/ip", func(c *gin.Context) { key = ByIP(c) })
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remote + ":1234"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		return key
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "")
+	r := gin.New()
+	if err := TrustProxiesFromEnv(r, "TRUSTED_PROXIES"); err != nil {
+		t.Fatal(err)
+	}
+	if key := clientIP(r, "192.0.2.1", "198.51.100.7"); key != "ip:192.0.2.1" {
+		t.Errorf("untrusted: key = %q, want the connection address", key)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", " 192.0.2.1, 10.0.0.0/8")
+	r = gin.New()
+	if err := TrustProxiesFromEnv(r, "TRUSTED_PROXIES"); err != nil {
+		t.Fatal(err)
+	}
+	if key := clientIP(r, "192.0.2.1", "198.51.100.7"); key != "ip:198.51.100.7" {
+		t.Errorf("trusted: key = %q, want the forwarded client", key)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "gateway")
+	if err := TrustProxiesFromEnv(gin.New(), "TRUSTED_PROXIES"); err == nil {
+		t.Error("invalid proxy: expected error")
+	}
+}
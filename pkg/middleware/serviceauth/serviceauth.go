@@ -1,35 +1,131 @@
+// Package serviceauth authenticates calls between the services. Every service signs its internal calls with its
+// own key (HMAC-SHA256 over caller, timestamp, nonce, method, target, identity headers and body hash); the called
+// service holds the keys of its callers and an allowlist of which caller may use which internal route or gRPC method.
 package serviceauth
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"os"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 )
 
-// InternalAuth validates that requests to internal endpoints include the correct secret header
-// This prevents external access to service-to-service endpoints
-func InternalAuth() gin.HandlerFunc {
-	secret := os.Getenv("INTERNAL_API_SECRET")
-	if secret == "" {
-		panic("INTERNAL_API_SECRET environment variable is required")
+// Headers carrying the signature of an internal call; gRPC calls use the same names as metadata keys
+const (
+	CallerHeader    = "X-Service-Caller"
+	TimestampHeader = "X-Service-Timestamp"
+	NonceHeader     = "X-Service-Nonce"
+	SignatureHeader = "X-Service-Signature"
+)
+
+// Identity headers of the user a call is made for, set by the API gateway. They are part of the signature, so they
+// cannot be changed on the way.
+const (
+	UserIDHeader   = "X-User-Id"
+	UserRoleHeader = "X-User-Role"
+)
+
+// verifier is shared by all routes and gRPC methods of a service, so a nonce is accepted only once per service
+var verifier = sync.OnceValues(VerifierFromEnv)
+
+// mustVerifier returns the verifier of this service and checks that all allowed callers have a key
+func mustVerifier(callers ...string) *Verifier {
+	v, err := verifier()
+	if err != nil {
+		panic(err.Error())
 	}
+	for _, caller := range callers {
+		if !v.Knows(caller) {
+			panic(fmt.Sprintf("SERVICE_CALLER_KEYS has no key for %s", caller))
+		}
+	}
+	return v
+}
 
-	return func(c *gin.Context) {
-		headerSecret := c.GetHeader("X-Internal-Secret")
+type callerKey struct{}
 
-		if headerSecret == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "internal secret required"})
+// WithCaller returns a context that carries the name of the calling service
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the calling service verified by InternalAuth or UnaryServerInterceptor, or ""
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// InternalAuth validates that requests to internal endpoints are signed by one of the given services.
+// This prevents external access to service-to-service endpoints and limits every route to the services that need it.
+func InternalAuth(callers ...string) gin.HandlerFunc {
+	if len(callers) == 0 {
+		panic("InternalAuth needs at least one allowed caller")
+	}
+	return internalAuth(mustVerifier(callers...), callers...)
+}
+
+func internalAuth(v *Verifier, callers ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(callers))
+	for _, caller := range callers {
+		allowed[caller] = true
+	}
+
+	return func(c *gin.Context) {
+		caller, err := verifyRequest(v, c.Request)
+		if errors.Is(err, errUnreadableBody) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "could not read request body"})
+			c.Abort()
+			return
+		}
+		if errors.Is(err, ErrUnsigned) {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "service signature required"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid service signature", "error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		if headerSecret != secret {
-			c.JSON(http.StatusForbidden, gin.H{"message": "invalid internal secret"})
+		if !allowed[caller] {
+			c.JSON(http.StatusForbidden, gin.H{"message": ErrCallerNotAllowed.Error(), "caller": caller})
 			c.Abort()
 			return
 		}
 
+		c.Request = c.Request.WithContext(WithCaller(c.Request.Context(), caller))
 		c.Next()
 	}
 }
+
+// VerifyRequest checks the signature of an HTTP request and returns the signing service. Unlike InternalAuth it
+// does not restrict the caller and leaves the response to the caller; used for the identity headers of the gateway.
+func VerifyRequest(r *http.Request) (string, error) {
+	v, err := verifier()
+	if err != nil {
+		return "", err
+	}
+	return verifyRequest(v, r)
+}
+
+var errUnreadableBody = errors.New("could not read request body")
+
+// verifyRequest verifies the signature of r; the body is read and put back for the handlers
+func verifyRequest(v *Verifier, r *http.Request) (string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", errUnreadableBody
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	caller := r.Header.Get(CallerHeader)
+	err = v.Verify(caller, r.Header.Get(TimestampHeader), r.Header.Get(NonceHeader), r.Header.Get(SignatureHeader),
+		r.Method, r.URL.RequestURI(), r.Header.Get(UserIDHeader), r.Header.Get(UserRoleHeader), body)
+	return caller, err
+}
@@ -0,0 +1,183 @@
+package serviceauth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var testKeys = map[string][]byte{"order-service": []byte("order-key"), "cart-service": []byte("cart-key")}
+
+func TestVerify(t *testing.T) {
+	v := NewVerifier(testKeys, time.Minute)
+	signer := NewSigner("order-service", []byte("order-key"))
+	h := signer.sign(http.MethodPost, "/api/v1/internal/things", "", "", []byte(`{"a":1}`))
+
+	verify := func(h map[string]string, body string) error {
+		return v.Verify(h[CallerHeader], h[TimestampHeader], h[NonceHeader], h[SignatureHeader], http.MethodPost, "/api/v1/internal/things", "", "", []byte(body))
+	}
+	if err := verify(h, `{"a":2}`); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered body: err = %v, want ErrBadSignature", err)
+	}
+	if err := verify(h, `{"a":1}`); err != nil {
+		t.Fatalf("signed call: %v", err)
+	}
+	if err := verify(h, `{"a":1}`); !errors.Is(err, ErrReplayed) {
+		t.Errorf("replay: err = %v, want ErrReplayed", err)
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	if err := verify(signer.sign(http.MethodPost, "/api/v1/internal/things", "", "", []byte(`{"a":1}`)), `{"a":1}`); !errors.Is(err, ErrStaleRequest) {
+		t.Errorf("old timestamp: err = %v, want ErrStaleRequest", err)
+	}
+
+	forged := NewSigner("payment-service", []byte("order-key")).sign(http.MethodPost, "/api/v1/internal/things", "", "", []byte(`{"a":1}`))
+	if err := verify(forged, `{"a":1}`); !errors.Is(err, ErrUnknownCaller) {
+		t.Errorf("unknown caller: err = %v, want ErrUnknownCaller", err)
+	}
+
+	if err := verify(map[string]string{}, ""); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("unsigned: err = %v, want ErrUnsigned", err)
+	}
+}
+
+func TestVerifierFromEnv(t *testing.T) {
+	t.Setenv("SERVICE_CALLER_KEYS", "order-service=a, cart-service=b")
+	v, err := VerifierFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Knows("order-service") || !v.Knows("cart-service") || v.Knows("user-service") {
+		t.Errorf("keys = %v", v.keys)
+	}
+
+	t.Setenv("SERVICE_CALLER_KEYS", "order-service")
+	if _, err := VerifierFromEnv(); err == nil {
+		t.Error("expected error for entry without key")
+	}
+}
+
+func TestInternalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/internal/things", internalAuth(NewVerifier(testKeys, time.Minute), "order-service"), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, CallerFromContext(c.Request.Context())+" "+string(body))
+	})
+
+	send := func(signer *Signer) *httptest.ResponseRecorder {
+		body := []byte(`{"a":1}`)
+		req := httptest.NewRequest(http.MethodPost, "/internal/things", bytes.NewReader(body))
+		if signer != nil {
+			signer.SignRequest(req, body)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(NewSigner("order-service", []byte("order-key"))); w.Code != http.StatusOK || w.Body.String() != `order-service {"a":1}` {
+		t.Errorf("signed call: %d %s", w.Code, w.Body)
+	}
+	if w := send(NewSigner("cart-service", []byte("cart-key"))); w.Code != http.StatusForbidden {
+		t.Errorf("caller not allowed: status = %d", w.Code)
+	}
+	if w := send(NewSigner("order-service", []byte("wrong"))); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong key: status = %d", w.Code)
+	}
+	if w := send(nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned: status = %d", w.Code)
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	req := httptest.NewRequest(http.MethodPut, "/cart/items/1?x=1", bytes.NewReader(body))
+	NewSigner("cart-service", []byte("cart-key")).SignRequest(req, body)
+
+	v := NewVerifier(testKeys, time.Minute)
+	caller, err := verifyRequest(v, req)
+	if err != nil || caller != "cart-service" {
+		t.Fatalf("caller = %q, err = %v", caller, err)
+	}
+	if got, _ := io.ReadAll(req.Body); !bytes.Equal(got, body) {
+		t.Errorf("body not restored: %s", got)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if _, err := verifyRequest(v, req); !errors.Is(err, ErrReplayed) {
+		t.Errorf("replay: err = %v, want ErrReplayed", err)
+	}
+}
+
+func TestVerifyRequestIdentity(t *testing.T) {
+	v := NewVerifier(map[string][]byte{"api-gateway": []byte("gateway-key")}, time.Minute)
+	signed := func() *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/products/1", nil)
+		req.Header.Set(UserIDHeader, "42")
+		req.Header.Set(UserRoleHeader, "user")
+		NewSigner("api-gateway", []byte("gateway-key")).SignRequest(req, nil)
+		return req
+	}
+
+	if _, err := verifyRequest(v, signed()); err != nil {
+		t.Fatalf("signed identity: %v", err)
+	}
+
+	req := signed()
+	req.Header.Set(UserRoleHeader, "admin")
+	if _, err := verifyRequest(v, req); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered role: err = %v, want ErrBadSignature", err)
+	}
+
+	req = signed()
+	req.Header.Set(UserIDHeader, "1")
+	if _, err := verifyRequest(v, req); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered user: err = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/test.v1.Test/Get"
+	interceptor := unaryServerInterceptor(NewVerifier(testKeys, time.Minute), Allowlist{method: {"cart-service"}})
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	req := wrapperspb.String("x")
+
+	call := func(signer *Signer, req any) error {
+		ctx, err := signer.SignRPC(context.Background(), method, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// outgoing metadata arrives as incoming metadata at the server
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, err = interceptor(metadata.NewIncomingContext(context.Background(), md), req, info, func(ctx context.Context, req any) (any, error) {
+			if CallerFromContext(ctx) != signer.Service() {
+				t.Errorf("caller = %q", CallerFromContext(ctx))
+			}
+			return nil, nil
+		})
+		return err
+	}
+
+	if err := call(NewSigner("cart-service", []byte("cart-key")), req); err != nil {
+		t.Fatalf("signed call: %v", err)
+	}
+	if err := call(NewSigner("order-service", []byte("order-key")), req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("caller not allowed: err = %v", err)
+	}
+	if err := call(NewSigner("cart-service", []byte("wrong")), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong key: err = %v", err)
+	}
+}
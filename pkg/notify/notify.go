@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+)
+
+// Message is a customer notification (e.g. abandoned cart reminder)
+type Message struct {
+	Kind    string            `json:"kind"` // e.g. abandoned_cart
+	To      string            `json:"to"`   // recipient email
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"` // template data such as links or amounts
+}
+
+// Notifier delivers messages to customers. Implementations must be safe for concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to the structured log; used for local development
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	logger.FromContext(ctx).Info("notification", "kind", msg.Kind, "to", msg.To, "subject", msg.Subject, "data", msg.Data)
+	return nil
+}
+
+// WebhookNotifier posts messages as JSON to an HTTP endpoint (e.g. a mail service)
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call notification webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("notification webhook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// FromEnv returns the notifier configured by NOTIFIER (log|webhook) and NOTIFIER_WEBHOOK_URL.
+// Defaults to the LogNotifier.
+func FromEnv() (Notifier, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("NOTIFIER"))) {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "webhook":
+		url := strings.TrimSpace(os.Getenv("NOTIFIER_WEBHOOK_URL"))
+		if url == "" {
+			return nil, fmt.Errorf("NOTIFIER_WEBHOOK_URL is required for the webhook notifier")
+		}
+		return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)}}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", os.Getenv("NOTIFIER"))
+	}
+}
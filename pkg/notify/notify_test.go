@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	n := &WebhookNotifier{URL: srv.URL, Client: srv.Client()}
+	msg := Message{Kind: "abandoned_cart", To: "max@example.com", Subject: "Your cart"}
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Kind != msg.Kind || got.To != msg.To {
+		t.Errorf("got %+v, want %+v", got, msg)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n := &WebhookNotifier{URL: srv.URL, Client: srv.Client()}
+	if err := n.Notify(context.Background(), Message{}); err == nil {
+		t.Error("expected error for failing webhook")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("NOTIFIER", "webhook")
+	t.Setenv("NOTIFIER_WEBHOOK_URL", "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("expected error without webhook url")
+	}
+
+	t.Setenv("NOTIFIER", "")
+	n, err := FromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := n.(LogNotifier); !ok {
+		t.Errorf("expected LogNotifier, got %T", n)
+	}
+}
@@ -0,0 +1,65 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2026, 3, 14, 15, 9, 26, 535897000, time.UTC), ID: 42}
+	got, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID {
+		t.Errorf("DecodeCursor() = %+v, want %+v", got, c)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if c, err := DecodeCursor(""); c != nil || err != nil {
+		t.Errorf("DecodeCursor(\"\") = %v, %v, want nil, nil", c, err)
+	}
+	for _, s := range []string{"%%%", "bm9jb2xvbg", "YWJjOjE", "MTIzOmFiYw", "MTIzOjA"} {
+		if _, err := DecodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"", DefaultLimit, false},
+		{"5", 5, false},
+		{"1000", MaxLimit, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"ten", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %d, %v, want %d, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := func(id int64) Cursor { return Cursor{CreatedAt: base.Add(-time.Duration(id) * time.Minute), ID: id} }
+
+	rows, next := Next([]int64{1, 2, 3}, 3, key)
+	if len(rows) != 3 || next != "" {
+		t.Errorf("Next() on last page = %v, %q, want 3 rows and no cursor", rows, next)
+	}
+
+	rows, next = Next([]int64{1, 2, 3}, 2, key)
+	if len(rows) != 2 || next != key(2).Encode() {
+		t.Errorf("Next() = %v, %q, want 2 rows and cursor of row 2", rows, next)
+	}
+}
@@ -0,0 +1,147 @@
+package promotions
+
+import (
+	"strings"
+	"time"
+)
+
+// Supported coupon types
+const (
+	TypePercentage   = "percentage"    // percent off the eligible subtotal
+	TypeFixed        = "fixed"         // fixed amount in cents off the eligible subtotal
+	TypeFreeShipping = "free_shipping" // waives the shipping cost
+)
+
+// Coupon is a promotion code with its rules and limits
+type Coupon struct {
+	ID             int64      `db:"id" json:"id" swaggerignore:"true"`
+	Code           string     `db:"code" json:"code" binding:"required" example:"WELCOME10"`
+	Description    string     `db:"description" json:"description,omitempty" example:"10% off your first order"`
+	Type           string     `db:"type" json:"type" binding:"required,oneof=percentage fixed free_shipping" example:"percentage"`
+	Value          int        `db:"value" json:"value" binding:"min=0" example:"10"`
+	MinOrderCents  int        `db:"min_order_cents" json:"minOrderCents" binding:"min=0" example:"2000"`
+	MaxUses        *int       `db:"max_uses" json:"maxUses,omitempty" example:"1000"`
+	MaxUsesPerUser *int       `db:"max_uses_per_user" json:"maxUsesPerUser,omitempty" example:"1"`
+	StartsAt       *time.Time `db:"starts_at" json:"startsAt,omitempty" example:"2025-01-01T00:00:00Z"`
+	EndsAt         *time.Time `db:"ends_at" json:"endsAt,omitempty" example:"2025-12-31T23:59:59Z"`
+	Active         *bool      `db:"active" json:"active" example:"true"` // Inactive only when false; new coupons default to active
+	ProductIDs     []int64    `json:"productIds,omitempty" example:"1,2"`
+	CategoryIDs    []int64    `json:"categoryIds,omitempty" example:"1"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt" swaggerignore:"true"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
+}
+
+// Line is a cart or order line the coupon is evaluated against
+type Line struct {
+	ProductID   int64
+	CategoryIDs []int64
+	Quantity    int
+	PriceCents  int
+}
+
+// DiscountLine is an applied discount as shown on carts and orders
+type DiscountLine struct {
+	CouponID     int64  `json:"-"`
+	Code         string `json:"code" example:"WELCOME10"`
+	Type         string `json:"type" example:"percentage"`
+	Description  string `json:"description,omitempty" example:"10% off your first order"`
+	AmountCents  int    `json:"amountCents" example:"500"`
+	FreeShipping bool   `json:"freeShipping,omitempty" example:"false"`
+}
+
+// CouponError explains why a coupon cannot be applied
+type CouponError struct {
+	Code   string
+	Reason string
+}
+
+func (e *CouponError) Error() string {
+	return "coupon " + e.Code + " cannot be applied: " + e.Reason
+}
+
+// NormalizeCode trims and upper-cases a coupon code
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks type specific settings that cannot be expressed with binding tags
+func (c *Coupon) Validate() error {
+	switch c.Type {
+	case TypePercentage:
+		if c.Value < 1 || c.Value > 100 {
+			return &CouponError{Code: c.Code, Reason: "percentage value must be between 1 and 100"}
+		}
+	case TypeFixed:
+		if c.Value < 1 {
+			return &CouponError{Code: c.Code, Reason: "fixed value must be at least 1 cent"}
+		}
+	}
+	if c.StartsAt != nil && c.EndsAt != nil && !c.EndsAt.After(*c.StartsAt) {
+		return &CouponError{Code: c.Code, Reason: "endsAt must be after startsAt"}
+	}
+	return nil
+}
+
+// eligible reports whether a line falls within the coupon's product/category scope
+func (c *Coupon) eligible(line Line) bool {
+	if len(c.ProductIDs) == 0 && len(c.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ProductIDs {
+		if id == line.ProductID {
+			return true
+		}
+	}
+	for _, scoped := range c.CategoryIDs {
+		for _, id := range line.CategoryIDs {
+			if id == scoped {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Evaluate checks the coupon rules (active flag, validity window, minimum order value and scope)
+// against the lines and calculates the discount. shippingCents is the shipping cost to waive for
+// free shipping coupons; pass 0 while the shipping method is not yet known.
+// Usage limits are checked separately because they require the database.
+func (c *Coupon) Evaluate(lines []Line, shippingCents int, now time.Time) (*DiscountLine, error) {
+	if c.Active != nil && !*c.Active {
+		return nil, &CouponError{Code: c.Code, Reason: "coupon is not active"}
+	}
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return nil, &CouponError{Code: c.Code, Reason: "coupon is not valid yet"}
+	}
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return nil, &CouponError{Code: c.Code, Reason: "coupon has expired"}
+	}
+
+	subtotal, eligibleSubtotal := 0, 0
+	for _, line := range lines {
+		lineTotal := line.PriceCents * line.Quantity
+		subtotal += lineTotal
+		if c.eligible(line) {
+			eligibleSubtotal += lineTotal
+		}
+	}
+
+	if subtotal < c.MinOrderCents {
+		return nil, &CouponError{Code: c.Code, Reason: "minimum order value not reached"}
+	}
+	if eligibleSubtotal == 0 {
+		return nil, &CouponError{Code: c.Code, Reason: "no eligible products in cart"}
+	}
+
+	discount := &DiscountLine{CouponID: c.ID, Code: c.Code, Type: c.Type, Description: c.Description}
+	switch c.Type {
+	case TypePercentage:
+		discount.AmountCents = eligibleSubtotal * c.Value / 100
+	case TypeFixed:
+		discount.AmountCents = min(c.Value, eligibleSubtotal)
+	case TypeFreeShipping:
+		discount.FreeShipping = true
+		discount.AmountCents = shippingCents
+	}
+	return discount, nil
+}
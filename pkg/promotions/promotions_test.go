@@ -0,0 +1,79 @@
+package promotions
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestCouponEvaluate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lines := []Line{
+		{ProductID: 1, CategoryIDs: []int64{10}, Quantity: 2, PriceCents: 1000},
+		{ProductID: 2, CategoryIDs: []int64{20}, Quantity: 1, PriceCents: 3000},
+	}
+
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		shipping int
+		want     int
+	}{
+		{"percentage on whole cart", Coupon{Type: TypePercentage, Value: 10, Active: boolPtr(true)}, 0, 500},
+		{"percentage on product", Coupon{Type: TypePercentage, Value: 50, Active: boolPtr(true), ProductIDs: []int64{1}}, 0, 1000},
+		{"fixed on category", Coupon{Type: TypeFixed, Value: 500, Active: boolPtr(true), CategoryIDs: []int64{20}}, 0, 500},
+		{"fixed capped at eligible subtotal", Coupon{Type: TypeFixed, Value: 5000, Active: boolPtr(true), ProductIDs: []int64{1}}, 0, 2000},
+		{"free shipping", Coupon{Type: TypeFreeShipping, Active: boolPtr(true)}, 495, 495},
+		{"active flag omitted", Coupon{Type: TypeFixed, Value: 100}, 0, 100},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.coupon.Evaluate(lines, tt.shipping, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got.AmountCents != tt.want {
+			t.Errorf("%s: AmountCents = %d, want %d", tt.name, got.AmountCents, tt.want)
+		}
+	}
+}
+
+func TestCouponEvaluateRejects(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	lines := []Line{{ProductID: 1, Quantity: 1, PriceCents: 1000}}
+
+	tests := []struct {
+		name   string
+		coupon Coupon
+	}{
+		{"inactive", Coupon{Type: TypeFixed, Value: 100, Active: boolPtr(false)}},
+		{"not started", Coupon{Type: TypeFixed, Value: 100, Active: boolPtr(true), StartsAt: &future}},
+		{"expired", Coupon{Type: TypeFixed, Value: 100, Active: boolPtr(true), EndsAt: &past}},
+		{"minimum order", Coupon{Type: TypeFixed, Value: 100, Active: boolPtr(true), MinOrderCents: 1001}},
+		{"out of scope", Coupon{Type: TypeFixed, Value: 100, Active: boolPtr(true), ProductIDs: []int64{2}}},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.coupon.Evaluate(lines, 0, now)
+		var couponErr *CouponError
+		if !errors.As(err, &couponErr) {
+			t.Errorf("%s: expected CouponError, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestCouponValidate(t *testing.T) {
+	if err := (&Coupon{Type: TypePercentage, Value: 101}).Validate(); err == nil {
+		t.Error("expected percentage above 100 to be invalid")
+	}
+	if err := (&Coupon{Type: TypeFixed}).Validate(); err == nil {
+		t.Error("expected fixed coupon without value to be invalid")
+	}
+	if err := (&Coupon{Type: TypeFreeShipping}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is implemented by both db.DB and pgx transactions so coupon checks
+// can run inside the order creation transaction
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ErrCouponNotFound is returned when no coupon exists for a code
+var ErrCouponNotFound = errors.New("coupon not found")
+
+const couponColumns = `id, code, COALESCE(description, ''), type, value, min_order_cents, max_uses, max_uses_per_user,
+	          starts_at, ends_at, active, created_at, updated_at`
+
+func scanCoupon(row pgx.Row, c *Coupon) error {
+	return row.Scan(&c.ID, &c.Code, &c.Description, &c.Type, &c.Value, &c.MinOrderCents, &c.MaxUses, &c.MaxUsesPerUser,
+		&c.StartsAt, &c.EndsAt, &c.Active, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// loadScope loads the product and category IDs a coupon is restricted to
+func (c *Coupon) loadScope(ctx context.Context, q Querier) error {
+	c.ProductIDs = []int64{}
+	c.CategoryIDs = []int64{}
+	err := q.QueryRow(ctx, `
+		SELECT
+		  COALESCE((SELECT array_agg(product_id ORDER BY product_id) FROM coupon_products WHERE coupon_id=$1), '{}'),
+		  COALESCE((SELECT array_agg(category_id ORDER BY category_id) FROM coupon_categories WHERE coupon_id=$1), '{}')
+	`, c.ID).Scan(&c.ProductIDs, &c.CategoryIDs)
+	return err
+}
+
+// saveScope replaces the product and category scope of a coupon
+func (c *Coupon) saveScope(ctx context.Context, q Querier) error {
+	if _, err := q.Exec(ctx, `DELETE FROM coupon_products WHERE coupon_id=$1`, c.ID); err != nil {
+		return err
+	}
+	if _, err := q.Exec(ctx, `DELETE FROM coupon_categories WHERE coupon_id=$1`, c.ID); err != nil {
+		return err
+	}
+	if len(c.ProductIDs) > 0 {
+		_, err := q.Exec(ctx, `INSERT INTO coupon_products (coupon_id, product_id) SELECT $1, unnest($2::bigint[]) ON CONFLICT DO NOTHING`, c.ID, c.ProductIDs)
+		if err != nil {
+			return err
+		}
+	}
+	if len(c.CategoryIDs) > 0 {
+		_, err := q.Exec(ctx, `INSERT INTO coupon_categories (coupon_id, category_id) SELECT $1, unnest($2::bigint[]) ON CONFLICT DO NOTHING`, c.ID, c.CategoryIDs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCoupons retrieves all coupons including their scope, newest first
+// used in: cart-service handlers.GetCoupons
+func GetCoupons(ctx context.Context) ([]Coupon, error) {
+	rows, err := db.DB.Query(ctx, `SELECT `+couponColumns+` FROM coupons ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := []Coupon{}
+	for rows.Next() {
+		var c Coupon
+		if err := scanCoupon(rows, &c); err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range coupons {
+		if err := coupons[i].loadScope(ctx, db.DB); err != nil {
+			return nil, err
+		}
+	}
+	return coupons, nil
+}
+
+// GetCouponByID retrieves a coupon including its scope
+// used in: cart-service models.Cart, handlers.UpdateCoupon, handlers.DeleteCoupon
+func GetCouponByID(ctx context.Context, id int64) (*Coupon, error) {
+	var c Coupon
+	if err := scanCoupon(db.DB.QueryRow(ctx, `SELECT `+couponColumns+` FROM coupons WHERE id=$1`, id), &c); err != nil {
+		return nil, err
+	}
+	if err := c.loadScope(ctx, db.DB); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCouponByCode retrieves a coupon by its case-insensitive code including its scope
+// used in: cart-service handlers.ApplyCoupon
+func GetCouponByCode(ctx context.Context, code string) (*Coupon, error) {
+	var c Coupon
+	err := scanCoupon(db.DB.QueryRow(ctx, `SELECT `+couponColumns+` FROM coupons WHERE upper(code)=$1`, NormalizeCode(code)), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+	if err := c.loadScope(ctx, db.DB); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// InsertCoupon creates a new coupon with its scope, active unless Active is false
+// used in: cart-service handlers.CreateCoupon
+func (c *Coupon) InsertCoupon(ctx context.Context) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	c.Code = NormalizeCode(c.Code)
+	query := `INSERT INTO coupons (code, description, type, value, min_order_cents, max_uses, max_uses_per_user, starts_at, ends_at, active, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, TRUE), now())
+	          RETURNING id, active, created_at`
+	err = tx.QueryRow(ctx, query, c.Code, c.Description, c.Type, c.Value, c.MinOrderCents, c.MaxUses, c.MaxUsesPerUser,
+		c.StartsAt, c.EndsAt, c.Active).Scan(&c.ID, &c.Active, &c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := c.saveScope(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpdateCoupon updates a coupon and replaces its scope
+// used in: cart-service handlers.UpdateCoupon
+func (c *Coupon) UpdateCoupon(ctx context.Context) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	c.Code = NormalizeCode(c.Code)
+	query := `UPDATE coupons
+	          SET code=$1, description=$2, type=$3, value=$4, min_order_cents=$5, max_uses=$6, max_uses_per_user=$7,
+	              starts_at=$8, ends_at=$9, active=$10, updated_at=now()
+	          WHERE id=$11
+	          RETURNING updated_at`
+	err = tx.QueryRow(ctx, query, c.Code, c.Description, c.Type, c.Value, c.MinOrderCents, c.MaxUses, c.MaxUsesPerUser,
+		c.StartsAt, c.EndsAt, c.Active, c.ID).Scan(&c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := c.saveScope(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteCoupon removes a coupon; carts lose it and orders keep their discount lines
+// used in: cart-service handlers.DeleteCoupon
+func (c *Coupon) DeleteCoupon(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, `DELETE FROM coupons WHERE id=$1`, c.ID)
+	return err
+}
+
+// CheckUsage verifies the global and per-user usage limits; cancelled orders do not count.
+// userID is nil for guest carts, whose per-user limit is checked by email at checkout.
+// used in: cart-service handlers.ApplyCoupon, cart-service models.Cart
+func (c *Coupon) CheckUsage(ctx context.Context, userID *int64) error {
+	return c.checkUsage(ctx, db.DB, userID, nil)
+}
+
+// checkUsage counts the active redemptions of the coupon using the given querier;
+// guests are identified by their email
+func (c *Coupon) checkUsage(ctx context.Context, q Querier, userID *int64, guestEmail *string) error {
+	if c.MaxUses == nil && c.MaxUsesPerUser == nil {
+		return nil
+	}
+
+	var total, byUser int
+	err := q.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id=$2 OR lower(guest_email)=lower($3))
+		FROM coupon_redemptions
+		WHERE coupon_id=$1 AND released_at IS NULL
+	`, c.ID, userID, guestEmail).Scan(&total, &byUser)
+	if err != nil {
+		return err
+	}
+
+	if c.MaxUses != nil && total >= *c.MaxUses {
+		return &CouponError{Code: c.Code, Reason: "coupon usage limit reached"}
+	}
+	if c.MaxUsesPerUser != nil && byUser >= *c.MaxUsesPerUser {
+		return &CouponError{Code: c.Code, Reason: "coupon already used the maximum number of times"}
+	}
+	return nil
+}
+
+// Redeem locks the coupon, re-checks its usage limits and records the redemption for an order.
+// Must be called inside the order creation transaction so concurrent checkouts cannot exceed the limits.
+// used in: order-service models.CreateFromCart
+func (c *Coupon) Redeem(ctx context.Context, tx pgx.Tx, userID *int64, guestEmail *string, orderID int64, discountCents int) error {
+	if _, err := tx.Exec(ctx, `SELECT id FROM coupons WHERE id=$1 FOR UPDATE`, c.ID); err != nil {
+		return err
+	}
+	if err := c.checkUsage(ctx, tx, userID, guestEmail); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, guest_email, order_id, discount_cents, created_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+	`, c.ID, userID, guestEmail, orderID, discountCents)
+	return err
+}
+
+// ReleaseForOrder releases all redemptions of an order so the coupon usage counts again
+// used in: order-service models.Order.UpdateStatus
+func ReleaseForOrder(ctx context.Context, q Querier, orderID int64) error {
+	_, err := q.Exec(ctx, `UPDATE coupon_redemptions SET released_at=now() WHERE order_id=$1 AND released_at IS NULL`, orderID)
+	return err
+}
+
+// CartLines loads the lines of a cart including the category IDs of each product
+// used in: cart-service models.Cart, order-service models.CreateFromCart
+func CartLines(ctx context.Context, q Querier, cartID int64) ([]Line, error) {
+	rows, err := q.Query(ctx, `
+		SELECT ci.product_id, ci.quantity, ci.price_cents,
+		       COALESCE((SELECT array_agg(pc.category_id) FROM product_categories pc WHERE pc.product_id = ci.product_id), '{}')
+		FROM cart_items ci
+		WHERE ci.cart_id=$1
+	`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []Line{}
+	for rows.Next() {
+		var line Line
+		if err := rows.Scan(&line.ProductID, &line.Quantity, &line.PriceCents, &line.CategoryIDs); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,102 @@
+package returns
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Return statuses
+const (
+	StatusRequested = "requested" // customer asked to return items
+	StatusApproved  = "approved"  // admin accepted the request, customer may send the items back
+	StatusRejected  = "rejected"  // admin declined the request or the parcel never arrived
+	StatusReceived  = "received"  // items arrived and were inspected
+	StatusRefunded  = "refunded"  // accepted items were refunded
+	StatusClosed    = "closed"    // items received but nothing was accepted, no refund
+)
+
+// Return reasons a customer can choose from
+var Reasons = []string{"damaged", "wrong_item", "not_as_described", "no_longer_needed", "other"}
+
+// Conditions of a returned item after inspection
+var Conditions = []string{"resellable", "opened", "damaged"}
+
+// ReturnableOrderStatuses are the order statuses that allow return requests (at least one parcel shipped)
+var ReturnableOrderStatuses = []string{"partially_shipped", "shipped", "delivered"}
+
+var transitions = map[string][]string{
+	StatusRequested: {StatusApproved, StatusRejected},
+	StatusApproved:  {StatusReceived, StatusRejected},
+	StatusReceived:  {StatusRefunded, StatusClosed},
+}
+
+// CanTransition reports whether a return may move from one status to another
+func CanTransition(from, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// ReturnError explains why a return request or inspection is invalid
+type ReturnError struct {
+	Reason string
+}
+
+func (e *ReturnError) Error() string {
+	return "invalid return: " + e.Reason
+}
+
+// Returnable returns the quantity per order item that can still be returned: shipped minus the quantities
+// of earlier returns that were not rejected (zero entries are omitted)
+func Returnable(shipped, returned map[int64]int) map[int64]int {
+	returnable := make(map[int64]int, len(shipped))
+	for itemID, qty := range shipped {
+		if left := qty - returned[itemID]; left > 0 {
+			returnable[itemID] = left
+		}
+	}
+	return returnable
+}
+
+// CheckRequest validates the requested quantities per order item against what can still be returned
+func CheckRequest(shipped, returned, requested map[int64]int) error {
+	if len(requested) == 0 {
+		return &ReturnError{Reason: "no items to return"}
+	}
+	returnable := Returnable(shipped, returned)
+	for itemID, qty := range requested {
+		if qty <= 0 {
+			return &ReturnError{Reason: fmt.Sprintf("quantity of order item %d must be positive", itemID)}
+		}
+		if qty > returnable[itemID] {
+			return &ReturnError{Reason: fmt.Sprintf("order item %d has only %d returnable", itemID, returnable[itemID])}
+		}
+	}
+	return nil
+}
+
+// CheckInspection validates the accepted quantities per order item against the requested ones
+func CheckInspection(requested, accepted map[int64]int) error {
+	for itemID, qty := range accepted {
+		max, ok := requested[itemID]
+		if !ok {
+			return &ReturnError{Reason: fmt.Sprintf("order item %d is not part of the return", itemID)}
+		}
+		if qty < 0 || qty > max {
+			return &ReturnError{Reason: fmt.Sprintf("accepted quantity of order item %d must be between 0 and %d", itemID, max)}
+		}
+	}
+	return nil
+}
+
+// RefundCents returns the refund for accepted lines worth lineCents at list price. Order discounts are
+// spread over the subtotal, so the refund is reduced by the line's share of discountCents. Shipping is
+// not refunded.
+func RefundCents(lineCents, subtotalCents, discountCents int) int {
+	if lineCents <= 0 {
+		return 0
+	}
+	if subtotalCents <= 0 || discountCents <= 0 {
+		return lineCents
+	}
+	share := (int64(lineCents)*int64(discountCents) + int64(subtotalCents)/2) / int64(subtotalCents)
+	return max(lineCents-int(share), 0)
+}
@@ -0,0 +1,94 @@
+package returns
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusRequested, StatusApproved, true},
+		{StatusRequested, StatusRejected, true},
+		{StatusApproved, StatusReceived, true},
+		{StatusReceived, StatusRefunded, true},
+		{StatusReceived, StatusClosed, true},
+		{StatusRequested, StatusReceived, false},
+		{StatusRejected, StatusApproved, false},
+		{StatusRefunded, StatusReceived, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	shipped := map[int64]int{1: 2, 2: 1}
+	returned := map[int64]int{1: 1}
+
+	tests := []struct {
+		name      string
+		requested map[int64]int
+		wantErr   bool
+	}{
+		{"returnable quantities", map[int64]int{1: 1, 2: 1}, false},
+		{"empty", map[int64]int{}, true},
+		{"already returned", map[int64]int{1: 2}, true},
+		{"not shipped", map[int64]int{3: 1}, true},
+		{"zero quantity", map[int64]int{2: 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRequest(shipped, returned, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var returnErr *ReturnError
+			if err != nil && !errors.As(err, &returnErr) {
+				t.Errorf("expected *ReturnError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestCheckInspection(t *testing.T) {
+	requested := map[int64]int{1: 2}
+
+	if err := CheckInspection(requested, map[int64]int{1: 0}); err != nil {
+		t.Errorf("nothing accepted: unexpected error %v", err)
+	}
+	if err := CheckInspection(requested, map[int64]int{1: 2}); err != nil {
+		t.Errorf("all accepted: unexpected error %v", err)
+	}
+	if err := CheckInspection(requested, map[int64]int{1: 3}); err == nil {
+		t.Error("more than requested: expected error")
+	}
+	if err := CheckInspection(requested, map[int64]int{2: 1}); err == nil {
+		t.Error("foreign item: expected error")
+	}
+}
+
+func TestRefundCents(t *testing.T) {
+	tests := []struct {
+		name                     string
+		line, subtotal, discount int
+		want                     int
+	}{
+		{"no discount", 2999, 5998, 0, 2999},
+		{"half of a discounted order", 5000, 10000, 1000, 4500},
+		{"rounded share", 3333, 10000, 1000, 3000},
+		{"nothing accepted", 0, 10000, 1000, 0},
+		{"discount exceeds line", 100, 100, 500, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RefundCents(tt.line, tt.subtotal, tt.discount); got != tt.want {
+				t.Errorf("RefundCents() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
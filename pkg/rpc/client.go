@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// AddrEnvName returns the environment variable holding the gRPC address of a service, e.g. PRODUCT_SERVICE_GRPC_ADDR
+func AddrEnvName(service string) string {
+	return strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_GRPC_ADDR"
+}
+
+// Dial returns a connection to the gRPC server of a service at <SERVICE>_GRPC_ADDR (default <service>:9090).
+// Calls are signed by serviceauth.SignerFromEnv, carry the request id and trace context of ctx and time out after
+// GRPC_CLIENT_TIMEOUT (default 10s) unless ctx has an earlier deadline. The connection is established lazily on the first call.
+func Dial(service string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	addr := os.Getenv(AddrEnvName(service))
+	if addr == "" {
+		addr = service + ":" + DefaultPort
+	}
+
+	timeout, err := env.Duration("GRPC_CLIENT_TIMEOUT", defaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := serviceauth.SignerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(clientInterceptor(service, signer, timeout)),
+		tracing.DialOption(),
+	}, opts...)
+	return grpc.NewClient(addr, opts...)
+}
+
+// MustDial is Dial for package-level clients; it panics on invalid configuration like serviceauth.InternalAuth
+func MustDial(service string, opts ...grpc.DialOption) *grpc.ClientConn {
+	conn, err := Dial(service, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("failed to set up gRPC client for %s: %v", service, err))
+	}
+	return conn
+}
+
+// WithRetries retries the given methods of a gRPC service when the server is unavailable. Only pass methods that
+// can safely run twice: the first attempt may have been executed before the connection broke. A retry carries the
+// signature of the first attempt, so the server rejects it as a replay if the first attempt did reach it.
+func WithRetries(service string, methods ...string) grpc.DialOption {
+	type name struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	names := make([]name, 0, len(methods))
+	for _, m := range methods {
+		names = append(names, name{Service: service, Method: m})
+	}
+	config := map[string]any{
+		"methodConfig": []any{map[string]any{
+			"name": names,
+			"retryPolicy": map[string]any{
+				"maxAttempts":          3,
+				"initialBackoff":       "0.2s",
+				"maxBackoff":           "2s",
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE"},
+			},
+		}},
+	}
+	b, _ := json.Marshal(config)
+	return grpc.WithDefaultServiceConfig(string(b))
+}
+
+// clientInterceptor adds the signature, the request id and the call timeout to outgoing calls
+func clientInterceptor(service string, signer *serviceauth.Signer, timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if signer == nil {
+			return serviceauth.ErrNoServiceKey
+		}
+		ctx, err := signer.SignRPC(ctx, method, req)
+		if err != nil {
+			return err
+		}
+		if reqID := logger.RequestIDFromContext(ctx); reqID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestIDKey(), reqID)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		start := time.Now()
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		code := status.Code(err)
+		metrics.ObserveCall(service, method, code.String(), serverFailure[code], time.Since(start))
+		return err
+	}
+}
+
+// serverFailure are the codes counted as failed calls, like 5xx statuses of HTTP calls; the others are answers of
+// the server, e.g. NotFound
+var serverFailure = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.DeadlineExceeded: true,
+	codes.Unimplemented:    true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+}
@@ -0,0 +1,142 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startServer serves the standard health service on an in-memory listener, callable by cart-service only, and
+// returns a dial option for it
+func startServer(t *testing.T) grpc.DialOption {
+	t.Helper()
+	t.Setenv("SERVICE_CALLER_KEYS", "cart-service=cart-key,order-service=order-key")
+
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(serviceauth.Allowlist{healthpb.Health_Check_FullMethodName: {"cart-service"}})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+}
+
+// check calls the health service as the service configured in SERVICE_NAME and SERVICE_KEY
+func check(t *testing.T, dialer grpc.DialOption) error {
+	t.Helper()
+	t.Setenv(AddrEnvName("test-service"), "passthrough:///bufnet")
+	conn, err := Dial("test-service", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestServiceAuth(t *testing.T) {
+	dialer := startServer(t)
+
+	t.Setenv("SERVICE_NAME", "cart-service")
+	t.Setenv("SERVICE_KEY", "cart-key")
+	if err := check(t, dialer); err != nil {
+		t.Fatalf("signed call: %v", err)
+	}
+
+	t.Setenv("SERVICE_KEY", "wrong")
+	if err := check(t, dialer); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong key: err = %v, want Unauthenticated", err)
+	}
+
+	t.Setenv("SERVICE_NAME", "order-service")
+	t.Setenv("SERVICE_KEY", "order-key")
+	if err := check(t, dialer); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("caller not on allowlist: err = %v, want PermissionDenied", err)
+	}
+
+	t.Setenv("SERVICE_NAME", "")
+	t.Setenv("SERVICE_KEY", "")
+	if err := check(t, dialer); !errors.Is(err, serviceauth.ErrNoServiceKey) {
+		t.Errorf("no key: err = %v, want ErrNoServiceKey", err)
+	}
+}
+
+func TestDialConfig(t *testing.T) {
+	t.Setenv("GRPC_CLIENT_TIMEOUT", "soon")
+	if _, err := Dial("product-service"); err == nil {
+		t.Error("expected error for invalid GRPC_CLIENT_TIMEOUT")
+	}
+	if AddrEnvName("product-service") != "PRODUCT_SERVICE_GRPC_ADDR" {
+		t.Errorf("AddrEnvName = %q", AddrEnvName("product-service"))
+	}
+}
+
+func TestClientInterceptor(t *testing.T) {
+	var md metadata.MD
+	var deadline time.Time
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		deadline, _ = ctx.Deadline()
+		return nil
+	}
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	signer := serviceauth.NewSigner("cart-service", []byte("key"))
+	if err := clientInterceptor("product-service", signer, time.Minute)(ctx, "/svc/Method", &healthpb.HealthCheckRequest{}, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if got := md.Get(serviceauth.CallerHeader); len(got) != 1 || got[0] != "cart-service" {
+		t.Errorf("caller = %v", got)
+	}
+	if got := md.Get(requestIDKey()); len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("request id = %v", got)
+	}
+	if time.Until(deadline) > time.Minute || time.Until(deadline) < 50*time.Second {
+		t.Errorf("deadline = %v", deadline)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDKey(), "req-1"))
+
+	var reqID string
+	handler := func(ctx context.Context, req any) (any, error) {
+		reqID = logger.RequestIDFromContext(ctx)
+		return nil, nil
+	}
+	loggingInterceptor(ctx, nil, info, handler)
+	if reqID != "req-1" {
+		t.Errorf("request id = %q, want req-1", reqID)
+	}
+
+	loggingInterceptor(context.Background(), nil, info, handler)
+	if reqID == "" {
+		t.Error("no request id generated")
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	_, err := recoveryInterceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("err = %v, want Internal", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package rpc contains the gRPC plumbing of the internal service-to-service API. Servers and clients exchange the
+// service signature (see serviceauth) and the request id as metadata, the gRPC counterparts of the headers used by
+// the REST endpoints.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPort is the port the gRPC servers listen on inside their containers
+const DefaultPort = "9090"
+
+// NewServer returns a gRPC server for internal calls. Every call continues the trace of the caller and gets a
+// request-scoped logger carrying the caller's request id, panics are turned into Internal errors and only calls
+// signed by a service on the allowlist of the method are let through.
+func NewServer(allow serviceauth.Allowlist, opts ...grpc.ServerOption) *grpc.Server {
+	interceptors := grpc.ChainUnaryInterceptor(loggingInterceptor, recoveryInterceptor, serviceauth.UnaryServerInterceptor(allow))
+	return grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), interceptors}, opts...)...)
+}
+
+// ListenAndServe serves srv on GRPC_PORT (default 9090) and blocks until the server stops
+func ListenAndServe(srv *grpc.Server) error {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = DefaultPort
+	}
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on gRPC port %s: %w", port, err)
+	}
+	slog.Info("gRPC server listening", "port", port)
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// requestIDKey returns the metadata key for request ids; metadata keys are lower case
+func requestIDKey() string {
+	return strings.ToLower(logger.RequestIDHeader())
+}
+
+// loggingInterceptor is the gRPC counterpart of logger.GinMiddleware
+func loggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var reqID string
+	if values := md.Get(requestIDKey()); len(values) > 0 {
+		reqID = values[0]
+	}
+	if reqID == "" {
+		reqID = uuid.NewString()
+	}
+
+	l := slog.Default().With(
+		slog.String("req_id", reqID),
+		slog.Group("client", slog.String("method", info.FullMethod)),
+	)
+	ctx = logger.WithRequestID(logger.NewContext(ctx, l), reqID)
+
+	resp, err := handler(ctx, req)
+
+	logger.FromContext(ctx).Info("rpc completed",
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return resp, err
+}
+
+// recoveryInterceptor turns a panic in a handler into an Internal error instead of crashing the service
+func recoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Error("panic in rpc handler", "method", info.FullMethod, "panic", r)
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
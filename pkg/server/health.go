@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+
+	"github.com/gin-gonic/gin"
+)
+
+const checkTimeout = 2 * time.Second
+
+// CheckFunc reports whether a dependency of the service works
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	run      CheckFunc
+	required bool // a failing required check makes the service unready, the others are only reported
+}
+
+// health answers /healthz and /readyz
+type health struct {
+	mu       sync.Mutex
+	checks   []check
+	draining atomic.Bool // set on shutdown, so no new requests are routed to the service
+}
+
+func (h *health) add(c check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, c)
+}
+
+// live answers /healthz: the process is up and serves requests
+func (h *health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ready answers /readyz with the result of every check; 503 if a required check fails or the service shuts down,
+// "degraded" if only dependencies fail
+func (h *health) ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+
+	h.mu.Lock()
+	checks := append([]check(nil), h.checks...)
+	h.mu.Unlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Request.Context(), checkTimeout)
+			defer cancel()
+			errs[i] = chk.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	status, code := "ready", http.StatusOK
+	results := map[string]string{}
+	for i, chk := range checks {
+		if errs[i] == nil {
+			results[chk.name] = "ok"
+			continue
+		}
+		results[chk.name] = errs[i].Error()
+		if chk.required {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		} else if code == http.StatusOK {
+			status = "degraded"
+		}
+	}
+	c.JSON(code, gin.H{"status": status, "checks": results})
+}
+
+var healthClient = &http.Client{Timeout: checkTimeout}
+
+// serviceCheck checks a service the current one calls by its /healthz endpoint; only liveness is checked, so a
+// failing dependency further down does not spread through all its callers
+func serviceCheck(service string) CheckFunc {
+	target := httpclient.ServiceURL(service) + "/healthz"
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := healthClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func readyz(t *testing.T, h *health) (int, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", h.ready)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, body
+}
+
+func TestReady(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name   string
+		checks []check
+		code   int
+		status string
+	}{
+		{"all checks pass", []check{{"database", ok, true}, {"product-service", ok, false}}, http.StatusOK, "ready"},
+		{"dependency down", []check{{"database", ok, true}, {"product-service", failing, false}}, http.StatusOK, "degraded"},
+		{"database down", []check{{"database", failing, true}, {"product-service", failing, false}}, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := readyz(t, &health{checks: tt.checks})
+			if code != tt.code || body["status"] != tt.status {
+				t.Fatalf("got %d %v, want %d %s", code, body["status"], tt.code, tt.status)
+			}
+			checks := body["checks"].(map[string]any)
+			for _, c := range tt.checks {
+				if c.run(context.Background()) == nil && checks[c.name] != "ok" {
+					t.Errorf("check %s: got %v, want ok", c.name, checks[c.name])
+				}
+			}
+		})
+	}
+}
+
+func TestReadyWhileDraining(t *testing.T) {
+	h := &health{checks: []check{{"database", func(ctx context.Context) error { return nil }, true}}}
+	h.draining.Store(true)
+
+	code, body := readyz(t, h)
+	if code != http.StatusServiceUnavailable || body["status"] != "shutting down" {
+		t.Fatalf("got %d %v, want 503 shutting down", code, body["status"])
+	}
+}
+
+func TestServiceCheck(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	t.Setenv("PRODUCT_SERVICE_URL", srv.URL)
+
+	check := serviceCheck("product-service")
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("healthy service: %v", err)
+	}
+	status = http.StatusInternalServerError
+	if err := check(context.Background()); err == nil {
+		t.Fatal("expected an error for a failing service")
+	}
+}
@@ -0,0 +1,219 @@
+// Package server is the common bootstrap of the services: it sets up logging, tracing and the database, serves the
+// Gin router (and the gRPC server of the internal API) with /healthz and /readyz and shuts everything down
+// gracefully on SIGTERM.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// Addr is the address the HTTP server listens on inside the container; Docker Compose maps it to <SERVICE>_PORT
+const Addr = ":8080"
+
+// defaultShutdownTimeout stays below the 10s Docker waits after SIGTERM before killing a container
+const defaultShutdownTimeout = 8 * time.Second
+
+// defaultDrainDelay gives load balancers and the gateway time to see /readyz fail before new connections are refused
+const defaultDrainDelay = 2 * time.Second
+
+// Server runs a service
+type Server struct {
+	Name   string
+	Router *gin.Engine
+
+	ctx             context.Context // cancelled on SIGTERM/SIGINT
+	stop            context.CancelFunc
+	health          *health
+	grpc            *grpc.Server
+	jobs            sync.WaitGroup
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	shutdownTracing func(context.Context) error
+}
+
+// New initialises the logger, tracing and the database (see db.Connect, its pool statistics are exported as metrics)
+// of a service and creates its router with /healthz and /readyz; the database is a required readiness check.
+// SHUTDOWN_TIMEOUT (Go duration, default 8s) is how long a shutdown waits for running requests and jobs.
+// READINESS_DRAIN_DELAY (Go duration, default 2s, 0 = off) is how long a shutting down service keeps serving with a
+// failing /readyz; it is part of SHUTDOWN_TIMEOUT and must be shorter.
+func New(name string) (*Server, error) {
+	if err := logger.InitFromEnv(); err != nil {
+		return nil, fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	s := &Server{Name: name, health: &health{}}
+	var err error
+	if s.shutdownTimeout, s.drainDelay, err = shutdownTimeouts(); err != nil {
+		return nil, err
+	}
+	s.ctx, s.stop = signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+
+	if s.shutdownTracing, err = tracing.Init(s.ctx, name); err != nil {
+		return nil, fmt.Errorf("failed to init tracing: %w", err)
+	}
+	if err := db.Connect(s.ctx); err != nil {
+		return nil, err
+	}
+	metrics.RegisterPool(db.DB)
+	s.health.add(check{name: "database", run: db.DB.Ping, required: true})
+
+	gin.DefaultWriter = io.Discard
+	s.Router = gin.Default()
+	// registered before the middlewares of the service, so probes are neither logged, traced nor counted
+	s.Router.GET("/healthz", s.health.live)
+	s.Router.GET("/readyz", s.health.ready)
+	return s, nil
+}
+
+// RequireReady adds a check the service cannot work without to /readyz
+func (s *Server) RequireReady(name string, fn CheckFunc) {
+	s.health.add(check{name: name, run: fn, required: true})
+}
+
+// DependsOn adds services the current one calls to /readyz, checked by their /healthz. A service that is down is
+// reported, but does not make the current one unready: its other endpoints keep working, and the clients' circuit
+// breakers already fail fast.
+func (s *Server) DependsOn(services ...string) {
+	for _, service := range services {
+		s.health.add(check{name: service, run: serviceCheck(service)})
+	}
+}
+
+// ServeGRPC serves srv (see rpc.NewServer) next to the HTTP server when Run is called
+func (s *Server) ServeGRPC(srv *grpc.Server) {
+	s.grpc = srv
+}
+
+// Go runs a background job until the service shuts down; ctx is cancelled on shutdown and the job is waited for
+// before the database is closed
+func (s *Server) Go(job func(ctx context.Context)) {
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		job(s.ctx)
+	}()
+}
+
+// Run serves until SIGTERM or SIGINT and then shuts down gracefully: /readyz reports 503 while requests are still
+// served for READINESS_DRAIN_DELAY, then the servers stop accepting connections and wait for running requests and
+// background jobs, all within SHUTDOWN_TIMEOUT. The database is closed last and the buffered spans are flushed.
+// Returns an error if a server could not be started.
+func (s *Server) Run() error {
+	httpServer := &http.Server{Addr: Addr, Handler: s.Router}
+	errc := make(chan error, 2)
+	go func() {
+		slog.Info("HTTP server listening", "service", s.Name, "addr", Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errc <- fmt.Errorf("HTTP server failed: %w", err)
+		}
+	}()
+	if s.grpc != nil {
+		go func() {
+			if err := rpc.ListenAndServe(s.grpc); err != nil {
+				errc <- fmt.Errorf("gRPC server failed: %w", err)
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-s.ctx.Done():
+		slog.Info("shutting down", "service", s.Name)
+	case runErr = <-errc:
+		slog.Error("shutting down after server failure", "service", s.Name, "error", runErr)
+	}
+	s.stop()
+	s.health.draining.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	// callers still routed here before they notice the failing /readyz are served, not refused
+	if runErr == nil && s.drainDelay > 0 {
+		slog.Info("draining before shutdown", "service", s.Name, "delay", s.drainDelay)
+		time.Sleep(s.drainDelay)
+	}
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		slog.Warn("requests still running at shutdown timeout", "error", err)
+	}
+	if s.grpc != nil {
+		stopGRPC(ctx, s.grpc)
+	}
+	if !wait(ctx, &s.jobs) {
+		slog.Warn("background jobs still running at shutdown timeout")
+	}
+
+	db.Close()
+	if err := s.shutdownTracing(ctx); err != nil {
+		slog.Warn("failed to flush spans", "error", err)
+	}
+	slog.Info("shutdown complete", "service", s.Name)
+	logger.Sync()
+	return runErr
+}
+
+// shutdownTimeouts reads SHUTDOWN_TIMEOUT and READINESS_DRAIN_DELAY; the delay must leave time within the timeout
+// to finish running requests
+func shutdownTimeouts() (timeout, drainDelay time.Duration, err error) {
+	if timeout, err = env.Duration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout); err != nil {
+		return 0, 0, err
+	}
+	if drainDelay, err = env.NonNegativeDuration("READINESS_DRAIN_DELAY", min(defaultDrainDelay, timeout/2)); err != nil {
+		return 0, 0, err
+	}
+	if drainDelay >= timeout {
+		return 0, 0, fmt.Errorf("READINESS_DRAIN_DELAY %s must be shorter than SHUTDOWN_TIMEOUT %s", drainDelay, timeout)
+	}
+	return timeout, drainDelay, nil
+}
+
+// stopGRPC waits for running calls until ctx is done and then cancels them
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("rpc calls still running at shutdown timeout")
+		srv.Stop()
+	}
+}
+
+// wait reports whether wg finished before ctx was done
+func wait(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShutdownTimeouts(t *testing.T) {
+	tests := []struct {
+		timeout, drainDelay string
+		wantTimeout         time.Duration
+		wantDrainDelay      time.Duration
+		wantErr             bool
+	}{
+		{"", "", 8 * time.Second, 2 * time.Second, false},
+		{"", "0", 8 * time.Second, 0, false},
+		{"2s", "", 2 * time.Second, time.Second, false},
+		{"8s", "8s", 0, 0, true},
+		{"", "-1s", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("SHUTDOWN_TIMEOUT", tt.timeout)
+		t.Setenv("READINESS_DRAIN_DELAY", tt.drainDelay)
+		timeout, drainDelay, err := shutdownTimeouts()
+		if (err != nil) != tt.wantErr || timeout != tt.wantTimeout || drainDelay != tt.wantDrainDelay {
+			t.Errorf("shutdownTimeouts(%q, %q) = %v, %v, %v", tt.timeout, tt.drainDelay, timeout, drainDelay, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package shipping
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Supported shipping method types
+const (
+	TypeFlat          = "flat"           // fixed rate per order
+	TypeWeight        = "weight"         // base rate plus a rate per started kilogram
+	TypeFreeThreshold = "free_threshold" // fixed rate, free once the subtotal reaches a threshold
+)
+
+// Currency used for all shipping rates
+const Currency = "EUR"
+
+// ErrMethodUnavailable is returned when a shipping method does not exist, is inactive,
+// does not serve the destination country or cannot carry the parcel
+var ErrMethodUnavailable = errors.New("shipping method not available for this destination")
+
+// Zone groups destination countries that share the same shipping methods
+type Zone struct {
+	ID        int64      `db:"id" json:"id" swaggerignore:"true"`
+	Name      string     `db:"name" json:"name" binding:"required" example:"Germany"`
+	Countries []string   `db:"countries" json:"countries" binding:"required,min=1" example:"DE,Germany"`
+	CreatedAt time.Time  `db:"created_at" json:"createdAt" swaggerignore:"true"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
+}
+
+// Method is a configurable shipping method within a zone
+type Method struct {
+	ID             int64      `db:"id" json:"id" swaggerignore:"true"`
+	ZoneID         int64      `db:"zone_id" json:"zoneId" binding:"required" example:"1"`
+	Code           string     `db:"code" json:"code" binding:"required" example:"standard"`
+	Name           string     `db:"name" json:"name" binding:"required" example:"Standard Shipping"`
+	Type           string     `db:"type" json:"type" binding:"required,oneof=flat weight free_threshold" example:"free_threshold"`
+	RateCents      int        `db:"rate_cents" json:"rateCents" binding:"min=0" example:"495"`
+	PerKgCents     int        `db:"per_kg_cents" json:"perKgCents" binding:"min=0" example:"0"`
+	FreeAboveCents *int       `db:"free_above_cents" json:"freeAboveCents,omitempty" example:"5000"`
+	MaxWeightGrams *int       `db:"max_weight_grams" json:"maxWeightGrams,omitempty" example:"31500"`
+	Active         *bool      `db:"active" json:"active" example:"true"` // Inactive only when false; new methods default to active
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt" swaggerignore:"true"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
+}
+
+// Parcel summarizes the contents of a cart for rate calculation
+type Parcel struct {
+	SubtotalCents int `json:"subtotalCents" example:"4999"`
+	WeightGrams   int `json:"weightGrams" example:"1800"`
+}
+
+// Quote is the calculated shipping cost of a method for a parcel
+type Quote struct {
+	MethodID  int64  `json:"methodId" example:"1"`
+	Code      string `json:"code" example:"standard"`
+	Name      string `json:"name" example:"Standard Shipping"`
+	Type      string `json:"type" example:"free_threshold"`
+	ZoneName  string `json:"zoneName" example:"Germany"`
+	CostCents int    `json:"costCents" example:"495"`
+	Currency  string `json:"currency" example:"EUR"`
+}
+
+// Validate checks type specific settings that cannot be expressed with binding tags
+func (m *Method) Validate() error {
+	if m.Type == TypeFreeThreshold && m.FreeAboveCents == nil {
+		return errors.New("freeAboveCents is required for type free_threshold")
+	}
+	if m.FreeAboveCents != nil && *m.FreeAboveCents < 0 {
+		return errors.New("freeAboveCents must not be negative")
+	}
+	if m.MaxWeightGrams != nil && *m.MaxWeightGrams <= 0 {
+		return errors.New("maxWeightGrams must be positive")
+	}
+	return nil
+}
+
+// Accepts reports whether the method can carry the parcel
+func (m *Method) Accepts(p Parcel) bool {
+	if m.Active != nil && !*m.Active {
+		return false
+	}
+	return m.MaxWeightGrams == nil || p.WeightGrams <= *m.MaxWeightGrams
+}
+
+// Cost calculates the shipping cost of the method for the parcel in cents
+func (m *Method) Cost(p Parcel) int {
+	switch m.Type {
+	case TypeWeight:
+		// charge per started kilogram
+		kg := (p.WeightGrams + 999) / 1000
+		return m.RateCents + kg*m.PerKgCents
+	case TypeFreeThreshold:
+		if m.FreeAboveCents != nil && p.SubtotalCents >= *m.FreeAboveCents {
+			return 0
+		}
+		return m.RateCents
+	default:
+		return m.RateCents
+	}
+}
+
+// quote builds the quote of the method for the parcel
+func (m *Method) quote(zoneName string, p Parcel) Quote {
+	return Quote{
+		MethodID:  m.ID,
+		Code:      m.Code,
+		Name:      m.Name,
+		Type:      m.Type,
+		ZoneName:  zoneName,
+		CostCents: m.Cost(p),
+		Currency:  Currency,
+	}
+}
+
+// sortQuotes orders quotes by cost, then by name for a stable result
+func sortQuotes(quotes []Quote) {
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if quotes[i].CostCents != quotes[j].CostCents {
+			return quotes[i].CostCents < quotes[j].CostCents
+		}
+		return quotes[i].Name < quotes[j].Name
+	})
+}
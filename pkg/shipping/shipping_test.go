@@ -0,0 +1,56 @@
+package shipping
+
+import "testing"
+
+func intPtr(v int) *int { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestMethodCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		method Method
+		parcel Parcel
+		want   int
+	}{
+		{"flat", Method{Type: TypeFlat, RateCents: 495}, Parcel{SubtotalCents: 10000, WeightGrams: 5000}, 495},
+		{"weight rounds up started kg", Method{Type: TypeWeight, RateCents: 500, PerKgCents: 100}, Parcel{WeightGrams: 1001}, 700},
+		{"weight exact kg", Method{Type: TypeWeight, RateCents: 500, PerKgCents: 100}, Parcel{WeightGrams: 2000}, 700},
+		{"weight empty parcel", Method{Type: TypeWeight, RateCents: 500, PerKgCents: 100}, Parcel{}, 500},
+		{"below threshold", Method{Type: TypeFreeThreshold, RateCents: 495, FreeAboveCents: intPtr(5000)}, Parcel{SubtotalCents: 4999}, 495},
+		{"at threshold", Method{Type: TypeFreeThreshold, RateCents: 495, FreeAboveCents: intPtr(5000)}, Parcel{SubtotalCents: 5000}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.method.Cost(tt.parcel); got != tt.want {
+			t.Errorf("%s: Cost() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMethodAccepts(t *testing.T) {
+	m := Method{Active: boolPtr(true), MaxWeightGrams: intPtr(1000)}
+	if !m.Accepts(Parcel{WeightGrams: 1000}) {
+		t.Error("expected parcel at max weight to be accepted")
+	}
+	if m.Accepts(Parcel{WeightGrams: 1001}) {
+		t.Error("expected parcel above max weight to be rejected")
+	}
+	m.Active = boolPtr(false)
+	if m.Accepts(Parcel{}) {
+		t.Error("expected inactive method to reject parcels")
+	}
+	m.Active = nil
+	if !m.Accepts(Parcel{}) {
+		t.Error("expected method without active flag to be active")
+	}
+}
+
+func TestMethodValidate(t *testing.T) {
+	if err := (&Method{Type: TypeFreeThreshold}).Validate(); err == nil {
+		t.Error("expected free_threshold without freeAboveCents to be invalid")
+	}
+	if err := (&Method{Type: TypeFlat}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier is implemented by both db.DB and pgx transactions, so an order can be quoted inside the transaction
+// that creates it
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const methodColumns = `m.id, m.zone_id, m.code, m.name, m.type, m.rate_cents, m.per_kg_cents,
+	          m.free_above_cents, m.max_weight_grams, m.active, m.created_at, m.updated_at`
+
+func scanMethod(row pgx.Row, m *Method) error {
+	return row.Scan(&m.ID, &m.ZoneID, &m.Code, &m.Name, &m.Type, &m.RateCents, &m.PerKgCents,
+		&m.FreeAboveCents, &m.MaxWeightGrams, &m.Active, &m.CreatedAt, &m.UpdatedAt)
+}
+
+// GetZones retrieves all shipping zones ordered by name
+// used in: cart-service handlers.GetShippingZones
+func GetZones(ctx context.Context) ([]Zone, error) {
+	query := `SELECT id, name, countries, created_at, updated_at FROM shipping_zones ORDER BY name`
+	rows, err := db.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []Zone{}
+	for rows.Next() {
+		var z Zone
+		if err := rows.Scan(&z.ID, &z.Name, &z.Countries, &z.CreatedAt, &z.UpdatedAt); err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+	return zones, rows.Err()
+}
+
+// GetZoneByID retrieves a shipping zone by its ID
+// used in: cart-service handlers.UpdateShippingZone, handlers.DeleteShippingZone
+func GetZoneByID(ctx context.Context, id int64) (*Zone, error) {
+	var z Zone
+	query := `SELECT id, name, countries, created_at, updated_at FROM shipping_zones WHERE id=$1`
+	if err := db.DB.QueryRow(ctx, query, id).Scan(&z.ID, &z.Name, &z.Countries, &z.CreatedAt, &z.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &z, nil
+}
+
+// InsertZone creates a new shipping zone
+// used in: cart-service handlers.CreateShippingZone
+func (z *Zone) InsertZone(ctx context.Context) error {
+	query := `INSERT INTO shipping_zones (name, countries, created_at)
+	          VALUES ($1, $2, now())
+	          RETURNING id, created_at`
+	return db.DB.QueryRow(ctx, query, z.Name, z.Countries).Scan(&z.ID, &z.CreatedAt)
+}
+
+// UpdateZone updates the name and countries of a shipping zone
+// used in: cart-service handlers.UpdateShippingZone
+func (z *Zone) UpdateZone(ctx context.Context) error {
+	query := `UPDATE shipping_zones SET name=$1, countries=$2, updated_at=now()
+	          WHERE id=$3
+	          RETURNING updated_at`
+	return db.DB.QueryRow(ctx, query, z.Name, z.Countries, z.ID).Scan(&z.UpdatedAt)
+}
+
+// DeleteZone removes a shipping zone including its methods
+// used in: cart-service handlers.DeleteShippingZone
+func (z *Zone) DeleteZone(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, `DELETE FROM shipping_zones WHERE id=$1`, z.ID)
+	return err
+}
+
+// GetMethods retrieves all shipping methods ordered by zone and rate
+// used in: cart-service handlers.GetShippingMethods
+func GetMethods(ctx context.Context) ([]Method, error) {
+	query := `SELECT ` + methodColumns + `
+	          FROM shipping_methods m
+	          ORDER BY m.zone_id, m.rate_cents`
+	rows, err := db.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []Method{}
+	for rows.Next() {
+		var m Method
+		if err := scanMethod(rows, &m); err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+	return methods, rows.Err()
+}
+
+// GetMethodByID retrieves a shipping method by its ID
+// used in: cart-service handlers.UpdateShippingMethod, handlers.DeleteShippingMethod
+func GetMethodByID(ctx context.Context, id int64) (*Method, error) {
+	var m Method
+	query := `SELECT ` + methodColumns + ` FROM shipping_methods m WHERE m.id=$1`
+	if err := scanMethod(db.DB.QueryRow(ctx, query, id), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// InsertMethod creates a new shipping method, active unless Active is false
+// used in: cart-service handlers.CreateShippingMethod
+func (m *Method) InsertMethod(ctx context.Context) error {
+	query := `INSERT INTO shipping_methods (zone_id, code, name, type, rate_cents, per_kg_cents, free_above_cents, max_weight_grams, active, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, TRUE), now())
+	          RETURNING id, active, created_at`
+	return db.DB.QueryRow(ctx, query, m.ZoneID, m.Code, m.Name, m.Type, m.RateCents, m.PerKgCents,
+		m.FreeAboveCents, m.MaxWeightGrams, m.Active).Scan(&m.ID, &m.Active, &m.CreatedAt)
+}
+
+// UpdateMethod updates all settings of a shipping method
+// used in: cart-service handlers.UpdateShippingMethod
+func (m *Method) UpdateMethod(ctx context.Context) error {
+	query := `UPDATE shipping_methods
+	          SET zone_id=$1, code=$2, name=$3, type=$4, rate_cents=$5, per_kg_cents=$6,
+	              free_above_cents=$7, max_weight_grams=$8, active=$9, updated_at=now()
+	          WHERE id=$10
+	          RETURNING updated_at`
+	return db.DB.QueryRow(ctx, query, m.ZoneID, m.Code, m.Name, m.Type, m.RateCents, m.PerKgCents,
+		m.FreeAboveCents, m.MaxWeightGrams, m.Active, m.ID).Scan(&m.UpdatedAt)
+}
+
+// DeleteMethod removes a shipping method (orders keep the method name snapshot)
+// used in: cart-service handlers.DeleteShippingMethod
+func (m *Method) DeleteMethod(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, `DELETE FROM shipping_methods WHERE id=$1`, m.ID)
+	return err
+}
+
+// QuotesForCountry calculates quotes of all active methods serving the country that can carry the parcel,
+// ordered from cheapest to most expensive
+// used in: cart-service handlers.GetShippingOptions
+func QuotesForCountry(ctx context.Context, country string, p Parcel) ([]Quote, error) {
+	query := `SELECT ` + methodColumns + `, z.name
+	          FROM shipping_methods m
+	          JOIN shipping_zones z ON m.zone_id = z.id
+	          WHERE m.active AND EXISTS (SELECT 1 FROM unnest(z.countries) c WHERE lower(c) = lower(trim($1)))`
+	rows, err := db.DB.Query(ctx, query, country)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := []Quote{}
+	for rows.Next() {
+		var m Method
+		var zoneName string
+		err := rows.Scan(&m.ID, &m.ZoneID, &m.Code, &m.Name, &m.Type, &m.RateCents, &m.PerKgCents,
+			&m.FreeAboveCents, &m.MaxWeightGrams, &m.Active, &m.CreatedAt, &m.UpdatedAt, &zoneName)
+		if err != nil {
+			return nil, err
+		}
+		if !m.Accepts(p) {
+			continue
+		}
+		quotes = append(quotes, m.quote(zoneName, p))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortQuotes(quotes)
+	return quotes, nil
+}
+
+// QuoteMethod calculates the quote of a specific method, ensuring it serves the country and can carry the parcel
+// used in: order-service models.CreateFromCart, models.CreateFromGuestCart
+func QuoteMethod(ctx context.Context, q Querier, methodID int64, country string, p Parcel) (*Quote, error) {
+	query := `SELECT ` + methodColumns + `, z.name
+	          FROM shipping_methods m
+	          JOIN shipping_zones z ON m.zone_id = z.id
+	          WHERE m.id=$1 AND EXISTS (SELECT 1 FROM unnest(z.countries) c WHERE lower(c) = lower(trim($2)))`
+	var m Method
+	var zoneName string
+	err := q.QueryRow(ctx, query, methodID, country).Scan(&m.ID, &m.ZoneID, &m.Code, &m.Name, &m.Type,
+		&m.RateCents, &m.PerKgCents, &m.FreeAboveCents, &m.MaxWeightGrams, &m.Active, &m.CreatedAt, &m.UpdatedAt, &zoneName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMethodUnavailable
+		}
+		return nil, err
+	}
+	if !m.Accepts(p) {
+		return nil, ErrMethodUnavailable
+	}
+
+	quote := m.quote(zoneName, p)
+	return &quote, nil
+}
+
+// ParcelForCart sums up the subtotal and weight of all items in a cart
+// used in: cart-service models.Cart.Parcel, order-service models.CreateFromCart, models.CreateFromGuestCart
+func ParcelForCart(ctx context.Context, q Querier, cartID int64) (Parcel, error) {
+	var p Parcel
+	query := `SELECT COALESCE(SUM(ci.price_cents * ci.quantity), 0), COALESCE(SUM(p.weight_grams * ci.quantity), 0)
+	          FROM cart_items ci
+	          JOIN products p ON ci.product_id = p.id
+	          WHERE ci.cart_id=$1`
+	err := q.QueryRow(ctx, query, cartID).Scan(&p.SubtotalCents, &p.WeightGrams)
+	return p, err
+}
@@ -0,0 +1,91 @@
+// Package tracing sets up OpenTelemetry tracing. Requests, database queries and calls between the services are
+// traced with W3C trace context (traceparent header), so one trace covers a request through all services; the
+// spans are exported with OTLP when an endpoint is configured.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+var serviceName = "unknown-service"
+
+// Init installs the tracer provider of the service and W3C trace context propagation; the returned function
+// flushes the spans still buffered and must be called before the service exits.
+//
+// Spans are exported with OTLP over HTTP if OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is
+// set; the exporter reads its other settings (headers, timeout, ...) from the standard OTEL_EXPORTER_OTLP_*
+// variables. Without an endpoint spans are still created, so logs carry trace ids and the trace context is passed
+// on to the next service. OTEL_SERVICE_NAME overrides the service name, OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG the sampler (default: parent based, all traces).
+func Init(ctx context.Context, service string) (func(context.Context) error, error) {
+	serviceName = service
+	if v := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); v != "" {
+		serviceName = v
+	}
+
+	res, err := resource.New(ctx, resource.WithTelemetrySDK(), resource.WithFromEnv(),
+		resource.WithAttributes(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if exporterConfigured() {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+func exporterConfigured() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// GinMiddleware starts a server span for every request, continuing the trace of the caller. It must be registered
+// before logger.GinMiddleware, so the request logger carries the trace id.
+func GinMiddleware() gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		return c.Request.URL.Path != "/metrics"
+	}))
+}
+
+// Transport wraps an http.RoundTripper (http.DefaultTransport if nil) to trace outgoing requests and send the
+// trace context with them
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// ServerOption traces the calls a gRPC server handles
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// DialOption traces the calls of a gRPC client and sends the trace context with them
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	shutdown, err := Init(context.Background(), "test-service")
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer shutdown(context.Background())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinMiddleware())
+	var serverSpan trace.SpanContext
+	router.GET("/ping", func(c *gin.Context) {
+		serverSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "caller")
+	defer span.End()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/ping", nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if !serverSpan.IsValid() {
+		t.Fatal("expected a server span")
+	}
+	if serverSpan.TraceID() != span.SpanContext().TraceID() {
+		t.Fatalf("trace id %s not propagated, server has %s", span.SpanContext().TraceID(), serverSpan.TraceID())
+	}
+	if serverSpan.SpanID() == span.SpanContext().SpanID() {
+		t.Fatal("expected the server to start its own span")
+	}
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+	cartmodels "rearatrox/go-ecommerce-backend/services/cart-service/models"
+	productmodels "rearatrox/go-ecommerce-backend/services/product-service/models"
+	usermodels "rearatrox/go-ecommerce-backend/services/user-service/models"
 )
 
 const (
@@ -21,6 +25,14 @@ var (
 	apiPrefix          = getEnv("API_PREFIX", "/api/v1")
 )
 
+// Service clients on the ports published by docker compose
+var (
+	ctx           = context.Background()
+	userClient    = clients.NewUserClient(serviceClient("user-service", userServicePort))
+	productClient = clients.NewProductClient(serviceClient("product-service", productServicePort))
+	cartClient    = clients.NewCartClient(serviceClient("cart-service", cartServicePort))
+)
+
 // User credentials
 var (
 	adminToken     string
@@ -80,23 +92,13 @@ func waitForServices() {
 	// This also verifies that migrations have run (admin user exists)
 	fmt.Println("  Checking user-service...")
 	for i := 0; i < 30; i++ {
-		loginData := map[string]string{
-			"email":    "Admin@example.com",
-			"password": "Admin123!",
-		}
-		jsonData, _ := json.Marshal(loginData)
-		url := fmt.Sprintf("%s:%s%s/auth/login", baseURL, userServicePort, apiPrefix)
-
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-		if err == nil && (resp.StatusCode == 200 || resp.StatusCode == 401 || resp.StatusCode == 404) {
+		_, err := userClient.Login(ctx, "Admin@example.com", "Admin123!")
+		status := httpclient.StatusCode(err)
+		if err == nil || status == http.StatusUnauthorized || status == http.StatusNotFound {
 			// 200 = login success, 401/404 = service is up (just wrong credentials or user doesn't exist yet)
-			resp.Body.Close()
 			fmt.Printf("  ✓ user-service ready\n")
 			break
 		}
-		if err == nil {
-			resp.Body.Close()
-		}
 		if i == 29 {
 			fmt.Printf("  ⚠ user-service might not be ready, continuing anyway...\n")
 		}
@@ -109,39 +111,29 @@ func waitForServices() {
 }
 
 func createDemoUsers() {
-	users := []map[string]interface{}{
+	users := []usermodels.User{
 		{
-			"email":     "customer1@example.com",
-			"password":  "Customer123!",
-			"firstName": "John",
-			"lastName":  "Doe",
-			"phone":     "+49123456789",
+			Email:     "customer1@example.com",
+			Password:  "Customer123!",
+			FirstName: ptr("John"),
+			LastName:  ptr("Doe"),
+			Phone:     ptr("+49123456789"),
 		},
 		{
-			"email":     "customer2@example.com",
-			"password":  "Customer123!",
-			"firstName": "Jane",
-			"lastName":  "Smith",
-			"phone":     "+49987654321",
+			Email:     "customer2@example.com",
+			Password:  "Customer123!",
+			FirstName: ptr("Jane"),
+			LastName:  ptr("Smith"),
+			Phone:     ptr("+49987654321"),
 		},
 	}
 
-	url := fmt.Sprintf("%s:%s%s/auth/signup", baseURL, userServicePort, apiPrefix)
-
 	for _, user := range users {
-		jsonData, _ := json.Marshal(user)
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			fmt.Printf("  ✗ Failed to create user %s: %v\n", user["email"], err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 201 || resp.StatusCode == 409 { // 409 = already exists
-			fmt.Printf("  ✓ User created/exists: %s\n", user["email"])
+		err := userClient.Signup(ctx, &user)
+		if err == nil || httpclient.StatusCode(err) == http.StatusConflict { // 409 = already exists
+			fmt.Printf("  ✓ User created/exists: %s\n", user.Email)
 		} else {
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("  ✗ Failed to create user %s: %s\n", user["email"], string(body))
+			fmt.Printf("  ✗ Failed to create user %s: %v\n", user.Email, err)
 		}
 	}
 }
@@ -158,32 +150,14 @@ func loginUsers() {
 		{"customer2@example.com", "Customer123!", &customer2Token, "customer2"},
 	}
 
-	url := fmt.Sprintf("%s:%s%s/auth/login", baseURL, userServicePort, apiPrefix)
-
 	for _, cred := range credentials {
-		loginData := map[string]string{
-			"email":    cred.email,
-			"password": cred.password,
-		}
-		jsonData, _ := json.Marshal(loginData)
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+		token, err := userClient.Login(ctx, cred.email, cred.password)
 		if err != nil {
 			fmt.Printf("  ✗ Failed to login %s: %v\n", cred.email, err)
 			continue
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 200 {
-			var result map[string]interface{}
-			json.NewDecoder(resp.Body).Decode(&result)
-			if token, ok := result["token"].(string); ok {
-				*cred.token = token
-				fmt.Printf("  ✓ Logged in: %s\n", cred.email)
-			}
-		} else {
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("  ✗ Failed to login %s: %s\n", cred.email, string(body))
-		}
+		*cred.token = token
+		fmt.Printf("  ✓ Logged in: %s\n", cred.email)
 	}
 }
 
@@ -191,268 +165,136 @@ func createAddresses() {
 	addresses := []struct {
 		token   string
 		user    string
-		address map[string]interface{}
+		address usermodels.Address
 	}{
 		{
 			customer1Token,
 			"customer1",
-			map[string]interface{}{
-				"fullName":   "Max Mustermann",
-				"street":     "Musterstraße 123",
-				"city":       "Berlin",
-				"postalCode": "10115",
-				"country":    "Germany",
-				"isDefault":  true,
-				"type":       "shipping",
+			usermodels.Address{
+				FullName:   "Max Mustermann",
+				Street:     "Musterstraße 123",
+				City:       "Berlin",
+				PostalCode: "10115",
+				Country:    "Germany",
+				IsDefault:  true,
+				Type:       "shipping",
 			},
 		},
 		{
 			customer1Token,
 			"customer1",
-			map[string]interface{}{
-				"fullName":   "Max Mustermann",
-				"street":     "Beispielweg 45",
-				"city":       "München",
-				"postalCode": "80331",
-				"country":    "Germany",
-				"isDefault":  false,
-				"type":       "billing",
+			usermodels.Address{
+				FullName:   "Max Mustermann",
+				Street:     "Beispielweg 45",
+				City:       "München",
+				PostalCode: "80331",
+				Country:    "Germany",
+				IsDefault:  false,
+				Type:       "billing",
 			},
 		},
 		{
 			customer2Token,
 			"customer2",
-			map[string]interface{}{
-				"fullName":   "Max Mustermann",
-				"street":     "Teststraße 789",
-				"city":       "Hamburg",
-				"postalCode": "20095",
-				"country":    "Germany",
-				"isDefault":  true,
-				"type":       "shipping",
+			usermodels.Address{
+				FullName:   "Max Mustermann",
+				Street:     "Teststraße 789",
+				City:       "Hamburg",
+				PostalCode: "20095",
+				Country:    "Germany",
+				IsDefault:  true,
+				Type:       "shipping",
 			},
 		},
 		{
 			customer2Token,
 			"customer2",
-			map[string]interface{}{
-				"fullName":   "Max Mustermann",
-				"street":     "Teststraße 789",
-				"city":       "Hamburg",
-				"postalCode": "20095",
-				"country":    "Germany",
-				"isDefault":  true,
-				"type":       "billing",
+			usermodels.Address{
+				FullName:   "Max Mustermann",
+				Street:     "Teststraße 789",
+				City:       "Hamburg",
+				PostalCode: "20095",
+				Country:    "Germany",
+				IsDefault:  true,
+				Type:       "billing",
 			},
 		},
 	}
 
-	url := fmt.Sprintf("%s:%s%s/users/me/addresses", baseURL, userServicePort, apiPrefix)
-
 	for _, addr := range addresses {
-		jsonData, _ := json.Marshal(addr.address)
-		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", addr.token)
-
-		resp, err := http.DefaultClient.Do(req)
+		created, err := userClient.CreateAddress(ctx, addr.token, &addr.address)
 		if err != nil {
 			fmt.Printf("  ✗ Failed to create address for %s: %v\n", addr.user, err)
 			continue
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 201 {
-			fmt.Printf("  ✓ Address created for %s: %s, %s\n", addr.user, addr.address["city"], addr.address["type"])
-		} else {
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("  ✗ Failed to create address for %s: %s\n", addr.user, string(body))
-		}
+		fmt.Printf("  ✓ Address created for %s: %s, %s\n", addr.user, created.City, created.Type)
 	}
 }
 
 func createCategories() {
-	categories := []map[string]interface{}{
+	categories := []productmodels.Category{
 		{
-			"name":        "Electronics",
-			"slug":        "electronics",
-			"description": "Electronic devices and gadgets",
+			Name:        "Electronics",
+			Slug:        "electronics",
+			Description: "Electronic devices and gadgets",
 		},
 		{
-			"name":        "Clothing",
-			"slug":        "clothing",
-			"description": "Fashion and apparel",
+			Name:        "Clothing",
+			Slug:        "clothing",
+			Description: "Fashion and apparel",
 		},
 		{
-			"name":        "Books",
-			"slug":        "books",
-			"description": "Books and literature",
+			Name:        "Books",
+			Slug:        "books",
+			Description: "Books and literature",
 		},
 		{
-			"name":        "Home & Garden",
-			"slug":        "home-garden",
-			"description": "Home decoration and garden supplies",
+			Name:        "Home & Garden",
+			Slug:        "home-garden",
+			Description: "Home decoration and garden supplies",
 		},
 	}
 
-	url := fmt.Sprintf("%s:%s%s/admin/categories/create", baseURL, productServicePort, apiPrefix)
-
 	for _, category := range categories {
-		jsonData, _ := json.Marshal(category)
-		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", adminToken)
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			fmt.Printf("  ✗ Failed to create category %s: %v\n", category["name"], err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 201 || resp.StatusCode == 409 {
-			fmt.Printf("  ✓ Category created/exists: %s\n", category["name"])
+		err := productClient.CreateCategory(ctx, adminToken, &category)
+		if err == nil || httpclient.StatusCode(err) == http.StatusConflict {
+			fmt.Printf("  ✓ Category created/exists: %s\n", category.Name)
 		} else {
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("  ✗ Failed to create category %s: %s\n", category["name"], string(body))
+			fmt.Printf("  ✗ Failed to create category %s: %v\n", category.Name, err)
 		}
 	}
 }
 
 func createProducts() {
-	products := []map[string]interface{}{
-		{
-			"name":        "Gaming Laptop XPS 15",
-			"sku":         "LAPTOP-001",
-			"description": "High-performance gaming laptop with RTX 4070",
-			"priceCents":  149999,
-			"stockQty":    25,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1603302576837-37561b2e2302?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{1}, // Electronics
-		},
-		{
-			"name":        "Wireless Mouse MX Master 3",
-			"sku":         "MOUSE-001",
-			"description": "Ergonomic wireless mouse with precision tracking",
-			"priceCents":  9999,
-			"stockQty":    100,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1527864550417-7fd91fc51a46?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{1},
-		},
-		{
-			"name":        "USB-C Charging Cable",
-			"sku":         "CABLE-001",
-			"description": "2-meter braided USB-C charging cable",
-			"priceCents":  1990,
-			"stockQty":    250,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1591290619762-d71b02ae3c99?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{1},
-		},
-		{
-			"name":        "Cotton T-Shirt Blue",
-			"sku":         "TSHIRT-001",
-			"description": "Comfortable 100% cotton t-shirt in classic blue",
-			"priceCents":  2999,
-			"stockQty":    150,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1521572163474-6864f9cf17ab?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{2}, // Clothing
-		},
-		{
-			"name":        "Slim Fit Jeans",
-			"sku":         "JEANS-001",
-			"description": "Modern slim fit jeans in dark wash",
-			"priceCents":  7999,
-			"stockQty":    75,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1542272604-787c3835535d?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{2},
-		},
-		{
-			"name":        "The Go Programming Language",
-			"sku":         "BOOK-001",
-			"description": "Comprehensive guide to Go programming by experts",
-			"priceCents":  4999,
-			"stockQty":    50,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1532012197267-da84d127e765?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{3}, // Books
-		},
-		{
-			"name":        "Clean Code",
-			"sku":         "BOOK-002",
-			"description": "A handbook of agile software craftsmanship by Robert C. Martin",
-			"priceCents":  4499,
-			"stockQty":    40,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1544947950-fa07a98d237f?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{3},
-		},
-		{
-			"name":        "LED Desk Lamp",
-			"sku":         "LAMP-001",
-			"description": "Adjustable LED desk lamp with touch controls",
-			"priceCents":  5999,
-			"stockQty":    60,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1507473885765-e6ed057f782c?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{4}, // Home & Garden
-		},
-		{
-			"name":        "Ceramic Plant Pot",
-			"sku":         "POT-001",
-			"description": "Modern 20cm ceramic plant pot with drainage",
-			"priceCents":  1999,
-			"stockQty":    120,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1485955900006-10f4d324d411?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{4},
-		},
-		{
-			"name":        "Coffee Mug Set (4pc)",
-			"sku":         "MUG-001",
-			"description": "Set of 4 premium ceramic coffee mugs",
-			"priceCents":  2999,
-			"stockQty":    80,
-			"status":      "active",
-			"imageUrl":    "https://images.unsplash.com/photo-1514228742587-6b1558fcca3d?w=800",
-			"currency":    "EUR",
-			"categoryIds": []int{4},
-		},
+	products := []productmodels.CreateProductRequest{
+		demoProduct("Gaming Laptop XPS 15", "LAPTOP-001", "High-performance gaming laptop with RTX 4070",
+			149999, 25, 2100, "https://images.unsplash.com/photo-1603302576837-37561b2e2302?w=800", 1), // Electronics
+		demoProduct("Wireless Mouse MX Master 3", "MOUSE-001", "Ergonomic wireless mouse with precision tracking",
+			9999, 100, 140, "https://images.unsplash.com/photo-1527864550417-7fd91fc51a46?w=800", 1),
+		demoProduct("USB-C Charging Cable", "CABLE-001", "2-meter braided USB-C charging cable",
+			1990, 250, 60, "https://images.unsplash.com/photo-1591290619762-d71b02ae3c99?w=800", 1),
+		demoProduct("Cotton T-Shirt Blue", "TSHIRT-001", "Comfortable 100% cotton t-shirt in classic blue",
+			2999, 150, 200, "https://images.unsplash.com/photo-1521572163474-6864f9cf17ab?w=800", 2), // Clothing
+		demoProduct("Slim Fit Jeans", "JEANS-001", "Modern slim fit jeans in dark wash",
+			7999, 75, 650, "https://images.unsplash.com/photo-1542272604-787c3835535d?w=800", 2),
+		demoProduct("The Go Programming Language", "BOOK-001", "Comprehensive guide to Go programming by experts",
+			4999, 50, 700, "https://images.unsplash.com/photo-1532012197267-da84d127e765?w=800", 3), // Books
+		demoProduct("Clean Code", "BOOK-002", "A handbook of agile software craftsmanship by Robert C. Martin",
+			4499, 40, 600, "https://images.unsplash.com/photo-1544947950-fa07a98d237f?w=800", 3),
+		demoProduct("LED Desk Lamp", "LAMP-001", "Adjustable LED desk lamp with touch controls",
+			5999, 60, 1200, "https://images.unsplash.com/photo-1507473885765-e6ed057f782c?w=800", 4), // Home & Garden
+		demoProduct("Ceramic Plant Pot", "POT-001", "Modern 20cm ceramic plant pot with drainage",
+			1999, 120, 900, "https://images.unsplash.com/photo-1485955900006-10f4d324d411?w=800", 4),
+		demoProduct("Coffee Mug Set (4pc)", "MUG-001", "Set of 4 premium ceramic coffee mugs",
+			2999, 80, 1500, "https://images.unsplash.com/photo-1514228742587-6b1558fcca3d?w=800", 4),
 	}
 
-	url := fmt.Sprintf("%s:%s%s/admin/products/create", baseURL, productServicePort, apiPrefix)
-
 	for _, product := range products {
-		jsonData, _ := json.Marshal(product)
-		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", adminToken)
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			fmt.Printf("  ✗ Failed to create product %s: %v\n", product["name"], err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 201 || resp.StatusCode == 409 {
-			fmt.Printf("  ✓ Product created/exists: %s (€%.2f)\n", product["name"], float64(product["priceCents"].(int))/100)
+		err := productClient.CreateProduct(ctx, adminToken, &product)
+		if err == nil || httpclient.StatusCode(err) == http.StatusConflict {
+			fmt.Printf("  ✓ Product created/exists: %s (€%.2f)\n", product.Name, float64(product.PriceCents)/100)
 		} else {
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("  ✗ Failed to create product %s: %s\n", product["name"], string(body))
+			fmt.Printf("  ✗ Failed to create product %s: %v\n", product.Name, err)
 		}
 	}
 }
@@ -461,54 +303,70 @@ func addItemsToCarts() {
 	cartItems := []struct {
 		token string
 		user  string
-		items []map[string]interface{}
+		items []cartmodels.AddItemRequest
 	}{
 		{
 			customer1Token,
 			"customer1",
-			[]map[string]interface{}{
-				{"productId": 1, "quantity": 1}, // Laptop
-				{"productId": 2, "quantity": 2}, // Mouse
-				{"productId": 6, "quantity": 1}, // Book
+			[]cartmodels.AddItemRequest{
+				{ProductID: 1, Quantity: 1}, // Laptop
+				{ProductID: 2, Quantity: 2}, // Mouse
+				{ProductID: 6, Quantity: 1}, // Book
 			},
 		},
 		{
 			customer2Token,
 			"customer2",
-			[]map[string]interface{}{
-				{"productId": 4, "quantity": 2},  // T-Shirt
-				{"productId": 10, "quantity": 1}, // Coffee Mug Set
+			[]cartmodels.AddItemRequest{
+				{ProductID: 4, Quantity: 2},  // T-Shirt
+				{ProductID: 10, Quantity: 1}, // Coffee Mug Set
 			},
 		},
 	}
 
-	url := fmt.Sprintf("%s:%s%s/cart/items", baseURL, cartServicePort, apiPrefix)
-
 	for _, cart := range cartItems {
 		for _, item := range cart.items {
-			jsonData, _ := json.Marshal(item)
-			req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("Authorization", cart.token)
-
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
+			if _, err := cartClient.AddItem(ctx, cart.token, item); err != nil {
 				fmt.Printf("  ✗ Failed to add item to cart for %s: %v\n", cart.user, err)
 				continue
 			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode == 201 || resp.StatusCode == 200 {
-				fmt.Printf("  ✓ Item added to cart for %s: Product ID %v (x%v)\n",
-					cart.user, item["productId"], item["quantity"])
-			} else {
-				body, _ := io.ReadAll(resp.Body)
-				fmt.Printf("  ✗ Failed to add item to cart for %s: %s\n", cart.user, string(body))
-			}
+			fmt.Printf("  ✓ Item added to cart for %s: Product ID %v (x%v)\n",
+				cart.user, item.ProductID, item.Quantity)
 		}
 	}
 }
 
+// demoProduct builds an active EUR product in one category
+func demoProduct(name, sku, description string, priceCents, stockQty, weightGrams int, imageURL string, categoryID int64) productmodels.CreateProductRequest {
+	return productmodels.CreateProductRequest{
+		Product: productmodels.Product{
+			Name:        name,
+			SKU:         sku,
+			Description: description,
+			PriceCents:  priceCents,
+			StockQty:    stockQty,
+			WeightGrams: weightGrams,
+			Status:      "active",
+			ImageURL:    imageURL,
+			Currency:    "EUR",
+		},
+		CategoryIds: []int64{categoryID},
+	}
+}
+
+// serviceClient calls a service on its published localhost port; no retries, the script reports failures itself
+func serviceClient(service, port string) *httpclient.Client {
+	cfg, _ := httpclient.ConfigFromEnv(service)
+	cfg.BaseURL = fmt.Sprintf("%s:%s%s", baseURL, port, apiPrefix)
+	cfg.Retries = 0
+	cfg.BreakerThreshold = 0
+	return httpclient.New(cfg)
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenValidator returns the user id and role of a valid token
+type TokenValidator func(ctx context.Context, token string) (int64, string, error)
+
+// Authenticate validates the bearer token of a request once for all services and stores the user in the context
+// for Proxy.Handle. Requests without a token pass anonymously, the services decide which routes need a user.
+// Requests with an invalid or expired token are rejected with 401 and never reach a service.
+func Authenticate(validate TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l := logger.FromContext(c.Request.Context())
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Next()
+			return
+		}
+
+		userId, userRole, err := validate(c.Request.Context(), token)
+		if err != nil {
+			l.Warn("token not accepted", "error", err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized", "error": err.Error()})
+			return
+		}
+
+		c.Set("userId", userId)
+		c.Set("userRole", userRole)
+		c.Next()
+	}
+}
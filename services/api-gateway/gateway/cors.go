@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/idempotency"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+
+	"github.com/gin-contrib/cors"
+)
+
+// CORSFromEnv builds the CORS config of the gateway. CORS_ALLOWED_ORIGINS is a comma separated list of origins
+// (default *), CORS_ALLOW_CREDENTIALS allows cookies and auth headers (default false; not allowed together with *)
+// and CORS_MAX_AGE (Go duration, default 12h) is how long browsers may cache a preflight.
+func CORSFromEnv() (cors.Config, error) {
+	cfg := cors.Config{
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowHeaders: []string{"Origin", "Content-Length", "Content-Type", "Authorization",
+			guesttoken.CartHeader, guesttoken.OrderHeader, idempotency.KeyHeader},
+		ExposeHeaders: []string{"Content-Length", "Retry-After", guesttoken.CartHeader, guesttoken.OrderHeader,
+			idempotency.ReplayedHeader, ratelimit.LimitHeader, ratelimit.RemainingHeader, ratelimit.ResetHeader,
+			ratelimit.PolicyHeader},
+		MaxAge: 12 * time.Hour,
+	}
+
+	origins := []string{"*"}
+	if v := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS")); v != "" {
+		origins = nil
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+	if len(origins) == 1 && origins[0] == "*" {
+		cfg.AllowAllOrigins = true
+	} else {
+		cfg.AllowOrigins = origins
+	}
+
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS %q", v)
+		}
+		cfg.AllowCredentials = allow
+	}
+	if cfg.AllowCredentials && cfg.AllowAllOrigins {
+		return cfg, errors.New("CORS_ALLOW_CREDENTIALS needs explicit CORS_ALLOWED_ORIGINS")
+	}
+
+	var err error
+	if cfg.MaxAge, err = env.NonNegativeDuration("CORS_MAX_AGE", cfg.MaxAge); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Proxy forwards requests to the service owning their path
+type Proxy struct {
+	apiPrefix string
+	routes    []Route
+	proxies   map[string]*httputil.ReverseProxy
+}
+
+// forwarded is what the proxy keeps about a request in its context
+type forwarded struct {
+	body         []byte      // signed with the request
+	limitHeaders http.Header // RateLimit headers of the gateway, used unless the service sends its own
+}
+
+type forwardedKey struct{}
+
+// NewProxy creates a proxy for the routes to the given upstreams. Every forwarded request is signed with signer, so
+// the services can trust the identity headers.
+func NewProxy(apiPrefix string, routes []Route, upstreams map[string]*url.URL, signer *serviceauth.Signer) *Proxy {
+	p := &Proxy{apiPrefix: apiPrefix, routes: routes, proxies: map[string]*httputil.ReverseProxy{}}
+	for service, target := range upstreams {
+		p.proxies[service] = &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.SetURL(target)
+				pr.SetXForwarded()
+				f := pr.In.Context().Value(forwardedKey{}).(*forwarded)
+				signer.SignRequest(pr.Out, f.body)
+			},
+			Transport:      tracing.Transport(nil),
+			ModifyResponse: mergeHeaders,
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				logger.FromContext(r.Context()).Error("upstream request failed", "service", service, "error", err)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(`{"message":"service unavailable"}`))
+			},
+		}
+	}
+	return p
+}
+
+// Handle forwards the request to its service. Identity and signature headers sent by the client are dropped; the
+// user verified by Authenticate is passed as UserIDHeader and UserRoleHeader instead of the token.
+func (p *Proxy) Handle(c *gin.Context) {
+	l := logger.FromContext(c.Request.Context())
+
+	path, ok := strings.CutPrefix(c.Request.URL.Path, p.apiPrefix)
+	service, found := Match(p.routes, path)
+	if !ok || !found {
+		l.Warn("unknown route")
+		c.JSON(http.StatusNotFound, gin.H{"error": "route not found"})
+		return
+	}
+	c.Set(metrics.RouteKey, "proxy:"+service)
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "could not read request body"})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+
+	header := c.Request.Header
+	for _, key := range []string{middleware.UserIDHeader, middleware.UserRoleHeader, serviceauth.CallerHeader,
+		serviceauth.TimestampHeader, serviceauth.NonceHeader, serviceauth.SignatureHeader} {
+		header.Del(key)
+	}
+	if userId, ok := c.Get("userId"); ok {
+		header.Del("Authorization")
+		header.Set(middleware.UserIDHeader, strconv.FormatInt(userId.(int64), 10))
+		header.Set(middleware.UserRoleHeader, c.GetString("userRole"))
+	}
+
+	// the proxy adds the response headers of the service to the ones already set, so the RateLimit headers of the
+	// gateway are held back until the response shows whether the service limits the route itself
+	f := &forwarded{body: body, limitHeaders: http.Header{}}
+	for _, key := range rateLimitHeaders {
+		if values := c.Writer.Header().Values(key); len(values) > 0 {
+			f.limitHeaders[key] = values
+			c.Writer.Header().Del(key)
+		}
+	}
+
+	ctx := context.WithValue(c.Request.Context(), forwardedKey{}, f)
+	p.proxies[service].ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
+
+var rateLimitHeaders = []string{ratelimit.LimitHeader, ratelimit.RemainingHeader, ratelimit.ResetHeader, ratelimit.PolicyHeader}
+
+// mergeHeaders removes the CORS headers of the services, the gateway answers CORS for all of them, and adds the
+// RateLimit headers of the gateway if the service has no stricter limit on the route
+func mergeHeaders(resp *http.Response) error {
+	for key := range resp.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			resp.Header.Del(key)
+		}
+	}
+	if resp.Header.Get(ratelimit.LimitHeader) == "" {
+		f := resp.Request.Context().Value(forwardedKey{}).(*forwarded)
+		for key, values := range f.limitHeaders {
+			resp.Header[key] = values
+		}
+	}
+	return nil
+}
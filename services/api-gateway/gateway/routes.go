@@ -0,0 +1,95 @@
+// Package gateway implements the API gateway: it routes requests by path prefix to the services, authenticates
+// them once and forwards the verified user as signed identity headers
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"rearatrox/go-ecommerce-backend/pkg/httpclient"
+)
+
+// Route sends all requests below Prefix (relative to API_PREFIX) to Service. A "*" segment matches any single path
+// segment, e.g. an id.
+type Route struct {
+	Prefix  string
+	Service string
+}
+
+// Routes lists the public path prefixes of the services. Admin routes share the /admin prefix, so they are listed
+// per resource. /internal is not listed: service-to-service endpoints are not reachable through the gateway.
+var Routes = []Route{
+	{"/auth", "user-service"},
+	{"/users", "user-service"},
+	{"/admin/users", "user-service"},
+
+	{"/products", "product-service"},
+	{"/categories", "product-service"},
+	{"/admin/products", "product-service"},
+	{"/admin/categories", "product-service"},
+
+	{"/cart", "cart-service"},
+	{"/wishlists", "cart-service"},
+	{"/admin/shipping", "cart-service"},
+	{"/admin/coupons", "cart-service"},
+	{"/admin/carts", "cart-service"},
+
+	{"/orders", "order-service"},
+	{"/returns", "order-service"},
+	{"/guest/orders", "order-service"},
+	{"/admin/orders", "order-service"},
+	{"/admin/carriers", "order-service"},
+	{"/admin/shipments", "order-service"},
+	{"/admin/returns", "order-service"},
+
+	{"/payments", "payment-service"},
+	{"/payment-intents", "payment-service"},
+	{"/guest/payments", "payment-service"},
+	{"/guest/payment-intents", "payment-service"},
+	{"/webhooks", "payment-service"},
+	{"/admin/payments", "payment-service"},
+	{"/admin/orders/*/refunds", "payment-service"},
+}
+
+// Match returns the service of the route with the longest prefix matching path; prefixes only match whole segments
+func Match(routes []Route, path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	service, longest := "", 0
+	for _, route := range routes {
+		prefix := strings.Split(strings.Trim(route.Prefix, "/"), "/")
+		if len(prefix) <= longest || len(prefix) > len(segments) {
+			continue
+		}
+		matches := true
+		for i, segment := range prefix {
+			if segment != "*" && segment != segments[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			service, longest = route.Service, len(prefix)
+		}
+	}
+	return service, longest > 0
+}
+
+// UpstreamsFromEnv returns the base URLs (without API_PREFIX) of the services in routes, read from the same
+// <SERVICE>_URL variables as the service clients and defaulting to the docker compose host http://<service>:8080
+func UpstreamsFromEnv(routes []Route) (map[string]*url.URL, error) {
+	upstreams := map[string]*url.URL{}
+	for _, route := range routes {
+		if _, ok := upstreams[route.Service]; ok {
+			continue
+		}
+		raw := httpclient.ServiceURL(route.Service)
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s %q", httpclient.EnvName(route.Service), raw)
+		}
+		upstreams[route.Service] = u
+	}
+	return upstreams, nil
+}
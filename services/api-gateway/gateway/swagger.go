@@ -0,0 +1,162 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SwaggerPaths are the path segments below API_PREFIX under which each service serves its Swagger UI
+var SwaggerPaths = map[string]string{
+	"user-service":    "users",
+	"product-service": "products",
+	"cart-service":    "cart",
+	"order-service":   "orders",
+	"payment-service": "payments",
+}
+
+const swaggerFetchTimeout = 5 * time.Second
+
+// Specs merges the Swagger specs of the services into one document for the gateway. It implements swag.Swagger,
+// so gin-swagger serves it like a generated spec; the specs are fetched on every read, so the document always
+// matches the running services.
+type Specs struct {
+	Host      string
+	APIPrefix string
+	Upstreams map[string]*url.URL
+	Client    *http.Client
+}
+
+// ReadDoc returns the merged spec. Definitions are prefixed with the service name (cart-service.models.Cart),
+// since the services use the same model names; internal paths are left out. Services that cannot be reached are
+// listed in the description.
+func (s *Specs) ReadDoc() string {
+	ctx, cancel := context.WithTimeout(context.Background(), swaggerFetchTimeout)
+	defer cancel()
+
+	services := make([]string, 0, len(SwaggerPaths))
+	for service := range SwaggerPaths {
+		if _, ok := s.Upstreams[service]; ok {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	specs := make([]map[string]any, len(services))
+	errs := make([]error, len(services))
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			specs[i], errs[i] = s.fetch(ctx, service)
+		}()
+	}
+	wg.Wait()
+
+	paths, definitions, security := map[string]any{}, map[string]any{}, map[string]any{}
+	var unavailable []string
+	for i, service := range services {
+		if errs[i] != nil {
+			slog.Default().Warn("could not fetch swagger spec", "service", service, "error", errs[i])
+			unavailable = append(unavailable, service)
+			continue
+		}
+		spec := prefixRefs(specs[i], service+".").(map[string]any)
+
+		for name, definition := range asMap(spec["definitions"]) {
+			definitions[service+"."+name] = definition
+		}
+		for name, definition := range asMap(spec["securityDefinitions"]) {
+			security[name] = definition
+		}
+		for path, operations := range asMap(spec["paths"]) {
+			if strings.HasPrefix(path, "/internal/") {
+				continue
+			}
+			merged := asMap(paths[path])
+			if merged == nil {
+				merged = map[string]any{}
+			}
+			for method, operation := range asMap(operations) {
+				merged[method] = operation
+			}
+			paths[path] = merged
+		}
+	}
+
+	description := "All public endpoints of the services, served through the API gateway"
+	if len(unavailable) > 0 {
+		description += ". Currently unavailable: " + strings.Join(unavailable, ", ")
+	}
+	doc, _ := json.Marshal(map[string]any{
+		"swagger": "2.0",
+		"info": map[string]any{
+			"title":       "E-Commerce Backend - API Gateway",
+			"description": description,
+			"version":     "1.0",
+		},
+		"host":                s.Host,
+		"basePath":            s.APIPrefix,
+		"paths":               paths,
+		"definitions":         definitions,
+		"securityDefinitions": security,
+	})
+	return string(doc)
+}
+
+// fetch loads the spec a service serves for its own Swagger UI
+func (s *Specs) fetch(ctx context.Context, service string) (map[string]any, error) {
+	target := fmt.Sprintf("%s%s/%s/swagger/doc.json", s.Upstreams[service], s.APIPrefix, SwaggerPaths[service])
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var spec map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// prefixRefs returns v with the prefix added to all references to definitions
+func prefixRefs(v any, prefix string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+					v[key] = "#/definitions/" + prefix + name
+				}
+				continue
+			}
+			v[key] = prefixRefs(value, prefix)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = prefixRefs(value, prefix)
+		}
+	}
+	return v
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+)
+
+// The API gateway is the single public entry point: it routes requests by path prefix to the services,
+// authenticates them once, applies CORS and rate limits and serves the merged Swagger spec of all services.
+func main() {
+	srv, err := server.New("api-gateway")
+	if err != nil {
+		log.Fatalf("failed to start api-gateway: %v", err)
+	}
+
+	// the services behind the gateway
+	srv.DependsOn("user-service", "product-service", "cart-service", "order-service", "payment-service")
+
+	if err := RegisterRoutes(srv.Router); err != nil {
+		log.Fatalf("failed to configure gateway: %v", err)
+	}
+
+	if err := srv.Run(); err != nil {
+		log.Fatalf("api-gateway failed: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
+	"rearatrox/go-ecommerce-backend/services/api-gateway/gateway"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+)
+
+const DEFAULT_PORT = "8080"
+
+func RegisterRoutes(router *gin.Engine) error {
+	// the gateway is the edge: client IPs for rate limiting are taken from X-Forwarded-For only if it was set by
+	// one of the GATEWAY_TRUSTED_PROXIES (comma separated IPs/CIDRs, e.g. a load balancer)
+	if err := ratelimit.TrustProxiesFromEnv(router, "GATEWAY_TRUSTED_PROXIES"); err != nil {
+		return err
+	}
+
+	corsConfig, err := gateway.CORSFromEnv()
+	if err != nil {
+		return err
+	}
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		return err
+	}
+	signer, err := serviceauth.SignerFromEnv()
+	if err != nil {
+		return err
+	}
+	if signer == nil || signer.Service() != middleware.GatewayService {
+		return errors.New("SERVICE_NAME=" + middleware.GatewayService + " and SERVICE_KEY are required")
+	}
+	upstreams, err := gateway.UpstreamsFromEnv(gateway.Routes)
+	if err != nil {
+		return err
+	}
+
+	// read API prefix, trim spaces and provide a sensible default
+	apiPrefix := strings.TrimSpace(os.Getenv("API_PREFIX"))
+	if apiPrefix == "" {
+		apiPrefix = "/api/v1"
+	}
+
+	port := os.Getenv("GATEWAY_PORT")
+	if port == "" {
+		port = DEFAULT_PORT
+	}
+	swag.Register("gateway", &gateway.Specs{
+		Host:      "localhost:" + port,
+		APIPrefix: apiPrefix,
+		Upstreams: upstreams,
+		Client:    http.DefaultClient,
+	})
+
+	router.Use(cors.New(corsConfig))
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
+	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
+	// overall limit per client IP in front of all services (RATE_LIMIT_GATEWAY), the services add stricter limits
+	// for single endpoints
+	router.Use(ratelimit.PolicyFromEnv(limits, ratelimit.Policy{
+		Name:  "gateway",
+		Limit: ratelimit.Limit{Requests: 20, Period: time.Second, Burst: 40},
+		Key:   ratelimit.ByIP,
+	}))
+
+	// merged spec of all services
+	router.GET(apiPrefix+"/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler, ginSwagger.InstanceName("gateway")))
+
+	// everything else is forwarded to the service owning the path, unknown paths are answered with 404
+	proxy := gateway.NewProxy(apiPrefix, gateway.Routes, upstreams, signer)
+	router.NoRoute(gateway.Authenticate(func(ctx context.Context, token string) (int64, string, error) {
+		return middleware.ValidateToken(token, db.DB, ctx)
+	}), proxy.Handle)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// RestoreCart godoc
+// @Summary      Restore abandoned cart
+// @Description  Reactivate an abandoned cart with the signed token from a recovery notification. Carts of registered users require the owner to be logged in; an empty active cart of the user is replaced. Guest carts are returned with a new cart token.
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.RestoreCartRequest  true  "Restore token"
+// @Success      200      {object}  models.Cart
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/restore [post]
+func RestoreCart(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("RestoreCart called", "user_id", userId)
+
+	var req models.RestoreCartRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	cartId, err := guesttoken.Verify(guesttoken.KindCartRestore, req.Token)
+	if err != nil {
+		l.Warn("invalid restore token", "user_id", userId, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid restore token.", "error": err.Error()})
+		return
+	}
+
+	cart, err := models.GetCartByID(context.Request.Context(), cartId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			l.Warn("cart to restore not found", "cart_id", cartId)
+			context.JSON(http.StatusNotFound, gin.H{"message": "cart not found."})
+			return
+		}
+		l.Error("failed to fetch cart", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	if cart.UserID != nil {
+		if _, ok := context.Get("userId"); !ok {
+			l.Warn("restore of user cart without login", "cart_id", cartId)
+			context.JSON(http.StatusUnauthorized, gin.H{"message": "please log in to restore your cart."})
+			return
+		}
+		if *cart.UserID != userId {
+			l.Warn("restore of foreign cart", "cart_id", cartId, "user_id", userId)
+			context.JSON(http.StatusForbidden, gin.H{"message": "cart belongs to another user."})
+			return
+		}
+	}
+
+	switch cart.Status {
+	case "abandoned":
+		err = cart.Restore(context.Request.Context(), true)
+	case "active":
+		// link was used before or the customer already came back
+		err = cart.Reload(context.Request.Context())
+	default:
+		l.Warn("cart cannot be restored", "cart_id", cartId, "status", cart.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": "cart can no longer be restored.", "status": cart.Status})
+		return
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrActiveCartExists) || errors.Is(err, models.ErrCartNotAbandoned) {
+			l.Warn("cart cannot be restored", "cart_id", cartId, "error", err)
+			context.JSON(http.StatusConflict, gin.H{"message": "cart cannot be restored.", "error": err.Error()})
+			return
+		}
+		l.Error("failed to restore cart", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not restore cart.", "error": err.Error()})
+		return
+	}
+
+	if cart.UserID == nil {
+		cart.Token = guesttoken.Sign(guesttoken.KindCart, cart.ID)
+		context.Header(guesttoken.CartHeader, cart.Token)
+	}
+
+	l.Info("cart restored", "cart_id", cart.ID, "user_id", userId)
+	context.JSON(http.StatusOK, cart)
+}
+
+// GetAbandonmentReport godoc
+// @Summary      Abandoned cart report (Admin)
+// @Description  Abandonment rate, recovery notifications, recovered carts and recovered revenue for carts created in a period (admin only). Defaults to the last 30 days.
+// @Tags         Carts (Admin)
+// @Produce      json
+// @Param        from  query     string  false  "Start date (YYYY-MM-DD, inclusive)"
+// @Param        to    query     string  false  "End date (YYYY-MM-DD, inclusive)"
+// @Success      200   {object}  models.AbandonmentReport
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{}
+// @Failure      500   {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/carts/abandonment [get]
+func GetAbandonmentReport(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetAbandonmentReport called", "from", context.Query("from"), "to", context.Query("to"))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -29)
+	to := today.AddDate(0, 0, 1)
+
+	var err error
+	if v := context.Query("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid from date.", "error": err.Error()})
+			return
+		}
+	}
+	if v := context.Query("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid to date.", "error": err.Error()})
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "from must not be after to."})
+		return
+	}
+
+	report, err := models.GetAbandonmentReport(context.Request.Context(), from, to)
+	if err != nil {
+		l.Error("failed to build abandonment report", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not build abandonment report.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, report)
+}
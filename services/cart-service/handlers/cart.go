@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/cartcheck"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
 	"rearatrox/go-ecommerce-backend/services/cart-service/models"
 	"strconv"
@@ -11,10 +12,11 @@ import (
 
 // GetCart godoc
 // @Summary      Get user's cart
-// @Description  Get the active cart for the authenticated user with all items
+// @Description  Get the active cart for the authenticated user or guest with all items. Lines whose price, availability or stock changed are reported in warnings. Guests without a cart get an empty cart; it is stored, with its X-Cart-Token, once an item is added or a coupon applied.
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
 // @Success      200  {object}  models.Cart
 // @Failure      401  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
@@ -25,7 +27,7 @@ func GetCart(context *gin.Context) {
 	userId := context.GetInt64("userId")
 	l.Debug("GetCart called", "user_id", userId)
 
-	cart, err := models.GetOrCreateCart(userId)
+	cart, err := resolveCart(context, false)
 	if err != nil {
 		l.Error("failed to get cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
@@ -42,10 +44,14 @@ func GetCart(context *gin.Context) {
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
+// @Param        Idempotency-Key  header    string                 false  "Retries with the same key replay the first response"
 // @Param        request  body      models.AddItemRequest  true  "Product and quantity to add"
 // @Success      200      {object}  models.Cart
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      401      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /cart/items [post]
@@ -62,7 +68,7 @@ func AddItem(context *gin.Context) {
 	}
 
 	// Get or create cart first to check existing quantity
-	cart, err := models.GetOrCreateCart(userId)
+	cart, err := resolveCart(context, true)
 	if err != nil {
 		l.Error("failed to get cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
@@ -82,7 +88,7 @@ func AddItem(context *gin.Context) {
 	totalQuantity := existingQuantity + req.Quantity
 
 	// Check stock availability with total quantity
-	stockResp, err := checkStockAvailability(int64(req.ProductID), totalQuantity)
+	stockResp, err := productService.CheckStock(context.Request.Context(), int64(req.ProductID), totalQuantity)
 	if err != nil {
 		l.Error("failed to check stock", "product_id", req.ProductID, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock availability.", "error": err.Error()})
@@ -102,14 +108,6 @@ func AddItem(context *gin.Context) {
 		return
 	}
 
-	// Get or create cart
-	cart, err = models.GetOrCreateCart(userId)
-	if err != nil {
-		l.Error("failed to get cart", "user_id", userId, "error", err)
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
-		return
-	}
-
 	// Add item to cart
 	cartItem := &models.CartItem{
 		CartID:    cart.ID,
@@ -117,14 +115,14 @@ func AddItem(context *gin.Context) {
 		Quantity:  req.Quantity,
 	}
 
-	if err := cartItem.AddOrUpdate(); err != nil {
+	if err := cartItem.AddOrUpdate(context.Request.Context()); err != nil {
 		l.Error("failed to add item to cart", "user_id", userId, "product_id", req.ProductID, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not add item to cart.", "error": err.Error()})
 		return
 	}
 
 	// Reload cart with updated items
-	if err := cart.Reload(); err != nil {
+	if err := cart.Reload(context.Request.Context()); err != nil {
 		l.Error("failed to reload cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
 		return
@@ -140,6 +138,7 @@ func AddItem(context *gin.Context) {
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
 // @Param        productId  path      int                       true  "Product ID"
 // @Param        request    body      models.UpdateItemRequest  true  "New quantity"
 // @Success      200        {object}  models.Cart
@@ -169,7 +168,7 @@ func UpdateItem(context *gin.Context) {
 	}
 
 	// Get cart
-	cart, err := models.GetOrCreateCart(userId)
+	cart, err := resolveCart(context, false)
 	if err != nil {
 		l.Error("failed to get cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
@@ -183,14 +182,14 @@ func UpdateItem(context *gin.Context) {
 		Quantity:  req.Quantity,
 	}
 
-	if err := cartItem.UpdateQuantity(); err != nil {
+	if err := cartItem.UpdateQuantity(context.Request.Context()); err != nil {
 		l.Error("failed to update item quantity", "user_id", userId, "product_id", productId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "item not found in cart.", "error": err.Error()})
 		return
 	}
 
 	// Reload cart with updated items
-	if err := cart.Reload(); err != nil {
+	if err := cart.Reload(context.Request.Context()); err != nil {
 		l.Error("failed to reload cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
 		return
@@ -206,6 +205,7 @@ func UpdateItem(context *gin.Context) {
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
 // @Param        productId  path      int  true  "Product ID"
 // @Success      200        {object}  models.Cart
 // @Failure      400        {object}  map[string]interface{}
@@ -227,7 +227,7 @@ func RemoveItem(context *gin.Context) {
 	}
 
 	// Get cart
-	cart, err := models.GetOrCreateCart(userId)
+	cart, err := resolveCart(context, false)
 	if err != nil {
 		l.Error("failed to get cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
@@ -240,14 +240,14 @@ func RemoveItem(context *gin.Context) {
 		ProductID: productId,
 	}
 
-	if err := cartItem.Remove(); err != nil {
+	if err := cartItem.Remove(context.Request.Context()); err != nil {
 		l.Error("failed to remove item from cart", "user_id", userId, "product_id", productId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "item not found in cart.", "error": err.Error()})
 		return
 	}
 
 	// Reload cart with updated items
-	if err := cart.Reload(); err != nil {
+	if err := cart.Reload(context.Request.Context()); err != nil {
 		l.Error("failed to reload cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
 		return
@@ -263,6 +263,7 @@ func RemoveItem(context *gin.Context) {
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
 // @Success      200  {object}  models.Cart
 // @Failure      401  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
@@ -274,7 +275,7 @@ func ClearCart(context *gin.Context) {
 	l.Debug("ClearCart called", "user_id", userId)
 
 	// Get cart
-	cart, err := models.GetOrCreateCart(userId)
+	cart, err := resolveCart(context, false)
 	if err != nil {
 		l.Error("failed to get cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
@@ -282,14 +283,14 @@ func ClearCart(context *gin.Context) {
 	}
 
 	// Clear cart
-	if err := cart.Clear(); err != nil {
+	if err := cart.Clear(context.Request.Context()); err != nil {
 		l.Error("failed to clear cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not clear cart.", "error": err.Error()})
 		return
 	}
 
 	// Reload cart (now empty)
-	if err := cart.Reload(); err != nil {
+	if err := cart.Reload(context.Request.Context()); err != nil {
 		l.Error("failed to reload cart", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
 		return
@@ -298,3 +299,45 @@ func ClearCart(context *gin.Context) {
 	l.Info("cleared cart", "user_id", userId, "cart_id", cart.ID)
 	context.JSON(http.StatusOK, cart)
 }
+
+// AcknowledgeCartChanges godoc
+// @Summary      Acknowledge cart changes
+// @Description  Accept all changes reported in the cart warnings: prices are updated to the current price, quantities are reduced to the available stock and unavailable products are removed. Required before checkout when the cart has warnings.
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
+// @Success      200  {object}  models.Cart
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/acknowledge [post]
+func AcknowledgeCartChanges(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("AcknowledgeCartChanges called", "user_id", userId)
+
+	cart, err := resolveCart(context, false)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	applied, err := cartcheck.Acknowledge(context.Request.Context(), cart.ID)
+	if err != nil {
+		l.Error("failed to apply cart changes", "cart_id", cart.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not apply cart changes.", "error": err.Error()})
+		return
+	}
+
+	// Reload cart with adjusted items
+	if err := cart.Reload(context.Request.Context()); err != nil {
+		l.Error("failed to reload cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
+		return
+	}
+
+	l.Info("acknowledged cart changes", "user_id", userId, "cart_id", cart.ID, "applied_count", len(applied))
+	context.JSON(http.StatusOK, cart)
+}
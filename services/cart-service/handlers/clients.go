@@ -0,0 +1,12 @@
+package handlers
+
+import "rearatrox/go-ecommerce-backend/pkg/clients"
+
+// productService checks stock when items are added, merged or moved into a cart
+var productService *clients.ProductGRPCClient
+
+// UseClients sets the clients of the other services the handlers call; main builds them and calls it before the
+// routes are registered
+func UseClients(product *clients.ProductGRPCClient) {
+	productService = product
+}
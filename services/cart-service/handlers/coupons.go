@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/promotions"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApplyCoupon godoc
+// @Summary      Apply coupon to cart
+// @Description  Apply a coupon code to the active cart. The cart is returned with the resulting discount lines.
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
+// @Param        request  body      models.ApplyCouponRequest  true  "Coupon code"
+// @Success      200      {object}  models.Cart
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/coupon [post]
+func ApplyCoupon(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("ApplyCoupon called", "user_id", userId)
+
+	var req models.ApplyCouponRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	coupon, err := promotions.GetCouponByCode(context.Request.Context(), req.Code)
+	if err != nil {
+		if errors.Is(err, promotions.ErrCouponNotFound) {
+			l.Warn("coupon not found", "user_id", userId, "code", req.Code)
+			context.JSON(http.StatusNotFound, gin.H{"message": "coupon not found."})
+			return
+		}
+		l.Error("failed to fetch coupon", "code", req.Code, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch coupon.", "error": err.Error()})
+		return
+	}
+
+	cart, err := resolveCart(context, true)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	lines, err := cart.Lines(context.Request.Context())
+	if err != nil {
+		l.Error("failed to load cart lines", "cart_id", cart.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	// reject coupons that do not apply right now instead of silently storing them
+	_, err = coupon.Evaluate(lines, 0, time.Now())
+	if err == nil {
+		err = coupon.CheckUsage(context.Request.Context(), cart.UserID)
+	}
+	var couponErr *promotions.CouponError
+	if errors.As(err, &couponErr) {
+		l.Warn("coupon not applicable", "user_id", userId, "code", coupon.Code, "reason", couponErr.Reason)
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "coupon cannot be applied.", "code": coupon.Code, "reason": couponErr.Reason})
+		return
+	}
+	if err != nil {
+		l.Error("failed to check coupon", "code", coupon.Code, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check coupon.", "error": err.Error()})
+		return
+	}
+
+	if err := cart.SetCoupon(context.Request.Context(), &coupon.ID); err != nil {
+		l.Error("failed to apply coupon", "cart_id", cart.ID, "coupon_id", coupon.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not apply coupon.", "error": err.Error()})
+		return
+	}
+
+	if err := cart.Reload(context.Request.Context()); err != nil {
+		l.Error("failed to reload cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
+		return
+	}
+
+	l.Info("applied coupon to cart", "user_id", userId, "cart_id", cart.ID, "code", coupon.Code, "discount_cents", cart.DiscountCents)
+	context.JSON(http.StatusOK, cart)
+}
+
+// RemoveCoupon godoc
+// @Summary      Remove coupon from cart
+// @Description  Remove the applied coupon from the active cart
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
+// @Success      200  {object}  models.Cart
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/coupon [delete]
+func RemoveCoupon(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("RemoveCoupon called", "user_id", userId)
+
+	cart, err := resolveCart(context, false)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	if err := cart.SetCoupon(context.Request.Context(), nil); err != nil {
+		l.Error("failed to remove coupon", "cart_id", cart.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not remove coupon.", "error": err.Error()})
+		return
+	}
+
+	if err := cart.Reload(context.Request.Context()); err != nil {
+		l.Error("failed to reload cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reload cart.", "error": err.Error()})
+		return
+	}
+
+	l.Info("removed coupon from cart", "user_id", userId, "cart_id", cart.ID)
+	context.JSON(http.StatusOK, cart)
+}
+
+// GetCoupons godoc
+// @Summary      Get all coupons
+// @Description  Get all coupons including inactive and expired ones. Requires authentication and authorization as role "admin".
+// @Tags         Coupons (Admin)
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   promotions.Coupon
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/coupons [get]
+func GetCoupons(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetCoupons called")
+
+	coupons, err := promotions.GetCoupons(context.Request.Context())
+	if err != nil {
+		l.Error("failed to fetch coupons", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch coupons.", "error": err.Error()})
+		return
+	}
+
+	l.Info("fetched coupons", "count", len(coupons))
+	context.JSON(http.StatusOK, coupons)
+}
+
+// CreateCoupon godoc
+// @Summary      Create a coupon
+// @Description  Create a coupon code. Leave productIds and categoryIds empty to apply it to the whole cart. Requires authentication and authorization as role "admin".
+// @Tags         Coupons (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        coupon  body      promotions.Coupon  true  "Coupon payload"
+// @Success      201     {object}  promotions.Coupon
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/coupons [post]
+func CreateCoupon(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CreateCoupon called")
+
+	var coupon promotions.Coupon
+	if err := context.ShouldBindJSON(&coupon); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	if err := coupon.Validate(); err != nil {
+		l.Warn("invalid coupon", "code", coupon.Code, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid coupon.", "error": err.Error()})
+		return
+	}
+
+	if err := coupon.InsertCoupon(context.Request.Context()); err != nil {
+		l.Error("failed to create coupon", "code", coupon.Code, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create coupon.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created coupon", "coupon_id", coupon.ID, "code", coupon.Code)
+	context.JSON(http.StatusCreated, coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary      Update a coupon
+// @Description  Update rules, limits and scope of a coupon. Requires authentication and authorization as role "admin".
+// @Tags         Coupons (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                true  "Coupon ID"
+// @Param        coupon  body      promotions.Coupon  true  "Coupon payload"
+// @Success      200     {object}  promotions.Coupon
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/coupons/{id} [put]
+func UpdateCoupon(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	couponId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid coupon id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse coupon id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("UpdateCoupon called", "coupon_id", couponId)
+
+	existing, err := promotions.GetCouponByID(context.Request.Context(), couponId)
+	if err != nil {
+		l.Error("failed to fetch coupon", "coupon_id", couponId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "coupon not found."})
+		return
+	}
+
+	var coupon promotions.Coupon
+	if err := context.ShouldBindJSON(&coupon); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+	coupon.ID = existing.ID
+	coupon.CreatedAt = existing.CreatedAt
+	if coupon.Active == nil {
+		// omitted keeps the current state
+		coupon.Active = existing.Active
+	}
+
+	if err := coupon.Validate(); err != nil {
+		l.Warn("invalid coupon", "coupon_id", couponId, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid coupon.", "error": err.Error()})
+		return
+	}
+
+	if err := coupon.UpdateCoupon(context.Request.Context()); err != nil {
+		l.Error("failed to update coupon", "coupon_id", couponId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update coupon.", "error": err.Error()})
+		return
+	}
+
+	l.Info("updated coupon", "coupon_id", couponId)
+	context.JSON(http.StatusOK, coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary      Delete a coupon
+// @Description  Delete a coupon. It is removed from carts; placed orders keep their discount lines. Requires authentication and authorization as role "admin".
+// @Tags         Coupons (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Coupon ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/coupons/{id} [delete]
+func DeleteCoupon(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	couponId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid coupon id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse coupon id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("DeleteCoupon called", "coupon_id", couponId)
+
+	coupon, err := promotions.GetCouponByID(context.Request.Context(), couponId)
+	if err != nil {
+		l.Error("failed to fetch coupon", "coupon_id", couponId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "coupon not found."})
+		return
+	}
+
+	if err := coupon.DeleteCoupon(context.Request.Context()); err != nil {
+		l.Error("failed to delete coupon", "coupon_id", couponId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete coupon.", "error": err.Error()})
+		return
+	}
+
+	l.Info("deleted coupon", "coupon_id", couponId)
+	context.JSON(http.StatusOK, gin.H{"message": "deleted coupon successfully"})
+}
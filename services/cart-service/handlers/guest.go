@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveCart returns the active cart of the authenticated user or, for anonymous requests,
+// the guest cart identified by the X-Cart-Token header. If the token is missing, invalid or
+// belongs to a cart that was already ordered or merged, a new guest cart is created when create
+// is set (first write, e.g. adding an item or applying a coupon); its token is returned in the
+// X-Cart-Token response header and the cartToken field. Otherwise an empty cart is returned
+// without storing it, so reads do not leave empty carts behind.
+func resolveCart(context *gin.Context, create bool) (*models.Cart, error) {
+	l := logger.FromContext(context.Request.Context())
+
+	if userId, ok := context.Get("userId"); ok {
+		return models.GetOrCreateCart(context.Request.Context(), userId.(int64))
+	}
+
+	if token := context.GetHeader(guesttoken.CartHeader); token != "" {
+		cartId, err := guesttoken.Verify(guesttoken.KindCart, token)
+		if err == nil {
+			cart, err := models.GetGuestCart(context.Request.Context(), cartId)
+			if err == nil {
+				cart.Token = token
+				context.Header(guesttoken.CartHeader, token)
+				return cart, nil
+			}
+			l.Debug("guest cart not active anymore", "cart_id", cartId, "error", err)
+		} else {
+			l.Warn("invalid cart token", "error", err)
+		}
+	}
+
+	if !create {
+		return &models.Cart{Status: "active"}, nil
+	}
+
+	cart, err := models.CreateGuestCart(context.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	cart.Token = guesttoken.Sign(guesttoken.KindCart, cart.ID)
+	context.Header(guesttoken.CartHeader, cart.Token)
+	l.Info("created guest cart", "cart_id", cart.ID)
+	return cart, nil
+}
+
+// MergeGuestCart godoc
+// @Summary      Merge guest cart into user cart
+// @Description  Moves the items of a guest cart into the user's active cart (internal, called by user-service on login). Quantities are added up and capped to the available stock.
+// @Tags         Internal
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.MergeCartRequest  true  "User and guest cart token"
+// @Success      200      {object}  models.MergeCartResponse
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Router       /internal/cart/merge [post]
+func MergeGuestCart(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	var req models.MergeCartRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("MergeGuestCart called", "user_id", req.UserID)
+
+	cartId, err := guesttoken.Verify(guesttoken.KindCart, req.CartToken)
+	if err != nil {
+		l.Warn("invalid cart token", "user_id", req.UserID, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid cart token.", "error": err.Error()})
+		return
+	}
+
+	guest, err := models.GetGuestCart(context.Request.Context(), cartId)
+	if err != nil {
+		l.Warn("guest cart not found", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "guest cart not found."})
+		return
+	}
+
+	target, err := models.GetOrCreateCart(context.Request.Context(), req.UserID)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", req.UserID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	existing := make(map[int64]int, len(target.Items))
+	for _, item := range target.Items {
+		existing[item.ProductID] = item.Quantity
+	}
+
+	// add guest quantities on top of the user's cart, capped to the available stock
+	quantities := make(map[int64]int, len(guest.Items))
+	adjustments := []models.MergeAdjustment{}
+	for _, item := range guest.Items {
+		wanted := existing[item.ProductID] + item.Quantity
+
+		stockResp, err := productService.CheckStock(context.Request.Context(), item.ProductID, wanted)
+		if err != nil {
+			l.Error("failed to check stock", "product_id", item.ProductID, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock availability.", "error": err.Error()})
+			return
+		}
+
+		merged := wanted
+		if !stockResp.Available {
+			merged = max(stockResp.AvailableQty, existing[item.ProductID])
+			adjustments = append(adjustments, models.MergeAdjustment{
+				ProductID: item.ProductID,
+				Requested: item.Quantity,
+				Merged:    merged - existing[item.ProductID],
+				Reason:    "insufficient stock",
+			})
+		}
+		quantities[item.ProductID] = merged
+	}
+
+	if err := models.MergeGuestCart(context.Request.Context(), guest, target, quantities); err != nil {
+		l.Error("failed to merge guest cart", "guest_cart_id", guest.ID, "cart_id", target.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not merge cart.", "error": err.Error()})
+		return
+	}
+
+	l.Info("merged guest cart", "user_id", req.UserID, "guest_cart_id", guest.ID, "cart_id", target.ID, "adjustments_count", len(adjustments))
+	context.JSON(http.StatusOK, models.MergeCartResponse{Cart: target, Adjustments: adjustments})
+}
@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/shipping"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingOptionsResponse struct {
+	Country string           `json:"country" example:"DE"`
+	Parcel  shipping.Parcel  `json:"parcel"`
+	Options []shipping.Quote `json:"options"`
+}
+
+// GetShippingOptions godoc
+// @Summary      Get shipping options for cart
+// @Description  Get shipping rate quotes for the active cart, either for one of the user's addresses or for a country
+// @Tags         Shipping
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token  header  string  false  "Guest cart token (omit when authenticated)"
+// @Param        addressId  query     int     false  "Shipping address ID (takes precedence over country)"
+// @Param        country    query     string  false  "Destination country"
+// @Success      200        {object}  ShippingOptionsResponse
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/shipping-options [get]
+func GetShippingOptions(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("GetShippingOptions called", "user_id", userId)
+
+	country := strings.TrimSpace(context.Query("country"))
+	if addressIdStr := context.Query("addressId"); addressIdStr != "" {
+		addressId, err := strconv.ParseInt(addressIdStr, 10, 64)
+		if err != nil {
+			l.Error("invalid address ID", "address_id", addressIdStr, "error", err)
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid address ID.", "error": err.Error()})
+			return
+		}
+
+		country, err = models.GetAddressCountry(context.Request.Context(), addressId, userId)
+		if err != nil {
+			l.Warn("address not found", "user_id", userId, "address_id", addressId, "error", err)
+			context.JSON(http.StatusNotFound, gin.H{"message": "address not found."})
+			return
+		}
+	}
+
+	if country == "" {
+		l.Warn("no destination given", "user_id", userId)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "addressId or country is required."})
+		return
+	}
+
+	cart, err := resolveCart(context, false)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	parcel, err := cart.Parcel(context.Request.Context())
+	if err != nil {
+		l.Error("failed to calculate parcel", "cart_id", cart.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not calculate shipping.", "error": err.Error()})
+		return
+	}
+
+	quotes, err := shipping.QuotesForCountry(context.Request.Context(), country, parcel)
+	if err != nil {
+		l.Error("failed to quote shipping", "cart_id", cart.ID, "country", country, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not calculate shipping.", "error": err.Error()})
+		return
+	}
+
+	l.Info("quoted shipping options", "user_id", userId, "cart_id", cart.ID, "country", country, "options_count", len(quotes))
+	context.JSON(http.StatusOK, ShippingOptionsResponse{Country: country, Parcel: parcel, Options: quotes})
+}
+
+// GetShippingZones godoc
+// @Summary      Get all shipping zones
+// @Description  Get all shipping zones with their countries. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   shipping.Zone
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/zones [get]
+func GetShippingZones(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetShippingZones called")
+
+	zones, err := shipping.GetZones(context.Request.Context())
+	if err != nil {
+		l.Error("failed to fetch shipping zones", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch shipping zones.", "error": err.Error()})
+		return
+	}
+
+	l.Info("fetched shipping zones", "count", len(zones))
+	context.JSON(http.StatusOK, zones)
+}
+
+// CreateShippingZone godoc
+// @Summary      Create a shipping zone
+// @Description  Create a shipping zone for a set of countries. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        zone  body      shipping.Zone  true  "Zone payload"
+// @Success      201   {object}  shipping.Zone
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Failure      500   {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/zones [post]
+func CreateShippingZone(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CreateShippingZone called")
+
+	var zone shipping.Zone
+	if err := context.ShouldBindJSON(&zone); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	if err := zone.InsertZone(context.Request.Context()); err != nil {
+		l.Error("failed to create shipping zone", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create shipping zone.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created shipping zone", "zone_id", zone.ID)
+	context.JSON(http.StatusCreated, zone)
+}
+
+// UpdateShippingZone godoc
+// @Summary      Update a shipping zone
+// @Description  Update name and countries of a shipping zone. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int            true  "Zone ID"
+// @Param        zone  body      shipping.Zone  true  "Zone payload"
+// @Success      200   {object}  shipping.Zone
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
+// @Failure      500   {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/zones/{id} [put]
+func UpdateShippingZone(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	zoneId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid zone id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse zone id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("UpdateShippingZone called", "zone_id", zoneId)
+
+	existing, err := shipping.GetZoneByID(context.Request.Context(), zoneId)
+	if err != nil {
+		l.Error("failed to fetch shipping zone", "zone_id", zoneId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "shipping zone not found."})
+		return
+	}
+
+	var zone shipping.Zone
+	if err := context.ShouldBindJSON(&zone); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+	zone.ID = existing.ID
+	zone.CreatedAt = existing.CreatedAt
+
+	if err := zone.UpdateZone(context.Request.Context()); err != nil {
+		l.Error("failed to update shipping zone", "zone_id", zoneId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update shipping zone.", "error": err.Error()})
+		return
+	}
+
+	l.Info("updated shipping zone", "zone_id", zoneId)
+	context.JSON(http.StatusOK, zone)
+}
+
+// DeleteShippingZone godoc
+// @Summary      Delete a shipping zone
+// @Description  Delete a shipping zone including all of its methods. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Zone ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/zones/{id} [delete]
+func DeleteShippingZone(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	zoneId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid zone id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse zone id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("DeleteShippingZone called", "zone_id", zoneId)
+
+	zone, err := shipping.GetZoneByID(context.Request.Context(), zoneId)
+	if err != nil {
+		l.Error("failed to fetch shipping zone", "zone_id", zoneId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "shipping zone not found."})
+		return
+	}
+
+	if err := zone.DeleteZone(context.Request.Context()); err != nil {
+		l.Error("failed to delete shipping zone", "zone_id", zoneId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete shipping zone.", "error": err.Error()})
+		return
+	}
+
+	l.Info("deleted shipping zone", "zone_id", zoneId)
+	context.JSON(http.StatusOK, gin.H{"message": "deleted shipping zone successfully"})
+}
+
+// GetShippingMethods godoc
+// @Summary      Get all shipping methods
+// @Description  Get all shipping methods including inactive ones. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   shipping.Method
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/methods [get]
+func GetShippingMethods(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetShippingMethods called")
+
+	methods, err := shipping.GetMethods(context.Request.Context())
+	if err != nil {
+		l.Error("failed to fetch shipping methods", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch shipping methods.", "error": err.Error()})
+		return
+	}
+
+	l.Info("fetched shipping methods", "count", len(methods))
+	context.JSON(http.StatusOK, methods)
+}
+
+// CreateShippingMethod godoc
+// @Summary      Create a shipping method
+// @Description  Create a flat rate, weight-based or free-above-threshold shipping method for a zone. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        method  body      shipping.Method  true  "Method payload"
+// @Success      201     {object}  shipping.Method
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/methods [post]
+func CreateShippingMethod(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CreateShippingMethod called")
+
+	var method shipping.Method
+	if err := context.ShouldBindJSON(&method); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	if err := method.Validate(); err != nil {
+		l.Warn("invalid shipping method", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid shipping method.", "error": err.Error()})
+		return
+	}
+
+	if err := method.InsertMethod(context.Request.Context()); err != nil {
+		l.Error("failed to create shipping method", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create shipping method.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created shipping method", "method_id", method.ID, "zone_id", method.ZoneID)
+	context.JSON(http.StatusCreated, method)
+}
+
+// UpdateShippingMethod godoc
+// @Summary      Update a shipping method
+// @Description  Update rates and settings of a shipping method. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int              true  "Method ID"
+// @Param        method  body      shipping.Method  true  "Method payload"
+// @Success      200     {object}  shipping.Method
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/methods/{id} [put]
+func UpdateShippingMethod(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	methodId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid method id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse method id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("UpdateShippingMethod called", "method_id", methodId)
+
+	existing, err := shipping.GetMethodByID(context.Request.Context(), methodId)
+	if err != nil {
+		l.Error("failed to fetch shipping method", "method_id", methodId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "shipping method not found."})
+		return
+	}
+
+	var method shipping.Method
+	if err := context.ShouldBindJSON(&method); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+	method.ID = existing.ID
+	method.CreatedAt = existing.CreatedAt
+	if method.Active == nil {
+		// omitted keeps the current state
+		method.Active = existing.Active
+	}
+
+	if err := method.Validate(); err != nil {
+		l.Warn("invalid shipping method", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid shipping method.", "error": err.Error()})
+		return
+	}
+
+	if err := method.UpdateMethod(context.Request.Context()); err != nil {
+		l.Error("failed to update shipping method", "method_id", methodId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update shipping method.", "error": err.Error()})
+		return
+	}
+
+	l.Info("updated shipping method", "method_id", methodId)
+	context.JSON(http.StatusOK, method)
+}
+
+// DeleteShippingMethod godoc
+// @Summary      Delete a shipping method
+// @Description  Delete a shipping method. Existing orders keep their shipping method name and cost. Requires authentication and authorization as role "admin".
+// @Tags         Shipping (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Method ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipping/methods/{id} [delete]
+func DeleteShippingMethod(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	methodId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid method id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse method id.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("DeleteShippingMethod called", "method_id", methodId)
+
+	method, err := shipping.GetMethodByID(context.Request.Context(), methodId)
+	if err != nil {
+		l.Error("failed to fetch shipping method", "method_id", methodId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "shipping method not found."})
+		return
+	}
+
+	if err := method.DeleteMethod(context.Request.Context()); err != nil {
+		l.Error("failed to delete shipping method", "method_id", methodId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete shipping method.", "error": err.Error()})
+		return
+	}
+
+	l.Info("deleted shipping method", "method_id", methodId)
+	context.JSON(http.StatusOK, gin.H{"message": "deleted shipping method successfully"})
+}
@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// getUserWishlist loads the wishlist from the :id path parameter for the authenticated user.
+// On failure the error response is written and false is returned.
+func getUserWishlist(context *gin.Context, l *slog.Logger) (*models.Wishlist, bool) {
+	userId := context.GetInt64("userId")
+
+	wishlistId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Warn("invalid wishlist id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse wishlist id.", "error": err.Error()})
+		return nil, false
+	}
+
+	wishlist, err := models.GetWishlist(context.Request.Context(), wishlistId, userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			l.Warn("wishlist not found", "wishlist_id", wishlistId, "user_id", userId)
+			context.JSON(http.StatusNotFound, gin.H{"message": "wishlist not found."})
+			return nil, false
+		}
+		l.Error("failed to fetch wishlist", "wishlist_id", wishlistId, "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch wishlist.", "error": err.Error()})
+		return nil, false
+	}
+	return wishlist, true
+}
+
+// GetWishlists godoc
+// @Summary      List wishlists
+// @Description  Get all wishlists of the authenticated user including the saved for later list
+// @Tags         Wishlists
+// @Produce      json
+// @Success      200  {array}   models.Wishlist
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists [get]
+func GetWishlists(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("GetWishlists called", "user_id", userId)
+
+	wishlists, err := models.GetWishlists(context.Request.Context(), userId)
+	if err != nil {
+		l.Error("failed to fetch wishlists", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch wishlists.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, wishlists)
+}
+
+// CreateWishlist godoc
+// @Summary      Create wishlist
+// @Description  Create a named wishlist for the authenticated user
+// @Tags         Wishlists
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.WishlistRequest  true  "Wishlist name"
+// @Success      201      {object}  models.Wishlist
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists [post]
+func CreateWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("CreateWishlist called", "user_id", userId)
+
+	var req models.WishlistRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	wishlist := &models.Wishlist{UserID: userId, Name: req.Name}
+	if err := wishlist.Insert(context.Request.Context()); err != nil {
+		if errors.Is(err, models.ErrWishlistNameTaken) {
+			l.Warn("wishlist name taken", "user_id", userId, "name", req.Name)
+			context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		l.Error("failed to create wishlist", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created wishlist", "user_id", userId, "wishlist_id", wishlist.ID)
+	context.JSON(http.StatusCreated, wishlist)
+}
+
+// GetWishlist godoc
+// @Summary      Get wishlist
+// @Description  Get a wishlist of the authenticated user with current prices and availability
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id   path      int  true  "Wishlist ID"
+// @Success      200  {object}  models.Wishlist
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id} [get]
+func GetWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetWishlist called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// UpdateWishlist godoc
+// @Summary      Rename wishlist
+// @Description  Rename a wishlist. The saved for later list cannot be renamed.
+// @Tags         Wishlists
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                     true  "Wishlist ID"
+// @Param        request  body      models.WishlistRequest  true  "New name"
+// @Success      200      {object}  models.Wishlist
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id} [put]
+func UpdateWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("UpdateWishlist called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	var req models.WishlistRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.Rename(context.Request.Context(), req.Name); err != nil {
+		if errors.Is(err, models.ErrWishlistNameTaken) || errors.Is(err, models.ErrSavedForLaterList) {
+			l.Warn("wishlist cannot be renamed", "wishlist_id", wishlist.ID, "error", err)
+			context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		l.Error("failed to rename wishlist", "wishlist_id", wishlist.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("renamed wishlist", "wishlist_id", wishlist.ID)
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// DeleteWishlist godoc
+// @Summary      Delete wishlist
+// @Description  Delete a wishlist with all its items; shared links stop working
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id   path      int  true  "Wishlist ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id} [delete]
+func DeleteWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("DeleteWishlist called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.Delete(context.Request.Context()); err != nil {
+		l.Error("failed to delete wishlist", "wishlist_id", wishlist.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("deleted wishlist", "wishlist_id", wishlist.ID)
+	context.JSON(http.StatusOK, gin.H{"message": "wishlist deleted."})
+}
+
+// AddWishlistItem godoc
+// @Summary      Add product to wishlist
+// @Description  Add a product to a wishlist or increase its quantity. Price and availability at this point are the baseline for price drop and back in stock notifications.
+// @Tags         Wishlists
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "Wishlist ID"
+// @Param        request  body      models.WishlistItemRequest  true  "Product and quantity (default 1)"
+// @Success      200      {object}  models.Wishlist
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id}/items [post]
+func AddWishlistItem(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("AddWishlistItem called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	var req models.WishlistItemRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.AddItem(context.Request.Context(), req.ProductID, req.Quantity); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			l.Warn("product not found", "product_id", req.ProductID)
+			context.JSON(http.StatusNotFound, gin.H{"message": "product not found."})
+			return
+		}
+		l.Error("failed to add wishlist item", "wishlist_id", wishlist.ID, "product_id", req.ProductID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not add item to wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("added item to wishlist", "wishlist_id", wishlist.ID, "product_id", req.ProductID, "quantity", req.Quantity)
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// RemoveWishlistItem godoc
+// @Summary      Remove product from wishlist
+// @Description  Remove a product from a wishlist
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id         path      int  true  "Wishlist ID"
+// @Param        productId  path      int  true  "Product ID"
+// @Success      200        {object}  models.Wishlist
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id}/items/{productId} [delete]
+func RemoveWishlistItem(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("RemoveWishlistItem called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	productId, err := strconv.ParseInt(context.Param("productId"), 10, 64)
+	if err != nil {
+		l.Error("invalid product ID", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid product ID.", "error": err.Error()})
+		return
+	}
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.RemoveItem(context.Request.Context(), productId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "item not found in wishlist."})
+			return
+		}
+		l.Error("failed to remove wishlist item", "wishlist_id", wishlist.ID, "product_id", productId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not remove item from wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("removed item from wishlist", "wishlist_id", wishlist.ID, "product_id", productId)
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// MoveWishlistItemToCart godoc
+// @Summary      Move wishlist item to cart
+// @Description  Move a product with its quantity from a wishlist (or the saved for later list) into the cart at the current price
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id         path      int  true  "Wishlist ID"
+// @Param        productId  path      int  true  "Product ID"
+// @Success      200        {object}  models.Cart
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Failure      409        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id}/items/{productId}/move-to-cart [post]
+func MoveWishlistItemToCart(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("MoveWishlistItemToCart called", "user_id", userId, "wishlist_id", context.Param("id"))
+
+	productId, err := strconv.ParseInt(context.Param("productId"), 10, 64)
+	if err != nil {
+		l.Error("invalid product ID", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid product ID.", "error": err.Error()})
+		return
+	}
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	item, found := wishlist.Item(productId)
+	if !found {
+		context.JSON(http.StatusNotFound, gin.H{"message": "item not found in wishlist."})
+		return
+	}
+
+	cart, err := models.GetOrCreateCart(context.Request.Context(), userId)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	inCart := 0
+	for _, cartItem := range cart.Items {
+		if cartItem.ProductID == productId {
+			inCart = cartItem.Quantity
+			break
+		}
+	}
+
+	stockResp, err := productService.CheckStock(context.Request.Context(), productId, inCart+item.Quantity)
+	if err != nil {
+		l.Error("failed to check stock", "product_id", productId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock availability.", "error": err.Error()})
+		return
+	}
+	if !stockResp.Available {
+		l.Warn("insufficient stock", "product_id", productId, "requested", item.Quantity, "available", stockResp.AvailableQty, "in_cart", inCart)
+		context.JSON(http.StatusConflict, gin.H{
+			"message":     "insufficient stock",
+			"requested":   item.Quantity,
+			"inCart":      inCart,
+			"totalNeeded": inCart + item.Quantity,
+			"available":   stockResp.AvailableQty,
+			"productId":   productId,
+		})
+		return
+	}
+
+	if err := wishlist.MoveToCart(context.Request.Context(), productId, cart); err != nil {
+		l.Error("failed to move wishlist item to cart", "wishlist_id", wishlist.ID, "product_id", productId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not move item to cart.", "error": err.Error()})
+		return
+	}
+
+	l.Info("moved wishlist item to cart", "wishlist_id", wishlist.ID, "cart_id", cart.ID, "product_id", productId, "quantity", item.Quantity)
+	context.JSON(http.StatusOK, cart)
+}
+
+// SaveForLater godoc
+// @Summary      Save cart item for later
+// @Description  Move a product with its quantity from the cart to the user's saved for later list (created on first use). Move it back via the wishlist move-to-cart endpoint.
+// @Tags         Cart
+// @Produce      json
+// @Param        productId  path      int  true  "Product ID"
+// @Success      200        {object}  models.Wishlist
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /cart/items/{productId}/save-for-later [post]
+func SaveForLater(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("SaveForLater called", "user_id", userId)
+
+	productId, err := strconv.ParseInt(context.Param("productId"), 10, 64)
+	if err != nil {
+		l.Error("invalid product ID", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid product ID.", "error": err.Error()})
+		return
+	}
+
+	cart, err := models.GetOrCreateCart(context.Request.Context(), userId)
+	if err != nil {
+		l.Error("failed to get cart", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart.", "error": err.Error()})
+		return
+	}
+
+	saved, err := models.GetOrCreateSavedForLater(context.Request.Context(), userId)
+	if err != nil {
+		l.Error("failed to get saved for later list", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch saved for later list.", "error": err.Error()})
+		return
+	}
+
+	if err := saved.SaveForLater(context.Request.Context(), cart, productId); err != nil {
+		if errors.Is(err, models.ErrItemNotInCart) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "item not found in cart."})
+			return
+		}
+		l.Error("failed to save item for later", "user_id", userId, "product_id", productId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not save item for later.", "error": err.Error()})
+		return
+	}
+
+	l.Info("saved cart item for later", "user_id", userId, "cart_id", cart.ID, "wishlist_id", saved.ID, "product_id", productId)
+	context.JSON(http.StatusOK, saved)
+}
+
+// ShareWishlist godoc
+// @Summary      Share wishlist
+// @Description  Create a read-only share link for a wishlist. The returned shareToken is used with GET /wishlists/shared/{token}; calling this again returns the existing token.
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id   path      int  true  "Wishlist ID"
+// @Success      200  {object}  models.Wishlist
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id}/share [post]
+func ShareWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("ShareWishlist called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.Share(context.Request.Context()); err != nil {
+		l.Error("failed to share wishlist", "wishlist_id", wishlist.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not share wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("shared wishlist", "wishlist_id", wishlist.ID)
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// UnshareWishlist godoc
+// @Summary      Stop sharing wishlist
+// @Description  Revoke the share link of a wishlist
+// @Tags         Wishlists
+// @Produce      json
+// @Param        id   path      int  true  "Wishlist ID"
+// @Success      200  {object}  models.Wishlist
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /wishlists/{id}/share [delete]
+func UnshareWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("UnshareWishlist called", "user_id", context.GetInt64("userId"), "wishlist_id", context.Param("id"))
+
+	wishlist, ok := getUserWishlist(context, l)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.Unshare(context.Request.Context()); err != nil {
+		l.Error("failed to unshare wishlist", "wishlist_id", wishlist.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not unshare wishlist.", "error": err.Error()})
+		return
+	}
+
+	l.Info("unshared wishlist", "wishlist_id", wishlist.ID)
+	context.JSON(http.StatusOK, wishlist)
+}
+
+// GetSharedWishlist godoc
+// @Summary      View shared wishlist
+// @Description  Read-only view of a shared wishlist (no authentication required)
+// @Tags         Wishlists
+// @Produce      json
+// @Param        token  path      string  true  "Share token"
+// @Success      200    {object}  models.Wishlist
+// @Failure      404    {object}  map[string]interface{}
+// @Failure      500    {object}  map[string]interface{}
+// @Router       /wishlists/shared/{token} [get]
+func GetSharedWishlist(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetSharedWishlist called")
+
+	wishlist, err := models.GetSharedWishlist(context.Request.Context(), context.Param("token"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "wishlist not found."})
+			return
+		}
+		l.Error("failed to fetch shared wishlist", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch wishlist.", "error": err.Error()})
+		return
+	}
+
+	// the share token is only shown to the owner
+	wishlist.ShareToken = nil
+	context.JSON(http.StatusOK, wishlist)
+}
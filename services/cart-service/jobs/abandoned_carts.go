@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/notify"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+)
+
+const (
+	DefaultAbandonAfter   = 24 * time.Hour
+	DefaultCheckInterval  = 15 * time.Minute
+	DefaultRestoreURL     = "http://localhost:3000/cart/restore"
+	notificationBatchSize = 100
+	// notifications that keep failing are given up after this window
+	notificationWindow = 7 * 24 * time.Hour
+)
+
+// AbandonedCartJob marks idle carts as abandoned and sends recovery notifications with a signed restore link
+type AbandonedCartJob struct {
+	AbandonAfter time.Duration // idle time after which an active cart counts as abandoned
+	Interval     time.Duration // time between two runs
+	RestoreURL   string        // frontend page that redeems the restore token
+	Notifier     notify.Notifier
+}
+
+// NewAbandonedCartJobFromEnv configures the job from ABANDONED_CART_AFTER, ABANDONED_CART_INTERVAL and CART_RESTORE_URL
+func NewAbandonedCartJobFromEnv(notifier notify.Notifier) (*AbandonedCartJob, error) {
+	var err error
+	job := &AbandonedCartJob{RestoreURL: DefaultRestoreURL, Notifier: notifier}
+	if job.AbandonAfter, err = env.Duration("ABANDONED_CART_AFTER", DefaultAbandonAfter); err != nil {
+		return nil, err
+	}
+	if job.Interval, err = env.Duration("ABANDONED_CART_INTERVAL", DefaultCheckInterval); err != nil {
+		return nil, err
+	}
+	if u := strings.TrimSpace(os.Getenv("CART_RESTORE_URL")); u != "" {
+		job.RestoreURL = u
+	}
+	return job, nil
+}
+
+// Start runs the job every Interval until ctx is cancelled
+func (j *AbandonedCartJob) Start(ctx context.Context) {
+	l := logger.FromContext(ctx)
+	l.Info("abandoned cart job started", "abandon_after", j.AbandonAfter.String(), "interval", j.Interval.String())
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			l.Error("abandoned cart job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("abandoned cart job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce marks idle carts as abandoned and notifies customers of abandoned carts not notified yet.
+// A failed notification is retried on the next run.
+func (j *AbandonedCartJob) RunOnce(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+
+	marked, err := models.MarkAbandonedCarts(ctx, time.Now().Add(-j.AbandonAfter))
+	if err != nil {
+		return fmt.Errorf("failed to mark abandoned carts: %w", err)
+	}
+	if marked > 0 {
+		l.Info("marked carts as abandoned", "count", marked)
+	}
+
+	carts, err := models.GetPendingRecoveryNotifications(ctx, time.Now().Add(-notificationWindow), notificationBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to fetch abandoned carts: %w", err)
+	}
+
+	for _, cart := range carts {
+		if err := j.Notifier.Notify(ctx, j.recoveryMessage(cart)); err != nil {
+			l.Warn("failed to send abandoned cart notification", "cart_id", cart.CartID, "user_id", cart.UserID, "error", err)
+			continue
+		}
+		if err := models.MarkNotified(ctx, cart.CartID); err != nil {
+			return fmt.Errorf("failed to mark cart %d as notified: %w", cart.CartID, err)
+		}
+		l.Info("sent abandoned cart notification", "cart_id", cart.CartID, "user_id", cart.UserID)
+	}
+	return nil
+}
+
+// recoveryMessage builds the notification including the signed restore link
+func (j *AbandonedCartJob) recoveryMessage(cart models.AbandonedCart) notify.Message {
+	restoreLink := j.RestoreURL + "?token=" + url.QueryEscape(guesttoken.Sign(guesttoken.KindCartRestore, cart.CartID))
+	total := fmt.Sprintf("%.2f", float64(cart.SubtotalCents)/100)
+
+	return notify.Message{
+		Kind:    "abandoned_cart",
+		To:      cart.Email,
+		Subject: "You left something in your cart",
+		Body:    fmt.Sprintf("Your cart with %d item(s) worth %s is still waiting for you: %s", cart.ItemCount, total, restoreLink),
+		Data: map[string]string{
+			"cartId":        fmt.Sprint(cart.CartID),
+			"itemCount":     fmt.Sprint(cart.ItemCount),
+			"subtotalCents": fmt.Sprint(cart.SubtotalCents),
+			"restoreLink":   restoreLink,
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/notify"
+	"rearatrox/go-ecommerce-backend/services/cart-service/models"
+)
+
+const (
+	DefaultWishlistAlertInterval = time.Hour
+	wishlistAlertBatchSize       = 500
+)
+
+// WishlistAlertJob notifies customers when a wishlisted product gets cheaper or comes back in stock
+type WishlistAlertJob struct {
+	Interval time.Duration
+	Notifier notify.Notifier
+}
+
+// NewWishlistAlertJobFromEnv configures the job from WISHLIST_ALERT_INTERVAL
+func NewWishlistAlertJobFromEnv(notifier notify.Notifier) (*WishlistAlertJob, error) {
+	interval, err := env.Duration("WISHLIST_ALERT_INTERVAL", DefaultWishlistAlertInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &WishlistAlertJob{Interval: interval, Notifier: notifier}, nil
+}
+
+// Start runs the job every Interval until ctx is cancelled
+func (j *WishlistAlertJob) Start(ctx context.Context) {
+	l := logger.FromContext(ctx)
+	l.Info("wishlist alert job started", "interval", j.Interval.String())
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			l.Error("wishlist alert job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("wishlist alert job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends price drop and back in stock alerts and moves the alert baseline of every changed item
+// to the current price and availability, so each change is reported once. Price increases and products
+// going out of stock only move the baseline. A failed notification is retried on the next run.
+func (j *WishlistAlertJob) RunOnce(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+
+	// page by id so items whose notification keeps failing do not block the ones behind them
+	var afterID int64
+	for {
+		alerts, err := models.GetWishlistAlerts(ctx, afterID, wishlistAlertBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch wishlist alerts: %w", err)
+		}
+
+		for _, alert := range alerts {
+			afterID = alert.ItemID
+
+			if msg, ok := alertMessage(alert); ok {
+				if err := j.Notifier.Notify(ctx, msg); err != nil {
+					l.Warn("failed to send wishlist alert", "item_id", alert.ItemID, "user_id", alert.UserID, "error", err)
+					continue
+				}
+				l.Info("sent wishlist alert", "kind", msg.Kind, "user_id", alert.UserID, "product_id", alert.ProductID)
+			}
+
+			if err := models.UpdateAlertBaseline(ctx, alert.ItemID, alert.PriceCents, alert.InStock); err != nil {
+				return fmt.Errorf("failed to update alert baseline of wishlist item %d: %w", alert.ItemID, err)
+			}
+		}
+
+		if len(alerts) < wishlistAlertBatchSize {
+			return nil
+		}
+	}
+}
+
+// alertMessage returns the notification for a change worth telling the customer about.
+// Products that are not available get no price drop alert; they are reported once back in stock.
+func alertMessage(alert models.WishlistAlert) (notify.Message, bool) {
+	data := map[string]string{
+		"productId":    fmt.Sprint(alert.ProductID),
+		"productName":  alert.ProductName,
+		"wishlistName": alert.WishlistName,
+		"priceCents":   fmt.Sprint(alert.PriceCents),
+	}
+	price := fmt.Sprintf("%.2f", float64(alert.PriceCents)/100)
+
+	switch {
+	case alert.InStock && !alert.AlertInStock:
+		return notify.Message{
+			Kind:    "wishlist_back_in_stock",
+			To:      alert.Email,
+			Subject: alert.ProductName + " is back in stock",
+			Body:    fmt.Sprintf("%s from your wishlist %q is available again for %s.", alert.ProductName, alert.WishlistName, price),
+			Data:    data,
+		}, true
+	case alert.InStock && alert.PriceCents < alert.AlertPriceCents:
+		data["previousPriceCents"] = fmt.Sprint(alert.AlertPriceCents)
+		return notify.Message{
+			Kind:    "wishlist_price_drop",
+			To:      alert.Email,
+			Subject: "Price drop: " + alert.ProductName,
+			Body: fmt.Sprintf("%s from your wishlist %q now costs %s instead of %.2f.",
+				alert.ProductName, alert.WishlistName, price, float64(alert.AlertPriceCents)/100),
+			Data: data,
+		}, true
+	default:
+		return notify.Message{}, false
+	}
+}
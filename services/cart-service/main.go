@@ -1,12 +1,13 @@
 package main
 
 import (
-	"io"
 	"log"
-	"rearatrox/go-ecommerce-backend/pkg/db"
-	"rearatrox/go-ecommerce-backend/pkg/logger"
-
-	"github.com/gin-gonic/gin"
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/notify"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+	"rearatrox/go-ecommerce-backend/services/cart-service/handlers"
+	"rearatrox/go-ecommerce-backend/services/cart-service/jobs"
 )
 
 // @title E-Commerce Backend - Cart-Service
@@ -28,18 +29,40 @@ import (
 // @in          header
 // @name        Authorization
 func main() {
+	srv, err := server.New("cart-service")
+	if err != nil {
+		log.Fatalf("failed to start cart-service: %v", err)
+	}
+	if err := guesttoken.CheckSecret(); err != nil {
+		log.Fatalf("failed to start cart-service: %v", err)
+	}
 
-	if err := logger.InitFromEnv(); err != nil {
-		log.Fatalf("failed to init logger: %v", err)
+	notifier, err := notify.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure notifier: %v", err)
 	}
-	defer logger.Sync()
 
-	db.InitDB()
+	// background detection of abandoned carts and recovery notifications
+	abandonedCarts, err := jobs.NewAbandonedCartJobFromEnv(notifier)
+	if err != nil {
+		log.Fatalf("failed to configure abandoned cart job: %v", err)
+	}
+	srv.Go(abandonedCarts.Start)
 
-	gin.DefaultWriter = io.Discard
-	router := gin.Default()
+	// price drop and back in stock alerts for wishlisted products
+	wishlistAlerts, err := jobs.NewWishlistAlertJobFromEnv(notifier)
+	if err != nil {
+		log.Fatalf("failed to configure wishlist alert job: %v", err)
+	}
+	srv.Go(wishlistAlerts.Start)
+
+	// stock checks over gRPC
+	handlers.UseClients(clients.NewProductGRPCClientFromEnv())
+	srv.DependsOn("product-service")
 
-	RegisterRoutes(router)
+	RegisterRoutes(srv.Router)
 
-	router.Run(":8080") // localhost:8080 --> CARTSERVICE_PORT mappt dann den Container
+	if err := srv.Run(); err != nil {
+		log.Fatalf("cart-service failed: %v", err)
+	}
 }
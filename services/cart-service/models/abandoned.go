@@ -0,0 +1,200 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrCartNotAbandoned = errors.New("cart is not abandoned")
+	ErrActiveCartExists = errors.New("user already has an active cart with items")
+)
+
+// AbandonedCart is an abandoned cart waiting for a recovery notification
+type AbandonedCart struct {
+	CartID        int64
+	UserID        int64
+	Email         string
+	ItemCount     int
+	SubtotalCents int
+	AbandonedAt   time.Time
+}
+
+// AbandonmentReport summarizes abandonment and recovery for carts created in a period
+type AbandonmentReport struct {
+	From                  time.Time `json:"from" example:"2025-01-01T00:00:00Z"`
+	To                    time.Time `json:"to" example:"2025-02-01T00:00:00Z"`
+	CheckedOutCarts       int       `json:"checkedOutCarts" example:"80"`  // Carts that were ordered
+	AbandonedCarts        int       `json:"abandonedCarts" example:"40"`   // Carts that were abandoned at least once
+	AbandonmentRate       float64   `json:"abandonmentRate" example:"0.4"` // Abandoned / (ordered without abandonment + abandoned)
+	NotifiedCarts         int       `json:"notifiedCarts" example:"30"`    // Abandoned carts a recovery notification was sent for
+	RecoveredCarts        int       `json:"recoveredCarts" example:"12"`   // Abandoned carts restored by link or ordered later
+	RecoveredOrders       int       `json:"recoveredOrders" example:"9"`   // Orders placed from formerly abandoned carts (not cancelled)
+	RecoveryRate          float64   `json:"recoveryRate" example:"0.225"`  // Recovered orders / abandoned carts
+	RecoveredRevenueCents int       `json:"recoveredRevenueCents" example:"45900"`
+}
+
+// MarkAbandonedCarts sets active carts with items that were idle since before cutoff to abandoned
+// and returns the number of affected carts
+// used in: jobs.AbandonedCartJob
+func MarkAbandonedCarts(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `UPDATE carts c
+	          SET status='abandoned', abandoned_at=now()
+	          WHERE c.status='active'
+	            AND COALESCE(c.updated_at, c.created_at) < $1
+	            AND EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = c.id)`
+	tag, err := db.DB.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetPendingRecoveryNotifications returns abandoned user carts without a notification that were
+// abandoned after since. Guest carts are skipped because there is no address to notify.
+// used in: jobs.AbandonedCartJob
+func GetPendingRecoveryNotifications(ctx context.Context, since time.Time, limit int) ([]AbandonedCart, error) {
+	query := `SELECT c.id, c.user_id, u.email, c.abandoned_at,
+	                 COUNT(ci.id), COALESCE(SUM(ci.quantity * ci.price_cents), 0)
+	          FROM carts c
+	          JOIN users u ON u.id = c.user_id
+	          JOIN cart_items ci ON ci.cart_id = c.id
+	          WHERE c.status='abandoned' AND c.notified_at IS NULL AND c.abandoned_at >= $1
+	          GROUP BY c.id, u.email
+	          ORDER BY c.abandoned_at
+	          LIMIT $2`
+	rows, err := db.DB.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carts := []AbandonedCart{}
+	for rows.Next() {
+		var c AbandonedCart
+		if err := rows.Scan(&c.CartID, &c.UserID, &c.Email, &c.AbandonedAt, &c.ItemCount, &c.SubtotalCents); err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	return carts, rows.Err()
+}
+
+// MarkNotified records that a recovery notification was sent for the cart
+// used in: jobs.AbandonedCartJob
+func MarkNotified(ctx context.Context, cartId int64) error {
+	_, err := db.DB.Exec(ctx, `UPDATE carts SET notified_at=now() WHERE id=$1`, cartId)
+	return err
+}
+
+// GetCartByID retrieves a cart in any status
+// used in: handlers.RestoreCart
+func GetCartByID(ctx context.Context, cartId int64) (*Cart, error) {
+	cart := &Cart{}
+	query := `SELECT ` + cartColumns + ` FROM carts WHERE id=$1`
+	err := db.DB.QueryRow(ctx, query, cartId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// Restore reactivates an abandoned cart. An empty active cart of the same user is dropped
+// to keep one active cart per user; ErrActiveCartExists is returned if it has items.
+// recovered marks the cart as recovered, which only the restore link does; a cart that is
+// reactivated automatically when the customer comes back counts as recovered once it is ordered.
+// used in: handlers.RestoreCart, GetOrCreateCart, GetGuestCart
+func (c *Cart) Restore(ctx context.Context, recovered bool) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if c.UserID != nil {
+		var activeId int64
+		var itemCount int
+		err = tx.QueryRow(ctx, `
+			SELECT c.id, (SELECT COUNT(*) FROM cart_items ci WHERE ci.cart_id = c.id)
+			FROM carts c
+			WHERE c.user_id=$1 AND c.status='active'
+			FOR UPDATE`, *c.UserID).Scan(&activeId, &itemCount)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			return err
+		case itemCount > 0:
+			return ErrActiveCartExists
+		default:
+			if _, err = tx.Exec(ctx, `DELETE FROM carts WHERE id=$1`, activeId); err != nil {
+				return err
+			}
+		}
+	}
+
+	query := `UPDATE carts
+	          SET status='active', recovered_at=CASE WHEN $2 THEN now() ELSE recovered_at END, updated_at=now()
+	          WHERE id=$1 AND status='abandoned'
+	          RETURNING status, updated_at`
+	err = tx.QueryRow(ctx, query, c.ID, recovered).Scan(&c.Status, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCartNotAbandoned
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	return c.Reload(ctx)
+}
+
+// GetAbandonmentReport aggregates abandonment and recovery figures for carts created in [from, to)
+// used in: handlers.GetAbandonmentReport
+func GetAbandonmentReport(ctx context.Context, from, to time.Time) (*AbandonmentReport, error) {
+	report := &AbandonmentReport{From: from, To: to}
+
+	query := `SELECT
+	            COUNT(*) FILTER (WHERE status='ordered'),
+	            COUNT(*) FILTER (WHERE status='ordered' AND abandoned_at IS NULL),
+	            COUNT(*) FILTER (WHERE abandoned_at IS NOT NULL),
+	            COUNT(*) FILTER (WHERE notified_at IS NOT NULL),
+	            COUNT(*) FILTER (WHERE recovered_at IS NOT NULL OR (abandoned_at IS NOT NULL AND status='ordered'))
+	          FROM carts
+	          WHERE created_at >= $1 AND created_at < $2`
+	var orderedDirectly int
+	err := db.DB.QueryRow(ctx, query, from, to).Scan(
+		&report.CheckedOutCarts, &orderedDirectly, &report.AbandonedCarts, &report.NotifiedCarts, &report.RecoveredCarts,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revenueQuery := `SELECT COUNT(o.id), COALESCE(SUM(o.total_cents), 0)
+	                 FROM orders o
+	                 JOIN carts c ON c.id = o.cart_id
+	                 WHERE c.created_at >= $1 AND c.created_at < $2
+	                   AND c.abandoned_at IS NOT NULL AND o.status <> 'cancelled'`
+	err = db.DB.QueryRow(ctx, revenueQuery, from, to).Scan(&report.RecoveredOrders, &report.RecoveredRevenueCents)
+	if err != nil {
+		return nil, err
+	}
+
+	if total := orderedDirectly + report.AbandonedCarts; total > 0 {
+		report.AbandonmentRate = float64(report.AbandonedCarts) / float64(total)
+	}
+	if report.AbandonedCarts > 0 {
+		report.RecoveryRate = float64(report.RecoveredOrders) / float64(report.AbandonedCarts)
+	}
+	return report, nil
+}
+
+type RestoreCartRequest struct {
+	Token string `json:"token" example:"12.1767225600.sig" binding:"required"`
+}
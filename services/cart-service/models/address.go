@@ -0,0 +1,15 @@
+package models
+
+import (
+	"context"
+	"rearatrox/go-ecommerce-backend/pkg/db"
+)
+
+// GetAddressCountry retrieves the country of an address owned by the user
+// used in: handlers.GetShippingOptions
+func GetAddressCountry(ctx context.Context, addressId, userId int64) (string, error) {
+	var country string
+	query := `SELECT country FROM addresses WHERE id=$1 AND user_id=$2`
+	err := db.DB.QueryRow(ctx, query, addressId, userId).Scan(&country)
+	return country, err
+}
@@ -1,78 +1,311 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"time"
 
+	"rearatrox/go-ecommerce-backend/pkg/cartcheck"
 	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/promotions"
+	"rearatrox/go-ecommerce-backend/pkg/shipping"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type Cart struct {
 	ID        int64      `db:"id" json:"id" swaggerignore:"true"`
-	UserID    int64      `db:"user_id" json:"userId" swaggerignore:"true"`
+	UserID    *int64     `db:"user_id" json:"userId,omitempty" swaggerignore:"true"` // nil for guest carts
 	Status    string     `db:"status" json:"status" example:"active"`
 	CreatedAt time.Time  `db:"created_at" json:"createdAt" swaggerignore:"true"`
 	UpdatedAt *time.Time `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
 	Items     []CartItem `json:"items,omitempty"`
-	Total     int        `json:"totalCents,omitempty"` // Total in cents
+
+	// Changes since items were added (price, availability, stock); must be acknowledged before checkout
+	Warnings []cartcheck.Warning `json:"warnings,omitempty"`
+
+	// Signed token identifying a guest cart, returned for anonymous requests only
+	Token string `json:"cartToken,omitempty" example:"12.1767225600.sig"`
+
+	// Coupon applied to the cart (see applyCoupon)
+	CouponID      *int64                    `db:"coupon_id" json:"-"`
+	CouponCode    *string                   `json:"couponCode,omitempty" example:"WELCOME10"`
+	CouponMessage string                    `json:"couponMessage,omitempty" example:"coupon WELCOME10 cannot be applied: minimum order value not reached"`
+	Discounts     []promotions.DiscountLine `json:"discounts,omitempty"`
+	SubtotalCents int                       `json:"subtotalCents,omitempty" example:"5998"` // Sum of items in cents
+	DiscountCents int                       `json:"discountCents,omitempty" example:"599"`
+	Total         int                       `json:"totalCents,omitempty" example:"5399"` // Subtotal minus discounts in cents
 }
 
+const cartColumns = `id, user_id, status, coupon_id, created_at, updated_at`
+
 // GetOrCreateCart retrieves the active cart for a user or creates a new one if none exists
-// used in: handlers.GetCart, handlers.AddItem, handlers.UpdateItem, handlers.RemoveItem, handlers.ClearCart
-func GetOrCreateCart(userId int64) (*Cart, error) {
+// used in: handlers.resolveCart, handlers.MergeGuestCart
+func GetOrCreateCart(ctx context.Context, userId int64) (*Cart, error) {
 	cart := &Cart{}
 
 	// Try to get existing active cart
-	query := `SELECT id, user_id, status, created_at, updated_at 
+	query := `SELECT id, user_id, status, coupon_id, created_at, updated_at 
 	          FROM carts 
 	          WHERE user_id=$1 AND status='active'`
-	err := db.DB.QueryRow(db.Ctx, query, userId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CreatedAt, &cart.UpdatedAt)
+	err := db.DB.QueryRow(ctx, query, userId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
 
 	if err != nil {
+		// A returning customer gets the most recently abandoned cart back before starting a new one
+		restored, restoreErr := restoreLatestAbandonedCart(ctx, userId)
+		if restoreErr != nil {
+			return nil, restoreErr
+		}
+		if restored != nil {
+			return restored, nil
+		}
+
 		// No active cart found, create new one
 		insertQuery := `INSERT INTO carts (user_id, status, created_at) 
 		                VALUES ($1, 'active', now()) 
-		                RETURNING id, user_id, status, created_at, updated_at`
-		err = db.DB.QueryRow(db.Ctx, insertQuery, userId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CreatedAt, &cart.UpdatedAt)
+		                RETURNING id, user_id, status, coupon_id, created_at, updated_at`
+		err = db.DB.QueryRow(ctx, insertQuery, userId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Load cart items and calculate total
-	items, total, err := GetCartItems(cart.ID)
+	if err := cart.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// restoreLatestAbandonedCart reactivates the user's most recently abandoned cart; nil if there is none
+func restoreLatestAbandonedCart(ctx context.Context, userId int64) (*Cart, error) {
+	cart := &Cart{}
+	query := `SELECT ` + cartColumns + ` 
+	          FROM carts 
+	          WHERE user_id=$1 AND status='abandoned' AND abandoned_at IS NOT NULL 
+	          ORDER BY abandoned_at DESC 
+	          LIMIT 1`
+	err := db.DB.QueryRow(ctx, query, userId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cart.Restore(ctx, false); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// CreateGuestCart creates a new anonymous cart
+// used in: handlers.resolveCart
+func CreateGuestCart(ctx context.Context) (*Cart, error) {
+	cart := &Cart{}
+	query := `INSERT INTO carts (user_id, status, created_at) 
+	          VALUES (NULL, 'active', now()) 
+	          RETURNING ` + cartColumns
+	err := db.DB.QueryRow(ctx, query).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	cart.Items = []CartItem{}
+	return cart, nil
+}
 
-	cart.Items = items
-	cart.Total = total
+// GetGuestCart retrieves an active anonymous cart by ID including items and totals.
+// An abandoned guest cart is reactivated when its owner comes back.
+// used in: handlers.resolveCart, handlers.MergeGuestCart
+func GetGuestCart(ctx context.Context, cartId int64) (*Cart, error) {
+	cart := &Cart{}
+	query := `SELECT ` + cartColumns + ` 
+	          FROM carts 
+	          WHERE id=$1 AND user_id IS NULL AND status IN ('active', 'abandoned')`
+	err := db.DB.QueryRow(ctx, query, cartId).Scan(&cart.ID, &cart.UserID, &cart.Status, &cart.CouponID, &cart.CreatedAt, &cart.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.Status == "abandoned" {
+		return cart, cart.Restore(ctx, false)
+	}
+
+	if err := cart.Reload(ctx); err != nil {
+		return nil, err
+	}
 
 	return cart, nil
 }
 
+// MergeGuestCart moves the items of a guest cart into the user's cart and marks the guest cart as merged.
+// quantities holds the final quantity per product in the target cart (already capped to available stock);
+// products missing from the map are dropped. The guest coupon is kept if the target cart has none.
+// used in: handlers.MergeGuestCart
+func MergeGuestCart(ctx context.Context, guest, target *Cart, quantities map[int64]int) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, item := range guest.Items {
+		quantity, ok := quantities[item.ProductID]
+		if !ok || quantity <= 0 {
+			continue
+		}
+		// idx_cart_items_cart_product allows one row per product, so existing rows are updated in place
+		// and keep their price snapshot
+		_, err = tx.Exec(ctx, `
+			INSERT INTO cart_items (cart_id, product_id, quantity, price_cents, created_at)
+			VALUES ($1, $2, $3, $4, now())
+			ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity=EXCLUDED.quantity, updated_at=now()
+		`, target.ID, item.ProductID, quantity, item.PriceCents)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE carts SET coupon_id=COALESCE(coupon_id, $1), updated_at=now() WHERE id=$2`, guest.CouponID, target.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE carts SET status='merged', updated_at=now() WHERE id=$1`, guest.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if target.CouponID == nil {
+		target.CouponID = guest.CouponID
+	}
+	return target.Reload(ctx)
+}
+
 // Clear removes all items from the cart
 // used in: handlers.ClearCart
-func (c *Cart) Clear() error {
+func (c *Cart) Clear(ctx context.Context) error {
 	query := `DELETE FROM cart_items WHERE cart_id=$1`
-	_, err := db.DB.Exec(db.Ctx, query, c.ID)
+	_, err := db.DB.Exec(ctx, query, c.ID)
 	if err != nil {
 		return err
 	}
 
 	// Update cart timestamp
 	updateQuery := `UPDATE carts SET updated_at=now() WHERE id=$1`
-	_, err = db.DB.Exec(db.Ctx, updateQuery, c.ID)
+	_, err = db.DB.Exec(ctx, updateQuery, c.ID)
 	return err
 }
 
-// Reload refreshes the cart data from database including items and total
-// used in: handlers.AddItem, handlers.UpdateItem, handlers.RemoveItem
-func (c *Cart) Reload() error {
-	items, total, err := GetCartItems(c.ID)
+// Reload refreshes the cart data from database including items, revalidation warnings, coupon and total
+// used in: GetOrCreateCart, GetGuestCart, handlers.AddItem, handlers.UpdateItem, handlers.RemoveItem
+func (c *Cart) Reload(ctx context.Context) error {
+	items, total, err := GetCartItems(ctx, c.ID)
 	if err != nil {
 		return err
 	}
 	c.Items = items
+	c.SubtotalCents = total
 	c.Total = total
+
+	warnings, err := cartcheck.Validate(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	c.Warnings = warnings
+
+	return c.applyCoupon(ctx)
+}
+
+// Lines returns the cart items as promotion lines including their categories
+// used in: handlers.ApplyCoupon
+func (c *Cart) Lines(ctx context.Context) ([]promotions.Line, error) {
+	return promotions.CartLines(ctx, db.DB, c.ID)
+}
+
+// Parcel returns the subtotal and weight of the cart items for shipping quotes
+// used in: handlers.GetShippingOptions
+func (c *Cart) Parcel(ctx context.Context) (shipping.Parcel, error) {
+	return shipping.ParcelForCart(ctx, db.DB, c.ID)
+}
+
+// SetCoupon stores the coupon on the cart; nil removes it
+// used in: handlers.ApplyCoupon, handlers.RemoveCoupon
+func (c *Cart) SetCoupon(ctx context.Context, couponId *int64) error {
+	query := `UPDATE carts SET coupon_id=$1, updated_at=now() WHERE id=$2`
+	_, err := db.DB.Exec(ctx, query, couponId, c.ID)
+	if err != nil {
+		return err
+	}
+	c.CouponID = couponId
 	return nil
 }
+
+// applyCoupon evaluates the coupon stored on the cart and fills the discount lines and total.
+// A coupon that no longer applies stays on the cart with an explanation, so it kicks in again
+// once the cart qualifies. Shipping is unknown here, so free shipping shows up without an amount.
+func (c *Cart) applyCoupon(ctx context.Context) error {
+	c.CouponCode = nil
+	c.CouponMessage = ""
+	c.Discounts = nil
+	c.DiscountCents = 0
+	if c.CouponID == nil {
+		return nil
+	}
+
+	coupon, err := promotions.GetCouponByID(ctx, *c.CouponID)
+	if err != nil {
+		return err
+	}
+	c.CouponCode = &coupon.Code
+
+	lines, err := c.Lines(ctx)
+	if err != nil {
+		return err
+	}
+
+	discount, err := coupon.Evaluate(lines, 0, time.Now())
+	if err == nil {
+		err = coupon.CheckUsage(ctx, c.UserID)
+	}
+	var couponErr *promotions.CouponError
+	if errors.As(err, &couponErr) {
+		c.CouponMessage = couponErr.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Discounts = []promotions.DiscountLine{*discount}
+	c.DiscountCents = discount.AmountCents
+	c.Total = c.SubtotalCents - c.DiscountCents
+	return nil
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code" example:"WELCOME10" binding:"required"`
+}
+
+type MergeCartRequest struct {
+	UserID    int64  `json:"userId" example:"1" binding:"required"`
+	CartToken string `json:"cartToken" example:"12.1767225600.sig" binding:"required"`
+}
+
+// MergeAdjustment reports a guest cart line whose quantity had to be reduced while merging
+type MergeAdjustment struct {
+	ProductID int64  `json:"productId" example:"1"`
+	Requested int    `json:"requested" example:"5"`
+	Merged    int    `json:"merged" example:"3"`
+	Reason    string `json:"reason" example:"insufficient stock"`
+}
+
+type MergeCartResponse struct {
+	Cart        *Cart             `json:"cart"`
+	Adjustments []MergeAdjustment `json:"adjustments,omitempty"`
+}
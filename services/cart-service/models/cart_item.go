@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
@@ -32,7 +33,7 @@ type UpdateItemRequest struct {
 
 // GetCartItems retrieves all items in a cart with product details and calculates the total price
 // used in: cart.GetOrCreateCart, cart.Reload
-func GetCartItems(cartId int64) ([]CartItem, int, error) {
+func GetCartItems(ctx context.Context, cartId int64) ([]CartItem, int, error) {
 	query := `SELECT 
 	            ci.id, ci.cart_id, ci.product_id, ci.quantity, ci.price_cents, 
 	            ci.created_at, ci.updated_at,
@@ -42,7 +43,7 @@ func GetCartItems(cartId int64) ([]CartItem, int, error) {
 	          WHERE ci.cart_id=$1
 	          ORDER BY ci.created_at DESC`
 
-	rows, err := db.DB.Query(db.Ctx, query, cartId)
+	rows, err := db.DB.Query(ctx, query, cartId)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -75,10 +76,10 @@ func GetCartItems(cartId int64) ([]CartItem, int, error) {
 
 // AddOrUpdate adds a product to cart or increases quantity if it already exists
 // used in: handlers.AddItem
-func (ci *CartItem) AddOrUpdate() error {
+func (ci *CartItem) AddOrUpdate(ctx context.Context) error {
 	// Get current product price
 	var priceCents int
-	err := db.DB.QueryRow(db.Ctx, `SELECT price_cents FROM products WHERE id=$1`, ci.ProductID).Scan(&priceCents)
+	err := db.DB.QueryRow(ctx, `SELECT price_cents FROM products WHERE id=$1`, ci.ProductID).Scan(&priceCents)
 	if err != nil {
 		return err
 	}
@@ -86,7 +87,7 @@ func (ci *CartItem) AddOrUpdate() error {
 	// Check if item already exists in cart
 	var existingID int64
 	var existingQuantity int
-	err = db.DB.QueryRow(db.Ctx, `
+	err = db.DB.QueryRow(ctx, `
 		SELECT id, quantity FROM cart_items 
 		WHERE cart_id=$1 AND product_id=$2
 	`, ci.CartID, ci.ProductID).Scan(&existingID, &existingQuantity)
@@ -96,7 +97,7 @@ func (ci *CartItem) AddOrUpdate() error {
 		query := `INSERT INTO cart_items (cart_id, product_id, quantity, price_cents, created_at) 
 		          VALUES ($1, $2, $3, $4, now())
 		          RETURNING id, created_at`
-		err = db.DB.QueryRow(db.Ctx, query, ci.CartID, ci.ProductID, ci.Quantity, priceCents).Scan(&ci.ID, &ci.CreatedAt)
+		err = db.DB.QueryRow(ctx, query, ci.CartID, ci.ProductID, ci.Quantity, priceCents).Scan(&ci.ID, &ci.CreatedAt)
 		if err != nil {
 			return err
 		}
@@ -108,7 +109,7 @@ func (ci *CartItem) AddOrUpdate() error {
 		          SET quantity=$1, updated_at=now() 
 		          WHERE id=$2
 		          RETURNING updated_at`
-		err = db.DB.QueryRow(db.Ctx, query, newQuantity, existingID).Scan(&ci.UpdatedAt)
+		err = db.DB.QueryRow(ctx, query, newQuantity, existingID).Scan(&ci.UpdatedAt)
 		if err != nil {
 			return err
 		}
@@ -118,38 +119,38 @@ func (ci *CartItem) AddOrUpdate() error {
 	}
 
 	// Update cart timestamp
-	_, err = db.DB.Exec(db.Ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
+	_, err = db.DB.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
 	return err
 }
 
 // UpdateQuantity sets a new quantity for an existing cart item
 // used in: handlers.UpdateItem
-func (ci *CartItem) UpdateQuantity() error {
+func (ci *CartItem) UpdateQuantity(ctx context.Context) error {
 	query := `UPDATE cart_items 
 	          SET quantity=$1, updated_at=now() 
 	          WHERE cart_id=$2 AND product_id=$3
 	          RETURNING id, updated_at`
-	err := db.DB.QueryRow(db.Ctx, query, ci.Quantity, ci.CartID, ci.ProductID).Scan(&ci.ID, &ci.UpdatedAt)
+	err := db.DB.QueryRow(ctx, query, ci.Quantity, ci.CartID, ci.ProductID).Scan(&ci.ID, &ci.UpdatedAt)
 	if err != nil {
 		return err
 	}
 
 	// Update cart timestamp
-	_, err = db.DB.Exec(db.Ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
+	_, err = db.DB.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
 	return err
 }
 
 // Remove deletes a cart item from the cart
 // used in: handlers.RemoveItem
-func (ci *CartItem) Remove() error {
+func (ci *CartItem) Remove(ctx context.Context) error {
 	query := `DELETE FROM cart_items 
 	          WHERE cart_id=$1 AND product_id=$2`
-	_, err := db.DB.Exec(db.Ctx, query, ci.CartID, ci.ProductID)
+	_, err := db.DB.Exec(ctx, query, ci.CartID, ci.ProductID)
 	if err != nil {
 		return err
 	}
 
 	// Update cart timestamp
-	_, err = db.DB.Exec(db.Ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
+	_, err = db.DB.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, ci.CartID)
 	return err
 }
@@ -0,0 +1,440 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kinds of wishlists
+const (
+	WishlistKindWishlist      = "wishlist"
+	WishlistKindSavedForLater = "saved_for_later"
+)
+
+const savedForLaterName = "Saved for later"
+
+var (
+	ErrWishlistNameTaken = errors.New("a wishlist with this name already exists")
+	ErrSavedForLaterList = errors.New("the saved for later list cannot be renamed")
+	ErrItemNotInCart     = errors.New("item not found in cart")
+)
+
+type Wishlist struct {
+	ID         int64          `db:"id" json:"id" example:"1"`
+	UserID     int64          `db:"user_id" json:"-"`
+	Name       string         `db:"name" json:"name" example:"Birthday"`
+	Kind       string         `db:"kind" json:"kind" example:"wishlist"`
+	ShareToken *string        `db:"share_token" json:"shareToken,omitempty" example:"kV2x9Qm3..."` // set while the list is shared
+	CreatedAt  time.Time      `db:"created_at" json:"createdAt" swaggerignore:"true"`
+	UpdatedAt  *time.Time     `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
+	Items      []WishlistItem `json:"items"`
+}
+
+type WishlistItem struct {
+	ID         int64     `db:"id" json:"id" swaggerignore:"true"`
+	WishlistID int64     `db:"wishlist_id" json:"-"`
+	ProductID  int64     `db:"product_id" json:"productId" example:"1"`
+	Quantity   int       `db:"quantity" json:"quantity" example:"1"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt" swaggerignore:"true"`
+
+	// Current product details (joined from products table)
+	ProductName     string `json:"productName" example:"Gaming Laptop"`
+	ProductImageURL string `json:"productImageUrl,omitempty" example:"https://example.com/laptop.jpg"`
+	PriceCents      int    `json:"priceCents" example:"2999"`
+	InStock         bool   `json:"inStock" example:"true"` // product is active and has stock
+}
+
+type WishlistRequest struct {
+	Name string `json:"name" example:"Birthday" binding:"required,max=100"`
+}
+
+type WishlistItemRequest struct {
+	ProductID int64 `json:"productId" example:"1" binding:"required"`
+	Quantity  int   `json:"quantity" example:"1" binding:"omitempty,min=1"`
+}
+
+// WishlistAlert is a wishlisted product whose price or availability differs from what the customer last saw
+type WishlistAlert struct {
+	ItemID          int64
+	UserID          int64
+	Email           string
+	WishlistName    string
+	ProductID       int64
+	ProductName     string
+	AlertPriceCents int
+	PriceCents      int
+	AlertInStock    bool
+	InStock         bool
+}
+
+const wishlistColumns = `id, user_id, name, kind, share_token, created_at, updated_at`
+
+func scanWishlist(row pgx.Row) (*Wishlist, error) {
+	w := &Wishlist{}
+	err := row.Scan(&w.ID, &w.UserID, &w.Name, &w.Kind, &w.ShareToken, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// GetWishlists retrieves all wishlists of a user including items, saved for later list first
+// used in: handlers.GetWishlists
+func GetWishlists(ctx context.Context, userId int64) ([]Wishlist, error) {
+	query := `SELECT ` + wishlistColumns + `
+	          FROM wishlists
+	          WHERE user_id=$1
+	          ORDER BY kind, created_at`
+	rows, err := db.DB.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wishlists := []Wishlist{}
+	for rows.Next() {
+		w, err := scanWishlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		wishlists = append(wishlists, *w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range wishlists {
+		if err := wishlists[i].LoadItems(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return wishlists, nil
+}
+
+// GetWishlist retrieves a wishlist of a user including items
+// used in: handlers.GetWishlist, handlers.UpdateWishlist, handlers.DeleteWishlist, handlers.AddWishlistItem,
+// handlers.RemoveWishlistItem, handlers.MoveWishlistItemToCart, handlers.ShareWishlist, handlers.UnshareWishlist
+func GetWishlist(ctx context.Context, id, userId int64) (*Wishlist, error) {
+	query := `SELECT ` + wishlistColumns + ` FROM wishlists WHERE id=$1 AND user_id=$2`
+	w, err := scanWishlist(db.DB.QueryRow(ctx, query, id, userId))
+	if err != nil {
+		return nil, err
+	}
+	return w, w.LoadItems(ctx)
+}
+
+// GetSharedWishlist retrieves a shared wishlist by its share token
+// used in: handlers.GetSharedWishlist
+func GetSharedWishlist(ctx context.Context, token string) (*Wishlist, error) {
+	query := `SELECT ` + wishlistColumns + ` FROM wishlists WHERE share_token=$1`
+	w, err := scanWishlist(db.DB.QueryRow(ctx, query, token))
+	if err != nil {
+		return nil, err
+	}
+	return w, w.LoadItems(ctx)
+}
+
+// GetOrCreateSavedForLater retrieves the user's saved for later list, creating it on first use
+// used in: handlers.SaveForLater
+func GetOrCreateSavedForLater(ctx context.Context, userId int64) (*Wishlist, error) {
+	query := `INSERT INTO wishlists (user_id, name, kind, created_at)
+	          VALUES ($1, $2, $3, now())
+	          ON CONFLICT (user_id) WHERE kind = 'saved_for_later' DO UPDATE SET kind=EXCLUDED.kind
+	          RETURNING ` + wishlistColumns
+	return scanWishlist(db.DB.QueryRow(ctx, query, userId, savedForLaterName, WishlistKindSavedForLater))
+}
+
+// LoadItems loads the wishlist items with current price and availability
+// used in: GetWishlists, GetWishlist, GetSharedWishlist
+func (w *Wishlist) LoadItems(ctx context.Context) error {
+	query := `SELECT wi.id, wi.wishlist_id, wi.product_id, wi.quantity, wi.created_at,
+	                 p.name, COALESCE(p.image_url, ''), p.price_cents, (p.status='active' AND p.stock_qty > 0)
+	          FROM wishlist_items wi
+	          JOIN products p ON p.id = wi.product_id
+	          WHERE wi.wishlist_id=$1
+	          ORDER BY wi.created_at DESC`
+	rows, err := db.DB.Query(ctx, query, w.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	w.Items = []WishlistItem{}
+	for rows.Next() {
+		var item WishlistItem
+		err := rows.Scan(&item.ID, &item.WishlistID, &item.ProductID, &item.Quantity, &item.CreatedAt,
+			&item.ProductName, &item.ProductImageURL, &item.PriceCents, &item.InStock)
+		if err != nil {
+			return err
+		}
+		w.Items = append(w.Items, item)
+	}
+	return rows.Err()
+}
+
+// Insert creates a new named wishlist for the user
+// used in: handlers.CreateWishlist
+func (w *Wishlist) Insert(ctx context.Context) error {
+	query := `INSERT INTO wishlists (user_id, name, kind, created_at)
+	          VALUES ($1, $2, $3, now())
+	          RETURNING id, kind, created_at`
+	err := db.DB.QueryRow(ctx, query, w.UserID, w.Name, WishlistKindWishlist).Scan(&w.ID, &w.Kind, &w.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrWishlistNameTaken
+	}
+	if err != nil {
+		return err
+	}
+	w.Items = []WishlistItem{}
+	return nil
+}
+
+// Rename changes the name of a wishlist; the saved for later list keeps its name
+// used in: handlers.UpdateWishlist
+func (w *Wishlist) Rename(ctx context.Context, name string) error {
+	if w.Kind == WishlistKindSavedForLater {
+		return ErrSavedForLaterList
+	}
+	query := `UPDATE wishlists SET name=$1, updated_at=now() WHERE id=$2 RETURNING updated_at`
+	err := db.DB.QueryRow(ctx, query, name, w.ID).Scan(&w.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrWishlistNameTaken
+	}
+	if err != nil {
+		return err
+	}
+	w.Name = name
+	return nil
+}
+
+// Delete removes the wishlist and its items
+// used in: handlers.DeleteWishlist
+func (w *Wishlist) Delete(ctx context.Context) error {
+	_, err := db.DB.Exec(ctx, `DELETE FROM wishlists WHERE id=$1`, w.ID)
+	return err
+}
+
+// Share creates a random share token for the read-only link; an existing token is kept
+// used in: handlers.ShareWishlist
+func (w *Wishlist) Share(ctx context.Context) error {
+	if w.ShareToken != nil {
+		return nil
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	query := `UPDATE wishlists SET share_token=$1, updated_at=now() WHERE id=$2 RETURNING updated_at`
+	if err := db.DB.QueryRow(ctx, query, token, w.ID).Scan(&w.UpdatedAt); err != nil {
+		return err
+	}
+	w.ShareToken = &token
+	return nil
+}
+
+// Unshare revokes the share token, invalidating existing links
+// used in: handlers.UnshareWishlist
+func (w *Wishlist) Unshare(ctx context.Context) error {
+	query := `UPDATE wishlists SET share_token=NULL, updated_at=now() WHERE id=$1 RETURNING updated_at`
+	if err := db.DB.QueryRow(ctx, query, w.ID).Scan(&w.UpdatedAt); err != nil {
+		return err
+	}
+	w.ShareToken = nil
+	return nil
+}
+
+// addItem adds a product or increases its quantity. The current price and availability become
+// the baseline for price drop and back in stock alerts of a new item.
+func addItem(ctx context.Context, tx pgx.Tx, wishlistId, productId int64, quantity int) error {
+	query := `INSERT INTO wishlist_items (wishlist_id, product_id, quantity, alert_price_cents, alert_in_stock, created_at)
+	          SELECT $1, p.id, $3, p.price_cents, (p.status='active' AND p.stock_qty > 0), now()
+	          FROM products p WHERE p.id=$2
+	          ON CONFLICT (wishlist_id, product_id) DO UPDATE SET quantity=wishlist_items.quantity + EXCLUDED.quantity, updated_at=now()`
+	tag, err := tx.Exec(ctx, query, wishlistId, productId, quantity)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	_, err = tx.Exec(ctx, `UPDATE wishlists SET updated_at=now() WHERE id=$1`, wishlistId)
+	return err
+}
+
+// AddItem adds a product to the wishlist; pgx.ErrNoRows if the product does not exist
+// used in: handlers.AddWishlistItem
+func (w *Wishlist) AddItem(ctx context.Context, productId int64, quantity int) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addItem(ctx, tx, w.ID, productId, quantity); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return w.LoadItems(ctx)
+}
+
+// RemoveItem removes a product from the wishlist; pgx.ErrNoRows if it is not on the list
+// used in: handlers.RemoveWishlistItem
+func (w *Wishlist) RemoveItem(ctx context.Context, productId int64) error {
+	tag, err := db.DB.Exec(ctx, `DELETE FROM wishlist_items WHERE wishlist_id=$1 AND product_id=$2`, w.ID, productId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if _, err := db.DB.Exec(ctx, `UPDATE wishlists SET updated_at=now() WHERE id=$1`, w.ID); err != nil {
+		return err
+	}
+	return w.LoadItems(ctx)
+}
+
+// Item returns the wishlist item of a product
+// used in: handlers.MoveWishlistItemToCart
+func (w *Wishlist) Item(productId int64) (*WishlistItem, bool) {
+	for i := range w.Items {
+		if w.Items[i].ProductID == productId {
+			return &w.Items[i], true
+		}
+	}
+	return nil, false
+}
+
+// MoveToCart moves a wishlist item into the cart at the current price, adding to an existing cart line
+// used in: handlers.MoveWishlistItemToCart
+func (w *Wishlist) MoveToCart(ctx context.Context, productId int64, cart *Cart) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var quantity int
+	err = tx.QueryRow(ctx, `DELETE FROM wishlist_items WHERE wishlist_id=$1 AND product_id=$2 RETURNING quantity`, w.ID, productId).Scan(&quantity)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO cart_items (cart_id, product_id, quantity, price_cents, created_at)
+		SELECT $1, p.id, $3, p.price_cents, now() FROM products p WHERE p.id=$2
+		ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity=cart_items.quantity + EXCLUDED.quantity, updated_at=now()
+	`, cart.ID, productId, quantity)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, cart.ID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE wishlists SET updated_at=now() WHERE id=$1`, w.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	if err := w.LoadItems(ctx); err != nil {
+		return err
+	}
+	return cart.Reload(ctx)
+}
+
+// SaveForLater moves a cart item with its quantity to the saved for later list; ErrItemNotInCart if missing
+// used in: handlers.SaveForLater
+func (w *Wishlist) SaveForLater(ctx context.Context, cart *Cart, productId int64) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var quantity int
+	err = tx.QueryRow(ctx, `DELETE FROM cart_items WHERE cart_id=$1 AND product_id=$2 RETURNING quantity`, cart.ID, productId).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrItemNotInCart
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := addItem(ctx, tx, w.ID, productId, quantity); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE carts SET updated_at=now() WHERE id=$1`, cart.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	if err := w.LoadItems(ctx); err != nil {
+		return err
+	}
+	return cart.Reload(ctx)
+}
+
+// GetWishlistAlerts returns wishlist items whose product price or availability changed since the last alert baseline,
+// ordered by item id and starting after afterID
+// used in: jobs.WishlistAlertJob
+func GetWishlistAlerts(ctx context.Context, afterID int64, limit int) ([]WishlistAlert, error) {
+	query := `SELECT wi.id, w.user_id, u.email, w.name, p.id, p.name,
+	                 wi.alert_price_cents, p.price_cents, wi.alert_in_stock, (p.status='active' AND p.stock_qty > 0)
+	          FROM wishlist_items wi
+	          JOIN wishlists w ON w.id = wi.wishlist_id
+	          JOIN users u ON u.id = w.user_id
+	          JOIN products p ON p.id = wi.product_id
+	          WHERE wi.id > $1
+	            AND (p.price_cents <> wi.alert_price_cents
+	                 OR wi.alert_in_stock <> (p.status='active' AND p.stock_qty > 0))
+	          ORDER BY wi.id
+	          LIMIT $2`
+	rows, err := db.DB.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []WishlistAlert{}
+	for rows.Next() {
+		var a WishlistAlert
+		err := rows.Scan(&a.ItemID, &a.UserID, &a.Email, &a.WishlistName, &a.ProductID, &a.ProductName,
+			&a.AlertPriceCents, &a.PriceCents, &a.AlertInStock, &a.InStock)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// UpdateAlertBaseline stores the price and availability the customer now knows about
+// used in: jobs.WishlistAlertJob
+func UpdateAlertBaseline(ctx context.Context, itemId int64, priceCents int, inStock bool) error {
+	query := `UPDATE wishlist_items SET alert_price_cents=$1, alert_in_stock=$2 WHERE id=$3`
+	_, err := db.DB.Exec(ctx, query, priceCents, inStock, itemId)
+	return err
+}
@@ -2,14 +2,20 @@ package main
 
 import (
 	"os"
+	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/idempotency"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
 	"rearatrox/go-ecommerce-backend/services/cart-service/handlers"
 	"strings"
+	"time"
 
 	docs "rearatrox/go-ecommerce-backend/services/cart-service/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -18,16 +24,14 @@ import (
 const DEFAULT_PORT = "8083"
 
 func RegisterRoutes(router *gin.Engine) {
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
 	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
 	router.NoRoute(func(c *gin.Context) {
 		log := logger.FromContext(c.Request.Context())
 		log.Warn("unknown route")
@@ -47,23 +51,99 @@ func RegisterRoutes(router *gin.Engine) {
 	docs.SwaggerInfo.Host = "localhost:" + port
 	docs.SwaggerInfo.BasePath = apiPrefix
 
+	// replays responses of retried requests sent with an Idempotency-Key header
+	idempotent := idempotency.Middleware(idempotency.NewPostgresStore(db.DB))
+
+	// client IPs of guests are taken from X-Forwarded-For only if set by the gateway (TRUSTED_PROXIES)
+	if err := ratelimit.TrustProxiesFromEnv(router, "TRUSTED_PROXIES"); err != nil {
+		panic(err.Error())
+	}
+
+	// per user limit of cart and wishlist changes, guests per client IP (RATE_LIMIT_CART)
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		panic(err.Error())
+	}
+	cartLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "cart", Limit: ratelimit.Limit{Requests: 60, Period: time.Minute}, Key: ratelimit.ByUser})
+
 	api := router.Group(apiPrefix)
 	{
 		// make sure the swagger UI knows where to fetch the generated spec
 		api.GET("/cart/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-		// All cart routes require authentication
-		authenticated := api.Group("/")
-		authenticated.Use(middleware.Authenticate)
+		// Internal endpoints (service-to-service communication, signed by the calling services named per route)
+		internal := api.Group("/internal")
+		{
+			internal.POST("/cart/merge", serviceauth.InternalAuth("user-service"), handlers.MergeGuestCart)
+		}
+
+		// Cart routes work for authenticated users and for guests identified by the X-Cart-Token header
+		cart := api.Group("/")
+		cart.Use(middleware.OptionalAuthenticate)
 		{
 			// Cart endpoints
-			authenticated.GET("/cart", handlers.GetCart)
-			authenticated.DELETE("/cart", handlers.ClearCart)
+			cart.GET("/cart", handlers.GetCart)
+			cart.DELETE("/cart", cartLimit, handlers.ClearCart)
+			cart.POST("/cart/acknowledge", cartLimit, handlers.AcknowledgeCartChanges)
+			cart.POST("/cart/restore", cartLimit, handlers.RestoreCart)
 
 			// Cart items
-			authenticated.POST("/cart/items", handlers.AddItem)
-			authenticated.PUT("/cart/items/:productId", handlers.UpdateItem)
-			authenticated.DELETE("/cart/items/:productId", handlers.RemoveItem)
+			cart.POST("/cart/items", cartLimit, idempotent, handlers.AddItem)
+			cart.PUT("/cart/items/:productId", cartLimit, handlers.UpdateItem)
+			cart.DELETE("/cart/items/:productId", cartLimit, handlers.RemoveItem)
+
+			// Coupon on the cart
+			cart.POST("/cart/coupon", cartLimit, handlers.ApplyCoupon)
+			cart.DELETE("/cart/coupon", cartLimit, handlers.RemoveCoupon)
+
+			// Shipping quotes for the cart
+			cart.GET("/cart/shipping-options", handlers.GetShippingOptions)
+		}
+
+		// Read-only shared wishlists (public)
+		api.GET("/wishlists/shared/:token", handlers.GetSharedWishlist)
+
+		authenticated := api.Group("/")
+		authenticated.Use(middleware.Authenticate)
+		{
+			// Save for later (registered users only)
+			authenticated.POST("/cart/items/:productId/save-for-later", cartLimit, handlers.SaveForLater)
+
+			// Wishlists
+			authenticated.GET("/wishlists", handlers.GetWishlists)
+			authenticated.POST("/wishlists", cartLimit, handlers.CreateWishlist)
+			authenticated.GET("/wishlists/:id", handlers.GetWishlist)
+			authenticated.PUT("/wishlists/:id", cartLimit, handlers.UpdateWishlist)
+			authenticated.DELETE("/wishlists/:id", cartLimit, handlers.DeleteWishlist)
+			authenticated.POST("/wishlists/:id/items", cartLimit, handlers.AddWishlistItem)
+			authenticated.DELETE("/wishlists/:id/items/:productId", cartLimit, handlers.RemoveWishlistItem)
+			authenticated.POST("/wishlists/:id/items/:productId/move-to-cart", cartLimit, handlers.MoveWishlistItemToCart)
+			authenticated.POST("/wishlists/:id/share", cartLimit, handlers.ShareWishlist)
+			authenticated.DELETE("/wishlists/:id/share", cartLimit, handlers.UnshareWishlist)
+
+			// admin-only
+			admin := authenticated.Group("/admin")
+			admin.Use(middleware.Authorize("admin"))
+			{
+				// Shipping configuration
+				admin.GET("/shipping/zones", handlers.GetShippingZones)
+				admin.POST("/shipping/zones", handlers.CreateShippingZone)
+				admin.PUT("/shipping/zones/:id", handlers.UpdateShippingZone)
+				admin.DELETE("/shipping/zones/:id", handlers.DeleteShippingZone)
+				admin.GET("/shipping/methods", handlers.GetShippingMethods)
+				admin.POST("/shipping/methods", handlers.CreateShippingMethod)
+				admin.PUT("/shipping/methods/:id", handlers.UpdateShippingMethod)
+				admin.DELETE("/shipping/methods/:id", handlers.DeleteShippingMethod)
+
+				// Coupons
+				admin.GET("/coupons", handlers.GetCoupons)
+				admin.POST("/coupons", handlers.CreateCoupon)
+				admin.PUT("/coupons/:id", handlers.UpdateCoupon)
+				admin.DELETE("/coupons/:id", handlers.DeleteCoupon)
+
+				// Abandoned cart reporting
+				admin.GET("/carts/abandonment", handlers.GetAbandonmentReport)
+			}
 		}
 	}
 }
@@ -0,0 +1,104 @@
+// Package grpcapi implements the internal gRPC API of the order-service (proto/order/v1/order.proto)
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/pb/orderpb"
+	"rearatrox/go-ecommerce-backend/services/order-service/handlers"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Allowlist names the services allowed to call each method
+var Allowlist = serviceauth.Allowlist{
+	orderpb.OrderInternal_GetOrder_FullMethodName:          {"payment-service"},
+	orderpb.OrderInternal_UpdateOrderStatus_FullMethodName: {"payment-service"},
+}
+
+// Server serves orders to the payment-service, like handlers.InternalGetOrder and handlers.InternalUpdateOrderStatus
+type Server struct {
+	orderpb.UnimplementedOrderInternalServer
+}
+
+// GetOrder returns an order without checking its owner
+func (s *Server) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	order, err := getOrder(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toProto(order), nil
+}
+
+// UpdateOrderStatus confirms or cancels an order
+func (s *Server) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.Order, error) {
+	l := logger.FromContext(ctx)
+	if req.GetStatus() == "" {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+
+	order, err := getOrder(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := handlers.ApplyInternalStatus(ctx, order, req.GetStatus()); err != nil {
+		switch {
+		case errors.Is(err, models.ErrOrderNotCancellable):
+			l.Warn("cannot cancel order in current state (internal)", "order_id", order.ID, "status", order.Status)
+			return nil, status.Errorf(codes.FailedPrecondition, "order cannot be cancelled in current state %q", order.Status)
+		case errors.Is(err, models.ErrOrderNotConfirmable):
+			l.Warn("cannot confirm order in current state (internal)", "order_id", order.ID, "status", order.Status)
+			return nil, status.Errorf(codes.FailedPrecondition, "only pending orders can be confirmed, order is %q", order.Status)
+		case errors.Is(err, models.ErrStockNotReserved) && status.Code(err) == codes.FailedPrecondition:
+			l.Warn("insufficient stock to confirm order", "order_id", order.ID, "error", err)
+			return nil, status.Error(codes.FailedPrecondition, "insufficient stock")
+		default:
+			l.Error("failed to update order status", "order_id", order.ID, "error", err)
+			return nil, status.Error(codes.Internal, "could not update order status")
+		}
+	}
+
+	l.Info("updated order status (internal)", "order_id", order.ID, "new_status", req.GetStatus())
+	return toProto(order), nil
+}
+
+func getOrder(ctx context.Context, id int64) (*models.Order, error) {
+	order, err := models.GetOrderByIDInternal(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get order", "order_id", id, "error", err)
+		return nil, status.Error(codes.Internal, "could not get order")
+	}
+	return order, nil
+}
+
+func toProto(o *models.Order) *orderpb.Order {
+	out := &orderpb.Order{
+		Id:            o.ID,
+		UserId:        o.UserID,
+		GuestEmail:    o.GuestEmail,
+		Status:        o.Status,
+		SubtotalCents: int32(o.SubtotalCents),
+		ShippingCents: int32(o.ShippingCents),
+		DiscountCents: int32(o.DiscountCents),
+		TotalCents:    int32(o.TotalCents),
+	}
+	for _, item := range o.Items {
+		out.Items = append(out.Items, &orderpb.OrderItem{
+			ProductId:   item.ProductID,
+			Quantity:    int32(item.Quantity),
+			PriceCents:  int32(item.PriceCents),
+			ProductName: item.ProductName,
+		})
+	}
+	return out
+}
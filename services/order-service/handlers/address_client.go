@@ -1,72 +1,49 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-)
-
-type AddressResponse struct {
-	ID      int64  `json:"id"`
-	UserID  int64  `json:"userId"`
-	Street  string `json:"street"`
-	City    string `json:"city"`
-	ZipCode string `json:"zipCode"`
-	Country string `json:"country"`
-}
-
-// verifyAddressOwnership checks if an address belongs to the given user
-func verifyAddressOwnership(addressID int64, userID int64, jwtToken string) error {
-	if addressID == 0 {
-		return nil // No address specified, which is allowed
-	}
-
-	userServiceURL := "http://user-service:8080"
-
-	apiPrefix := os.Getenv("API_PREFIX")
-	if apiPrefix == "" {
-		apiPrefix = "/api/v1"
-	}
 
-	url := fmt.Sprintf("%s%s/users/me/addresses/%d", userServiceURL, apiPrefix, addressID)
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	usermodels "rearatrox/go-ecommerce-backend/services/user-service/models"
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Authorization", jwtToken)
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to call user-service: %w", err)
+// addressSnapshot copies the saved address into the snapshot stored on the order
+func addressSnapshot(a *usermodels.Address) *models.Address {
+	if a == nil {
+		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("address not found")
+	return &models.Address{
+		FullName:   a.FullName,
+		Street:     a.Street,
+		PostalCode: a.PostalCode,
+		City:       a.City,
+		Country:    a.Country,
 	}
+}
 
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("address does not belong to user")
+// verifyAddressOwnership checks if an address belongs to the given user and returns it
+func verifyAddressOwnership(ctx context.Context, addressID int64, userID int64) (*usermodels.Address, error) {
+	if addressID == 0 {
+		return nil, nil // No address specified, which is allowed
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("user-service returned status %d: %s", resp.StatusCode, string(body))
+	// the user-service only finds addresses of the given user
+	address, err := userService.GetAddress(ctx, userID, addressID)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("address not found")
 	}
-
-	var address AddressResponse
-	if err := json.NewDecoder(resp.Body).Decode(&address); err != nil {
-		return fmt.Errorf("failed to decode address response: %w", err)
+	if err != nil {
+		return nil, err
 	}
 
 	// Double-check that the address belongs to the user
 	if address.UserID != userID {
-		return fmt.Errorf("address does not belong to user")
+		return nil, fmt.Errorf("address does not belong to user")
 	}
 
-	return nil
+	return address, nil
 }
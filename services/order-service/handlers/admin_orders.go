@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// parseOrderSearch reads the admin order filters from the query string
+func parseOrderSearch(context *gin.Context) (models.OrderSearch, error) {
+	s := models.OrderSearch{
+		Status: context.Query("status"),
+		Email:  strings.TrimSpace(context.Query("email")),
+	}
+
+	if v := context.Query("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return s, fmt.Errorf("invalid from date: %w", err)
+		}
+		s.From = &from
+	}
+	if v := context.Query("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return s, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = to.AddDate(0, 0, 1)
+		s.To = &to
+	}
+	if v := context.Query("userId"); v != "" {
+		userId, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return s, fmt.Errorf("invalid user ID: %w", err)
+		}
+		s.UserID = &userId
+	}
+
+	ints := []struct {
+		name   string
+		target **int
+	}{{"minTotalCents", &s.MinTotalCents}, {"maxTotalCents", &s.MaxTotalCents}}
+	for _, p := range ints {
+		if v := context.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return s, fmt.Errorf("invalid %s: %w", p.name, err)
+			}
+			*p.target = &n
+		}
+	}
+
+	var err error
+	if v := context.Query("limit"); v != "" {
+		if s.Limit, err = strconv.Atoi(v); err != nil {
+			return s, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	if v := context.Query("offset"); v != "" {
+		if s.Offset, err = strconv.Atoi(v); err != nil {
+			return s, fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// getAdminOrderParam loads any order from the :id path parameter. Returns false if a response was written.
+func getAdminOrderParam(context *gin.Context) (*models.Order, bool) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return nil, false
+	}
+
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to get order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return nil, false
+	}
+	return order, true
+}
+
+// respondAdminOrder answers with the full admin view of an order
+func respondAdminOrder(context *gin.Context, orderId int64) {
+	l := logger.FromContext(context.Request.Context())
+
+	order, err := models.GetAdminOrder(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to get order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch order.", "error": err.Error()})
+		return
+	}
+	context.JSON(http.StatusOK, order)
+}
+
+// AdminListOrders godoc
+// @Summary      Search orders
+// @Description  List all orders, newest first, filtered by status, creation date range, customer and total (admin only)
+// @Tags         Orders (Admin)
+// @Produce      json
+// @Param        status         query     string  false  "Order status"
+// @Param        from           query     string  false  "Created on or after (YYYY-MM-DD)"
+// @Param        to             query     string  false  "Created on or before (YYYY-MM-DD)"
+// @Param        userId         query     int     false  "Customer user ID"
+// @Param        email          query     string  false  "Part of the customer or guest email"
+// @Param        minTotalCents  query     int     false  "Minimum order total in cents"
+// @Param        maxTotalCents  query     int     false  "Maximum order total in cents"
+// @Param        limit          query     int     false  "Page size (default 50, max 200)"
+// @Param        offset         query     int     false  "Number of orders to skip"
+// @Success      200            {object}  models.OrderPage
+// @Failure      400            {object}  map[string]interface{}
+// @Failure      401            {object}  map[string]interface{}
+// @Failure      403            {object}  map[string]interface{}
+// @Failure      500            {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders [get]
+func AdminListOrders(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	search, err := parseOrderSearch(context)
+	if err != nil {
+		l.Warn("invalid order search", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid search.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("AdminListOrders called", "status", search.Status, "email", search.Email, "limit", search.Limit, "offset", search.Offset)
+
+	page, err := models.SearchOrders(context.Request.Context(), search)
+	if err != nil {
+		l.Error("failed to search orders", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch orders.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, page)
+}
+
+// AdminGetOrder godoc
+// @Summary      Get any order
+// @Description  Get an order of any customer with items, addresses, shipments, payments and refunds, returns, invoices and internal notes (admin only)
+// @Tags         Orders (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {object}  models.AdminOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id} [get]
+func AdminGetOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("AdminGetOrder called", "order_id", orderId)
+
+	order, err := models.GetAdminOrder(context.Request.Context(), orderId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+			return
+		}
+		l.Error("failed to get order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch order.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, order)
+}
+
+// AddOrderNote godoc
+// @Summary      Add internal note
+// @Description  Add an internal staff note to an order; notes are never shown to the customer (admin only)
+// @Tags         Orders (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                      true  "Order ID"
+// @Param        request  body      models.OrderNoteRequest  true  "Note"
+// @Success      201      {object}  models.OrderNote
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/notes [post]
+func AddOrderNote(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	adminId := context.GetInt64("userId")
+
+	var req models.OrderNoteRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("AddOrderNote called", "order_id", context.Param("id"), "admin_id", adminId)
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+
+	note, err := order.AddNote(context.Request.Context(), req.Body, adminId)
+	if err != nil {
+		l.Error("failed to add order note", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not add note.", "error": err.Error()})
+		return
+	}
+
+	l.Info("added order note", "order_id", order.ID, "note_id", note.ID, "admin_id", adminId)
+	context.JSON(http.StatusCreated, note)
+}
+
+// AdminShipOrder godoc
+// @Summary      Ship order
+// @Description  Ship everything not shipped yet in one parcel with the given carrier and tracking number (admin only). Items in the request are ignored; use the shipments endpoint for partial shipments.
+// @Tags         Orders (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true  "Order ID"
+// @Param        request  body      models.CreateShipmentRequest  true  "Carrier and tracking"
+// @Success      200      {object}  models.AdminOrder
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/ship [post]
+func AdminShipOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	var req models.CreateShipmentRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+	req.Items = nil
+
+	l.Debug("AdminShipOrder called", "order_id", context.Param("id"), "carrier", req.Carrier)
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+
+	shipment, err := order.CreateShipment(context.Request.Context(), req)
+	if respondShipmentError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to ship order", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not ship order.", "error": err.Error()})
+		return
+	}
+
+	l.Info("shipped order", "order_id", order.ID, "shipment_id", shipment.ID, "order_status", order.Status)
+	respondAdminOrder(context, order.ID)
+}
+
+// AdminDeliverOrder godoc
+// @Summary      Mark order delivered
+// @Description  Mark every shipment of a fully shipped order as delivered, which makes the order delivered (admin only)
+// @Tags         Orders (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {object}  models.AdminOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/deliver [post]
+func AdminDeliverOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("AdminDeliverOrder called", "order_id", context.Param("id"))
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+
+	err := order.MarkDelivered(context.Request.Context())
+	if errors.Is(err, models.ErrOrderNotShippable) || errors.Is(err, models.ErrOrderNotFullyShipped) {
+		l.Warn("order cannot be delivered", "order_id", order.ID, "status", order.Status, "error", err)
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error(), "status": order.Status})
+		return
+	}
+	if err != nil {
+		l.Error("failed to mark order delivered", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not mark order delivered.", "error": err.Error()})
+		return
+	}
+
+	l.Info("marked order delivered", "order_id", order.ID, "order_status", order.Status)
+	respondAdminOrder(context, order.ID)
+}
+
+// AdminCancelOrder godoc
+// @Summary      Cancel order
+// @Description  Cancel a pending or confirmed order that has not been shipped (admin only). Stock reduced at confirmation is restored. With refund the full payment of a confirmed order is refunded via payment-service once the order is cancelled; if the refund fails the order stays cancelled and 502 is returned. The note is stored as internal order note.
+// @Tags         Orders (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                        true   "Order ID"
+// @Param        request  body      models.AdminCancelRequest  false  "Refund and note"
+// @Success      200      {object}  models.AdminOrder
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Failure      502      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/cancel [post]
+func AdminCancelOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	adminId := context.GetInt64("userId")
+
+	var req models.AdminCancelRequest
+	if context.Request.ContentLength > 0 {
+		if err := context.ShouldBindJSON(&req); err != nil {
+			l.Error("failed to bind request", "error", err)
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+			return
+		}
+	}
+
+	l.Debug("AdminCancelOrder called", "order_id", context.Param("id"), "refund", req.Refund, "admin_id", adminId)
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+
+	if (order.Status != "pending" && order.Status != "confirmed") || len(order.Shipments) > 0 {
+		l.Warn("cannot cancel order in current state", "order_id", order.ID, "status", order.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": "order cannot be cancelled in current state", "status": order.Status})
+		return
+	}
+
+	// the locked transition decides whether the order was confirmed, i.e. paid, and refunds only follow a cancellation
+	previous, err := cancelOrderFrom(context.Request.Context(), order, false)
+	if errors.Is(err, models.ErrOrderNotCancellable) {
+		l.Warn("cannot cancel order in current state", "order_id", order.ID, "status", order.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": "order cannot be cancelled in current state", "status": order.Status})
+		return
+	}
+	if err != nil {
+		l.Error("failed to cancel order", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not cancel order.", "error": err.Error()})
+		return
+	}
+
+	if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
+		if _, err := order.AddNote(context.Request.Context(), *req.Note, adminId); err != nil {
+			l.Error("failed to add order note", "order_id", order.ID, "error", err)
+		}
+	}
+
+	refunded := req.Refund && previous == "confirmed"
+	if refunded {
+		refund, err := createRefund(context.Request.Context(), order.ID, nil, order.TotalCents, "order cancelled")
+		if err != nil {
+			l.Error("failed to refund cancelled order", "order_id", order.ID, "error", err)
+			context.JSON(http.StatusBadGateway, gin.H{"message": "order cancelled, but could not refund it.", "error": err.Error()})
+			return
+		}
+		l.Info("refunded order", "order_id", order.ID, "refund_id", refund.ID, "amount_cents", refund.AmountCents)
+		issueCreditNote(context, order, refund.ID, order.InvoiceLines(), order.ShippingCents, order.DiscountCents)
+	}
+
+	l.Info("cancelled order (admin)", "order_id", order.ID, "refund", refunded, "admin_id", adminId)
+	respondAdminOrder(context, order.ID)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/cartcheck"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/promotions"
+	"rearatrox/go-ecommerce-backend/pkg/shipping"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ensureCartUnchanged revalidates the cart against current prices, product status and stock and rejects
+// the checkout with the per-line warnings until the customer acknowledged them via POST /cart/acknowledge
+// used in: CreateOrder, CreateGuestOrder
+func ensureCartUnchanged(context *gin.Context, cartId int64) bool {
+	l := logger.FromContext(context.Request.Context())
+
+	warnings, err := cartcheck.Validate(context.Request.Context(), cartId)
+	if err != nil {
+		l.Error("failed to revalidate cart", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not validate cart.", "error": err.Error()})
+		return false
+	}
+
+	if len(warnings) > 0 {
+		l.Warn("cart changed since items were added", "cart_id", cartId, "warnings_count", len(warnings))
+		context.JSON(http.StatusConflict, gin.H{
+			"message":  "cart has changed, please review and acknowledge the changes via POST /cart/acknowledge.",
+			"warnings": warnings,
+		})
+		return false
+	}
+	return true
+}
+
+// ensureStock checks the stock of all cart items and writes the error response if one is short
+// used in: CreateOrder, CreateGuestOrder
+func ensureStock(context *gin.Context, cartItems []models.CartItem) bool {
+	l := logger.FromContext(context.Request.Context())
+
+	for _, item := range cartItems {
+		stockResp, err := productService.CheckStock(context.Request.Context(), item.ProductID, item.Quantity)
+		if err != nil {
+			l.Error("failed to check stock", "product_id", item.ProductID, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock availability.", "error": err.Error()})
+			return false
+		}
+
+		if !stockResp.Available {
+			l.Warn("insufficient stock for order", "product_id", item.ProductID, "requested", item.Quantity, "available", stockResp.AvailableQty)
+			context.JSON(http.StatusConflict, gin.H{
+				"message":     "insufficient stock",
+				"productId":   item.ProductID,
+				"productName": item.ProductName,
+				"requested":   item.Quantity,
+				"available":   stockResp.AvailableQty,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// respondShippingError writes a 400 response if order creation failed because the chosen shipping method cannot
+// deliver the cart to the destination country
+// used in: CreateOrder, CreateGuestOrder
+func respondShippingError(context *gin.Context, err error, methodId int64, country string) bool {
+	if !errors.Is(err, shipping.ErrMethodUnavailable) {
+		return false
+	}
+	l := logger.FromContext(context.Request.Context())
+	l.Warn("shipping method unavailable", "shipping_method_id", methodId, "country", country)
+	context.JSON(http.StatusBadRequest, gin.H{"message": "shipping method not available.", "error": err.Error()})
+	return true
+}
+
+// respondCouponError writes a 422 response if order creation failed because the cart coupon no longer applies
+// used in: CreateOrder, CreateGuestOrder
+func respondCouponError(context *gin.Context, err error) bool {
+	var couponErr *promotions.CouponError
+	if !errors.As(err, &couponErr) {
+		return false
+	}
+	logger.FromContext(context.Request.Context()).Warn("coupon not applicable", "code", couponErr.Code, "reason", couponErr.Reason)
+	context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "coupon cannot be applied.", "code": couponErr.Code, "reason": couponErr.Reason})
+	return true
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	paymentmodels "rearatrox/go-ecommerce-backend/services/payment-service/models"
+)
+
+var (
+	productService *clients.ProductGRPCClient // stock checks, reservations and restocking
+	userService    *clients.UserGRPCClient    // saved addresses of the customer
+	paymentService *clients.PaymentClient     // refunds and cancelling open payments
+)
+
+// UseClients sets the clients of the other services the handlers call; main builds them and calls it before the
+// routes are registered and the jobs are started
+func UseClients(product *clients.ProductGRPCClient, user *clients.UserGRPCClient, payment *clients.PaymentClient) {
+	productService, userService, paymentService = product, user, payment
+}
+
+// createRefund asks the payment-service to refund part of the order's payment
+func createRefund(ctx context.Context, orderID int64, returnID *int64, amountCents int, reason string) (*paymentmodels.Refund, error) {
+	return paymentService.CreateRefund(ctx, paymentmodels.CreateRefundRequest{
+		OrderID:     orderID,
+		ReturnID:    returnID,
+		AmountCents: amountCents,
+		Reason:      &reason,
+	})
+}
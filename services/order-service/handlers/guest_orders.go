@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GuestAddress struct {
+	FullName   string `json:"fullName" binding:"required" example:"Jane Doe"`
+	Street     string `json:"street" binding:"required" example:"Hauptstraße 1"`
+	PostalCode string `json:"postalCode" binding:"required" example:"10115"`
+	City       string `json:"city" binding:"required" example:"Berlin"`
+	Country    string `json:"country" binding:"required" example:"DE"`
+}
+
+type CreateGuestOrderRequest struct {
+	Email            string        `json:"email" binding:"required,email" example:"guest@example.com"`
+	ShippingAddress  *GuestAddress `json:"shippingAddress" binding:"required"`
+	BillingAddress   *GuestAddress `json:"billingAddress"`
+	ShippingMethodID *int64        `json:"shippingMethodId" binding:"required" example:"1"`
+}
+
+// toAddress converts the inline guest address into the snapshot stored on the order
+func (a *GuestAddress) toAddress() *models.Address {
+	if a == nil {
+		return nil
+	}
+	return &models.Address{
+		FullName:   strings.TrimSpace(a.FullName),
+		Street:     strings.TrimSpace(a.Street),
+		PostalCode: strings.TrimSpace(a.PostalCode),
+		City:       strings.TrimSpace(a.City),
+		Country:    strings.TrimSpace(a.Country),
+	}
+}
+
+// CreateGuestOrder godoc
+// @Summary      Guest checkout
+// @Description  Creates an order from the guest cart identified by the X-Cart-Token header, using an email and inline addresses instead of an account. The response contains an orderToken (also sent as X-Order-Token header) to view and pay the order.
+// @Tags         Guest Checkout
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token  header    string                   true  "Guest cart token"
+// @Param        Idempotency-Key  header    string                   false  "Retries with the same key replay the first response"
+// @Param        request       body      CreateGuestOrderRequest  true  "Email, addresses and shipping method"
+// @Success      201           {object}  models.Order
+// @Failure      400           {object}  map[string]interface{}
+// @Failure      401           {object}  map[string]interface{}
+// @Failure      409           {object}  map[string]interface{}
+// @Failure      422           {object}  map[string]interface{}
+// @Failure      500           {object}  map[string]interface{}
+// @Router       /guest/orders [post]
+func CreateGuestOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CreateGuestOrder called")
+
+	cartId, err := guesttoken.Verify(guesttoken.KindCart, context.GetHeader(guesttoken.CartHeader))
+	if err != nil {
+		l.Warn("invalid cart token", "error", err)
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "valid cart token required.", "error": err.Error()})
+		return
+	}
+
+	var req CreateGuestOrderRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	// Get cart items to check stock before creating order
+	cartItems, err := models.GetCartItemsForGuestCart(context.Request.Context(), cartId)
+	if err != nil {
+		l.Error("failed to get cart items", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart items.", "error": err.Error()})
+		return
+	}
+
+	if len(cartItems) == 0 {
+		l.Warn("empty cart", "cart_id", cartId)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "cannot create order from empty cart."})
+		return
+	}
+
+	// Revalidate prices and availability; changes must be acknowledged first
+	if !ensureCartUnchanged(context, cartItems[0].CartID) {
+		return
+	}
+
+	if !ensureStock(context, cartItems) {
+		return
+	}
+
+	shippingAddress := req.ShippingAddress.toAddress()
+	order, err := models.CreateFromGuestCart(context.Request.Context(), cartId, strings.TrimSpace(req.Email), shippingAddress, req.BillingAddress.toAddress(), *req.ShippingMethodID)
+	if respondShippingError(context, err, *req.ShippingMethodID, shippingAddress.Country) || respondCouponError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to create guest order", "cart_id", cartId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create order.", "error": err.Error()})
+		return
+	}
+
+	order.Token = guesttoken.Sign(guesttoken.KindOrder, order.ID)
+	context.Header(guesttoken.OrderHeader, order.Token)
+
+	l.Info("created guest order", "cart_id", cartId, "order_id", order.ID, "shipping_cents", order.ShippingCents, "discount_cents", order.DiscountCents, "total_cents", order.TotalCents)
+	metrics.OrdersCreated.WithLabelValues("guest").Inc()
+	context.JSON(http.StatusCreated, order)
+}
+
+// GetGuestOrder godoc
+// @Summary      Get guest order
+// @Description  Get a guest order using the order token returned at guest checkout
+// @Tags         Guest Checkout
+// @Accept       json
+// @Produce      json
+// @Param        X-Order-Token  header    string  true  "Guest order token"
+// @Param        id             path      int     true  "Order ID"
+// @Success      200            {object}  models.Order
+// @Failure      400            {object}  map[string]interface{}
+// @Failure      401            {object}  map[string]interface{}
+// @Failure      404            {object}  map[string]interface{}
+// @Router       /guest/orders/{id} [get]
+func GetGuestOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	orderIdStr := context.Param("id")
+
+	orderId, err := strconv.ParseInt(orderIdStr, 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", orderIdStr, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("GetGuestOrder called", "order_id", orderId)
+
+	tokenOrderId, err := guesttoken.Verify(guesttoken.KindOrder, context.GetHeader(guesttoken.OrderHeader))
+	if err != nil || tokenOrderId != orderId {
+		l.Warn("invalid order token", "order_id", orderId, "error", err)
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "valid order token required."})
+		return
+	}
+
+	order, err := models.GetGuestOrderByID(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to get guest order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return
+	}
+
+	l.Info("fetched guest order", "order_id", orderId)
+	context.JSON(http.StatusOK, order)
+}
+
+// InternalGetOrder godoc
+// @Summary      Internal order lookup
+// @Description  Get an order without user validation (for service-to-service calls, e.g. guest payments)
+// @Tags         Internal
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/orders/{id} [get]
+func InternalGetOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	orderIdStr := context.Param("id")
+
+	orderId, err := strconv.ParseInt(orderIdStr, 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", orderIdStr, "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("InternalGetOrder called", "order_id", orderId)
+
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to get order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return
+	}
+
+	context.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"rearatrox/go-ecommerce-backend/pkg/invoice"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// issueInvoice issues the invoice of a confirmed order. Failures are logged only: the invoice is issued on first
+// download if it is still missing.
+func issueInvoice(ctx context.Context, order *models.Order) {
+	l := logger.FromContext(ctx)
+
+	cfg, err := invoice.ConfigFromEnv()
+	if err != nil {
+		l.Error("invalid invoice configuration", "error", err)
+		return
+	}
+	inv, err := order.IssueInvoice(ctx, cfg)
+	if err != nil {
+		l.Error("failed to issue invoice", "order_id", order.ID, "error", err)
+		return
+	}
+	l.Info("issued invoice", "order_id", order.ID, "number", inv.Number, "total_cents", inv.TotalCents)
+}
+
+// issueCreditNote issues the credit note for a refund. Failures are logged only, the refund itself succeeded.
+func issueCreditNote(context *gin.Context, order *models.Order, refundId int64, lines []invoice.Line, shippingCents, discountCents int) {
+	l := logger.FromContext(context.Request.Context())
+
+	cfg, err := invoice.ConfigFromEnv()
+	if err != nil {
+		l.Error("invalid invoice configuration", "error", err)
+		return
+	}
+	note, err := order.IssueCreditNote(context.Request.Context(), cfg, refundId, lines, shippingCents, discountCents)
+	if err != nil {
+		l.Error("failed to issue credit note", "order_id", order.ID, "refund_id", refundId, "error", err)
+		return
+	}
+	l.Info("issued credit note", "order_id", order.ID, "refund_id", refundId, "number", note.Number, "total_cents", note.TotalCents)
+}
+
+// issueReturnCreditNote issues the credit note for a refunded return: the accepted items less their discount share
+func issueReturnCreditNote(context *gin.Context, r *models.Return, refundId int64) {
+	l := logger.FromContext(context.Request.Context())
+
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), r.OrderID)
+	if err != nil {
+		l.Error("failed to get order for credit note", "order_id", r.OrderID, "return_id", r.ID, "error", err)
+		return
+	}
+
+	var lines []invoice.Line
+	subtotal := 0
+	for _, item := range r.Items {
+		if item.AcceptedQuantity == nil || *item.AcceptedQuantity == 0 {
+			continue
+		}
+		line := invoice.Line{Description: item.ProductName, Quantity: *item.AcceptedQuantity, UnitCents: item.PriceCents}
+		lines = append(lines, line)
+		subtotal += line.TotalCents()
+	}
+	issueCreditNote(context, order, refundId, lines, 0, max(subtotal-r.RefundCents, 0))
+}
+
+// serveInvoice answers with the PDF or, with format=xml, the e-invoice XML of an invoice or credit note
+func serveInvoice(context *gin.Context, inv *models.Invoice) {
+	switch format := context.DefaultQuery("format", "pdf"); format {
+	case "pdf":
+		context.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, inv.Number))
+		context.Data(http.StatusOK, "application/pdf", inv.PDF)
+	case "xml":
+		if inv.XML == nil {
+			context.JSON(http.StatusNotFound, gin.H{"message": "no e-invoice available for this document."})
+			return
+		}
+		context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xml"`, inv.Number))
+		context.Data(http.StatusOK, "application/xml", inv.XML)
+	default:
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid format, use pdf or xml."})
+	}
+}
+
+// respondOrderInvoice answers with the invoice of an order, issuing it if the order is paid but has none yet
+func respondOrderInvoice(context *gin.Context, order *models.Order) {
+	l := logger.FromContext(context.Request.Context())
+
+	cfg, err := invoice.ConfigFromEnv()
+	if err != nil {
+		l.Error("invalid invoice configuration", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not issue invoice.", "error": err.Error()})
+		return
+	}
+
+	inv, err := order.IssueInvoice(context.Request.Context(), cfg)
+	if errors.Is(err, models.ErrOrderNotInvoiceable) {
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error(), "status": order.Status})
+		return
+	}
+	if err != nil {
+		l.Error("failed to issue invoice", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not issue invoice.", "error": err.Error()})
+		return
+	}
+	serveInvoice(context, inv)
+}
+
+// respondInvoiceDocument answers with an invoice or credit note of an order by its :number path parameter
+func respondInvoiceDocument(context *gin.Context, orderId int64) {
+	l := logger.FromContext(context.Request.Context())
+
+	inv, err := models.GetInvoiceDocument(context.Request.Context(), orderId, context.Param("number"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "invoice not found."})
+			return
+		}
+		l.Error("failed to get invoice", "order_id", orderId, "number", context.Param("number"), "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch invoice.", "error": err.Error()})
+		return
+	}
+	serveInvoice(context, inv)
+}
+
+// getUserOrderParam loads the authenticated user's order from the :id path parameter. Returns false if a response
+// was written.
+func getUserOrderParam(context *gin.Context) (*models.Order, bool) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return nil, false
+	}
+
+	order, err := models.GetOrderByID(context.Request.Context(), orderId, userId)
+	if err != nil {
+		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return nil, false
+	}
+	return order, true
+}
+
+// GetOrderInvoice godoc
+// @Summary      Download the invoice of an order
+// @Description  Download the invoice of an own paid order as PDF or, with format=xml, as EN 16931 e-invoice (ZUGFeRD/XRechnung CII). The invoice is issued when the order is confirmed; a missing one is issued on first download.
+// @Tags         Invoices
+// @Produce      application/pdf
+// @Produce      application/xml
+// @Param        id      path      int     true   "Order ID"
+// @Param        format  query     string  false  "Document format"  Enums(pdf, xml)  default(pdf)
+// @Success      200     {file}    file
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      409     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /orders/{id}/invoice [get]
+func GetOrderInvoice(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetOrderInvoice called", "user_id", context.GetInt64("userId"), "order_id", context.Param("id"))
+
+	order, ok := getUserOrderParam(context)
+	if !ok {
+		return
+	}
+	respondOrderInvoice(context, order)
+}
+
+// ListOrderInvoices godoc
+// @Summary      List invoices of an order
+// @Description  List the invoice and credit notes of an own order
+// @Tags         Invoices
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Invoice
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /orders/{id}/invoices [get]
+func ListOrderInvoices(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("ListOrderInvoices called", "user_id", context.GetInt64("userId"), "order_id", context.Param("id"))
+
+	order, ok := getUserOrderParam(context)
+	if !ok {
+		return
+	}
+
+	invoices, err := models.GetOrderInvoices(context.Request.Context(), order.ID)
+	if err != nil {
+		l.Error("failed to list invoices", "order_id", order.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch invoices.", "error": err.Error()})
+		return
+	}
+	context.JSON(http.StatusOK, invoices)
+}
+
+// GetOrderInvoiceDocument godoc
+// @Summary      Download an invoice or credit note
+// @Description  Download an invoice or credit note of an own order by number as PDF or, with format=xml, as e-invoice
+// @Tags         Invoices
+// @Produce      application/pdf
+// @Produce      application/xml
+// @Param        id      path      int     true   "Order ID"
+// @Param        number  path      string  true   "Invoice or credit note number"
+// @Param        format  query     string  false  "Document format"  Enums(pdf, xml)  default(pdf)
+// @Success      200     {file}    file
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /orders/{id}/invoices/{number} [get]
+func GetOrderInvoiceDocument(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetOrderInvoiceDocument called", "user_id", context.GetInt64("userId"), "order_id", context.Param("id"), "number", context.Param("number"))
+
+	order, ok := getUserOrderParam(context)
+	if !ok {
+		return
+	}
+	respondInvoiceDocument(context, order.ID)
+}
+
+// AdminGetOrderInvoice godoc
+// @Summary      Download the invoice of any order
+// @Description  Download the invoice of a paid order as PDF or, with format=xml, as e-invoice; a missing invoice is issued (admin only). Invoices and credit notes of an order are listed in the admin order view.
+// @Tags         Invoices (Admin)
+// @Produce      application/pdf
+// @Produce      application/xml
+// @Param        id      path      int     true   "Order ID"
+// @Param        format  query     string  false  "Document format"  Enums(pdf, xml)  default(pdf)
+// @Success      200     {file}    file
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      409     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/invoice [get]
+func AdminGetOrderInvoice(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("AdminGetOrderInvoice called", "order_id", context.Param("id"))
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+	respondOrderInvoice(context, order)
+}
+
+// AdminGetOrderInvoiceDocument godoc
+// @Summary      Download an invoice or credit note of any order
+// @Description  Download an invoice or credit note by number as PDF or, with format=xml, as e-invoice (admin only)
+// @Tags         Invoices (Admin)
+// @Produce      application/pdf
+// @Produce      application/xml
+// @Param        id      path      int     true   "Order ID"
+// @Param        number  path      string  true   "Invoice or credit note number"
+// @Param        format  query     string  false  "Document format"  Enums(pdf, xml)  default(pdf)
+// @Success      200     {file}    file
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/invoices/{number} [get]
+func AdminGetOrderInvoiceDocument(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("AdminGetOrderInvoiceDocument called", "order_id", context.Param("id"), "number", context.Param("number"))
+
+	order, ok := getAdminOrderParam(context)
+	if !ok {
+		return
+	}
+	respondInvoiceDocument(context, order.ID)
+}
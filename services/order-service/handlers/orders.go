@@ -1,29 +1,40 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
 	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	usermodels "rearatrox/go-ecommerce-backend/services/user-service/models"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CreateOrderRequest struct {
-	ShippingAddressID *int64 `json:"shippingAddressId" example:"1"`
+	ShippingAddressID *int64 `json:"shippingAddressId" binding:"required" example:"1"` // Required: the shipping cost depends on the destination. Orders without it are rejected with 400 (it was optional before shipping costs)
 	BillingAddressID  *int64 `json:"billingAddressId" example:"2"`
+	ShippingMethodID  *int64 `json:"shippingMethodId" binding:"required" example:"1"`
 }
 
 // CreateOrder godoc
 // @Summary      Create order from cart
-// @Description  Creates a new order from the user's active cart and marks cart as ordered
+// @Description  Creates a new order from the user's active cart including the shipping cost of the chosen method and the cart coupon, and marks cart as ordered. The shipping address is required, the cost depends on its country. Fails with 409 and per-line warnings if prices or availability changed and were not acknowledged yet
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CreateOrderRequest  true  "Address IDs (optional)"
+// @Param        Idempotency-Key  header    string              false  "Retries with the same key replay the first response"
+// @Param        request  body      CreateOrderRequest  true  "Shipping address and method (required), billing address (optional)"
 // @Success      201      {object}  models.Order
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      401      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /orders [post]
@@ -39,25 +50,20 @@ func CreateOrder(context *gin.Context) {
 		return
 	}
 
-	// Get JWT token for service-to-service calls
-	jwtToken := context.GetHeader("Authorization")
-	if jwtToken == "" {
-		l.Error("missing authorization token", "user_id", userId)
-		context.JSON(http.StatusUnauthorized, gin.H{"message": "authorization required."})
-		return
+	// Verify address ownership
+	shippingAddress, err := verifyAddressOwnership(context.Request.Context(), *req.ShippingAddressID, userId)
+	if err == nil && shippingAddress == nil {
+		err = errors.New("address not found")
 	}
-
-	// Verify address ownership if addresses are provided
-	if req.ShippingAddressID != nil {
-		if err := verifyAddressOwnership(*req.ShippingAddressID, userId, jwtToken); err != nil {
-			l.Warn("invalid shipping address", "user_id", userId, "address_id", *req.ShippingAddressID, "error", err)
-			context.JSON(http.StatusForbidden, gin.H{"message": "invalid shipping address.", "error": err.Error()})
-			return
-		}
+	if err != nil {
+		l.Warn("invalid shipping address", "user_id", userId, "address_id", *req.ShippingAddressID, "error", err)
+		context.JSON(http.StatusForbidden, gin.H{"message": "invalid shipping address.", "error": err.Error()})
+		return
 	}
 
+	var billingAddress *usermodels.Address
 	if req.BillingAddressID != nil {
-		if err := verifyAddressOwnership(*req.BillingAddressID, userId, jwtToken); err != nil {
+		if billingAddress, err = verifyAddressOwnership(context.Request.Context(), *req.BillingAddressID, userId); err != nil {
 			l.Warn("invalid billing address", "user_id", userId, "address_id", *req.BillingAddressID, "error", err)
 			context.JSON(http.StatusForbidden, gin.H{"message": "invalid billing address.", "error": err.Error()})
 			return
@@ -65,7 +71,7 @@ func CreateOrder(context *gin.Context) {
 	}
 
 	// Get cart items to check stock before creating order
-	cartItems, err := models.GetCartItemsForUser(userId)
+	cartItems, err := models.GetCartItemsForUser(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("failed to get cart items", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch cart items.", "error": err.Error()})
@@ -78,43 +84,36 @@ func CreateOrder(context *gin.Context) {
 		return
 	}
 
-	// Check stock availability for all items
-	for _, item := range cartItems {
-		stockResp, err := checkStockAvailability(item.ProductID, item.Quantity)
-		if err != nil {
-			l.Error("failed to check stock", "product_id", item.ProductID, "error", err)
-			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock availability.", "error": err.Error()})
-			return
-		}
+	// Revalidate prices and availability; changes must be acknowledged first
+	if !ensureCartUnchanged(context, cartItems[0].CartID) {
+		return
+	}
 
-		if !stockResp.Available {
-			l.Warn("insufficient stock for order", "product_id", item.ProductID, "requested", item.Quantity, "available", stockResp.AvailableQty)
-			context.JSON(http.StatusConflict, gin.H{
-				"message":     "insufficient stock",
-				"productId":   item.ProductID,
-				"productName": item.ProductName,
-				"requested":   item.Quantity,
-				"available":   stockResp.AvailableQty,
-			})
-			return
-		}
+	// Check stock availability for all items
+	if !ensureStock(context, cartItems) {
+		return
 	}
 
-	// Create order from active cart
-	order, err := models.CreateFromCart(userId, req.ShippingAddressID, req.BillingAddressID)
+	// Create order from active cart, quoting the chosen shipping method for it
+	order, err := models.CreateFromCart(context.Request.Context(), userId, req.ShippingAddressID, req.BillingAddressID,
+		addressSnapshot(shippingAddress), addressSnapshot(billingAddress), *req.ShippingMethodID)
+	if respondShippingError(context, err, *req.ShippingMethodID, shippingAddress.Country) || respondCouponError(context, err) {
+		return
+	}
 	if err != nil {
 		l.Error("failed to create order", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create order.", "error": err.Error()})
 		return
 	}
 
-	l.Info("created order", "user_id", userId, "order_id", order.ID, "total_cents", order.TotalCents)
+	l.Info("created order", "user_id", userId, "order_id", order.ID, "shipping_cents", order.ShippingCents, "discount_cents", order.DiscountCents, "total_cents", order.TotalCents)
+	metrics.OrdersCreated.WithLabelValues("user").Inc()
 	context.JSON(http.StatusCreated, order)
 }
 
 // GetOrder godoc
 // @Summary      Get order by ID
-// @Description  Get details of a specific order including items and addresses
+// @Description  Get details of a specific order including items, addresses and shipments with carrier and tracking link
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
@@ -140,7 +139,7 @@ func GetOrder(context *gin.Context) {
 
 	l.Debug("GetOrder called", "user_id", userId, "order_id", orderId)
 
-	order, err := models.GetOrderByID(orderId, userId)
+	order, err := models.GetOrderByID(context.Request.Context(), orderId, userId)
 	if err != nil {
 		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
@@ -151,107 +150,116 @@ func GetOrder(context *gin.Context) {
 	context.JSON(http.StatusOK, order)
 }
 
+// parseOrderHistory reads the order history filters and page from the query string
+func parseOrderHistory(context *gin.Context) (models.OrderHistoryFilter, error) {
+	f := models.OrderHistoryFilter{Status: context.Query("status")}
+
+	var err error
+	if f.Limit, err = pagination.ParseLimit(context.Query("limit")); err != nil {
+		return f, err
+	}
+	if f.Cursor, err = pagination.DecodeCursor(context.Query("cursor")); err != nil {
+		return f, err
+	}
+	if v := context.Query("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %w", err)
+		}
+		f.From = &from
+	}
+	if v := context.Query("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = to.AddDate(0, 0, 1)
+		f.To = &to
+	}
+	return f, nil
+}
+
 // ListOrders godoc
 // @Summary      List user's orders
-// @Description  Get all orders for the authenticated user
+// @Description  Get the authenticated user's orders, newest first, one page at a time. Pass nextCursor of a page as cursor to fetch the next one.
 // @Tags         Orders
-// @Accept       json
 // @Produce      json
-// @Success      200  {array}   models.Order
-// @Failure      401  {object}  map[string]interface{}
-// @Failure      500  {object}  map[string]interface{}
+// @Param        status  query     string  false  "Order status"
+// @Param        from    query     string  false  "Created on or after (YYYY-MM-DD)"
+// @Param        to      query     string  false  "Created on or before (YYYY-MM-DD)"
+// @Param        limit   query     int     false  "Page size (default 20, max 100)"
+// @Param        cursor  query     string  false  "Cursor from the previous page"
+// @Success      200     {object}  models.OrderHistory
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /orders [get]
 func ListOrders(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	userId := context.GetInt64("userId")
-	l.Debug("ListOrders called", "user_id", userId)
 
-	orders, err := models.GetUserOrders(userId)
+	filter, err := parseOrderHistory(context)
+	if err != nil {
+		l.Error("invalid order history query", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid query.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("ListOrders called", "user_id", userId, "status", filter.Status, "limit", filter.Limit, "cursor", context.Query("cursor"))
+
+	history, err := models.GetUserOrders(context.Request.Context(), userId, filter)
 	if err != nil {
 		l.Error("failed to get orders", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch orders.", "error": err.Error()})
 		return
 	}
 
-	l.Info("fetched orders", "user_id", userId, "count", len(orders))
-	context.JSON(http.StatusOK, orders)
-}
-
-type UpdateStatusRequest struct {
-	Status string `json:"status" example:"confirmed" binding:"required"`
+	l.Info("fetched orders", "user_id", userId, "count", len(history.Orders))
+	context.JSON(http.StatusOK, history)
 }
 
 // UpdateOrderStatus godoc
 // @Summary      Update order status
-// @Description  Update the status of an order (for future payment integration)
+// @Description  Update the status of an order. Customers can only cancel pending or confirmed orders; orders are confirmed by their payment and follow their shipments afterwards.
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
-// @Param        id       path      int                  true  "Order ID"
-// @Param        request  body      UpdateStatusRequest  true  "New status"
+// @Param        id       path      int                           true  "Order ID"
+// @Param        request  body      models.CustomerStatusRequest  true  "New status"
 // @Success      200      {object}  models.Order
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      401      {object}  map[string]interface{}
 // @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /orders/{id}/status [patch]
 func UpdateOrderStatus(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	userId := context.GetInt64("userId")
-	orderIdStr := context.Param("id")
-
-	orderId, err := strconv.ParseInt(orderIdStr, 10, 64)
-	if err != nil {
-		l.Error("invalid order ID", "order_id", orderIdStr, "error", err)
-		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
-		return
-	}
 
-	var req UpdateStatusRequest
+	var req models.CustomerStatusRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		l.Error("failed to bind request", "error", err)
 		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
 		return
 	}
 
-	l.Debug("UpdateOrderStatus called", "user_id", userId, "order_id", orderId, "new_status", req.Status)
-
-	// Get order
-	order, err := models.GetOrderByID(orderId, userId)
-	if err != nil {
-		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
-		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
-		return
-	}
-
-	// If status changes to 'confirmed', reduce stock
-	if req.Status == "confirmed" && order.Status != "confirmed" {
-		l.Debug("order confirmed, reducing stock", "order_id", orderId)
-
-		if err := reduceStockForOrder(order.Items); err != nil {
-			l.Error("failed to reduce stock", "order_id", orderId, "error", err)
-			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reduce stock.", "error": err.Error()})
-			return
-		}
-		l.Info("stock reduced", "order_id", orderId, "items_count", len(order.Items))
-	}
+	l.Debug("UpdateOrderStatus called", "user_id", userId, "order_id", context.Param("id"), "new_status", req.Status)
 
-	// Update status
-	if err := order.UpdateStatus(req.Status); err != nil {
-		l.Error("failed to update order status", "user_id", userId, "order_id", orderId, "error", err)
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update order status.", "error": err.Error()})
+	order, ok := getOrderParam(context)
+	if !ok {
 		return
 	}
 
-	l.Info("updated order status", "user_id", userId, "order_id", orderId, "new_status", req.Status)
-	context.JSON(http.StatusOK, order)
+	// cancelled is the only status customers may set
+	respondCancelOrder(context, order)
 }
 
 // CancelOrder godoc
 // @Summary      Cancel an order
-// @Description  Cancel an order (only possible for pending orders)
+// @Description  Cancel an order (only possible for pending or confirmed orders). Stock of a confirmed order is restored, the open payment of a pending order is cancelled.
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
@@ -265,59 +273,76 @@ func UpdateOrderStatus(context *gin.Context) {
 // @Security     BearerAuth
 // @Router       /orders/{id}/cancel [patch]
 func CancelOrder(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CancelOrder called", "user_id", context.GetInt64("userId"), "order_id", context.Param("id"))
+
+	order, ok := getOrderParam(context)
+	if !ok {
+		return
+	}
+
+	respondCancelOrder(context, order)
+}
+
+// getOrderParam loads the authenticated user's order from the :id path parameter. Returns false if a response was
+// written.
+func getOrderParam(context *gin.Context) (*models.Order, bool) {
 	l := logger.FromContext(context.Request.Context())
 	userId := context.GetInt64("userId")
-	orderIdStr := context.Param("id")
 
-	orderId, err := strconv.ParseInt(orderIdStr, 10, 64)
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 	if err != nil {
-		l.Error("invalid order ID", "order_id", orderIdStr, "error", err)
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
 		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
-		return
+		return nil, false
 	}
 
-	l.Debug("CancelOrder called", "user_id", userId, "order_id", orderId)
-
-	// Get order
-	order, err := models.GetOrderByID(orderId, userId)
+	order, err := models.GetOrderByID(context.Request.Context(), orderId, userId)
 	if err != nil {
 		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
-		return
+		return nil, false
 	}
+	return order, true
+}
+
+// respondCancelOrder cancels a customer's order and sends the result
+func respondCancelOrder(context *gin.Context, order *models.Order) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
 
 	// Only allow cancellation of pending or confirmed orders (not shipped/delivered)
-	if order.Status != "pending" && order.Status != "confirmed" {
-		l.Warn("cannot cancel order in current state", "order_id", orderId, "status", order.Status)
+	err := cancelOrder(context.Request.Context(), order)
+	if errors.Is(err, models.ErrOrderNotCancellable) {
+		l.Warn("cannot cancel order in current state", "order_id", order.ID, "status", order.Status)
 		context.JSON(http.StatusConflict, gin.H{
 			"message": "order cannot be cancelled in current state",
 			"status":  order.Status,
 		})
 		return
 	}
-
-	// Update status to cancelled
-	if err := order.UpdateStatus("cancelled"); err != nil {
-		l.Error("failed to cancel order", "user_id", userId, "order_id", orderId, "error", err)
+	if err != nil {
+		l.Error("failed to cancel order", "user_id", userId, "order_id", order.ID, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not cancel order.", "error": err.Error()})
 		return
 	}
 
-	l.Info("cancelled order", "user_id", userId, "order_id", orderId)
+	l.Info("cancelled order", "user_id", userId, "order_id", order.ID)
 	context.JSON(http.StatusOK, order)
 }
 
 // InternalUpdateOrderStatus godoc
 // @Summary      Internal order status update
-// @Description  Updates order status without authentication (for service-to-service calls from payment-service)
+// @Description  Updates order status without authentication (for service-to-service calls from payment-service). Confirming is only possible for pending orders, cancelling only for pending or confirmed orders; repeated confirmations and cancellations are ignored.
 // @Tags         Internal
 // @Accept       json
 // @Produce      json
 // @Param        id       path      int                  true  "Order ID"
-// @Param        request  body      UpdateStatusRequest  true  "New status"
+// @Param        request  body      models.UpdateStatusRequest  true  "New status"
 // @Success      200      {object}  models.Order
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Router       /internal/orders/{id}/status [patch]
 func InternalUpdateOrderStatus(context *gin.Context) {
@@ -331,7 +356,7 @@ func InternalUpdateOrderStatus(context *gin.Context) {
 		return
 	}
 
-	var req UpdateStatusRequest
+	var req models.UpdateStatusRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		l.Error("failed to bind request", "error", err)
 		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
@@ -341,32 +366,129 @@ func InternalUpdateOrderStatus(context *gin.Context) {
 	l.Debug("InternalUpdateOrderStatus called", "order_id", orderId, "new_status", req.Status)
 
 	// Get order without user validation (internal call)
-	order, err := models.GetOrderByIDInternal(orderId)
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), orderId)
 	if err != nil {
 		l.Error("failed to get order", "order_id", orderId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
 		return
 	}
 
-	// If status changes to 'confirmed', reduce stock
-	if req.Status == "confirmed" && order.Status != "confirmed" {
-		l.Debug("order confirmed, reducing stock (internal)", "order_id", orderId)
-
-		if err := reduceStockForOrder(order.Items); err != nil {
+	if err := ApplyInternalStatus(context.Request.Context(), order, req.Status); err != nil {
+		switch {
+		case errors.Is(err, models.ErrOrderNotCancellable):
+			l.Warn("cannot cancel order in current state (internal)", "order_id", orderId, "status", order.Status)
+			context.JSON(http.StatusConflict, gin.H{"message": "order cannot be cancelled in current state", "status": order.Status})
+		case errors.Is(err, models.ErrOrderNotConfirmable):
+			l.Warn("cannot confirm order in current state (internal)", "order_id", orderId, "status", order.Status)
+			context.JSON(http.StatusConflict, gin.H{"message": "only pending orders can be confirmed", "status": order.Status})
+		case errors.Is(err, models.ErrStockNotReserved):
 			l.Error("failed to reduce stock", "order_id", orderId, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not reduce stock.", "error": err.Error()})
-			return
+		default:
+			l.Error("failed to update order status", "order_id", orderId, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update order status.", "error": err.Error()})
 		}
-		l.Info("stock reduced (internal)", "order_id", orderId, "items_count", len(order.Items))
-	}
-
-	// Update status
-	if err := order.UpdateStatus(req.Status); err != nil {
-		l.Error("failed to update order status", "order_id", orderId, "error", err)
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update order status.", "error": err.Error()})
 		return
 	}
 
 	l.Info("updated order status (internal)", "order_id", orderId, "new_status", req.Status)
 	context.JSON(http.StatusOK, order)
 }
+
+// ApplyInternalStatus changes the status of an order on behalf of another service: confirming takes the items out
+// of stock and issues the invoice, cancelling a confirmed order puts them back. Repeated confirmations and
+// cancellations are ignored. Only pending orders can be confirmed (models.ErrOrderNotConfirmable), e.g. a payment that
+// succeeds after its order was cancelled; orders that are neither pending nor confirmed cannot be cancelled
+// (models.ErrOrderNotCancellable).
+// used in: InternalUpdateOrderStatus, grpcapi.Server.UpdateOrderStatus
+func ApplyInternalStatus(ctx context.Context, order *models.Order, status string) error {
+	l := logger.FromContext(ctx)
+
+	// Confirmation of an order whose payment succeeded
+	if status == "confirmed" {
+		if order.Status == "confirmed" {
+			l.Debug("order already confirmed (internal)", "order_id", order.ID)
+			return nil
+		}
+
+		err := order.Confirm(ctx, func() error {
+			l.Debug("order confirmed, reducing stock (internal)", "order_id", order.ID)
+			if err := reduceStockForOrder(ctx, order); err != nil {
+				return fmt.Errorf("%w: %w", models.ErrStockNotReserved, err)
+			}
+			l.Info("stock reduced (internal)", "order_id", order.ID, "items_count", len(order.Items))
+			return nil
+		}, func() {
+			l.Info("order cancelled while reducing stock, restoring it (internal)", "order_id", order.ID)
+			restoreStockForOrder(ctx, order)
+		})
+		if errors.Is(err, models.ErrOrderNotConfirmable) && order.Status == "confirmed" {
+			// confirmed concurrently, e.g. by the webhook and the reconciliation
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		issueInvoice(ctx, order)
+		return nil
+	}
+
+	// Cancellation of an order whose payment was cancelled or expired
+	if status == "cancelled" {
+		if order.Status == "cancelled" {
+			l.Debug("order already cancelled (internal)", "order_id", order.ID)
+			return nil
+		}
+		err := cancelOrder(ctx, order)
+		if errors.Is(err, models.ErrOrderNotCancellable) && order.Status == "cancelled" {
+			// cancelled concurrently, e.g. by the customer
+			return nil
+		}
+		return err
+	}
+
+	return order.UpdateStatus(ctx, status)
+}
+
+// cancelOrder cancels a pending or confirmed order for every cancel route: the status change and the release of
+// its coupon happen under a row lock, then the stock taken at confirmation is put back and the open payment intent
+// of a pending order is cancelled in payment-service, so it cannot be paid anymore. Failures of these follow-ups are
+// logged only, the order is cancelled either way. Returns models.ErrOrderNotCancellable for any other status,
+// including cancelled.
+// used in: respondCancelOrder, ApplyInternalStatus, ExpireOrder
+func cancelOrder(ctx context.Context, order *models.Order) error {
+	_, err := cancelOrderFrom(ctx, order, false)
+	return err
+}
+
+// ExpireOrder cancels a pending order that was left unpaid, like cancelOrder. An order confirmed in the meantime is
+// kept and models.ErrOrderNotCancellable returned.
+// used in: jobs.StaleOrderJob
+func ExpireOrder(ctx context.Context, order *models.Order) error {
+	_, err := cancelOrderFrom(ctx, order, true)
+	return err
+}
+
+// cancelOrderFrom implements cancelOrder and ExpireOrder and returns the status the order had before
+// used in: cancelOrder, ExpireOrder, AdminCancelOrder
+func cancelOrderFrom(ctx context.Context, order *models.Order, pendingOnly bool) (previous string, err error) {
+	l := logger.FromContext(ctx)
+
+	previous, err = order.Cancel(ctx, pendingOnly)
+	if err != nil {
+		return previous, err
+	}
+
+	switch previous {
+	case "confirmed":
+		// stock is reduced on confirmation, pending orders hold none
+		restoreStockForOrder(ctx, order)
+	case "pending":
+		// a payment succeeding anyway is refunded by payment-service, as the order cannot be confirmed anymore
+		if err := paymentService.CancelOrderPayment(ctx, order.ID); err != nil {
+			l.Warn("failed to cancel payment of cancelled order", "order_id", order.ID, "error", err)
+		}
+	}
+	return previous, nil
+}
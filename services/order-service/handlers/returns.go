@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/returns"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// respondReturnError writes 422 for invalid return requests or inspections and 409 for status conflicts.
+// Returns true if a response was written.
+func respondReturnError(context *gin.Context, err error) bool {
+	l := logger.FromContext(context.Request.Context())
+
+	var returnErr *returns.ReturnError
+	if errors.As(err, &returnErr) {
+		l.Warn("invalid return", "reason", returnErr.Reason)
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid return.", "reason": returnErr.Reason})
+		return true
+	}
+	if errors.Is(err, models.ErrOrderNotReturnable) || errors.Is(err, models.ErrReturnStatus) {
+		l.Warn("return status conflict", "error", err)
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return true
+	}
+	return false
+}
+
+// getReturnParam loads the return from the :id path parameter. Returns false if a response was written.
+func getReturnParam(context *gin.Context) (*models.Return, bool) {
+	l := logger.FromContext(context.Request.Context())
+
+	returnId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid return ID", "return_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid return ID."})
+		return nil, false
+	}
+
+	r, err := models.GetReturnByID(context.Request.Context(), returnId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "return not found."})
+			return nil, false
+		}
+		l.Error("failed to get return", "return_id", returnId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch return.", "error": err.Error()})
+		return nil, false
+	}
+	return r, true
+}
+
+// restockReturn puts accepted items back into stock via product-service. Failed items stay pending and are
+// retried with the next refund attempt.
+func restockReturn(context *gin.Context, r *models.Return) {
+	l := logger.FromContext(context.Request.Context())
+
+	for _, item := range r.PendingRestocks() {
+		if err := productService.Restock(context.Request.Context(), item.ProductID, *item.AcceptedQuantity); err != nil {
+			l.Warn("failed to restock returned item", "return_id", r.ID, "product_id", item.ProductID, "error", err)
+			continue
+		}
+		if err := r.MarkRestocked(context.Request.Context(), item.ID); err != nil {
+			l.Error("failed to mark returned item as restocked", "return_id", r.ID, "item_id", item.ID, "error", err)
+			continue
+		}
+		l.Info("restocked returned item", "return_id", r.ID, "product_id", item.ProductID, "quantity", *item.AcceptedQuantity)
+	}
+}
+
+// completeReturn restocks the accepted items, refunds the return via payment-service and marks it refunded
+// (or closed if nothing was accepted). A failed refund keeps the return received and answers 502.
+func completeReturn(context *gin.Context, r *models.Return) {
+	l := logger.FromContext(context.Request.Context())
+	adminId := context.GetInt64("userId")
+
+	restockReturn(context, r)
+
+	var refundId *int64
+	if r.RefundCents > 0 {
+		refund, err := createRefund(context.Request.Context(), r.OrderID, &r.ID, r.RefundCents, fmt.Sprintf("return %d", r.ID))
+		if err != nil {
+			l.Error("failed to refund return", "return_id", r.ID, "order_id", r.OrderID, "refund_cents", r.RefundCents, "error", err)
+			context.JSON(http.StatusBadGateway, gin.H{"message": "return received, but the refund failed. retry via the refund endpoint.", "error": err.Error(), "return": r})
+			return
+		}
+		refundId = &refund.ID
+		l.Info("refunded return", "return_id", r.ID, "refund_id", refund.ID, "refund_status", refund.Status, "amount_cents", refund.AmountCents)
+	}
+
+	if err := r.Complete(context.Request.Context(), refundId, adminId); err != nil {
+		l.Error("failed to complete return", "return_id", r.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not complete return.", "error": err.Error()})
+		return
+	}
+
+	if refundId != nil {
+		issueReturnCreditNote(context, r, *refundId)
+	}
+
+	l.Info("completed return", "return_id", r.ID, "status", r.Status, "refund_cents", r.RefundCents)
+	context.JSON(http.StatusOK, r)
+}
+
+// CreateReturn godoc
+// @Summary      Request a return
+// @Description  Request a return of shipped order lines with a reason per line. Quantities cannot exceed what was shipped minus earlier returns that were not rejected.
+// @Tags         Returns
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "Order ID"
+// @Param        request  body      models.CreateReturnRequest  true  "Order lines, quantities and reasons"
+// @Success      201      {object}  models.Return
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /orders/{id}/returns [post]
+func CreateReturn(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	var req models.CreateReturnRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("CreateReturn called", "user_id", userId, "order_id", orderId, "items_count", len(req.Items))
+
+	order, err := models.GetOrderByID(context.Request.Context(), orderId, userId)
+	if err != nil {
+		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return
+	}
+
+	r, err := order.CreateReturn(context.Request.Context(), userId, req)
+	if respondReturnError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to create return", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create return.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created return", "user_id", userId, "order_id", orderId, "return_id", r.ID)
+	context.JSON(http.StatusCreated, r)
+}
+
+// ListOrderReturns godoc
+// @Summary      List returns of an order
+// @Description  Get all returns the authenticated user requested for an order
+// @Tags         Returns
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Return
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /orders/{id}/returns [get]
+func ListOrderReturns(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("ListOrderReturns called", "user_id", userId, "order_id", orderId)
+
+	if _, err := models.GetOrderByID(context.Request.Context(), orderId, userId); err != nil {
+		l.Error("failed to get order", "user_id", userId, "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return
+	}
+
+	list, err := models.GetOrderReturns(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to fetch returns", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch returns.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, list)
+}
+
+// GetReturn godoc
+// @Summary      Get a return
+// @Description  Get a return of the authenticated user including items, inspection results and status history
+// @Tags         Returns
+// @Produce      json
+// @Param        id   path      int  true  "Return ID"
+// @Success      200  {object}  models.Return
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /returns/{id} [get]
+func GetReturn(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+
+	returnId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid return ID", "return_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid return ID."})
+		return
+	}
+
+	l.Debug("GetReturn called", "user_id", userId, "return_id", returnId)
+
+	r, err := models.GetUserReturn(context.Request.Context(), returnId, userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "return not found."})
+			return
+		}
+		l.Error("failed to get return", "return_id", returnId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch return.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, r)
+}
+
+// AdminListReturns godoc
+// @Summary      List returns
+// @Description  Get all returns, oldest first, optionally filtered by status (admin only)
+// @Tags         Returns (Admin)
+// @Produce      json
+// @Param        status  query     string  false  "Filter by status"  Enums(requested, approved, rejected, received, refunded, closed)
+// @Success      200     {array}   models.Return
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns [get]
+func AdminListReturns(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	status := context.Query("status")
+	l.Debug("AdminListReturns called", "status", status)
+
+	list, err := models.GetReturns(context.Request.Context(), status)
+	if err != nil {
+		l.Error("failed to fetch returns", "status", status, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch returns.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, list)
+}
+
+// AdminGetReturn godoc
+// @Summary      Get a return
+// @Description  Get any return including items, inspection results and status history (admin only)
+// @Tags         Returns (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Return ID"
+// @Success      200  {object}  models.Return
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns/{id} [get]
+func AdminGetReturn(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("AdminGetReturn called", "return_id", context.Param("id"))
+
+	r, ok := getReturnParam(context)
+	if !ok {
+		return
+	}
+	context.JSON(http.StatusOK, r)
+}
+
+// ApproveReturn godoc
+// @Summary      Approve a return
+// @Description  Approve a requested return so the customer can send the items back (admin only). The note is shown to the customer.
+// @Tags         Returns (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true   "Return ID"
+// @Param        request  body      models.ReturnDecisionRequest  false  "Note for the customer"
+// @Success      200      {object}  models.Return
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns/{id}/approve [post]
+func ApproveReturn(context *gin.Context) {
+	setReturnStatus(context, returns.StatusApproved)
+}
+
+// RejectReturn godoc
+// @Summary      Reject a return
+// @Description  Reject a requested or approved return (admin only). The note is shown to the customer.
+// @Tags         Returns (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true   "Return ID"
+// @Param        request  body      models.ReturnDecisionRequest  false  "Rejection reason"
+// @Success      200      {object}  models.Return
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns/{id}/reject [post]
+func RejectReturn(context *gin.Context) {
+	setReturnStatus(context, returns.StatusRejected)
+}
+
+// setReturnStatus applies an admin decision (approved, rejected) to the return from the :id path parameter
+func setReturnStatus(context *gin.Context, status string) {
+	l := logger.FromContext(context.Request.Context())
+	adminId := context.GetInt64("userId")
+
+	var req models.ReturnDecisionRequest
+	if context.Request.ContentLength > 0 {
+		if err := context.ShouldBindJSON(&req); err != nil {
+			l.Error("failed to bind request", "error", err)
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+			return
+		}
+	}
+
+	l.Debug("setReturnStatus called", "return_id", context.Param("id"), "status", status, "admin_id", adminId)
+
+	r, ok := getReturnParam(context)
+	if !ok {
+		return
+	}
+
+	err := r.SetStatus(context.Request.Context(), status, req.Note, adminId)
+	if respondReturnError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to update return status", "return_id", r.ID, "status", status, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update return.", "error": err.Error()})
+		return
+	}
+
+	l.Info("updated return status", "return_id", r.ID, "status", r.Status, "admin_id", adminId)
+	context.JSON(http.StatusOK, r)
+}
+
+// ReceiveReturn godoc
+// @Summary      Receive and inspect a return
+// @Description  Record the inspection of the returned items of an approved return (admin only). Accepted items marked for restock go back into stock via product-service and the accepted quantities are refunded via payment-service (item price minus the order discount share, shipping is not refunded). Returns without accepted items are closed. If the refund fails the return stays received and the refund can be retried.
+// @Tags         Returns (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                       true   "Return ID"
+// @Param        request  body      models.InspectionRequest  false  "Inspection results"
+// @Success      200      {object}  models.Return
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Failure      502      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns/{id}/receive [post]
+func ReceiveReturn(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	adminId := context.GetInt64("userId")
+
+	var req models.InspectionRequest
+	if context.Request.ContentLength > 0 {
+		if err := context.ShouldBindJSON(&req); err != nil {
+			l.Error("failed to bind request", "error", err)
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+			return
+		}
+	}
+
+	l.Debug("ReceiveReturn called", "return_id", context.Param("id"), "admin_id", adminId, "items_count", len(req.Items))
+
+	r, ok := getReturnParam(context)
+	if !ok {
+		return
+	}
+
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), r.OrderID)
+	if err != nil {
+		l.Error("failed to get order", "order_id", r.OrderID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch order.", "error": err.Error()})
+		return
+	}
+
+	err = r.Receive(context.Request.Context(), order, req, adminId)
+	if respondReturnError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to receive return", "return_id", r.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not receive return.", "error": err.Error()})
+		return
+	}
+
+	l.Info("received return", "return_id", r.ID, "refund_cents", r.RefundCents, "admin_id", adminId)
+	completeReturn(context, r)
+}
+
+// RefundReturn godoc
+// @Summary      Retry refund of a return
+// @Description  Retry restocking and refunding a received return whose refund failed (admin only)
+// @Tags         Returns (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Return ID"
+// @Success      200  {object}  models.Return
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Failure      502  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/returns/{id}/refund [post]
+func RefundReturn(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("RefundReturn called", "return_id", context.Param("id"))
+
+	r, ok := getReturnParam(context)
+	if !ok {
+		return
+	}
+	if r.Status != returns.StatusReceived {
+		l.Warn("return not awaiting refund", "return_id", r.ID, "status", r.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": models.ErrReturnStatus.Error(), "status": r.Status})
+		return
+	}
+
+	completeReturn(context, r)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/fulfillment"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// respondShipmentError writes 422 for invalid shipments and 409 for orders that cannot be shipped.
+// Returns true if a response was written.
+func respondShipmentError(context *gin.Context, err error) bool {
+	l := logger.FromContext(context.Request.Context())
+
+	var shipmentErr *fulfillment.ShipmentError
+	if errors.As(err, &shipmentErr) {
+		l.Warn("invalid shipment", "reason", shipmentErr.Reason)
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid shipment.", "reason": shipmentErr.Reason})
+		return true
+	}
+	if errors.Is(err, models.ErrOrderNotShippable) {
+		l.Warn("order not shippable", "error", err)
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return true
+	}
+	return false
+}
+
+// GetCarriers godoc
+// @Summary      List carriers
+// @Description  Carriers available for shipments (admin only). Known carriers get a tracking link generated from the tracking number.
+// @Tags         Shipments (Admin)
+// @Produce      json
+// @Success      200  {array}   fulfillment.Carrier
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/carriers [get]
+func GetCarriers(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("GetCarriers called")
+
+	context.JSON(http.StatusOK, fulfillment.Carriers())
+}
+
+// GetOrderShipments godoc
+// @Summary      List shipments of an order
+// @Description  Get all shipments of an order with shipped items (admin only)
+// @Tags         Shipments (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Shipment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/shipments [get]
+func GetOrderShipments(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("GetOrderShipments called", "order_id", orderId)
+
+	shipments, err := models.GetOrderShipments(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to fetch shipments", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch shipments.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, shipments)
+}
+
+// CreateShipment godoc
+// @Summary      Create shipment
+// @Description  Record a parcel for a paid order with carrier, tracking number and shipped items (admin only). Partial and split shipments are supported; without items everything not shipped yet is included. The order status becomes partially_shipped or shipped.
+// @Tags         Shipments (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true  "Order ID"
+// @Param        request  body      models.CreateShipmentRequest  true  "Carrier, tracking and items"
+// @Success      201      {object}  models.Shipment
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/shipments [post]
+func CreateShipment(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	var req models.CreateShipmentRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("CreateShipment called", "order_id", orderId, "carrier", req.Carrier, "items_count", len(req.Items))
+
+	order, err := models.GetOrderByIDInternal(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to get order", "order_id", orderId, "error", err)
+		context.JSON(http.StatusNotFound, gin.H{"message": "order not found."})
+		return
+	}
+
+	shipment, err := order.CreateShipment(context.Request.Context(), req)
+	if respondShipmentError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to create shipment", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create shipment.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created shipment", "order_id", orderId, "shipment_id", shipment.ID, "carrier", shipment.Carrier, "order_status", order.Status)
+	context.JSON(http.StatusCreated, shipment)
+}
+
+// UpdateShipment godoc
+// @Summary      Update shipment
+// @Description  Update carrier status (shipped, in_transit, delivered) and tracking details of a shipment (admin only). The order becomes delivered once all items are shipped and every shipment is delivered.
+// @Tags         Shipments (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true  "Shipment ID"
+// @Param        request  body      models.UpdateShipmentRequest  true  "Status and tracking"
+// @Success      200      {object}  models.Shipment
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/shipments/{id} [patch]
+func UpdateShipment(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	shipmentId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid shipment ID", "shipment_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid shipment ID."})
+		return
+	}
+
+	var req models.UpdateShipmentRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("UpdateShipment called", "shipment_id", shipmentId, "status", req.Status)
+
+	shipment, err := models.GetShipmentByID(context.Request.Context(), shipmentId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "shipment not found."})
+			return
+		}
+		l.Error("failed to get shipment", "shipment_id", shipmentId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch shipment.", "error": err.Error()})
+		return
+	}
+
+	err = shipment.Update(context.Request.Context(), req)
+	if respondShipmentError(context, err) {
+		return
+	}
+	if err != nil {
+		l.Error("failed to update shipment", "shipment_id", shipmentId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update shipment.", "error": err.Error()})
+		return
+	}
+
+	l.Info("updated shipment", "shipment_id", shipmentId, "order_id", shipment.OrderID, "status", shipment.Status)
+	context.JSON(http.StatusOK, shipment)
+}
@@ -1,127 +1,29 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"context"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
 	"rearatrox/go-ecommerce-backend/services/order-service/models"
+	productmodels "rearatrox/go-ecommerce-backend/services/product-service/models"
 )
 
-type CheckStockRequest struct {
-	ProductID int64 `json:"productId"`
-	Quantity  int   `json:"quantity"`
-}
-
-type CheckStockResponse struct {
-	Available    bool  `json:"available"`
-	RequestedQty int   `json:"requestedQty"`
-	AvailableQty int   `json:"availableQty"`
-	ProductID    int64 `json:"productId"`
-}
-
-type ReduceStockRequest struct {
-	ProductID int64 `json:"productId"`
-	Quantity  int   `json:"quantity"`
-}
-
-// checkStockAvailability calls the product-service to check stock
-func checkStockAvailability(productID int64, quantity int) (*CheckStockResponse, error) {
-	productServiceURL := "http://product-service:8080"
-
-	apiPrefix := os.Getenv("API_PREFIX")
-	if apiPrefix == "" {
-		apiPrefix = "/api/v1"
-	}
-
-	url := fmt.Sprintf("%s%s/products/stock/check", productServiceURL, apiPrefix)
-
-	reqBody := CheckStockRequest{
-		ProductID: productID,
-		Quantity:  quantity,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+// reduceStockForOrder takes all items of an order out of stock at once; if one item is short, none is taken. The
+// reservation is keyed by the order id, so calling it again for the same order takes nothing.
+func reduceStockForOrder(ctx context.Context, order *models.Order) error {
+	reservation := make([]productmodels.ReduceStockRequest, 0, len(order.Items))
+	for _, item := range order.Items {
+		reservation = append(reservation, productmodels.ReduceStockRequest{ProductID: item.ProductID, Quantity: item.Quantity})
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("stock check failed: %s", string(body))
-	}
-
-	var stockResp CheckStockResponse
-	if err := json.NewDecoder(resp.Body).Decode(&stockResp); err != nil {
-		return nil, err
-	}
-
-	return &stockResp, nil
-}
-
-// reduceStock calls the product-service to reduce stock (internal endpoint, no auth required)
-func reduceStock(productID int64, quantity int) error {
-	productServiceURL := "http://product-service:8080"
-
-	apiPrefix := os.Getenv("API_PREFIX")
-	if apiPrefix == "" {
-		apiPrefix = "/api/v1"
-	}
-
-	internalSecret := os.Getenv("INTERNAL_API_SECRET")
-	if internalSecret == "" {
-		return fmt.Errorf("INTERNAL_API_SECRET not configured")
-	}
-
-	url := fmt.Sprintf("%s%s/internal/products/stock/reduce", productServiceURL, apiPrefix)
-
-	reqBody := ReduceStockRequest{
-		ProductID: productID,
-		Quantity:  quantity,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Internal-Secret", internalSecret)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("stock reduce failed: %s", string(body))
-	}
-
-	return nil
+	return productService.ReserveStock(ctx, order.ID, reservation)
 }
 
-// reduceStockForOrder reduces stock for all items in an order
-func reduceStockForOrder(items []models.OrderItem) error {
-	for _, item := range items {
-		if err := reduceStock(item.ProductID, item.Quantity); err != nil {
-			return fmt.Errorf("failed to reduce stock for product %d: %w", item.ProductID, err)
+// restoreStockForOrder puts all items of an order back into stock; failures are logged only
+func restoreStockForOrder(ctx context.Context, order *models.Order) {
+	l := logger.FromContext(ctx)
+	for _, item := range order.Items {
+		if err := productService.Restock(ctx, item.ProductID, item.Quantity); err != nil {
+			l.Warn("failed to restore stock", "order_id", order.ID, "product_id", item.ProductID, "error", err)
 		}
 	}
-	return nil
 }
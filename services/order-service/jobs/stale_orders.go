@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/order-service/models"
+)
+
+const (
+	DefaultOrderExpireAfter    = time.Hour
+	DefaultOrderExpiryInterval = 5 * time.Minute
+	orderExpiryBatchSize       = 100
+)
+
+// CancelFunc cancels a pending order
+type CancelFunc func(ctx context.Context, order *models.Order) error
+
+// StaleOrderJob cancels pending orders that never got a payment, e.g. because the customer left before paying.
+// Orders with a payment are expired together with it by payment-service.
+type StaleOrderJob struct {
+	ExpireAfter time.Duration // time after creation at which an unpaid pending order expires
+	Interval    time.Duration // time between two runs
+	Cancel      CancelFunc
+}
+
+// NewStaleOrderJobFromEnv configures the job from ORDER_EXPIRE_AFTER and ORDER_EXPIRY_INTERVAL
+func NewStaleOrderJobFromEnv(cancel CancelFunc) (*StaleOrderJob, error) {
+	var err error
+	job := &StaleOrderJob{Cancel: cancel}
+	if job.ExpireAfter, err = env.Duration("ORDER_EXPIRE_AFTER", DefaultOrderExpireAfter); err != nil {
+		return nil, err
+	}
+	if job.Interval, err = env.Duration("ORDER_EXPIRY_INTERVAL", DefaultOrderExpiryInterval); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Start runs the job every Interval until ctx is cancelled
+func (j *StaleOrderJob) Start(ctx context.Context) {
+	l := logger.FromContext(ctx)
+	l.Info("stale order job started", "expire_after", j.ExpireAfter.String(), "interval", j.Interval.String())
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			l.Error("stale order job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("stale order job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce cancels pending orders older than ExpireAfter that have no pending, processing or succeeded payment. An
+// order whose cancellation fails stays pending and is retried on the next run.
+func (j *StaleOrderJob) RunOnce(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+	createdBefore := time.Now().Add(-j.ExpireAfter)
+
+	// page by id so orders that keep failing do not block the ones behind them
+	var afterID int64
+	for {
+		orders, err := models.GetStalePendingOrders(ctx, createdBefore, afterID, orderExpiryBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch stale orders: %w", err)
+		}
+
+		for i := range orders {
+			order := &orders[i]
+			afterID = order.ID
+
+			err := j.Cancel(ctx, order)
+			if errors.Is(err, models.ErrOrderNotCancellable) {
+				// paid or cancelled in the meantime
+				l.Info("stale order no longer pending", "order_id", order.ID, "status", order.Status)
+				continue
+			}
+			if err != nil {
+				l.Warn("failed to cancel stale order", "order_id", order.ID, "error", err)
+				continue
+			}
+			l.Info("stale order cancelled", "order_id", order.ID, "created_at", order.CreatedAt)
+		}
+
+		if len(orders) < orderExpiryBatchSize {
+			return nil
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
-	"io"
 	"log"
-	"rearatrox/go-ecommerce-backend/pkg/db"
-	"rearatrox/go-ecommerce-backend/pkg/logger"
-
-	"github.com/gin-gonic/gin"
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/pb/orderpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+	"rearatrox/go-ecommerce-backend/services/order-service/grpcapi"
+	"rearatrox/go-ecommerce-backend/services/order-service/handlers"
+	"rearatrox/go-ecommerce-backend/services/order-service/jobs"
 )
 
 // @title E-Commerce Backend - Order-Service
@@ -28,18 +31,33 @@ import (
 // @in          header
 // @name        Authorization
 func main() {
-
-	if err := logger.InitFromEnv(); err != nil {
-		log.Fatalf("failed to init logger: %v", err)
+	srv, err := server.New("order-service")
+	if err != nil {
+		log.Fatalf("failed to start order-service: %v", err)
+	}
+	if err := guesttoken.CheckSecret(); err != nil {
+		log.Fatalf("failed to start order-service: %v", err)
 	}
-	defer logger.Sync()
 
-	db.InitDB()
+	// internal API for the other services, the REST /internal endpoints stay available
+	grpcServer := rpc.NewServer(grpcapi.Allowlist)
+	orderpb.RegisterOrderInternalServer(grpcServer, &grpcapi.Server{})
+	srv.ServeGRPC(grpcServer)
 
-	gin.DefaultWriter = io.Discard
-	router := gin.Default()
+	// stock and addresses over gRPC, refunds over REST
+	handlers.UseClients(clients.NewProductGRPCClientFromEnv(), clients.NewUserGRPCClientFromEnv(), clients.NewPaymentClientFromEnv())
+	srv.DependsOn("product-service", "user-service", "payment-service")
 
-	RegisterRoutes(router)
+	// background cancellation of pending orders that never got a payment
+	staleOrders, err := jobs.NewStaleOrderJobFromEnv(handlers.ExpireOrder)
+	if err != nil {
+		log.Fatalf("failed to configure stale order job: %v", err)
+	}
+	srv.Go(staleOrders.Start)
+
+	RegisterRoutes(srv.Router)
 
-	router.Run(":8080") // localhost:8080 --> ORDERSERVICE_PORT mappt dann den Container
+	if err := srv.Run(); err != nil {
+		log.Fatalf("order-service failed: %v", err)
+	}
 }
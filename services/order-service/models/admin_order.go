@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+)
+
+const (
+	DefaultOrderSearchLimit = 50
+	MaxOrderSearchLimit     = 200
+)
+
+// OrderSearch filters the admin order list; zero values do not filter
+type OrderSearch struct {
+	Status        string
+	From          *time.Time // created at or after
+	To            *time.Time // created before
+	UserID        *int64
+	Email         string // substring of the customer or guest email
+	MinTotalCents *int
+	MaxTotalCents *int
+	Limit         int
+	Offset        int
+}
+
+type OrderPage struct {
+	Orders []Order `json:"orders"`
+	Total  int     `json:"total" example:"120"`
+	Limit  int     `json:"limit" example:"50"`
+	Offset int     `json:"offset" example:"0"`
+}
+
+// AdminOrder is an order with everything staff needs to handle it
+type AdminOrder struct {
+	Order
+	CustomerEmail *string        `json:"customerEmail,omitempty" example:"jane@example.com"`
+	Payments      []OrderPayment `json:"payments"`
+	Returns       []Return       `json:"returns"`
+	Invoices      []Invoice      `json:"invoices"`
+	Notes         []OrderNote    `json:"notes"`
+}
+
+// OrderPayment is a payment of an order as recorded by payment-service, including its refunds
+type OrderPayment struct {
+	ID                    int64         `json:"id" example:"1"`
+	AmountCents           int           `json:"amountCents" example:"5999"`
+	Currency              string        `json:"currency" example:"EUR"`
+	Status                string        `json:"status" example:"succeeded"`
+	StripePaymentIntentID *string       `json:"stripePaymentIntentId,omitempty" example:"pi_1234567890"`
+	CreatedAt             time.Time     `json:"createdAt"`
+	Refunds               []OrderRefund `json:"refunds"`
+}
+
+type OrderRefund struct {
+	ID          int64     `json:"id" example:"1"`
+	ReturnID    *int64    `json:"returnId,omitempty" example:"1"`
+	AmountCents int       `json:"amountCents" example:"2999"`
+	Status      string    `json:"status" example:"succeeded"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type OrderNote struct {
+	ID           int64     `db:"id" json:"id" example:"1"`
+	AuthorUserID *int64    `db:"author_user_id" json:"authorUserId,omitempty" example:"1"`
+	Body         string    `db:"body" json:"body" example:"Customer asked to hold the parcel until Monday"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+}
+
+type OrderNoteRequest struct {
+	Body string `json:"body" example:"Customer asked to hold the parcel until Monday" binding:"required,max=2000"`
+}
+
+type AdminCancelRequest struct {
+	// Refund the full payment of a confirmed order
+	Refund bool `json:"refund" example:"true"`
+	// Stored as internal order note
+	Note *string `json:"note" example:"Out of stock at the supplier"`
+}
+
+// where builds the WHERE clause and arguments of the search
+func (s OrderSearch) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if s.Status != "" {
+		add("o.status = $%d", s.Status)
+	}
+	if s.From != nil {
+		add("o.created_at >= $%d", *s.From)
+	}
+	if s.To != nil {
+		add("o.created_at < $%d", *s.To)
+	}
+	if s.UserID != nil {
+		add("o.user_id = $%d", *s.UserID)
+	}
+	if s.Email != "" {
+		add("COALESCE(u.email, o.guest_email) ILIKE $%d", "%"+s.Email+"%")
+	}
+	if s.MinTotalCents != nil {
+		add("o.total_cents >= $%d", *s.MinTotalCents)
+	}
+	if s.MaxTotalCents != nil {
+		add("o.total_cents <= $%d", *s.MaxTotalCents)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// SearchOrders lists all orders matching the search, newest first, with the total number of matches
+// used in: handlers.AdminListOrders
+func SearchOrders(ctx context.Context, s OrderSearch) (*OrderPage, error) {
+	if s.Limit <= 0 || s.Limit > MaxOrderSearchLimit {
+		s.Limit = DefaultOrderSearchLimit
+	}
+	if s.Offset < 0 {
+		s.Offset = 0
+	}
+
+	where, args := s.where()
+	from := ` FROM orders o LEFT JOIN users u ON u.id = o.user_id` + where
+
+	page := &OrderPage{Orders: []Order{}, Limit: s.Limit, Offset: s.Offset}
+	if err := db.DB.QueryRow(ctx, `SELECT COUNT(*)`+from, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM orders WHERE id IN (SELECT o.id%s)
+	          ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d`, orderColumns, from, s.Limit, s.Offset)
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order Order
+		if err := scanOrder(rows, &order); err != nil {
+			return nil, err
+		}
+		page.Orders = append(page.Orders, order)
+	}
+	return page, rows.Err()
+}
+
+// GetAdminOrder retrieves any order with items, addresses, shipments, payments, returns and internal notes
+// used in: handlers.AdminGetOrder
+func GetAdminOrder(ctx context.Context, orderId int64) (*AdminOrder, error) {
+	order, err := GetOrderByIDInternal(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &AdminOrder{Order: *order}
+	if order.UserID != nil {
+		var email string
+		if err := db.DB.QueryRow(ctx, `SELECT email FROM users WHERE id=$1`, *order.UserID).Scan(&email); err == nil {
+			o.CustomerEmail = &email
+		}
+	}
+	if o.Payments, err = GetOrderPayments(ctx, orderId); err != nil {
+		return nil, err
+	}
+	if o.Returns, err = GetOrderReturns(ctx, orderId); err != nil {
+		return nil, err
+	}
+	if o.Invoices, err = GetOrderInvoices(ctx, orderId); err != nil {
+		return nil, err
+	}
+	if o.Notes, err = GetOrderNotes(ctx, orderId); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// GetOrderPayments retrieves the payments of an order with their refunds
+// used in: GetAdminOrder
+func GetOrderPayments(ctx context.Context, orderId int64) ([]OrderPayment, error) {
+	rows, err := db.DB.Query(ctx, `
+		SELECT id, amount_cents, currency, status, stripe_payment_intent_id, created_at
+		FROM payments WHERE order_id=$1 ORDER BY created_at`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []OrderPayment{}
+	index := map[int64]int{}
+	for rows.Next() {
+		var p OrderPayment
+		if err := rows.Scan(&p.ID, &p.AmountCents, &p.Currency, &p.Status, &p.StripePaymentIntentID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		p.Refunds = []OrderRefund{}
+		index[p.ID] = len(payments)
+		payments = append(payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	refundRows, err := db.DB.Query(ctx, `
+		SELECT id, payment_id, return_id, amount_cents, status, created_at
+		FROM refunds WHERE order_id=$1 ORDER BY created_at`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer refundRows.Close()
+
+	for refundRows.Next() {
+		var r OrderRefund
+		var paymentId int64
+		if err := refundRows.Scan(&r.ID, &paymentId, &r.ReturnID, &r.AmountCents, &r.Status, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := index[paymentId]; ok {
+			payments[i].Refunds = append(payments[i].Refunds, r)
+		}
+	}
+	return payments, refundRows.Err()
+}
+
+// GetOrderNotes retrieves the internal notes of an order, oldest first
+// used in: GetAdminOrder
+func GetOrderNotes(ctx context.Context, orderId int64) ([]OrderNote, error) {
+	rows, err := db.DB.Query(ctx, `SELECT id, author_user_id, body, created_at FROM order_notes WHERE order_id=$1 ORDER BY created_at, id`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []OrderNote{}
+	for rows.Next() {
+		var n OrderNote
+		if err := rows.Scan(&n.ID, &n.AuthorUserID, &n.Body, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// AddNote stores an internal staff note on the order
+// used in: handlers.AddOrderNote, handlers.AdminCancelOrder
+func (o *Order) AddNote(ctx context.Context, body string, authorUserId int64) (*OrderNote, error) {
+	n := &OrderNote{AuthorUserID: &authorUserId, Body: body}
+	err := db.DB.QueryRow(ctx, `INSERT INTO order_notes (order_id, author_user_id, body, created_at) VALUES ($1, $2, $3, now()) RETURNING id, created_at`,
+		o.ID, authorUserId, body).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
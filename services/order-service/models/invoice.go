@@ -0,0 +1,280 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/invoice"
+	"rearatrox/go-ecommerce-backend/pkg/shipping"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOrderNotInvoiceable is returned when an invoice is requested for an order that has not been paid
+var ErrOrderNotInvoiceable = errors.New("order has not been paid yet")
+
+// invoiceableStatuses are the statuses of paid orders
+var invoiceableStatuses = []string{"confirmed", "partially_shipped", "shipped", "delivered"}
+
+type Invoice struct {
+	ID         int64     `db:"id" json:"id" example:"1"`
+	OrderID    int64     `db:"order_id" json:"orderId" example:"1"`
+	Kind       string    `db:"kind" json:"kind" example:"invoice"`
+	Number     string    `db:"number" json:"number" example:"INV-2026-000001"`
+	InvoiceID  *int64    `db:"invoice_id" json:"invoiceId,omitempty" example:"1"`
+	RefundID   *int64    `db:"refund_id" json:"refundId,omitempty" example:"1"`
+	Currency   string    `db:"currency" json:"currency" example:"EUR"`
+	VATRate    int       `db:"vat_rate" json:"vatRate" example:"19"`
+	NetCents   int       `db:"net_cents" json:"netCents" example:"5041"`
+	TaxCents   int       `db:"tax_cents" json:"taxCents" example:"958"`
+	TotalCents int       `db:"total_cents" json:"totalCents" example:"5999"`
+	EInvoice   bool      `json:"eInvoice" example:"true"` // structured XML available
+	IssuedAt   time.Time `db:"issued_at" json:"issuedAt"`
+	PDF        []byte    `db:"pdf" json:"-"`
+	XML        []byte    `db:"xml" json:"-"`
+}
+
+const invoiceColumns = `id, order_id, kind, number, invoice_id, refund_id, currency, vat_rate, net_cents, tax_cents, total_cents, xml IS NOT NULL, issued_at`
+
+func scanInvoice(row pgx.Row, inv *Invoice, documents bool) error {
+	dest := []any{&inv.ID, &inv.OrderID, &inv.Kind, &inv.Number, &inv.InvoiceID, &inv.RefundID, &inv.Currency, &inv.VATRate,
+		&inv.NetCents, &inv.TaxCents, &inv.TotalCents, &inv.EInvoice, &inv.IssuedAt}
+	if documents {
+		dest = append(dest, &inv.PDF, &inv.XML)
+	}
+	return row.Scan(dest...)
+}
+
+// GetOrderInvoices retrieves the invoice and credit notes of an order without the documents
+// used in: handlers.ListOrderInvoices, GetAdminOrder
+func GetOrderInvoices(ctx context.Context, orderId int64) ([]Invoice, error) {
+	rows, err := db.DB.Query(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE order_id=$1 ORDER BY issued_at, id`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []Invoice{}
+	for rows.Next() {
+		var inv Invoice
+		if err := scanInvoice(rows, &inv, false); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// GetInvoiceDocument retrieves an invoice or credit note of an order by number, including PDF and XML
+// used in: handlers.GetOrderInvoiceDocument, handlers.AdminGetOrderInvoiceDocument
+func GetInvoiceDocument(ctx context.Context, orderId int64, number string) (*Invoice, error) {
+	inv := &Invoice{}
+	query := `SELECT ` + invoiceColumns + `, pdf, xml FROM invoices WHERE order_id=$1 AND number=$2`
+	if err := scanInvoice(db.DB.QueryRow(ctx, query, orderId, number), inv, true); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// IsInvoiceable reports whether the order has been paid and can be invoiced
+func (o *Order) IsInvoiceable() bool {
+	return slices.Contains(invoiceableStatuses, o.Status)
+}
+
+// InvoiceLines returns the order items as invoice lines
+// used in: invoiceDocument, handlers.AdminCancelOrder
+func (o *Order) InvoiceLines() []invoice.Line {
+	lines := make([]invoice.Line, 0, len(o.Items))
+	for _, item := range o.Items {
+		lines = append(lines, invoice.Line{Description: item.ProductName, Quantity: item.Quantity, UnitCents: item.PriceCents})
+	}
+	return lines
+}
+
+// buyer returns the invoice recipient from the billing (or else shipping) address and the customer's email
+func (o *Order) buyer(ctx context.Context, tx pgx.Tx) (invoice.Party, error) {
+	var party invoice.Party
+	addr := o.BillingAddress
+	if addr == nil {
+		addr = o.ShippingAddress
+	}
+	if addr != nil {
+		party = invoice.Party{Name: addr.FullName, Street: addr.Street, PostalCode: addr.PostalCode, City: addr.City, Country: addr.Country}
+	}
+
+	if o.GuestEmail != nil {
+		party.Email = *o.GuestEmail
+	} else if o.UserID != nil {
+		if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id=$1`, *o.UserID).Scan(&party.Email); err != nil && err != pgx.ErrNoRows {
+			return party, err
+		}
+	}
+	if party.Name == "" {
+		party.Name = party.Email
+	}
+	return party, nil
+}
+
+// nextInvoiceNumber draws the next number of the yearly sequence of a document kind. The sequence row stays locked
+// until the transaction ends, so numbers are gapless: a rolled back invoice gives its number back.
+func nextInvoiceNumber(ctx context.Context, tx pgx.Tx, kind string, year int) (string, error) {
+	var seq int64
+	query := `INSERT INTO invoice_sequences (kind, year, last_number) VALUES ($1, $2, 1)
+	          ON CONFLICT (kind, year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+	          RETURNING last_number`
+	if err := tx.QueryRow(ctx, query, kind, year).Scan(&seq); err != nil {
+		return "", err
+	}
+	return invoice.FormatNumber(kind, year, seq), nil
+}
+
+// issue numbers, renders and stores a document within the transaction
+func (o *Order) issue(ctx context.Context, tx pgx.Tx, cfg invoice.Config, doc *invoice.Document, invoiceId, refundId *int64) (*Invoice, error) {
+	buyer, err := o.buyer(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	number, err := nextInvoiceNumber(ctx, tx, doc.Kind, now.Year())
+	if err != nil {
+		return nil, err
+	}
+	doc.Number = number
+	doc.IssueDate = now
+	doc.OrderID = o.ID
+	doc.Currency = shipping.Currency
+	doc.VATRate = cfg.VATRate
+	doc.Seller = cfg.Seller
+	doc.Buyer = buyer
+
+	inv := &Invoice{
+		OrderID:   o.ID,
+		Kind:      doc.Kind,
+		Number:    number,
+		InvoiceID: invoiceId,
+		RefundID:  refundId,
+		Currency:  doc.Currency,
+		VATRate:   doc.VATRate,
+		EInvoice:  cfg.EInvoice,
+	}
+	totals := doc.Totals()
+	inv.NetCents, inv.TaxCents, inv.TotalCents = totals.NetCents, totals.TaxCents, totals.GrossCents
+
+	if inv.PDF, err = invoice.RenderPDF(doc); err != nil {
+		return nil, err
+	}
+	if cfg.EInvoice {
+		if inv.XML, err = invoice.RenderXML(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `INSERT INTO invoices (order_id, kind, number, invoice_id, refund_id, currency, vat_rate, net_cents, tax_cents, total_cents, pdf, xml)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	          RETURNING id, issued_at`
+	err = tx.QueryRow(ctx, query, inv.OrderID, inv.Kind, inv.Number, inv.InvoiceID, inv.RefundID, inv.Currency, inv.VATRate,
+		inv.NetCents, inv.TaxCents, inv.TotalCents, inv.PDF, inv.XML).Scan(&inv.ID, &inv.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// invoiceDocument returns the invoice for all items, shipping and discounts of the order
+func (o *Order) invoiceDocument() *invoice.Document {
+	return &invoice.Document{
+		Kind:          invoice.KindInvoice,
+		Lines:         o.InvoiceLines(),
+		ShippingCents: o.ShippingCents,
+		DiscountCents: o.DiscountCents,
+	}
+}
+
+// lockedInvoice locks the order and returns its invoice including PDF and XML, nil if none has been issued yet
+func lockedInvoice(ctx context.Context, tx pgx.Tx, orderId int64) (*Invoice, error) {
+	if _, err := tx.Exec(ctx, `SELECT id FROM orders WHERE id=$1 FOR UPDATE`, orderId); err != nil {
+		return nil, err
+	}
+	inv := &Invoice{}
+	query := `SELECT ` + invoiceColumns + `, pdf, xml FROM invoices WHERE order_id=$1 AND kind=$2`
+	err := scanInvoice(tx.QueryRow(ctx, query, orderId, invoice.KindInvoice), inv, true)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// IssueInvoice issues the invoice for a paid order, or returns the existing one. The order needs its items and
+// addresses loaded.
+// used in: handlers.issueInvoice, handlers.GetOrderInvoice, handlers.AdminGetOrderInvoice
+func (o *Order) IssueInvoice(ctx context.Context, cfg invoice.Config) (*Invoice, error) {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inv, err := lockedInvoice(ctx, tx, o.ID)
+	if err != nil || inv != nil {
+		return inv, err
+	}
+	if !o.IsInvoiceable() {
+		return nil, ErrOrderNotInvoiceable
+	}
+
+	if inv, err = o.issue(ctx, tx, cfg, o.invoiceDocument(), nil, nil); err != nil {
+		return nil, err
+	}
+	return inv, tx.Commit(ctx)
+}
+
+// IssueCreditNote issues a credit note for a refund against the order's invoice, issuing the invoice first if it is
+// missing. Only one credit note is issued per refund; repeated calls return the existing one.
+// used in: handlers.completeReturn, handlers.AdminCancelOrder
+func (o *Order) IssueCreditNote(ctx context.Context, cfg invoice.Config, refundId int64, lines []invoice.Line, shippingCents, discountCents int) (*Invoice, error) {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	original, err := lockedInvoice(ctx, tx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		if original, err = o.issue(ctx, tx, cfg, o.invoiceDocument(), nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	existing := &Invoice{}
+	query := `SELECT ` + invoiceColumns + `, pdf, xml FROM invoices WHERE refund_id=$1`
+	err = scanInvoice(tx.QueryRow(ctx, query, refundId), existing, true)
+	if err == nil {
+		return existing, tx.Commit(ctx)
+	}
+	if err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	doc := &invoice.Document{
+		Kind:          invoice.KindCreditNote,
+		RefersTo:      original.Number,
+		Lines:         lines,
+		ShippingCents: shippingCents,
+		DiscountCents: discountCents,
+	}
+	note, err := o.issue(ctx, tx, cfg, doc, &original.ID, &refundId)
+	if err != nil {
+		return nil, err
+	}
+	return note, tx.Commit(ctx)
+}
@@ -1,216 +1,452 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
+	"rearatrox/go-ecommerce-backend/pkg/promotions"
+	"rearatrox/go-ecommerce-backend/pkg/shipping"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrOrderNotCancellable is returned when an order that is neither pending nor confirmed is cancelled
+var ErrOrderNotCancellable = errors.New("order cannot be cancelled in its current status")
+
+// ErrOrderNotConfirmable is returned when an order that is no longer pending is confirmed, e.g. after it was cancelled
+var ErrOrderNotConfirmable = errors.New("only pending orders can be confirmed")
+
+// ErrStockNotReserved is returned when confirming an order fails because its items could not be taken out of stock
+var ErrStockNotReserved = errors.New("could not reduce stock")
+
 type Order struct {
 	ID                int64       `db:"id" json:"id" swaggerignore:"true"`
-	UserID            int64       `db:"user_id" json:"userId" swaggerignore:"true"`
+	UserID            *int64      `db:"user_id" json:"userId,omitempty" swaggerignore:"true"` // nil for guest orders
+	GuestEmail        *string     `db:"guest_email" json:"guestEmail,omitempty" example:"guest@example.com"`
 	CartID            int64       `db:"cart_id" json:"cartId" swaggerignore:"true"`
 	Status            string      `db:"status" json:"status" example:"pending"`
+	SubtotalCents     int         `db:"subtotal_cents" json:"subtotalCents" example:"5504"`
+	ShippingCents     int         `db:"shipping_cents" json:"shippingCents" example:"495"`
+	DiscountCents     int         `db:"discount_cents" json:"discountCents" example:"0"`
 	TotalCents        int         `db:"total_cents" json:"totalCents" example:"5999"`
+	ShippingMethodID  *int64      `db:"shipping_method_id" json:"shippingMethodId,omitempty" example:"1"`
+	ShippingMethod    *string     `db:"shipping_method_name" json:"shippingMethod,omitempty" example:"Standard Shipping"`
 	ShippingAddressID *int64      `db:"shipping_address_id" json:"shippingAddressId,omitempty" example:"1"`
 	BillingAddressID  *int64      `db:"billing_address_id" json:"billingAddressId,omitempty" example:"1"`
 	CreatedAt         time.Time   `db:"created_at" json:"createdAt" swaggerignore:"true"`
 	UpdatedAt         *time.Time  `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
 	Items             []OrderItem `json:"items,omitempty"`
 
-	// Address details (joined)
-	ShippingAddress *Address `json:"shippingAddress,omitempty"`
-	BillingAddress  *Address `json:"billingAddress,omitempty"`
+	// Applied coupons
+	Discounts []promotions.DiscountLine `json:"discounts,omitempty"`
+
+	// Parcels with carrier and tracking (tracking view)
+	Shipments []Shipment `json:"shipments,omitempty"`
+
+	// Address snapshots taken at checkout, unaffected by later changes to the saved addresses
+	ShippingAddress *Address `db:"shipping_address" json:"shippingAddress,omitempty"`
+	BillingAddress  *Address `db:"billing_address" json:"billingAddress,omitempty"`
+
+	// Signed token granting a guest access to the order, returned on guest checkout only
+	Token string `json:"orderToken,omitempty" swaggerignore:"true"`
+}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status" example:"confirmed" binding:"required"`
 }
 
+// CustomerStatusRequest is a status change customers may make to their own orders. Confirmation follows the payment
+// and the fulfilment statuses follow the shipments.
+type CustomerStatusRequest struct {
+	Status string `json:"status" example:"cancelled" binding:"required,oneof=cancelled"`
+}
+
+// Address is the snapshot of a shipping or billing address stored on the order
 type Address struct {
-	ID        int64  `json:"id"`
-	Street    string `json:"street" example:"Main Street 123"`
-	City      string `json:"city" example:"New York"`
-	State     string `json:"state" example:"NY"`
-	ZipCode   string `json:"zipCode" example:"10001"`
-	Country   string `json:"country" example:"USA"`
-	IsDefault bool   `json:"isDefault"`
+	FullName   string `json:"fullName" example:"Jane Doe"`
+	Street     string `json:"street" example:"Hauptstraße 1"`
+	PostalCode string `json:"postalCode" example:"10115"`
+	City       string `json:"city" example:"Berlin"`
+	Country    string `json:"country" example:"DE"`
 }
 
-// CreateFromCart creates a new order from an active cart and marks cart as ordered
+const orderColumns = `id, user_id, guest_email, cart_id, status, subtotal_cents, shipping_cents, discount_cents, total_cents,
+	          shipping_method_id, shipping_method_name, shipping_address_id, billing_address_id, shipping_address, billing_address,
+	          created_at, updated_at`
+
+// scanOrder scans a row selected with orderColumns into the order
+func scanOrder(row pgx.Row, o *Order) error {
+	return row.Scan(
+		&o.ID, &o.UserID, &o.GuestEmail, &o.CartID, &o.Status, &o.SubtotalCents, &o.ShippingCents, &o.DiscountCents, &o.TotalCents,
+		&o.ShippingMethodID, &o.ShippingMethod, &o.ShippingAddressID, &o.BillingAddressID, &o.ShippingAddress, &o.BillingAddress,
+		&o.CreatedAt, &o.UpdatedAt,
+	)
+}
+
+// CreateFromCart creates a new order from the user's active cart with the cost of the shipping method, redeems the
+// cart's coupon and marks cart as ordered. The saved addresses are referenced by ID and copied into the order as
+// snapshots. Returns shipping.ErrMethodUnavailable if the method cannot deliver the cart to the shipping address and
+// a *promotions.CouponError if the coupon no longer applies.
 // used in: handlers.CreateOrder
-func CreateFromCart(userId int64, shippingAddressId, billingAddressId *int64) (*Order, error) {
+func CreateFromCart(ctx context.Context, userId int64, shippingAddressId, billingAddressId *int64, shippingAddress, billingAddress *Address, shippingMethodId int64) (*Order, error) {
+	order := &Order{
+		UserID:            &userId,
+		ShippingAddressID: shippingAddressId,
+		BillingAddressID:  billingAddressId,
+		ShippingAddress:   shippingAddress,
+		BillingAddress:    billingAddress,
+	}
+	if err := order.createFromCart(ctx, `c.user_id=$1`, userId, shippingMethodId); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateFromGuestCart creates a new guest order from an anonymous cart like CreateFromCart; the inline addresses are
+// stored on the order
+// used in: handlers.CreateGuestOrder
+func CreateFromGuestCart(ctx context.Context, cartId int64, email string, shippingAddress, billingAddress *Address, shippingMethodId int64) (*Order, error) {
+	order := &Order{
+		GuestEmail:      &email,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+	}
+	if err := order.createFromCart(ctx, `c.id=$1 AND c.user_id IS NULL`, cartId, shippingMethodId); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// createFromCart fills the order from the active cart matching cartFilter and stores it in one transaction
+func (o *Order) createFromCart(ctx context.Context, cartFilter string, cartArg any, shippingMethodId int64) error {
 	// Start transaction
-	tx, err := db.DB.Begin(db.Ctx)
+	tx, err := db.DB.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer tx.Rollback(db.Ctx)
+	defer tx.Rollback(ctx)
 
 	// Get active cart
-	var cartID int64
-	var total int
-	err = tx.QueryRow(db.Ctx, `
-		SELECT c.id, COALESCE(SUM(ci.price_cents * ci.quantity), 0) as total
+	var couponID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT c.id, c.coupon_id, COALESCE(SUM(ci.price_cents * ci.quantity), 0) as total
 		FROM carts c
 		LEFT JOIN cart_items ci ON c.id = ci.cart_id
-		WHERE c.user_id=$1 AND c.status='active'
+		WHERE `+cartFilter+` AND c.status='active'
 		GROUP BY c.id
-	`, userId).Scan(&cartID, &total)
+	`, cartArg).Scan(&o.CartID, &couponID, &o.SubtotalCents)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// Quote shipping for the items being ordered, so a free shipping threshold applies to the subtotal charged
+	parcel, err := shipping.ParcelForCart(ctx, tx, o.CartID)
+	if err != nil {
+		return err
+	}
+	parcel.SubtotalCents = o.SubtotalCents
+	shippingQuote, err := shipping.QuoteMethod(ctx, tx, shippingMethodId, o.ShippingAddress.Country, parcel)
+	if err != nil {
+		return err
 	}
 
 	// Create order
-	order := &Order{
-		UserID:            userId,
-		CartID:            cartID,
-		Status:            "pending",
-		TotalCents:        total,
-		ShippingAddressID: shippingAddressId,
-		BillingAddressID:  billingAddressId,
+	o.Status = "pending"
+	o.ShippingMethodID = &shippingQuote.MethodID
+	o.ShippingMethod = &shippingQuote.Name
+	o.ShippingCents = shippingQuote.CostCents
+
+	// Re-evaluate the cart's coupon now that the shipping cost is known
+	var coupon *promotions.Coupon
+	if couponID != nil {
+		coupon, err = promotions.GetCouponByID(ctx, *couponID)
+		if err != nil {
+			return err
+		}
+		lines, err := promotions.CartLines(ctx, tx, o.CartID)
+		if err != nil {
+			return err
+		}
+		discount, err := coupon.Evaluate(lines, o.ShippingCents, time.Now())
+		if err != nil {
+			return err
+		}
+		o.Discounts = []promotions.DiscountLine{*discount}
+		o.DiscountCents = discount.AmountCents
 	}
+	o.TotalCents = o.SubtotalCents + o.ShippingCents - o.DiscountCents
 
-	query := `INSERT INTO orders (user_id, cart_id, status, total_cents, shipping_address_id, billing_address_id, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, now())
+	query := `INSERT INTO orders (user_id, guest_email, cart_id, status, subtotal_cents, shipping_cents, discount_cents, total_cents,
+	                              shipping_method_id, shipping_method_name, shipping_address_id, billing_address_id,
+	                              shipping_address, billing_address, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, now())
 	          RETURNING id, created_at`
-	err = tx.QueryRow(db.Ctx, query, order.UserID, order.CartID, order.Status, order.TotalCents,
-		order.ShippingAddressID, order.BillingAddressID).Scan(&order.ID, &order.CreatedAt)
+	err = tx.QueryRow(ctx, query, o.UserID, o.GuestEmail, o.CartID, o.Status, o.SubtotalCents, o.ShippingCents, o.DiscountCents, o.TotalCents,
+		o.ShippingMethodID, o.ShippingMethod, o.ShippingAddressID, o.BillingAddressID,
+		o.ShippingAddress, o.BillingAddress).Scan(&o.ID, &o.CreatedAt)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// Record discount lines and the redemption (checks usage limits under a row lock)
+	if coupon != nil {
+		if err = coupon.Redeem(ctx, tx, o.UserID, o.GuestEmail, o.ID, o.DiscountCents); err != nil {
+			return err
+		}
+		for _, d := range o.Discounts {
+			if err = insertOrderDiscount(ctx, tx, o.ID, d); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Copy cart items to order items
-	_, err = tx.Exec(db.Ctx, `
+	_, err = tx.Exec(ctx, `
 		INSERT INTO order_items (order_id, product_id, quantity, price_cents, product_name, created_at)
 		SELECT $1, ci.product_id, ci.quantity, ci.price_cents, p.name, now()
 		FROM cart_items ci
 		JOIN products p ON ci.product_id = p.id
 		WHERE ci.cart_id=$2
-	`, order.ID, cartID)
+	`, o.ID, o.CartID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Update cart status to 'ordered'
-	_, err = tx.Exec(db.Ctx, `UPDATE carts SET status='ordered', updated_at=now() WHERE id=$1`, cartID)
+	_, err = tx.Exec(ctx, `UPDATE carts SET status='ordered', updated_at=now() WHERE id=$1`, o.CartID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Commit transaction
-	if err = tx.Commit(db.Ctx); err != nil {
-		return nil, err
+	if err = tx.Commit(ctx); err != nil {
+		return err
 	}
 
 	// Load order items
-	if err = order.LoadItems(); err != nil {
+	return o.LoadItems(ctx)
+}
+
+// GetOrderByID retrieves a specific order by ID for a user including items and addresses
+// used in: handlers.GetOrder, handlers.UpdateOrderStatus, handlers.CancelOrder
+func GetOrderByID(ctx context.Context, orderId, userId int64) (*Order, error) {
+	order := &Order{}
+	query := `SELECT ` + orderColumns + `
+	          FROM orders
+	          WHERE id=$1 AND user_id=$2`
+	err := scanOrder(db.DB.QueryRow(ctx, query, orderId, userId), order)
+	if err != nil {
 		return nil, err
 	}
 
-	// Load addresses
-	if err = order.LoadAddresses(); err != nil {
+	// Load items, discounts and shipments
+	if err = order.LoadItems(ctx); err != nil {
+		return nil, err
+	}
+	if err = order.LoadDiscounts(ctx); err != nil {
+		return nil, err
+	}
+	if err = order.LoadShipments(ctx); err != nil {
 		return nil, err
 	}
 
 	return order, nil
 }
 
-// GetOrderByID retrieves a specific order by ID for a user including items and addresses
-// used in: handlers.GetOrder, handlers.UpdateOrderStatus
-func GetOrderByID(orderId, userId int64) (*Order, error) {
+// GetOrderByIDInternal retrieves an order by ID without user validation (for internal service calls)
+// used in: handlers.InternalUpdateOrderStatus, handlers.InternalGetOrder, grpcapi.Server.GetOrder, grpcapi.Server.UpdateOrderStatus
+func GetOrderByIDInternal(ctx context.Context, orderId int64) (*Order, error) {
 	order := &Order{}
-	query := `SELECT id, user_id, cart_id, status, total_cents, shipping_address_id, billing_address_id, created_at, updated_at
+	query := `SELECT ` + orderColumns + `
 	          FROM orders
-	          WHERE id=$1 AND user_id=$2`
-	err := db.DB.QueryRow(db.Ctx, query, orderId, userId).Scan(
-		&order.ID, &order.UserID, &order.CartID, &order.Status, &order.TotalCents,
-		&order.ShippingAddressID, &order.BillingAddressID, &order.CreatedAt, &order.UpdatedAt,
-	)
+	          WHERE id=$1`
+	err := scanOrder(db.DB.QueryRow(ctx, query, orderId), order)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load items and addresses
-	if err = order.LoadItems(); err != nil {
+	// Load items, discounts and shipments
+	if err = order.LoadItems(ctx); err != nil {
 		return nil, err
 	}
-	if err = order.LoadAddresses(); err != nil {
+	if err = order.LoadDiscounts(ctx); err != nil {
+		return nil, err
+	}
+	if err = order.LoadShipments(ctx); err != nil {
 		return nil, err
 	}
 
 	return order, nil
 }
 
-// GetOrderByIDInternal retrieves an order by ID without user validation (for internal service calls)
-// used in: handlers.InternalUpdateOrderStatus
-func GetOrderByIDInternal(orderId int64) (*Order, error) {
+// GetGuestOrderByID retrieves a guest order by ID including items and addresses
+// used in: handlers.GetGuestOrder
+func GetGuestOrderByID(ctx context.Context, orderId int64) (*Order, error) {
 	order := &Order{}
-	query := `SELECT id, user_id, cart_id, status, total_cents, shipping_address_id, billing_address_id, created_at, updated_at
+	query := `SELECT ` + orderColumns + `
 	          FROM orders
-	          WHERE id=$1`
-	err := db.DB.QueryRow(db.Ctx, query, orderId).Scan(
-		&order.ID, &order.UserID, &order.CartID, &order.Status, &order.TotalCents,
-		&order.ShippingAddressID, &order.BillingAddressID, &order.CreatedAt, &order.UpdatedAt,
-	)
+	          WHERE id=$1 AND user_id IS NULL`
+	err := scanOrder(db.DB.QueryRow(ctx, query, orderId), order)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load items and addresses
-	if err = order.LoadItems(); err != nil {
+	if err = order.LoadItems(ctx); err != nil {
 		return nil, err
 	}
-	if err = order.LoadAddresses(); err != nil {
+	if err = order.LoadDiscounts(ctx); err != nil {
+		return nil, err
+	}
+	if err = order.LoadShipments(ctx); err != nil {
 		return nil, err
 	}
 
 	return order, nil
 }
 
-// GetUserOrders retrieves all orders for a user ordered by creation date
-// used in: handlers.ListOrders
-func GetUserOrders(userId int64) ([]Order, error) {
-	query := `SELECT id, user_id, cart_id, status, total_cents, shipping_address_id, billing_address_id, created_at, updated_at
+// GetStalePendingOrders retrieves pending orders created before createdBefore without an active payment, ordered by
+// id and starting after afterID. Items are not loaded, pending orders hold no stock.
+// used in: jobs.StaleOrderJob
+func GetStalePendingOrders(ctx context.Context, createdBefore time.Time, afterID int64, limit int) ([]Order, error) {
+	query := `SELECT ` + orderColumns + `
 	          FROM orders
-	          WHERE user_id=$1
-	          ORDER BY created_at DESC`
-
-	rows, err := db.DB.Query(db.Ctx, query, userId)
+	          WHERE status='pending' AND created_at < $1 AND id > $2
+	            AND NOT EXISTS (
+	                SELECT 1 FROM payments
+	                WHERE payments.order_id = orders.id AND payments.status IN ('pending', 'processing', 'succeeded'))
+	          ORDER BY id
+	          LIMIT $3`
+	rows, err := db.DB.Query(ctx, query, createdBefore, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var orders []Order
+	orders := []Order{}
 	for rows.Next() {
 		var order Order
-		err := rows.Scan(
-			&order.ID, &order.UserID, &order.CartID, &order.Status, &order.TotalCents,
-			&order.ShippingAddressID, &order.BillingAddressID, &order.CreatedAt, &order.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanOrder(rows, &order); err != nil {
 			return nil, err
 		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
 
-		// Load items and addresses for each order
-		if err = order.LoadItems(); err != nil {
-			return nil, err
-		}
-		if err = order.LoadAddresses(); err != nil {
+// OrderHistoryFilter narrows and pages a user's order history
+type OrderHistoryFilter struct {
+	Status string
+	From   *time.Time // created at or after
+	To     *time.Time // created before
+	Cursor *pagination.Cursor
+	Limit  int
+}
+
+// OrderHistory is a page of a user's orders, newest first
+type OrderHistory struct {
+	Orders []Order `json:"orders"`
+	// Pass as cursor to fetch the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty" example:"MTc2NzIyNTYwMDAwMDAwMDo0Mg"`
+}
+
+// GetUserOrders retrieves a page of a user's orders, newest first, with items and discounts
+// used in: handlers.ListOrders
+func GetUserOrders(ctx context.Context, userId int64, f OrderHistoryFilter) (*OrderHistory, error) {
+	where := []string{"user_id=$1"}
+	args := []any{userId}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.Status != "" {
+		where = append(where, "status="+arg(f.Status))
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "created_at < "+arg(*f.To))
+	}
+	if f.Cursor != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(f.Cursor.CreatedAt), arg(f.Cursor.ID)))
+	}
+
+	query := `SELECT ` + orderColumns + `
+	          FROM orders
+	          WHERE ` + strings.Join(where, " AND ") + `
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ` + arg(f.Limit+1)
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []Order{}
+	for rows.Next() {
+		var order Order
+		if err := scanOrder(rows, &order); err != nil {
 			return nil, err
 		}
-
 		orders = append(orders, order)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history := &OrderHistory{}
+	history.Orders, history.NextCursor = pagination.Next(orders, f.Limit, func(o Order) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+	})
+	if err := loadOrderDetails(ctx, history.Orders); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// loadOrderDetails loads items and discounts of several orders with one query each
+// used in: GetUserOrders
+func loadOrderDetails(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
 
-	if orders == nil {
-		orders = []Order{}
+	orderIds := make([]int64, len(orders))
+	for i, o := range orders {
+		orderIds[i] = o.ID
 	}
 
-	return orders, nil
+	items, err := getItemsByOrderIDs(ctx, orderIds)
+	if err != nil {
+		return err
+	}
+	discounts, err := getDiscountsByOrderIDs(ctx, orderIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range orders {
+		o := &orders[i]
+		o.Items = items[o.ID]
+		if o.Items == nil {
+			o.Items = []OrderItem{}
+		}
+		o.Discounts = discounts[o.ID]
+	}
+	return nil
 }
 
 // LoadItems loads all order items for an order
-// used in: CreateFromCart, GetOrderByID, GetUserOrders
-func (o *Order) LoadItems() error {
-	items, err := GetOrderItems(o.ID)
+// used in: CreateFromCart, GetOrderByID, GetOrderByIDInternal
+func (o *Order) LoadItems(ctx context.Context) error {
+	items, err := GetOrderItems(ctx, o.ID)
 	if err != nil {
 		return err
 	}
@@ -218,44 +454,127 @@ func (o *Order) LoadItems() error {
 	return nil
 }
 
-// LoadAddresses loads shipping and billing address details for an order
-// used in: CreateFromCart, GetOrderByID, GetUserOrders
-func (o *Order) LoadAddresses() error {
-	if o.ShippingAddressID != nil {
-		addr := &Address{}
-		query := `SELECT id, street, city, state, zip_code, country, is_default
-		          FROM addresses WHERE id=$1`
-		err := db.DB.QueryRow(db.Ctx, query, *o.ShippingAddressID).Scan(
-			&addr.ID, &addr.Street, &addr.City, &addr.State, &addr.ZipCode, &addr.Country, &addr.IsDefault,
-		)
-		if err == nil {
-			o.ShippingAddress = addr
-		}
+// LoadDiscounts loads the applied coupon discounts for an order
+// used in: GetOrderByID, GetOrderByIDInternal
+func (o *Order) LoadDiscounts(ctx context.Context) error {
+	discounts, err := GetOrderDiscounts(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	o.Discounts = discounts
+	return nil
+}
+
+// Confirm sets a pending order to confirmed. reserve takes the items out of stock before the row lock is taken, so
+// no lock is held during the remote call; it must be idempotent per order, so a retried confirmation takes the stock
+// only once. An error from it aborts the confirmation. If the order was cancelled in the meantime, release puts the
+// reserved stock back, called by one confirmation only. Returns ErrOrderNotConfirmable if the order is no longer
+// pending; Status then holds the current status.
+// used in: handlers.ApplyInternalStatus
+func (o *Order) Confirm(ctx context.Context, reserve func() error, release func()) error {
+	if o.Status != "pending" {
+		return ErrOrderNotConfirmable
+	}
+	if err := reserve(); err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	if o.BillingAddressID != nil {
-		addr := &Address{}
-		query := `SELECT id, street, city, state, zip_code, country, is_default
-		          FROM addresses WHERE id=$1`
-		err := db.DB.QueryRow(db.Ctx, query, *o.BillingAddressID).Scan(
-			&addr.ID, &addr.Street, &addr.City, &addr.State, &addr.ZipCode, &addr.Country, &addr.IsDefault,
-		)
-		if err == nil {
-			o.BillingAddress = addr
+	var current string
+	var returned bool
+	if err = tx.QueryRow(ctx, `SELECT status, stock_returned FROM orders WHERE id=$1 FOR UPDATE`, o.ID).Scan(&current, &returned); err != nil {
+		return err
+	}
+	if current != "pending" {
+		o.Status = current
+		// a cancelled order that was never confirmed holds the stock just reserved
+		if current != "cancelled" || returned {
+			return ErrOrderNotConfirmable
 		}
+		if _, err = tx.Exec(ctx, `UPDATE orders SET stock_returned=true WHERE id=$1`, o.ID); err != nil {
+			return err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return err
+		}
+		release()
+		return ErrOrderNotConfirmable
 	}
 
+	if _, err = tx.Exec(ctx, `UPDATE orders SET status='confirmed', updated_at=now() WHERE id=$1`, o.ID); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	o.Status = "confirmed"
+	now := time.Now()
+	o.UpdatedAt = &now
 	return nil
 }
 
-// UpdateStatus changes the order status (e.g., pending, confirmed, shipped, delivered, cancelled)
-// used in: handlers.UpdateOrderStatus
-func (o *Order) UpdateStatus(newStatus string) error {
-	query := `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`
-	_, err := db.DB.Exec(db.Ctx, query, newStatus, o.ID)
+// Cancel sets a pending or confirmed order to cancelled under a row lock and releases its coupon redemptions. With
+// pendingOnly a confirmed order is kept as well. It returns the status the order had before. Returns
+// ErrOrderNotCancellable for any other status, including cancelled; Status then holds the current status.
+// used in: handlers.cancelOrderFrom
+func (o *Order) Cancel(ctx context.Context, pendingOnly bool) (previous string, err error) {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, o.ID).Scan(&previous); err != nil {
+		return "", err
+	}
+	if previous != "pending" && (previous != "confirmed" || pendingOnly) {
+		o.Status = previous
+		return previous, ErrOrderNotCancellable
+	}
+
+	// the caller puts the stock of a confirmed order back
+	if _, err = tx.Exec(ctx, `UPDATE orders SET status='cancelled', stock_returned=(status='confirmed'), updated_at=now() WHERE id=$1`, o.ID); err != nil {
+		return "", err
+	}
+	if err = promotions.ReleaseForOrder(ctx, tx, o.ID); err != nil {
+		return "", err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	o.Status = "cancelled"
+	now := time.Now()
+	o.UpdatedAt = &now
+	return previous, nil
+}
+
+// UpdateStatus changes the order status (e.g., pending, confirmed, shipped, delivered, cancelled).
+// Cancelling an order releases its coupon redemptions.
+// used in: handlers.ApplyInternalStatus
+func (o *Order) UpdateStatus(ctx context.Context, newStatus string) error {
+	tx, err := db.DB.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`
+	if _, err = tx.Exec(ctx, query, newStatus, o.ID); err != nil {
+		return err
+	}
+	if newStatus == "cancelled" {
+		if err = promotions.ReleaseForOrder(ctx, tx, o.ID); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
 	o.Status = newStatus
 	now := time.Now()
 	o.UpdatedAt = &now
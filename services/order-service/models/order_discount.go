@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/promotions"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// insertOrderDiscount stores an applied discount line on the order inside the creation transaction
+// used in: CreateFromCart
+func insertOrderDiscount(ctx context.Context, tx pgx.Tx, orderId int64, d promotions.DiscountLine) error {
+	query := `INSERT INTO order_discounts (order_id, coupon_id, code, type, description, amount_cents, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, now())`
+	_, err := tx.Exec(ctx, query, orderId, d.CouponID, d.Code, d.Type, d.Description, d.AmountCents)
+	return err
+}
+
+// GetOrderDiscounts retrieves the discount lines of an order
+// used in: order.LoadDiscounts
+func GetOrderDiscounts(ctx context.Context, orderId int64) ([]promotions.DiscountLine, error) {
+	query := `SELECT COALESCE(coupon_id, 0), code, type, COALESCE(description, ''), amount_cents
+	          FROM order_discounts
+	          WHERE order_id=$1
+	          ORDER BY id`
+
+	rows, err := db.DB.Query(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discounts []promotions.DiscountLine
+	for rows.Next() {
+		var d promotions.DiscountLine
+		if err := rows.Scan(&d.CouponID, &d.Code, &d.Type, &d.Description, &d.AmountCents); err != nil {
+			return nil, err
+		}
+		d.FreeShipping = d.Type == promotions.TypeFreeShipping
+		discounts = append(discounts, d)
+	}
+
+	return discounts, rows.Err()
+}
+
+// getDiscountsByOrderIDs retrieves the discount lines of several orders in one query, keyed by order ID
+// used in: loadOrderDetails
+func getDiscountsByOrderIDs(ctx context.Context, orderIds []int64) (map[int64][]promotions.DiscountLine, error) {
+	query := `SELECT order_id, COALESCE(coupon_id, 0), code, type, COALESCE(description, ''), amount_cents
+	          FROM order_discounts
+	          WHERE order_id = ANY($1)
+	          ORDER BY order_id, id`
+
+	rows, err := db.DB.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := map[int64][]promotions.DiscountLine{}
+	for rows.Next() {
+		var orderId int64
+		var d promotions.DiscountLine
+		if err := rows.Scan(&orderId, &d.CouponID, &d.Code, &d.Type, &d.Description, &d.AmountCents); err != nil {
+			return nil, err
+		}
+		d.FreeShipping = d.Type == promotions.TypeFreeShipping
+		discounts[orderId] = append(discounts[orderId], d)
+	}
+	return discounts, rows.Err()
+}
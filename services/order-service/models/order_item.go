@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
@@ -19,13 +20,13 @@ type OrderItem struct {
 
 // GetOrderItems retrieves all items for a specific order with product details
 // used in: order.LoadItems
-func GetOrderItems(orderId int64) ([]OrderItem, error) {
+func GetOrderItems(ctx context.Context, orderId int64) ([]OrderItem, error) {
 	query := `SELECT id, order_id, product_id, quantity, price_cents, product_name, created_at, updated_at
 	          FROM order_items
 	          WHERE order_id=$1
 	          ORDER BY created_at DESC`
 
-	rows, err := db.DB.Query(db.Ctx, query, orderId)
+	rows, err := db.DB.Query(ctx, query, orderId)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +52,37 @@ func GetOrderItems(orderId int64) ([]OrderItem, error) {
 	return items, nil
 }
 
+// getItemsByOrderIDs retrieves the items of several orders in one query, keyed by order ID
+// used in: loadOrderDetails
+func getItemsByOrderIDs(ctx context.Context, orderIds []int64) (map[int64][]OrderItem, error) {
+	query := `SELECT id, order_id, product_id, quantity, price_cents, product_name, created_at, updated_at
+	          FROM order_items
+	          WHERE order_id = ANY($1)
+	          ORDER BY order_id, created_at DESC`
+
+	rows, err := db.DB.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := map[int64][]OrderItem{}
+	for rows.Next() {
+		var item OrderItem
+		err := rows.Scan(
+			&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.PriceCents,
+			&item.ProductName, &item.CreatedAt, &item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items[item.OrderID] = append(items[item.OrderID], item)
+	}
+	return items, rows.Err()
+}
+
 type CartItem struct {
+	CartID      int64  `db:"cart_id"`
 	ProductID   int64  `db:"product_id"`
 	Quantity    int    `db:"quantity"`
 	ProductName string `db:"product_name"`
@@ -59,14 +90,24 @@ type CartItem struct {
 
 // GetCartItemsForUser retrieves cart items for stock validation before creating an order
 // used in: handlers.CreateOrder
-func GetCartItemsForUser(userId int64) ([]CartItem, error) {
-	query := `SELECT ci.product_id, ci.quantity, p.name
+func GetCartItemsForUser(ctx context.Context, userId int64) ([]CartItem, error) {
+	return getActiveCartItems(ctx, `c.user_id=$1`, userId)
+}
+
+// GetCartItemsForGuestCart retrieves the items of an anonymous cart for stock validation before guest checkout
+// used in: handlers.CreateGuestOrder
+func GetCartItemsForGuestCart(ctx context.Context, cartId int64) ([]CartItem, error) {
+	return getActiveCartItems(ctx, `c.id=$1 AND c.user_id IS NULL`, cartId)
+}
+
+func getActiveCartItems(ctx context.Context, cartFilter string, cartArg any) ([]CartItem, error) {
+	query := `SELECT ci.cart_id, ci.product_id, ci.quantity, p.name
 	          FROM cart_items ci
 	          JOIN carts c ON ci.cart_id = c.id
 	          JOIN products p ON ci.product_id = p.id
-	          WHERE c.user_id=$1 AND c.status='active'`
+	          WHERE ` + cartFilter + ` AND c.status='active'`
 
-	rows, err := db.DB.Query(db.Ctx, query, userId)
+	rows, err := db.DB.Query(ctx, query, cartArg)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +116,7 @@ func GetCartItemsForUser(userId int64) ([]CartItem, error) {
 	var items []CartItem
 	for rows.Next() {
 		var item CartItem
-		err := rows.Scan(&item.ProductID, &item.Quantity, &item.ProductName)
+		err := rows.Scan(&item.CartID, &item.ProductID, &item.Quantity, &item.ProductName)
 		if err != nil {
 			return nil, err
 		}
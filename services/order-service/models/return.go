@@ -0,0 +1,485 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/returns"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOrderNotReturnable is returned when a return is requested for an order that has not been shipped
+var ErrOrderNotReturnable = errors.New("order cannot be returned in its current status")
+
+// ErrReturnStatus is returned when a return cannot move to the requested status
+var ErrReturnStatus = errors.New("return cannot change to this status")
+
+type Return struct {
+	ID          int64                `db:"id" json:"id" example:"1"`
+	OrderID     int64                `db:"order_id" json:"orderId" example:"1"`
+	UserID      int64                `db:"user_id" json:"userId" example:"1"`
+	Status      string               `db:"status" json:"status" example:"requested"`
+	RefundCents int                  `db:"refund_cents" json:"refundCents" example:"2999"`
+	RefundID    *int64               `db:"refund_id" json:"refundId,omitempty" example:"1"`
+	Note        *string              `db:"note" json:"note,omitempty" example:"Please use the enclosed return label"`
+	CreatedAt   time.Time            `db:"created_at" json:"createdAt"`
+	UpdatedAt   *time.Time           `db:"updated_at" json:"updatedAt,omitempty"`
+	Items       []ReturnItem         `json:"items"`
+	History     []ReturnStatusChange `json:"history,omitempty"`
+}
+
+type ReturnItem struct {
+	ID               int64      `db:"id" json:"id" example:"1"`
+	OrderItemID      int64      `db:"order_item_id" json:"orderItemId" example:"1"`
+	ProductID        int64      `json:"productId" example:"1"`
+	ProductName      string     `json:"productName" example:"Gaming Laptop"`
+	PriceCents       int        `json:"priceCents" example:"2999"`
+	Quantity         int        `db:"quantity" json:"quantity" example:"1"`
+	Reason           string     `db:"reason" json:"reason" example:"damaged"`
+	Comment          *string    `db:"comment" json:"comment,omitempty" example:"Screen arrived cracked"`
+	AcceptedQuantity *int       `db:"accepted_quantity" json:"acceptedQuantity,omitempty" example:"1"`
+	Condition        *string    `db:"condition" json:"condition,omitempty" example:"resellable"`
+	Restock          bool       `db:"restock" json:"restock" example:"true"`
+	RestockedAt      *time.Time `db:"restocked_at" json:"restockedAt,omitempty"`
+}
+
+type ReturnStatusChange struct {
+	FromStatus  *string   `db:"from_status" json:"fromStatus,omitempty" example:"requested"`
+	ToStatus    string    `db:"to_status" json:"toStatus" example:"approved"`
+	Note        *string   `db:"note" json:"note,omitempty"`
+	ActorUserID *int64    `db:"actor_user_id" json:"actorUserId,omitempty" example:"1"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
+
+type ReturnItemRequest struct {
+	OrderItemID int64   `json:"orderItemId" example:"1" binding:"required"`
+	Quantity    int     `json:"quantity" example:"1" binding:"required,min=1"`
+	Reason      string  `json:"reason" example:"damaged" binding:"required,oneof=damaged wrong_item not_as_described no_longer_needed other"`
+	Comment     *string `json:"comment" example:"Screen arrived cracked"`
+}
+
+type CreateReturnRequest struct {
+	Items []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReturnDecisionRequest struct {
+	// Shown to the customer, e.g. return instructions or the rejection reason
+	Note *string `json:"note" example:"Please use the enclosed return label"`
+}
+
+type InspectionItemRequest struct {
+	OrderItemID int64 `json:"orderItemId" example:"1" binding:"required"`
+	// Accepted quantity; omitted accepts the full returned quantity
+	AcceptedQuantity *int   `json:"acceptedQuantity" example:"1" binding:"omitempty,min=0"`
+	Condition        string `json:"condition" example:"resellable" binding:"omitempty,oneof=resellable opened damaged"`
+	// Put accepted items back into stock; omitted restocks resellable items only
+	Restock *bool `json:"restock" example:"true"`
+}
+
+type InspectionRequest struct {
+	// Inspection results per returned order item; items not listed are accepted in full as resellable
+	Items []InspectionItemRequest `json:"items" binding:"dive"`
+	Note  *string                 `json:"note" example:"Packaging opened, item unused"`
+}
+
+const returnColumns = `id, order_id, user_id, status, refund_cents, refund_id, note, created_at, updated_at`
+
+func scanReturn(row pgx.Row, r *Return) error {
+	return row.Scan(&r.ID, &r.OrderID, &r.UserID, &r.Status, &r.RefundCents, &r.RefundID, &r.Note, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// queryReturns runs a query selecting returnColumns and loads the items of every return
+func queryReturns(ctx context.Context, query string, args ...any) ([]Return, error) {
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Return{}
+	for rows.Next() {
+		var r Return
+		if err := scanReturn(rows, &r); err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		if err := list[i].loadItems(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// GetReturnByID retrieves a return including items and status history
+// used in: handlers.AdminGetReturn, handlers.ApproveReturn, handlers.RejectReturn, handlers.ReceiveReturn, handlers.RefundReturn
+func GetReturnByID(ctx context.Context, returnId int64) (*Return, error) {
+	r := &Return{}
+	query := `SELECT ` + returnColumns + ` FROM returns WHERE id=$1`
+	if err := scanReturn(db.DB.QueryRow(ctx, query, returnId), r); err != nil {
+		return nil, err
+	}
+	if err := r.loadItems(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.loadHistory(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetUserReturn retrieves a return of a customer including items and status history
+// used in: handlers.GetReturn
+func GetUserReturn(ctx context.Context, returnId, userId int64) (*Return, error) {
+	r, err := GetReturnByID(ctx, returnId)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userId {
+		return nil, pgx.ErrNoRows
+	}
+	return r, nil
+}
+
+// GetOrderReturns retrieves all returns of an order including items
+// used in: handlers.ListOrderReturns
+func GetOrderReturns(ctx context.Context, orderId int64) ([]Return, error) {
+	return queryReturns(ctx, `SELECT `+returnColumns+` FROM returns WHERE order_id=$1 ORDER BY created_at DESC`, orderId)
+}
+
+// GetReturns retrieves all returns, optionally filtered by status, oldest first so the queue is worked in order
+// used in: handlers.AdminListReturns
+func GetReturns(ctx context.Context, status string) ([]Return, error) {
+	if status != "" {
+		return queryReturns(ctx, `SELECT `+returnColumns+` FROM returns WHERE status=$1 ORDER BY created_at`, status)
+	}
+	return queryReturns(ctx, `SELECT `+returnColumns+` FROM returns ORDER BY created_at`)
+}
+
+func (r *Return) loadItems(ctx context.Context) error {
+	rows, err := db.DB.Query(ctx, `
+		SELECT ri.id, ri.order_item_id, oi.product_id, oi.product_name, oi.price_cents, ri.quantity, ri.reason, ri.comment,
+		       ri.accepted_quantity, ri.condition, ri.restock, ri.restocked_at
+		FROM return_items ri
+		JOIN order_items oi ON oi.id = ri.order_item_id
+		WHERE ri.return_id=$1
+		ORDER BY ri.id`, r.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	r.Items = []ReturnItem{}
+	for rows.Next() {
+		var item ReturnItem
+		err := rows.Scan(&item.ID, &item.OrderItemID, &item.ProductID, &item.ProductName, &item.PriceCents, &item.Quantity,
+			&item.Reason, &item.Comment, &item.AcceptedQuantity, &item.Condition, &item.Restock, &item.RestockedAt)
+		if err != nil {
+			return err
+		}
+		r.Items = append(r.Items, item)
+	}
+	return rows.Err()
+}
+
+func (r *Return) loadHistory(ctx context.Context) error {
+	rows, err := db.DB.Query(ctx, `
+		SELECT from_status, to_status, note, actor_user_id, created_at
+		FROM return_status_history
+		WHERE return_id=$1
+		ORDER BY created_at, id`, r.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	r.History = []ReturnStatusChange{}
+	for rows.Next() {
+		var change ReturnStatusChange
+		if err := rows.Scan(&change.FromStatus, &change.ToStatus, &change.Note, &change.ActorUserID, &change.CreatedAt); err != nil {
+			return err
+		}
+		r.History = append(r.History, change)
+	}
+	return rows.Err()
+}
+
+// returnedQuantities returns shipped quantities per order item and the quantities of returns that were not
+// rejected (accepted quantities once inspected)
+func returnedQuantities(ctx context.Context, tx pgx.Tx, orderId int64) (shipped, returned map[int64]int, err error) {
+	shipped = map[int64]int{}
+	returned = map[int64]int{}
+
+	rows, err := tx.Query(ctx, `
+		SELECT si.order_item_id, SUM(si.quantity)
+		FROM shipment_items si
+		JOIN shipments s ON s.id = si.shipment_id
+		WHERE s.order_id=$1
+		GROUP BY si.order_item_id`, orderId)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var itemId int64
+		var qty int
+		if err := rows.Scan(&itemId, &qty); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		shipped[itemId] = qty
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	returnRows, err := tx.Query(ctx, `
+		SELECT ri.order_item_id, SUM(COALESCE(ri.accepted_quantity, ri.quantity))
+		FROM return_items ri
+		JOIN returns r ON r.id = ri.return_id
+		WHERE r.order_id=$1 AND r.status<>$2
+		GROUP BY ri.order_item_id`, orderId, returns.StatusRejected)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer returnRows.Close()
+	for returnRows.Next() {
+		var itemId int64
+		var qty int
+		if err := returnRows.Scan(&itemId, &qty); err != nil {
+			return nil, nil, err
+		}
+		returned[itemId] = qty
+	}
+	return shipped, returned, returnRows.Err()
+}
+
+// addReturnHistory records a status change of a return
+func addReturnHistory(ctx context.Context, tx pgx.Tx, returnId int64, from *string, to string, note *string, actorUserId *int64) error {
+	_, err := tx.Exec(ctx, `INSERT INTO return_status_history (return_id, from_status, to_status, note, actor_user_id, created_at)
+	                           VALUES ($1, $2, $3, $4, $5, now())`, returnId, from, to, note, actorUserId)
+	return err
+}
+
+// CreateReturn requests a return of shipped order lines for the customer. Returns a *returns.ReturnError for
+// quantities that were not shipped or are already being returned and ErrOrderNotReturnable for unshipped orders.
+// used in: handlers.CreateReturn
+func (o *Order) CreateReturn(ctx context.Context, userId int64, req CreateReturnRequest) (*Return, error) {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, o.ID).Scan(&status); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(returns.ReturnableOrderStatuses, status) {
+		return nil, ErrOrderNotReturnable
+	}
+
+	shipped, returned, err := returnedQuantities(ctx, tx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	requested := map[int64]int{}
+	for _, item := range req.Items {
+		requested[item.OrderItemID] += item.Quantity
+	}
+	if err := returns.CheckRequest(shipped, returned, requested); err != nil {
+		return nil, err
+	}
+
+	var returnId int64
+	err = tx.QueryRow(ctx, `INSERT INTO returns (order_id, user_id, status, created_at) VALUES ($1, $2, $3, now()) RETURNING id`,
+		o.ID, userId, returns.StatusRequested).Scan(&returnId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range req.Items {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO return_items (return_id, order_item_id, quantity, reason, comment) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (return_id, order_item_id) DO UPDATE SET quantity = return_items.quantity + EXCLUDED.quantity`,
+			returnId, item.OrderItemID, item.Quantity, item.Reason, item.Comment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := addReturnHistory(ctx, tx, returnId, nil, returns.StatusRequested, nil, &userId); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetReturnByID(ctx, returnId)
+}
+
+// transition locks the return, checks the status change and records it in the status history
+func (r *Return) transition(ctx context.Context, tx pgx.Tx, to string, note *string, actorUserId *int64) error {
+	var from string
+	if err := tx.QueryRow(ctx, `SELECT status FROM returns WHERE id=$1 FOR UPDATE`, r.ID).Scan(&from); err != nil {
+		return err
+	}
+	if !returns.CanTransition(from, to) {
+		return ErrReturnStatus
+	}
+
+	err := tx.QueryRow(ctx, `UPDATE returns SET status=$1, note=COALESCE($2, note), updated_at=now() WHERE id=$3 RETURNING note, updated_at`,
+		to, note, r.ID).Scan(&r.Note, &r.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := addReturnHistory(ctx, tx, r.ID, &from, to, note, actorUserId); err != nil {
+		return err
+	}
+	r.Status = to
+	return nil
+}
+
+// SetStatus moves the return to a new status (approved, rejected) and records who did it.
+// Returns ErrReturnStatus for status changes the workflow does not allow.
+// used in: handlers.ApproveReturn, handlers.RejectReturn
+func (r *Return) SetStatus(ctx context.Context, status string, note *string, actorUserId int64) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.transition(ctx, tx, status, note, &actorUserId); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return r.loadHistory(ctx)
+}
+
+// Receive records the inspection of the returned items of an approved return and computes the refund for the
+// accepted quantities (item price minus the order discount share, shipping is not refunded).
+// Returns a *returns.ReturnError for invalid inspection results and ErrReturnStatus if the return is not approved.
+// used in: handlers.ReceiveReturn
+func (r *Return) Receive(ctx context.Context, order *Order, req InspectionRequest, actorUserId int64) error {
+	inspections := map[int64]InspectionItemRequest{}
+	requested := map[int64]int{}
+	accepted := map[int64]int{}
+	for _, item := range r.Items {
+		requested[item.OrderItemID] = item.Quantity
+		accepted[item.OrderItemID] = item.Quantity
+	}
+	for _, inspection := range req.Items {
+		inspections[inspection.OrderItemID] = inspection
+		if inspection.AcceptedQuantity != nil {
+			accepted[inspection.OrderItemID] = *inspection.AcceptedQuantity
+		}
+	}
+	if err := returns.CheckInspection(requested, accepted); err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.transition(ctx, tx, returns.StatusReceived, req.Note, &actorUserId); err != nil {
+		return err
+	}
+
+	lineCents := 0
+	for _, item := range r.Items {
+		inspection := inspections[item.OrderItemID]
+		condition := inspection.Condition
+		if condition == "" {
+			condition = "resellable"
+		}
+		restock := condition == "resellable"
+		if inspection.Restock != nil {
+			restock = *inspection.Restock
+		}
+		qty := accepted[item.OrderItemID]
+		lineCents += item.PriceCents * qty
+
+		_, err = tx.Exec(ctx, `UPDATE return_items SET accepted_quantity=$1, condition=$2, restock=$3 WHERE id=$4`,
+			qty, condition, restock && qty > 0, item.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	refundCents := returns.RefundCents(lineCents, order.SubtotalCents, order.DiscountCents)
+	if _, err = tx.Exec(ctx, `UPDATE returns SET refund_cents=$1 WHERE id=$2`, refundCents, r.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	r.RefundCents = refundCents
+	if err := r.loadItems(ctx); err != nil {
+		return err
+	}
+	return r.loadHistory(ctx)
+}
+
+// PendingRestocks returns the received items that should go back into stock but have not been restocked yet
+// used in: handlers.restockReturn
+func (r *Return) PendingRestocks() []ReturnItem {
+	var pending []ReturnItem
+	for _, item := range r.Items {
+		if item.Restock && item.RestockedAt == nil && item.AcceptedQuantity != nil && *item.AcceptedQuantity > 0 {
+			pending = append(pending, item)
+		}
+	}
+	return pending
+}
+
+// MarkRestocked records that a returned item was put back into stock
+// used in: handlers.restockReturn
+func (r *Return) MarkRestocked(ctx context.Context, itemId int64) error {
+	_, err := db.DB.Exec(ctx, `UPDATE return_items SET restocked_at=now() WHERE id=$1 AND return_id=$2`, itemId, r.ID)
+	if err != nil {
+		return err
+	}
+	return r.loadItems(ctx)
+}
+
+// Complete finishes a received return: refunded with the payment-service refund, or closed if nothing was accepted
+// used in: handlers.ReceiveReturn, handlers.RefundReturn
+func (r *Return) Complete(ctx context.Context, refundId *int64, actorUserId int64) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status := returns.StatusRefunded
+	if r.RefundCents == 0 {
+		status = returns.StatusClosed
+	}
+	if err := r.transition(ctx, tx, status, nil, &actorUserId); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `UPDATE returns SET refund_id=$1 WHERE id=$2`, refundId, r.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	r.RefundID = refundId
+	return r.loadHistory(ctx)
+}
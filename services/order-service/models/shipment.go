@@ -0,0 +1,405 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/fulfillment"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOrderNotShippable is returned when shipments are created for orders that are not paid yet or cancelled
+var ErrOrderNotShippable = errors.New("order cannot be shipped in its current status")
+
+// ErrOrderNotFullyShipped is returned when an order is marked delivered while items are not shipped yet
+var ErrOrderNotFullyShipped = errors.New("order has items that are not shipped yet")
+
+type Shipment struct {
+	ID             int64          `db:"id" json:"id" example:"1"`
+	OrderID        int64          `db:"order_id" json:"orderId" example:"1"`
+	Carrier        string         `db:"carrier" json:"carrier" example:"dhl"`
+	CarrierName    string         `json:"carrierName" example:"DHL"`
+	TrackingNumber *string        `db:"tracking_number" json:"trackingNumber,omitempty" example:"00340434161094042557"`
+	TrackingURL    *string        `db:"tracking_url" json:"trackingUrl,omitempty" example:"https://www.dhl.com/de-en/home/tracking/tracking-parcel.html?tracking-id=00340434161094042557"`
+	Status         string         `db:"status" json:"status" example:"shipped"`
+	ShippedAt      time.Time      `db:"shipped_at" json:"shippedAt"`
+	DeliveredAt    *time.Time     `db:"delivered_at" json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time      `db:"created_at" json:"createdAt" swaggerignore:"true"`
+	UpdatedAt      *time.Time     `db:"updated_at" json:"updatedAt,omitempty" swaggerignore:"true"`
+	Items          []ShipmentItem `json:"items"`
+}
+
+type ShipmentItem struct {
+	OrderItemID int64  `db:"order_item_id" json:"orderItemId" example:"1"`
+	ProductID   int64  `json:"productId" example:"1"`
+	ProductName string `json:"productName" example:"Gaming Laptop"`
+	Quantity    int    `db:"quantity" json:"quantity" example:"1"`
+}
+
+type ShipmentItemRequest struct {
+	OrderItemID int64 `json:"orderItemId" example:"1" binding:"required"`
+	Quantity    int   `json:"quantity" example:"1" binding:"required,min=1"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        string  `json:"carrier" example:"dhl" binding:"required"`
+	TrackingNumber *string `json:"trackingNumber" example:"00340434161094042557"`
+	// Overrides the carrier's tracking page, e.g. for carrier "other"
+	TrackingURL *string `json:"trackingUrl" example:"https://tracking.example.com/00340434161094042557"`
+	// Items and quantities in this parcel; empty ships everything not shipped yet
+	Items []ShipmentItemRequest `json:"items"`
+}
+
+type UpdateShipmentRequest struct {
+	Status         string  `json:"status" example:"delivered" binding:"required,oneof=shipped in_transit delivered"`
+	TrackingNumber *string `json:"trackingNumber" example:"00340434161094042557"`
+	TrackingURL    *string `json:"trackingUrl" example:"https://tracking.example.com/00340434161094042557"`
+}
+
+const shipmentColumns = `id, order_id, carrier, tracking_number, tracking_url, status, shipped_at, delivered_at, created_at, updated_at`
+
+func scanShipment(row pgx.Row, s *Shipment) error {
+	err := row.Scan(&s.ID, &s.OrderID, &s.Carrier, &s.TrackingNumber, &s.TrackingURL, &s.Status,
+		&s.ShippedAt, &s.DeliveredAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.CarrierName = s.Carrier
+	if c, ok := fulfillment.LookupCarrier(s.Carrier); ok {
+		s.CarrierName = c.Name
+	}
+	return nil
+}
+
+// trackingURL returns the explicit tracking URL or the carrier's tracking page for the tracking number
+func trackingURL(carrier fulfillment.Carrier, trackingNumber, explicit *string) *string {
+	if explicit != nil && *explicit != "" {
+		return explicit
+	}
+	if trackingNumber == nil {
+		return nil
+	}
+	if link := carrier.TrackingLink(*trackingNumber); link != "" {
+		return &link
+	}
+	return nil
+}
+
+// GetOrderShipments retrieves all shipments of an order including shipped items
+// used in: order.LoadShipments
+func GetOrderShipments(ctx context.Context, orderId int64) ([]Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE order_id=$1 ORDER BY shipped_at, id`
+	rows, err := db.DB.Query(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []Shipment{}
+	index := map[int64]int{}
+	for rows.Next() {
+		var s Shipment
+		if err := scanShipment(rows, &s); err != nil {
+			return nil, err
+		}
+		s.Items = []ShipmentItem{}
+		index[s.ID] = len(shipments)
+		shipments = append(shipments, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return shipments, nil
+	}
+
+	itemRows, err := db.DB.Query(ctx, `
+		SELECT si.shipment_id, si.order_item_id, oi.product_id, oi.product_name, si.quantity
+		FROM shipment_items si
+		JOIN order_items oi ON oi.id = si.order_item_id
+		JOIN shipments s ON s.id = si.shipment_id
+		WHERE s.order_id=$1
+		ORDER BY si.id`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var shipmentId int64
+		var item ShipmentItem
+		if err := itemRows.Scan(&shipmentId, &item.OrderItemID, &item.ProductID, &item.ProductName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		if i, ok := index[shipmentId]; ok {
+			shipments[i].Items = append(shipments[i].Items, item)
+		}
+	}
+	return shipments, itemRows.Err()
+}
+
+// GetShipmentByID retrieves a shipment including items
+// used in: handlers.UpdateShipment
+func GetShipmentByID(ctx context.Context, shipmentId int64) (*Shipment, error) {
+	s := &Shipment{}
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE id=$1`
+	if err := scanShipment(db.DB.QueryRow(ctx, query, shipmentId), s); err != nil {
+		return nil, err
+	}
+
+	shipments, err := GetOrderShipments(ctx, s.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range shipments {
+		if other.ID == s.ID {
+			s.Items = other.Items
+		}
+	}
+	return s, nil
+}
+
+// LoadShipments loads the shipments of an order for the tracking view
+// used in: GetOrderByID, GetOrderByIDInternal, GetGuestOrderByID
+func (o *Order) LoadShipments(ctx context.Context) error {
+	shipments, err := GetOrderShipments(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	o.Shipments = shipments
+	return nil
+}
+
+// shippedQuantities returns ordered and already shipped quantities per order item and the shipment statuses
+func shippedQuantities(ctx context.Context, tx pgx.Tx, orderId int64) (ordered, shipped map[int64]int, statuses []string, err error) {
+	ordered = map[int64]int{}
+	shipped = map[int64]int{}
+
+	rows, err := tx.Query(ctx, `
+		SELECT oi.id, oi.quantity, COALESCE(SUM(si.quantity), 0)
+		FROM order_items oi
+		LEFT JOIN shipment_items si ON si.order_item_id = oi.id
+		WHERE oi.order_id=$1
+		GROUP BY oi.id`, orderId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for rows.Next() {
+		var itemId int64
+		var qty, shippedQty int
+		if err := rows.Scan(&itemId, &qty, &shippedQty); err != nil {
+			rows.Close()
+			return nil, nil, nil, err
+		}
+		ordered[itemId] = qty
+		shipped[itemId] = shippedQty
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	statusRows, err := tx.Query(ctx, `SELECT status FROM shipments WHERE order_id=$1`, orderId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer statusRows.Close()
+	for statusRows.Next() {
+		var status string
+		if err := statusRows.Scan(&status); err != nil {
+			return nil, nil, nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return ordered, shipped, statuses, statusRows.Err()
+}
+
+// syncFulfilmentStatus derives the order status from its shipments and stores it
+func syncFulfilmentStatus(ctx context.Context, tx pgx.Tx, orderId int64) (string, error) {
+	ordered, shipped, statuses, err := shippedQuantities(ctx, tx, orderId)
+	if err != nil {
+		return "", err
+	}
+
+	status := fulfillment.OrderStatus(ordered, shipped, statuses)
+	if status == "" {
+		return "", nil
+	}
+	_, err = tx.Exec(ctx, `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2 AND status<>$1`, status, orderId)
+	return status, err
+}
+
+// lockShippableOrder locks the order row and checks that it is paid and not cancelled
+func lockShippableOrder(ctx context.Context, tx pgx.Tx, orderId int64) error {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, orderId).Scan(&status)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case "confirmed", fulfillment.OrderPartiallyShipped, fulfillment.OrderShipped, fulfillment.OrderDelivered:
+		return nil
+	default:
+		return ErrOrderNotShippable
+	}
+}
+
+// CreateShipment records a parcel for the order and derives the order status (partially_shipped, shipped).
+// Without items everything not shipped yet goes into the parcel. Returns a *fulfillment.ShipmentError for
+// invalid carriers or quantities and ErrOrderNotShippable for pending or cancelled orders.
+// used in: handlers.CreateShipment
+func (o *Order) CreateShipment(ctx context.Context, req CreateShipmentRequest) (*Shipment, error) {
+	carrier, ok := fulfillment.LookupCarrier(req.Carrier)
+	if !ok {
+		return nil, &fulfillment.ShipmentError{Reason: "unknown carrier " + req.Carrier}
+	}
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockShippableOrder(ctx, tx, o.ID); err != nil {
+		return nil, err
+	}
+
+	ordered, shipped, _, err := shippedQuantities(ctx, tx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := map[int64]int{}
+	for _, item := range req.Items {
+		requested[item.OrderItemID] += item.Quantity
+	}
+	if len(req.Items) == 0 {
+		requested = fulfillment.Remaining(ordered, shipped)
+	}
+	if err := fulfillment.CheckShipment(ordered, shipped, requested); err != nil {
+		return nil, err
+	}
+
+	s := &Shipment{}
+	query := `INSERT INTO shipments (order_id, carrier, tracking_number, tracking_url, status, shipped_at, created_at)
+	          VALUES ($1, $2, $3, $4, $5, now(), now())
+	          RETURNING ` + shipmentColumns
+	err = scanShipment(tx.QueryRow(ctx, query, o.ID, carrier.Code, req.TrackingNumber,
+		trackingURL(carrier, req.TrackingNumber, req.TrackingURL), fulfillment.StatusShipped), s)
+	if err != nil {
+		return nil, err
+	}
+
+	for itemId, qty := range requested {
+		_, err = tx.Exec(ctx, `INSERT INTO shipment_items (shipment_id, order_item_id, quantity) VALUES ($1, $2, $3)`, s.ID, itemId, qty)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := syncFulfilmentStatus(ctx, tx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	o.Status = status
+	shipments, err := GetOrderShipments(ctx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	o.Shipments = shipments
+	for _, other := range shipments {
+		if other.ID == s.ID {
+			s.Items = other.Items
+		}
+	}
+	return s, nil
+}
+
+// Update sets the carrier status and tracking details of a shipment and derives the order status
+// (delivered once every parcel of a fully shipped order is delivered)
+// used in: handlers.UpdateShipment
+func (s *Shipment) Update(ctx context.Context, req UpdateShipmentRequest) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockShippableOrder(ctx, tx, s.OrderID); err != nil {
+		return err
+	}
+
+	if req.TrackingNumber != nil {
+		s.TrackingNumber = req.TrackingNumber
+		s.TrackingURL = nil
+	}
+	if req.TrackingURL != nil || req.TrackingNumber != nil {
+		carrier, _ := fulfillment.LookupCarrier(s.Carrier)
+		s.TrackingURL = trackingURL(carrier, s.TrackingNumber, req.TrackingURL)
+	}
+
+	query := `UPDATE shipments
+	          SET status=$1, tracking_number=$2, tracking_url=$3,
+	              delivered_at=CASE WHEN $1='delivered' THEN COALESCE(delivered_at, now()) ELSE NULL END,
+	              updated_at=now()
+	          WHERE id=$4
+	          RETURNING delivered_at, updated_at`
+	err = tx.QueryRow(ctx, query, req.Status, s.TrackingNumber, s.TrackingURL, s.ID).Scan(&s.DeliveredAt, &s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.Status = req.Status
+
+	if _, err = syncFulfilmentStatus(ctx, tx, s.OrderID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkDelivered marks every shipment of a fully shipped order as delivered, which makes the order delivered.
+// Returns ErrOrderNotFullyShipped while items are not shipped yet and ErrOrderNotShippable for unpaid or cancelled orders.
+// used in: handlers.AdminDeliverOrder
+func (o *Order) MarkDelivered(ctx context.Context) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockShippableOrder(ctx, tx, o.ID); err != nil {
+		return err
+	}
+
+	ordered, shipped, statuses, err := shippedQuantities(ctx, tx, o.ID)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 || len(fulfillment.Remaining(ordered, shipped)) > 0 {
+		return ErrOrderNotFullyShipped
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE shipments SET status=$1, delivered_at=now(), updated_at=now() WHERE order_id=$2 AND status<>$1`,
+		fulfillment.StatusDelivered, o.ID)
+	if err != nil {
+		return err
+	}
+
+	status, err := syncFulfilmentStatus(ctx, tx, o.ID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	o.Status = status
+	return o.LoadShipments(ctx)
+}
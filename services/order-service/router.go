@@ -2,15 +2,20 @@ package main
 
 import (
 	"os"
+	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/idempotency"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
 	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
 	"rearatrox/go-ecommerce-backend/services/order-service/handlers"
 	"strings"
+	"time"
 
 	docs "rearatrox/go-ecommerce-backend/services/order-service/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -19,16 +24,14 @@ import (
 const DEFAULT_PORT = "8084"
 
 func RegisterRoutes(router *gin.Engine) {
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
 	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
 	router.NoRoute(func(c *gin.Context) {
 		log := logger.FromContext(c.Request.Context())
 		log.Warn("unknown route")
@@ -48,16 +51,38 @@ func RegisterRoutes(router *gin.Engine) {
 	docs.SwaggerInfo.Host = "localhost:" + port
 	docs.SwaggerInfo.BasePath = apiPrefix
 
+	// replays responses of retried requests sent with an Idempotency-Key header
+	idempotent := idempotency.Middleware(idempotency.NewPostgresStore(db.DB))
+
+	// client IPs of guests are taken from X-Forwarded-For only if set by the gateway (TRUSTED_PROXIES)
+	if err := ratelimit.TrustProxiesFromEnv(router, "TRUSTED_PROXIES"); err != nil {
+		panic(err.Error())
+	}
+
+	// per user limit of checkouts, cancellations and returns, guests per client IP (RATE_LIMIT_ORDERS)
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		panic(err.Error())
+	}
+	ordersLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "orders", Limit: ratelimit.Limit{Requests: 20, Period: time.Minute}, Key: ratelimit.ByUser})
+
 	api := router.Group(apiPrefix)
 	{
 		// make sure the swagger UI knows where to fetch the generated spec
 		api.GET("/orders/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-		// Internal endpoints (service-to-service communication with secret)
+		// Internal endpoints (service-to-service communication, signed by the calling services named per route)
 		internal := api.Group("/internal")
-		internal.Use(serviceauth.InternalAuth())
 		{
-			internal.PATCH("/orders/:id/status", handlers.InternalUpdateOrderStatus)
+			internal.GET("/orders/:id", serviceauth.InternalAuth("payment-service"), handlers.InternalGetOrder)
+			internal.PATCH("/orders/:id/status", serviceauth.InternalAuth("payment-service"), handlers.InternalUpdateOrderStatus)
+		}
+
+		// Guest checkout (authorized by signed cart and order tokens)
+		guest := api.Group("/guest")
+		{
+			guest.POST("/orders", ordersLimit, idempotent, handlers.CreateGuestOrder)
+			guest.GET("/orders/:id", handlers.GetGuestOrder)
 		}
 
 		// All order routes require authentication
@@ -65,11 +90,52 @@ func RegisterRoutes(router *gin.Engine) {
 		authenticated.Use(middleware.Authenticate)
 		{
 			// Order endpoints
-			authenticated.POST("/orders", handlers.CreateOrder)
+			authenticated.POST("/orders", ordersLimit, idempotent, handlers.CreateOrder)
 			authenticated.GET("/orders", handlers.ListOrders)
 			authenticated.GET("/orders/:id", handlers.GetOrder)
-			authenticated.PATCH("/orders/:id/status", handlers.UpdateOrderStatus)
-			authenticated.PATCH("/orders/:id/cancel", handlers.CancelOrder)
+			authenticated.PATCH("/orders/:id/status", ordersLimit, handlers.UpdateOrderStatus)
+			authenticated.PATCH("/orders/:id/cancel", ordersLimit, handlers.CancelOrder)
+
+			// Returns
+			authenticated.POST("/orders/:id/returns", ordersLimit, handlers.CreateReturn)
+			authenticated.GET("/orders/:id/returns", handlers.ListOrderReturns)
+			authenticated.GET("/returns/:id", handlers.GetReturn)
+
+			// Invoices
+			authenticated.GET("/orders/:id/invoice", handlers.GetOrderInvoice)
+			authenticated.GET("/orders/:id/invoices", handlers.ListOrderInvoices)
+			authenticated.GET("/orders/:id/invoices/:number", handlers.GetOrderInvoiceDocument)
+
+			// admin-only
+			admin := authenticated.Group("/admin")
+			admin.Use(middleware.Authorize("admin"))
+			{
+				// Order console
+				admin.GET("/orders", handlers.AdminListOrders)
+				admin.GET("/orders/:id", handlers.AdminGetOrder)
+				admin.POST("/orders/:id/notes", handlers.AddOrderNote)
+				admin.POST("/orders/:id/ship", handlers.AdminShipOrder)
+				admin.POST("/orders/:id/deliver", handlers.AdminDeliverOrder)
+				admin.POST("/orders/:id/cancel", handlers.AdminCancelOrder)
+
+				// Fulfilment
+				admin.GET("/carriers", handlers.GetCarriers)
+				admin.GET("/orders/:id/shipments", handlers.GetOrderShipments)
+				admin.POST("/orders/:id/shipments", handlers.CreateShipment)
+				admin.PATCH("/shipments/:id", handlers.UpdateShipment)
+
+				// Returns
+				admin.GET("/returns", handlers.AdminListReturns)
+				admin.GET("/returns/:id", handlers.AdminGetReturn)
+				admin.POST("/returns/:id/approve", handlers.ApproveReturn)
+				admin.POST("/returns/:id/reject", handlers.RejectReturn)
+				admin.POST("/returns/:id/receive", handlers.ReceiveReturn)
+				admin.POST("/returns/:id/refund", handlers.RefundReturn)
+
+				// Invoices
+				admin.GET("/orders/:id/invoice", handlers.AdminGetOrderInvoice)
+				admin.GET("/orders/:id/invoices/:number", handlers.AdminGetOrderInvoiceDocument)
+			}
 		}
 	}
 }
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/paymentintent"
+)
+
+// CancelPayment cancels the payment intent of a pending payment at Stripe, marks the payment cancelled and cancels
+// its order in order-service. It returns models.ErrPaymentNotPending if the payment was completed or cancelled in the
+// meantime. A failed order update is logged only: the payment itself is cancelled.
+// used in: handlers.CancelMyPayment, handlers.CancelGuestPayment, jobs.PaymentExpiryJob
+func CancelPayment(ctx context.Context, payment *models.Payment, reason string) error {
+	l := logger.FromContext(ctx)
+
+	if payment.Status != "pending" {
+		return models.ErrPaymentNotPending
+	}
+
+	if payment.StripePaymentIntentID != nil {
+		if err := cancelPaymentIntent(ctx, *payment.StripePaymentIntentID, reason); err != nil {
+			return err
+		}
+	}
+
+	cancelled, err := models.CancelPending(ctx, payment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+	if !cancelled {
+		return models.ErrPaymentNotPending
+	}
+	payment.Status = "cancelled"
+	l.Info("payment cancelled", "payment_id", payment.ID, "order_id", payment.OrderID, "reason", reason)
+
+	cancelUnpaidOrder(ctx, payment)
+	return nil
+}
+
+// cancelUnpaidOrder cancels the order of a cancelled payment in order-service, which releases its coupon. Failures are
+// logged only: the payment itself is cancelled either way.
+// used in: handlers.CancelPayment, handlers.WebhookHandler, handlers.ReconcilePayment
+func cancelUnpaidOrder(ctx context.Context, payment *models.Payment) {
+	l := logger.FromContext(ctx)
+
+	if err := orderService.UpdateStatus(ctx, payment.OrderID, "cancelled"); err != nil {
+		l.Error("failed to cancel order", "order_id", payment.OrderID, "payment_id", payment.ID, "error", err)
+		return
+	}
+	l.Info("order cancelled", "order_id", payment.OrderID, "payment_id", payment.ID)
+}
+
+// cancelPaymentIntent cancels a payment intent at Stripe, with reason if not empty. An intent that is already
+// cancelled, e.g. in the Stripe dashboard, counts as cancelled; one that succeeded or is processing cannot be
+// cancelled anymore.
+func cancelPaymentIntent(ctx context.Context, paymentIntentID, reason string) error {
+	params := &stripe.PaymentIntentCancelParams{}
+	if reason != "" {
+		params.CancellationReason = stripe.String(reason)
+	}
+	params.Context = ctx
+	_, cancelErr := paymentintent.Cancel(paymentIntentID, params)
+	if cancelErr == nil {
+		return nil
+	}
+
+	getParams := &stripe.PaymentIntentParams{}
+	getParams.Context = ctx
+	pi, err := paymentintent.Get(paymentIntentID, getParams)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment intent: %w", cancelErr)
+	}
+	switch pi.Status {
+	case stripe.PaymentIntentStatusCanceled:
+		return nil
+	case stripe.PaymentIntentStatusSucceeded, stripe.PaymentIntentStatusProcessing:
+		return models.ErrPaymentNotPending
+	default:
+		return fmt.Errorf("failed to cancel payment intent: %w", cancelErr)
+	}
+}
+
+// respondCancelPayment cancels a payment whose ownership was verified and sends the result
+func respondCancelPayment(context *gin.Context, payment *models.Payment) {
+	l := logger.FromContext(context.Request.Context())
+
+	err := CancelPayment(context.Request.Context(), payment, models.CancelReasonCustomer)
+	if errors.Is(err, models.ErrPaymentNotPending) {
+		l.Warn("payment not pending", "payment_id", payment.ID, "status", payment.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": "only pending payments can be cancelled.", "status": payment.Status})
+		return
+	}
+	if err != nil {
+		l.Error("failed to cancel payment", "payment_id", payment.ID, "error", err)
+		context.JSON(http.StatusBadGateway, gin.H{"message": "could not cancel payment.", "error": err.Error()})
+		return
+	}
+
+	payment.StripeClientSecret = nil
+	context.JSON(http.StatusOK, payment)
+}
+
+// getPaymentParam loads the payment of the id path parameter and sends an error response if that fails
+func getPaymentParam(context *gin.Context) (*models.Payment, bool) {
+	l := logger.FromContext(context.Request.Context())
+
+	paymentID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid payment id", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid payment id."})
+		return nil, false
+	}
+
+	payment, err := models.GetByID(context.Request.Context(), paymentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		l.Warn("payment not found", "payment_id", paymentID)
+		context.JSON(http.StatusNotFound, gin.H{"message": "payment not found."})
+		return nil, false
+	}
+	if err != nil {
+		l.Error("failed to get payment", "payment_id", paymentID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not get payment.", "error": err.Error()})
+		return nil, false
+	}
+	return payment, true
+}
+
+// CancelMyPayment godoc
+// @Summary      Cancel payment
+// @Description  Cancel a pending payment of the authenticated user. The payment intent is cancelled at Stripe and the order is cancelled.
+// @Tags         Payments
+// @Produce      json
+// @Param        id   path      int  true  "Payment ID"
+// @Success      200  {object}  models.Payment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      502  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /payments/{id}/cancel [post]
+func CancelMyPayment(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+	l.Debug("CancelMyPayment called", "user_id", userId, "payment_id", context.Param("id"))
+
+	payment, ok := getPaymentParam(context)
+	if !ok {
+		return
+	}
+
+	if payment.UserID == nil || *payment.UserID != userId {
+		l.Warn("unauthorized payment access", "payment_id", payment.ID, "user_id", userId)
+		context.JSON(http.StatusForbidden, gin.H{"message": "access denied."})
+		return
+	}
+
+	respondCancelPayment(context, payment)
+}
+
+// CancelGuestPayment godoc
+// @Summary      Cancel guest payment
+// @Description  Cancel a pending payment of a guest order, authorized by the order token returned at guest checkout. The payment intent is cancelled at Stripe and the order is cancelled.
+// @Tags         Payments
+// @Produce      json
+// @Param        X-Order-Token  header    string  true  "Guest order token"
+// @Param        id             path      int     true  "Payment ID"
+// @Success      200            {object}  models.Payment
+// @Failure      400            {object}  map[string]interface{}
+// @Failure      401            {object}  map[string]interface{}
+// @Failure      404            {object}  map[string]interface{}
+// @Failure      409            {object}  map[string]interface{}
+// @Failure      502            {object}  map[string]interface{}
+// @Router       /guest/payments/{id}/cancel [post]
+func CancelGuestPayment(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CancelGuestPayment called", "payment_id", context.Param("id"))
+
+	payment, ok := getPaymentParam(context)
+	if !ok {
+		return
+	}
+
+	tokenOrderId, err := guesttoken.Verify(guesttoken.KindOrder, context.GetHeader(guesttoken.OrderHeader))
+	if err != nil || payment.UserID != nil || tokenOrderId != payment.OrderID {
+		l.Warn("invalid order token", "payment_id", payment.ID, "error", err)
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "valid order token required."})
+		return
+	}
+
+	respondCancelPayment(context, payment)
+}
+
+// InternalCancelOrderPayment godoc
+// @Summary      Cancel payment of a cancelled order (internal)
+// @Description  Cancel the payment intent of an order that was cancelled in order-service, so it cannot be paid anymore. Pending payments and failed ones still open for another attempt are cancelled; without such a payment nothing happens. Payments that are processing or succeeded cannot be cancelled.
+// @Tags         Payments
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {object}  models.Payment
+// @Success      204
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Failure      502  {object}  map[string]interface{}
+// @Router       /internal/orders/{id}/payment/cancel [post]
+func InternalCancelOrderPayment(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("InternalCancelOrderPayment called", "order_id", orderId)
+
+	payment, err := models.GetByOrderID(context.Request.Context(), orderId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		context.Status(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		l.Error("failed to get payment", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not get payment.", "error": err.Error()})
+		return
+	}
+
+	switch payment.Status {
+	case "pending", "failed":
+	case "cancelled":
+		context.Status(http.StatusNoContent)
+		return
+	default:
+		l.Warn("payment of cancelled order cannot be cancelled", "order_id", orderId, "payment_id", payment.ID, "status", payment.Status)
+		context.JSON(http.StatusConflict, gin.H{"message": "payment can no longer be cancelled.", "status": payment.Status})
+		return
+	}
+
+	// Stripe has no cancellation reason for orders cancelled by the shop
+	if payment.StripePaymentIntentID != nil {
+		err := cancelPaymentIntent(context.Request.Context(), *payment.StripePaymentIntentID, "")
+		if errors.Is(err, models.ErrPaymentNotPending) {
+			l.Warn("payment intent of cancelled order completed", "order_id", orderId, "payment_id", payment.ID)
+			context.JSON(http.StatusConflict, gin.H{"message": "payment can no longer be cancelled.", "status": payment.Status})
+			return
+		}
+		if err != nil {
+			l.Error("failed to cancel payment intent", "order_id", orderId, "payment_id", payment.ID, "error", err)
+			context.JSON(http.StatusBadGateway, gin.H{"message": "could not cancel payment.", "error": err.Error()})
+			return
+		}
+	}
+
+	updated, err := models.UpdateStatusFrom(context.Request.Context(), payment.ID, payment.Status, "cancelled")
+	if err != nil {
+		l.Error("failed to update payment status", "payment_id", payment.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update payment.", "error": err.Error()})
+		return
+	}
+	if !updated {
+		l.Warn("payment changed while cancelling", "order_id", orderId, "payment_id", payment.ID)
+		context.JSON(http.StatusConflict, gin.H{"message": "payment changed in the meantime, try again."})
+		return
+	}
+	payment.Status = "cancelled"
+
+	l.Info("payment of cancelled order cancelled", "order_id", orderId, "payment_id", payment.ID)
+	payment.StripeClientSecret = nil
+	context.JSON(http.StatusOK, payment)
+}
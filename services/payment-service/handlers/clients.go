@@ -0,0 +1,12 @@
+package handlers
+
+import "rearatrox/go-ecommerce-backend/pkg/clients"
+
+// orderService provides the orders to pay and receives their status changes
+var orderService *clients.OrderGRPCClient
+
+// UseClients sets the clients of the other services the handlers call; main builds them and calls it before the
+// routes are registered and the jobs are started
+func UseClients(order *clients.OrderGRPCClient) {
+	orderService = order
+}
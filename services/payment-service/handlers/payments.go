@@ -2,15 +2,23 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
+	ordermodels "rearatrox/go-ecommerce-backend/services/order-service/models"
 	"rearatrox/go-ecommerce-backend/services/payment-service/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/paymentintent"
 	"github.com/stripe/stripe-go/v81/webhook"
@@ -32,11 +40,13 @@ type CreatePaymentIntentResponse struct {
 // @Tags         Payments
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key  header    string                      false  "Retries with the same key replay the first response"
 // @Param        request  body      CreatePaymentIntentRequest  true  "Order ID"
 // @Success      201      {object}  CreatePaymentIntentResponse
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      401      {object}  map[string]interface{}
 // @Failure      409      {object}  map[string]interface{}
+// @Failure      422      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /payment-intents [post]
@@ -52,43 +62,95 @@ func CreatePaymentIntent(context *gin.Context) {
 		return
 	}
 
-	// Get JWT token from request header
-	jwtToken := context.GetHeader("Authorization")
-	if jwtToken == "" {
-		l.Error("missing authorization token", "order_id", req.OrderID)
-		context.JSON(http.StatusUnauthorized, gin.H{"message": "authorization required."})
+	// Get order details from order-service FIRST to verify ownership
+	order, err := orderService.GetOrder(context.Request.Context(), req.OrderID)
+	if err != nil {
+		l.Error("failed to get order details", "order_id", req.OrderID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch order details.", "error": err.Error()})
 		return
 	}
 
-	// Get order details from order-service FIRST to verify ownership
-	order, err := getOrderDetails(req.OrderID, jwtToken)
+	// Verify user owns this order BEFORE doing anything else
+	if order.UserID == nil || *order.UserID != userId {
+		l.Warn("unauthorized order access", "order_id", req.OrderID, "user_id", userId)
+		context.JSON(http.StatusForbidden, gin.H{"message": "access denied."})
+		return
+	}
+
+	createPaymentIntentForOrder(context, order, &userId)
+}
+
+// CreateGuestPaymentIntent godoc
+// @Summary      Create payment intent for guest order
+// @Description  Creates a Stripe Payment Intent for a guest order, authorized by the order token returned at guest checkout
+// @Tags         Payments
+// @Accept       json
+// @Produce      json
+// @Param        X-Order-Token  header    string                      true  "Guest order token"
+// @Param        Idempotency-Key  header    string                      false  "Retries with the same key replay the first response"
+// @Param        request        body      CreatePaymentIntentRequest  true  "Order ID"
+// @Success      201            {object}  CreatePaymentIntentResponse
+// @Failure      400            {object}  map[string]interface{}
+// @Failure      401            {object}  map[string]interface{}
+// @Failure      403            {object}  map[string]interface{}
+// @Failure      409            {object}  map[string]interface{}
+// @Failure      422            {object}  map[string]interface{}
+// @Failure      500            {object}  map[string]interface{}
+// @Router       /guest/payment-intents [post]
+func CreateGuestPaymentIntent(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("CreateGuestPaymentIntent called")
+
+	var req CreatePaymentIntentRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	tokenOrderId, err := guesttoken.Verify(guesttoken.KindOrder, context.GetHeader(guesttoken.OrderHeader))
+	if err != nil || tokenOrderId != req.OrderID {
+		l.Warn("invalid order token", "order_id", req.OrderID, "error", err)
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "valid order token required."})
+		return
+	}
+
+	order, err := orderService.GetOrder(context.Request.Context(), req.OrderID)
 	if err != nil {
 		l.Error("failed to get order details", "order_id", req.OrderID, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch order details.", "error": err.Error()})
 		return
 	}
 
-	// Verify user owns this order BEFORE doing anything else
-	if order.UserID != userId {
-		l.Warn("unauthorized order access", "order_id", req.OrderID, "user_id", userId, "order_user_id", order.UserID)
+	// Orders of registered customers must be paid through the authenticated endpoint
+	if order.UserID != nil {
+		l.Warn("guest payment for customer order", "order_id", req.OrderID)
 		context.JSON(http.StatusForbidden, gin.H{"message": "access denied."})
 		return
 	}
 
+	createPaymentIntentForOrder(context, order, nil)
+}
+
+// createPaymentIntentForOrder creates (or returns the pending) Stripe Payment Intent for a verified order;
+// userId is nil for guest orders
+func createPaymentIntentForOrder(context *gin.Context, order *ordermodels.Order, userId *int64) {
+	l := logger.FromContext(context.Request.Context())
+
 	// Check if payment already exists for this order
-	existingPayment, err := models.GetByOrderID(req.OrderID)
+	existingPayment, err := models.GetByOrderID(context.Request.Context(), order.ID)
 	if err == nil && existingPayment != nil {
 		// Allow retry if previous payment failed or was cancelled
 		if existingPayment.Status == "failed" || existingPayment.Status == "cancelled" {
-			l.Info("previous payment failed/cancelled, allowing retry", "order_id", req.OrderID, "old_payment_id", existingPayment.ID, "old_status", existingPayment.Status)
+			l.Info("previous payment failed/cancelled, allowing retry", "order_id", order.ID, "old_payment_id", existingPayment.ID, "old_status", existingPayment.Status)
 			// Mark old payment as superseded
-			if err := models.UpdateStatus(existingPayment.ID, "superseded"); err != nil {
+			if err := models.UpdateStatus(context.Request.Context(), existingPayment.ID, "superseded"); err != nil {
 				l.Error("failed to mark old payment as superseded", "payment_id", existingPayment.ID, "error", err)
 				// Continue anyway - this is not critical
 			}
 		} else if existingPayment.Status == "pending" {
 			// Return existing pending payment intent
-			l.Info("returning existing pending payment intent", "order_id", req.OrderID, "payment_id", existingPayment.ID)
+			l.Info("returning existing pending payment intent", "order_id", order.ID, "payment_id", existingPayment.ID)
 			context.JSON(http.StatusOK, CreatePaymentIntentResponse{
 				PaymentID:     existingPayment.ID,
 				ClientSecret:  *existingPayment.StripeClientSecret,
@@ -97,7 +159,7 @@ func CreatePaymentIntent(context *gin.Context) {
 			return
 		} else {
 			// Payment succeeded or in other final state - cannot create new one
-			l.Warn("payment already exists in final state", "order_id", req.OrderID, "payment_id", existingPayment.ID, "status", existingPayment.Status)
+			l.Warn("payment already exists in final state", "order_id", order.ID, "payment_id", existingPayment.ID, "status", existingPayment.Status)
 			context.JSON(http.StatusConflict, gin.H{
 				"message":         "payment already exists for this order",
 				"paymentId":       existingPayment.ID,
@@ -111,22 +173,31 @@ func CreatePaymentIntent(context *gin.Context) {
 
 	// Verify order is in pending state
 	if order.Status != "pending" {
-		l.Warn("order not in pending state", "order_id", req.OrderID, "status", order.Status)
+		l.Warn("order not in pending state", "order_id", order.ID, "status", order.Status)
 		context.JSON(http.StatusConflict, gin.H{"message": "order is not in pending state.", "status": order.Status})
 		return
 	}
 
+	// TotalCents already includes the shipping cost and coupon discounts of the order
 	amountCents := order.TotalCents
 
 	// Create Stripe Payment Intent
+	metadata := map[string]string{
+		"order_id":       strconv.FormatInt(order.ID, 10),
+		"shipping_cents": strconv.Itoa(order.ShippingCents),
+		"discount_cents": strconv.Itoa(order.DiscountCents),
+	}
+	if userId != nil {
+		metadata["user_id"] = strconv.FormatInt(*userId, 10)
+	} else {
+		metadata["guest"] = "true"
+	}
+
 	params := &stripe.PaymentIntentParams{
 		Amount:   stripe.Int64(int64(amountCents)),
 		Currency: stripe.String("eur"),
 		Params: stripe.Params{
-			Metadata: map[string]string{
-				"order_id": strconv.FormatInt(req.OrderID, 10),
-				"user_id":  strconv.FormatInt(userId, 10),
-			},
+			Metadata: metadata,
 		},
 	}
 
@@ -139,7 +210,7 @@ func CreatePaymentIntent(context *gin.Context) {
 
 	// Save payment to database
 	payment := &models.Payment{
-		OrderID:               req.OrderID,
+		OrderID:               order.ID,
 		UserID:                userId,
 		AmountCents:           amountCents,
 		Currency:              "EUR",
@@ -148,13 +219,13 @@ func CreatePaymentIntent(context *gin.Context) {
 		StripeClientSecret:    &pi.ClientSecret,
 	}
 
-	if err := models.Create(payment); err != nil {
+	if err := models.Create(context.Request.Context(), payment); err != nil {
 		l.Error("failed to create payment record", "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not save payment.", "error": err.Error()})
 		return
 	}
 
-	l.Info("created payment intent", "payment_id", payment.ID, "order_id", req.OrderID, "amount_cents", amountCents)
+	l.Info("created payment intent", "payment_id", payment.ID, "order_id", order.ID, "amount_cents", amountCents)
 	context.JSON(http.StatusCreated, CreatePaymentIntentResponse{
 		PaymentID:     payment.ID,
 		ClientSecret:  pi.ClientSecret,
@@ -186,7 +257,7 @@ func GetPaymentStatus(context *gin.Context) {
 		return
 	}
 
-	payment, err := models.GetByID(paymentID)
+	payment, err := models.GetByID(context.Request.Context(), paymentID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			l.Warn("payment not found", "payment_id", paymentID)
@@ -199,8 +270,8 @@ func GetPaymentStatus(context *gin.Context) {
 	}
 
 	// Verify user owns this payment
-	if payment.UserID != userId {
-		l.Warn("unauthorized payment access", "payment_id", paymentID, "user_id", userId, "payment_user_id", payment.UserID)
+	if payment.UserID == nil || *payment.UserID != userId {
+		l.Warn("unauthorized payment access", "payment_id", paymentID, "user_id", userId)
 		context.JSON(http.StatusForbidden, gin.H{"message": "access denied."})
 		return
 	}
@@ -209,6 +280,75 @@ func GetPaymentStatus(context *gin.Context) {
 	context.JSON(http.StatusOK, payment)
 }
 
+// parsePaymentHistory reads the payment history filters and page from the query string
+func parsePaymentHistory(context *gin.Context) (models.PaymentHistoryFilter, error) {
+	f := models.PaymentHistoryFilter{Status: context.Query("status")}
+
+	var err error
+	if f.Limit, err = pagination.ParseLimit(context.Query("limit")); err != nil {
+		return f, err
+	}
+	if f.Cursor, err = pagination.DecodeCursor(context.Query("cursor")); err != nil {
+		return f, err
+	}
+	if v := context.Query("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %w", err)
+		}
+		f.From = &from
+	}
+	if v := context.Query("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = to.AddDate(0, 0, 1)
+		f.To = &to
+	}
+	return f, nil
+}
+
+// ListPayments godoc
+// @Summary      List user's payments
+// @Description  Get the authenticated user's payment history, newest first, one page at a time. Pass nextCursor of a page as cursor to fetch the next one.
+// @Tags         Payments
+// @Produce      json
+// @Param        status  query     string  false  "Payment status"
+// @Param        from    query     string  false  "Created on or after (YYYY-MM-DD)"
+// @Param        to      query     string  false  "Created on or before (YYYY-MM-DD)"
+// @Param        limit   query     int     false  "Page size (default 20, max 100)"
+// @Param        cursor  query     string  false  "Cursor from the previous page"
+// @Success      200     {object}  models.PaymentHistory
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      500     {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /payments [get]
+func ListPayments(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	userId := context.GetInt64("userId")
+
+	filter, err := parsePaymentHistory(context)
+	if err != nil {
+		l.Error("invalid payment history query", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid query.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("ListPayments called", "user_id", userId, "status", filter.Status, "limit", filter.Limit, "cursor", context.Query("cursor"))
+
+	history, err := models.GetUserPayments(context.Request.Context(), userId, filter)
+	if err != nil {
+		l.Error("failed to get payments", "user_id", userId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch payments.", "error": err.Error()})
+		return
+	}
+
+	l.Info("fetched payments", "user_id", userId, "count", len(history.Payments))
+	context.JSON(http.StatusOK, history)
+}
+
 // WebhookHandler godoc
 // @Summary      Stripe webhook handler
 // @Description  Handles Stripe webhook events for payment updates
@@ -258,28 +398,25 @@ func WebhookHandler(context *gin.Context) {
 		}
 
 		// Update payment status
-		payment, err := models.GetByStripePaymentIntentID(pi.ID)
+		payment, err := models.GetByStripePaymentIntentID(context.Request.Context(), pi.ID)
 		if err != nil {
 			l.Error("failed to find payment", "payment_intent_id", pi.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "payment not found."})
 			return
 		}
 
-		if err := models.UpdateStatus(payment.ID, "succeeded"); err != nil {
+		if err := models.UpdateStatus(context.Request.Context(), payment.ID, "succeeded"); err != nil {
 			l.Error("failed to update payment status", "payment_id", payment.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update payment."})
 			return
 		}
 
 		l.Info("payment succeeded", "payment_id", payment.ID, "order_id", payment.OrderID)
+		metrics.Payments.WithLabelValues("succeeded", "webhook").Inc()
 
 		// Update order status to "confirmed"
-		if err := updateOrderStatus(payment.OrderID, "confirmed"); err != nil {
-			l.Error("failed to update order status", "order_id", payment.OrderID, "error", err)
-			// Don't return error - payment was successful, this is just a notification issue
-		} else {
-			l.Info("order confirmed", "order_id", payment.OrderID)
-		}
+		// Don't return error - payment was successful, this is just a notification issue
+		_ = confirmPaidOrder(context.Request.Context(), payment)
 
 	case "payment_intent.payment_failed":
 		var pi stripe.PaymentIntent
@@ -289,20 +426,21 @@ func WebhookHandler(context *gin.Context) {
 			return
 		}
 
-		payment, err := models.GetByStripePaymentIntentID(pi.ID)
+		payment, err := models.GetByStripePaymentIntentID(context.Request.Context(), pi.ID)
 		if err != nil {
 			l.Error("failed to find payment", "payment_intent_id", pi.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "payment not found."})
 			return
 		}
 
-		if err := models.UpdateStatus(payment.ID, "failed"); err != nil {
+		if err := models.UpdateStatus(context.Request.Context(), payment.ID, "failed"); err != nil {
 			l.Error("failed to update payment status", "payment_id", payment.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update payment."})
 			return
 		}
 
 		l.Info("payment failed", "payment_id", payment.ID, "order_id", payment.OrderID)
+		metrics.Payments.WithLabelValues("failed", "webhook").Inc()
 
 	case "payment_intent.canceled":
 		var pi stripe.PaymentIntent
@@ -312,14 +450,14 @@ func WebhookHandler(context *gin.Context) {
 			return
 		}
 
-		payment, err := models.GetByStripePaymentIntentID(pi.ID)
+		payment, err := models.GetByStripePaymentIntentID(context.Request.Context(), pi.ID)
 		if err != nil {
 			l.Error("failed to find payment", "payment_intent_id", pi.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "payment not found."})
 			return
 		}
 
-		if err := models.UpdateStatus(payment.ID, "cancelled"); err != nil {
+		if err := models.UpdateStatus(context.Request.Context(), payment.ID, "cancelled"); err != nil {
 			l.Error("failed to update payment status", "payment_id", payment.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update payment."})
 			return
@@ -327,6 +465,37 @@ func WebhookHandler(context *gin.Context) {
 
 		l.Info("payment cancelled", "payment_id", payment.ID, "order_id", payment.OrderID)
 
+		// cancelled at Stripe, e.g. in the dashboard or by the provider's expiry: the order will not be paid anymore
+		cancelUnpaidOrder(context.Request.Context(), payment)
+
+	case "refund.updated":
+		var sr stripe.Refund
+		if err := sr.UnmarshalJSON(event.Data.Raw); err != nil {
+			l.Error("failed to unmarshal refund", "error", err)
+			context.JSON(http.StatusBadRequest, gin.H{"message": "invalid event data."})
+			return
+		}
+
+		r, err := models.GetRefundByStripeRefundID(context.Request.Context(), sr.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// refunds issued outside the shop (e.g. Stripe dashboard) are not tracked
+			l.Debug("ignoring unknown refund", "stripe_refund_id", sr.ID)
+			break
+		}
+		if err != nil {
+			l.Error("failed to find refund", "stripe_refund_id", sr.ID, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "refund not found."})
+			return
+		}
+
+		if err := r.UpdateStatus(context.Request.Context(), refundStatus(sr.Status), nil); err != nil {
+			l.Error("failed to update refund status", "refund_id", r.ID, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update refund."})
+			return
+		}
+
+		l.Info("refund updated", "refund_id", r.ID, "order_id", r.OrderID, "status", r.Status)
+
 	default:
 		l.Debug("unhandled webhook event type", "event_type", event.Type)
 	}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/paymentintent"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// paymentStatus maps a Stripe payment intent to the payment status stored in the database
+func paymentStatus(pi *stripe.PaymentIntent) string {
+	switch pi.Status {
+	case stripe.PaymentIntentStatusSucceeded:
+		return "succeeded"
+	case stripe.PaymentIntentStatusProcessing:
+		return "processing"
+	case stripe.PaymentIntentStatusCanceled:
+		return "cancelled"
+	case stripe.PaymentIntentStatusRequiresPaymentMethod:
+		// back to requires_payment_method after a declined attempt
+		if pi.LastPaymentError != nil {
+			return "failed"
+		}
+		return "pending"
+	default:
+		return "pending"
+	}
+}
+
+// confirmPaidOrder confirms the order of a succeeded payment in order-service, which reduces stock and issues the
+// invoice. order-service only confirms pending orders: if the order was cancelled before the payment went through,
+// e.g. by the customer or the expiry, the payment is refunded in full instead.
+// used in: handlers.WebhookHandler, handlers.ReconcilePayment
+func confirmPaidOrder(ctx context.Context, payment *models.Payment) error {
+	l := logger.FromContext(ctx)
+
+	err := orderService.UpdateStatus(ctx, payment.OrderID, "confirmed")
+	if err == nil {
+		l.Info("order confirmed", "order_id", payment.OrderID)
+		return nil
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		l.Error("failed to update order status", "order_id", payment.OrderID, "error", err)
+		return err
+	}
+
+	order, getErr := orderService.GetOrder(ctx, payment.OrderID)
+	if getErr != nil {
+		l.Error("failed to fetch unconfirmable order", "order_id", payment.OrderID, "payment_id", payment.ID, "error", getErr)
+		return err
+	}
+	if order.Status != "cancelled" {
+		l.Error("paid order cannot be confirmed", "order_id", payment.OrderID, "payment_id", payment.ID, "order_status", order.Status, "error", err)
+		return err
+	}
+
+	reason := "order cancelled before payment"
+	r, err := refundPayment(ctx, payment, nil, payment.AmountCents, &reason)
+	if errors.Is(err, models.ErrRefundExceedsPayment) {
+		// refunded already, e.g. on an earlier delivery of the webhook
+		l.Info("payment of cancelled order already refunded", "order_id", payment.OrderID, "payment_id", payment.ID)
+		return nil
+	}
+	if err != nil {
+		l.Error("failed to refund payment of cancelled order", "order_id", payment.OrderID, "payment_id", payment.ID, "error", err)
+		return fmt.Errorf("order cancelled, refund failed: %w", err)
+	}
+	l.Warn("refunded payment of cancelled order", "order_id", payment.OrderID, "payment_id", payment.ID, "refund_id", r.ID, "amount_cents", r.AmountCents)
+	return nil
+}
+
+// ReconcilePayment compares a payment with its payment intent at Stripe. If they differ, the local status is
+// corrected, the order is updated as the webhook would have done and the discrepancy is recorded and returned.
+// Returns nil if the payment is in sync or was changed concurrently, e.g. by a late webhook.
+// used in: jobs.ReconciliationJob
+func ReconcilePayment(ctx context.Context, payment *models.Payment) (*models.Discrepancy, error) {
+	l := logger.FromContext(ctx)
+
+	if payment.StripePaymentIntentID == nil {
+		return nil, nil
+	}
+
+	params := &stripe.PaymentIntentParams{}
+	params.Context = ctx
+	pi, err := paymentintent.Get(*payment.StripePaymentIntentID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment intent: %w", err)
+	}
+
+	status := paymentStatus(pi)
+	if status == payment.Status {
+		return nil, nil
+	}
+
+	d := &models.Discrepancy{
+		PaymentID:             payment.ID,
+		OrderID:               payment.OrderID,
+		StripePaymentIntentID: pi.ID,
+		LocalStatus:           payment.Status,
+		ProviderStatus:        string(pi.Status),
+	}
+
+	updated, err := models.UpdateStatusFrom(ctx, payment.ID, payment.Status, status)
+	switch {
+	case err != nil:
+		msg := "failed to update payment status: " + err.Error()
+		d.Error = &msg
+	case !updated:
+		l.Debug("payment changed during reconciliation", "payment_id", payment.ID)
+		return nil, nil
+	default:
+		d.CorrectedStatus = &status
+		payment.Status = status
+		if status == "succeeded" || status == "failed" {
+			metrics.Payments.WithLabelValues(status, "reconciliation").Inc()
+		}
+		if status == "succeeded" {
+			if err := confirmPaidOrder(ctx, payment); err != nil {
+				msg := "failed to confirm order: " + err.Error()
+				d.Error = &msg
+			}
+		}
+		if status == "cancelled" {
+			cancelUnpaidOrder(ctx, payment)
+		}
+	}
+
+	if err := models.CreateDiscrepancy(ctx, d); err != nil {
+		return d, fmt.Errorf("failed to record discrepancy: %w", err)
+	}
+	l.Warn("payment out of sync with stripe", "payment_id", payment.ID, "order_id", payment.OrderID,
+		"local_status", d.LocalStatus, "provider_status", d.ProviderStatus, "corrected", d.CorrectedStatus != nil)
+	return d, nil
+}
+
+// parseDiscrepancyFilter reads the report filters and page from the query string
+func parseDiscrepancyFilter(context *gin.Context) (models.DiscrepancyFilter, error) {
+	var f models.DiscrepancyFilter
+
+	var err error
+	if f.Limit, err = pagination.ParseLimit(context.Query("limit")); err != nil {
+		return f, err
+	}
+	if f.Cursor, err = pagination.DecodeCursor(context.Query("cursor")); err != nil {
+		return f, err
+	}
+	if v := context.Query("paymentId"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid paymentId: %w", err)
+		}
+		f.PaymentID = &id
+	}
+	if v := context.Query("failed"); v != "" {
+		failed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid failed flag: %w", err)
+		}
+		f.Failed = &failed
+	}
+	if v := context.Query("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %w", err)
+		}
+		f.From = &from
+	}
+	if v := context.Query("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = to.AddDate(0, 0, 1)
+		f.To = &to
+	}
+	return f, nil
+}
+
+// GetPaymentDiscrepancies godoc
+// @Summary      Payment reconciliation report
+// @Description  Payments whose local status differed from Stripe, as found by the reconciliation job, newest first. Pass nextCursor of a page as cursor to fetch the next one. Requires authentication and authorization as role "admin".
+// @Tags         Payments (Admin)
+// @Produce      json
+// @Param        paymentId  query     int     false  "Payment ID"
+// @Param        failed     query     bool    false  "Only discrepancies whose correction failed (true) or succeeded (false)"
+// @Param        from       query     string  false  "Detected on or after (YYYY-MM-DD)"
+// @Param        to         query     string  false  "Detected on or before (YYYY-MM-DD)"
+// @Param        limit      query     int     false  "Page size (default 20, max 100)"
+// @Param        cursor     query     string  false  "Cursor from the previous page"
+// @Success      200        {object}  models.DiscrepancyReport
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      403        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/payments/discrepancies [get]
+func GetPaymentDiscrepancies(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	filter, err := parseDiscrepancyFilter(context)
+	if err != nil {
+		l.Error("invalid discrepancy report query", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid query.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("GetPaymentDiscrepancies called", "payment_id", filter.PaymentID, "failed", filter.Failed, "limit", filter.Limit)
+
+	report, err := models.GetDiscrepancies(context.Request.Context(), filter)
+	if err != nil {
+		l.Error("failed to get payment discrepancies", "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch discrepancies.", "error": err.Error()})
+		return
+	}
+
+	l.Info("fetched payment discrepancies", "count", len(report.Discrepancies))
+	context.JSON(http.StatusOK, report)
+}
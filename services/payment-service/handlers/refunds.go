@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/refund"
+)
+
+// refundStatus maps a Stripe refund status to the refund status stored in the database
+func refundStatus(status stripe.RefundStatus) string {
+	switch status {
+	case stripe.RefundStatusSucceeded:
+		return "succeeded"
+	case stripe.RefundStatusFailed, stripe.RefundStatusCanceled:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
+
+// refundPayment records a refund of part of a succeeded payment and creates it at Stripe. A refund that Stripe
+// rejects is kept as failed. Returns models.ErrRefundExceedsPayment if more would be refunded than was paid.
+// used in: handlers.InternalCreateRefund, handlers.confirmPaidOrder
+func refundPayment(ctx context.Context, payment *models.Payment, returnID *int64, amountCents int, reason *string) (*models.Refund, error) {
+	l := logger.FromContext(ctx)
+
+	r := &models.Refund{
+		PaymentID:   payment.ID,
+		OrderID:     payment.OrderID,
+		ReturnID:    returnID,
+		AmountCents: amountCents,
+		Currency:    payment.Currency,
+		Reason:      reason,
+	}
+	if err := models.CreateRefund(ctx, r); err != nil {
+		if errors.Is(err, models.ErrRefundExceedsPayment) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: payment.StripePaymentIntentID,
+		Amount:        stripe.Int64(int64(r.AmountCents)),
+		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
+	}
+	params.Context = ctx
+	params.AddMetadata("order_id", strconv.FormatInt(r.OrderID, 10))
+	params.AddMetadata("refund_id", strconv.FormatInt(r.ID, 10))
+	if r.ReturnID != nil {
+		params.AddMetadata("return_id", strconv.FormatInt(*r.ReturnID, 10))
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("refund-%d", r.ID))
+
+	sr, err := refund.New(params)
+	if err != nil {
+		if updateErr := r.UpdateStatus(ctx, "failed", nil); updateErr != nil {
+			l.Error("failed to mark refund as failed", "refund_id", r.ID, "error", updateErr)
+		}
+		return nil, fmt.Errorf("failed to create stripe refund: %w", err)
+	}
+
+	if err := r.UpdateStatus(ctx, refundStatus(sr.Status), &sr.ID); err != nil {
+		return nil, fmt.Errorf("failed to update refund: %w", err)
+	}
+	return r, nil
+}
+
+// InternalCreateRefund godoc
+// @Summary      Create refund (internal)
+// @Description  Refund part of the succeeded payment of an order through Stripe (used by Order service for returns). A return is refunded at most once; repeated calls return the existing refund.
+// @Tags         Refunds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateRefundRequest  true  "Order, return and amount"
+// @Success      200      {object}  models.Refund
+// @Success      201      {object}  models.Refund
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Router       /internal/refunds [post]
+func InternalCreateRefund(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	var req models.CreateRefundRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("InternalCreateRefund called", "order_id", req.OrderID, "return_id", req.ReturnID, "amount_cents", req.AmountCents)
+
+	if req.ReturnID != nil {
+		existing, err := models.GetActiveRefundByReturnID(context.Request.Context(), *req.ReturnID)
+		if err == nil {
+			l.Info("returning existing refund", "refund_id", existing.ID, "return_id", *req.ReturnID, "status", existing.Status)
+			context.JSON(http.StatusOK, existing)
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			l.Error("failed to fetch refund", "return_id", *req.ReturnID, "error", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch refund.", "error": err.Error()})
+			return
+		}
+	}
+
+	payment, err := models.GetByOrderID(context.Request.Context(), req.OrderID)
+	if err != nil || payment.Status != "succeeded" || payment.StripePaymentIntentID == nil {
+		l.Warn("order has no succeeded payment", "order_id", req.OrderID, "error", err)
+		context.JSON(http.StatusConflict, gin.H{"message": "order has no succeeded payment."})
+		return
+	}
+
+	r, err := refundPayment(context.Request.Context(), payment, req.ReturnID, req.AmountCents, req.Reason)
+	if errors.Is(err, models.ErrRefundExceedsPayment) {
+		l.Warn("refund exceeds payment", "payment_id", payment.ID, "amount_cents", req.AmountCents)
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		l.Error("failed to refund payment", "payment_id", payment.ID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create refund.", "error": err.Error()})
+		return
+	}
+
+	l.Info("created refund", "refund_id", r.ID, "payment_id", payment.ID, "order_id", r.OrderID, "amount_cents", r.AmountCents, "status", r.Status)
+	context.JSON(http.StatusCreated, r)
+}
+
+// GetOrderRefunds godoc
+// @Summary      List refunds of an order
+// @Description  Get all refunds of an order (admin only)
+// @Tags         Refunds (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Refund
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /admin/orders/{id}/refunds [get]
+func GetOrderRefunds(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	orderId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		l.Error("invalid order ID", "order_id", context.Param("id"), "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid order ID."})
+		return
+	}
+
+	l.Debug("GetOrderRefunds called", "order_id", orderId)
+
+	refunds, err := models.GetRefundsByOrderID(context.Request.Context(), orderId)
+	if err != nil {
+		l.Error("failed to fetch refunds", "order_id", orderId, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch refunds.", "error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, refunds)
+}
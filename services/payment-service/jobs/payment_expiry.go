@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+)
+
+const (
+	DefaultPaymentExpireAfter    = time.Hour
+	DefaultPaymentExpiryInterval = 5 * time.Minute
+	paymentExpiryBatchSize       = 100
+)
+
+// CancelFunc cancels a pending payment with the given Stripe cancellation reason
+type CancelFunc func(ctx context.Context, payment *models.Payment, reason string) error
+
+// PaymentExpiryJob cancels payments that stayed pending for too long, together with their orders
+type PaymentExpiryJob struct {
+	ExpireAfter time.Duration // time after creation at which a pending payment expires
+	Interval    time.Duration // time between two runs
+	Cancel      CancelFunc
+}
+
+// NewPaymentExpiryJobFromEnv configures the job from PAYMENT_EXPIRE_AFTER and PAYMENT_EXPIRY_INTERVAL
+func NewPaymentExpiryJobFromEnv(cancel CancelFunc) (*PaymentExpiryJob, error) {
+	var err error
+	job := &PaymentExpiryJob{Cancel: cancel}
+	if job.ExpireAfter, err = env.Duration("PAYMENT_EXPIRE_AFTER", DefaultPaymentExpireAfter); err != nil {
+		return nil, err
+	}
+	if job.Interval, err = env.Duration("PAYMENT_EXPIRY_INTERVAL", DefaultPaymentExpiryInterval); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Start runs the job every Interval until ctx is cancelled
+func (j *PaymentExpiryJob) Start(ctx context.Context) {
+	l := logger.FromContext(ctx)
+	l.Info("payment expiry job started", "expire_after", j.ExpireAfter.String(), "interval", j.Interval.String())
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			l.Error("payment expiry job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("payment expiry job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce cancels pending payments older than ExpireAfter. A payment whose cancellation fails at Stripe stays pending
+// and is retried on the next run.
+func (j *PaymentExpiryJob) RunOnce(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+	createdBefore := time.Now().Add(-j.ExpireAfter)
+
+	// page by id so payments that keep failing do not block the ones behind them
+	var afterID int64
+	for {
+		payments, err := models.GetStalePendingPayments(ctx, createdBefore, afterID, paymentExpiryBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch stale payments: %w", err)
+		}
+
+		for i := range payments {
+			payment := &payments[i]
+			afterID = payment.ID
+
+			err := j.Cancel(ctx, payment, models.CancelReasonAbandoned)
+			if errors.Is(err, models.ErrPaymentNotPending) {
+				// completed in the meantime, the webhook updates the payment
+				l.Info("stale payment no longer pending", "payment_id", payment.ID, "order_id", payment.OrderID)
+				continue
+			}
+			if err != nil {
+				l.Warn("failed to cancel expired payment", "payment_id", payment.ID, "order_id", payment.OrderID, "error", err)
+				continue
+			}
+			l.Info("expired payment cancelled", "payment_id", payment.ID, "order_id", payment.OrderID, "created_at", payment.CreatedAt)
+		}
+
+		if len(payments) < paymentExpiryBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/env"
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/services/payment-service/models"
+)
+
+const (
+	DefaultReconcileInterval = 15 * time.Minute
+	DefaultReconcileMinAge   = 10 * time.Minute
+	reconcileBatchSize       = 100
+)
+
+// ReconcileFunc compares a payment with the provider, corrects it and returns the recorded discrepancy, if any
+type ReconcileFunc func(ctx context.Context, payment *models.Payment) (*models.Discrepancy, error)
+
+// ReconciliationJob compares unsettled payments with their payment intents at Stripe to catch lost webhooks
+type ReconciliationJob struct {
+	Interval  time.Duration // time between two runs
+	MinAge    time.Duration // payments changed more recently are left to the webhook
+	Reconcile ReconcileFunc
+}
+
+// NewReconciliationJobFromEnv configures the job from PAYMENT_RECONCILE_INTERVAL and PAYMENT_RECONCILE_MIN_AGE
+func NewReconciliationJobFromEnv(reconcile ReconcileFunc) (*ReconciliationJob, error) {
+	var err error
+	job := &ReconciliationJob{Reconcile: reconcile}
+	if job.Interval, err = env.Duration("PAYMENT_RECONCILE_INTERVAL", DefaultReconcileInterval); err != nil {
+		return nil, err
+	}
+	if job.MinAge, err = env.Duration("PAYMENT_RECONCILE_MIN_AGE", DefaultReconcileMinAge); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Start runs the job every Interval until ctx is cancelled
+func (j *ReconciliationJob) Start(ctx context.Context) {
+	l := logger.FromContext(ctx)
+	l.Info("payment reconciliation job started", "interval", j.Interval.String(), "min_age", j.MinAge.String())
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			l.Error("payment reconciliation job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("payment reconciliation job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce reconciles all pending and processing payments not changed within MinAge. A payment that cannot be
+// fetched from Stripe is retried on the next run.
+func (j *ReconciliationJob) RunOnce(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+	changedBefore := time.Now().Add(-j.MinAge)
+
+	checked, found, failed := 0, 0, 0
+	var afterID int64
+	for {
+		payments, err := models.GetUnsettledPayments(ctx, changedBefore, afterID, reconcileBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch unsettled payments: %w", err)
+		}
+
+		for i := range payments {
+			payment := &payments[i]
+			afterID = payment.ID
+			checked++
+
+			d, err := j.Reconcile(ctx, payment)
+			if err != nil {
+				failed++
+				l.Warn("failed to reconcile payment", "payment_id", payment.ID, "order_id", payment.OrderID, "error", err)
+				continue
+			}
+			if d != nil {
+				found++
+			}
+		}
+
+		if len(payments) < reconcileBatchSize {
+			break
+		}
+	}
+
+	if checked > 0 {
+		l.Info("payments reconciled", "checked", checked, "discrepancies", found, "failed", failed)
+	}
+	return nil
+}
@@ -1,14 +1,15 @@
 package main
 
 import (
-	"io"
 	"log"
 	"os"
 
-	"rearatrox/go-ecommerce-backend/pkg/db"
-	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+	"rearatrox/go-ecommerce-backend/services/payment-service/handlers"
+	"rearatrox/go-ecommerce-backend/services/payment-service/jobs"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stripe/stripe-go/v81"
 )
 
@@ -31,11 +32,13 @@ import (
 // @in          header
 // @name        Authorization
 func main() {
-
-	if err := logger.InitFromEnv(); err != nil {
-		log.Fatalf("failed to init logger: %v", err)
+	srv, err := server.New("payment-service")
+	if err != nil {
+		log.Fatalf("failed to start payment-service: %v", err)
+	}
+	if err := guesttoken.CheckSecret(); err != nil {
+		log.Fatalf("failed to start payment-service: %v", err)
 	}
-	defer logger.Sync()
 
 	// Initialize Stripe
 	stripeKey := os.Getenv("STRIPE_SECRET_KEY")
@@ -44,13 +47,27 @@ func main() {
 	}
 	stripe.Key = stripeKey
 
-	db.InitDB()
+	// order lookups and status updates over gRPC
+	handlers.UseClients(clients.NewOrderGRPCClientFromEnv())
+	srv.DependsOn("order-service")
 
-	gin.DefaultWriter = io.Discard
-	router := gin.Default()
+	// background cancellation of payments that stayed pending for too long
+	paymentExpiry, err := jobs.NewPaymentExpiryJobFromEnv(handlers.CancelPayment)
+	if err != nil {
+		log.Fatalf("failed to configure payment expiry job: %v", err)
+	}
+	srv.Go(paymentExpiry.Start)
 
-	RegisterRoutes(router)
+	// catches payments whose webhook got lost
+	reconciliation, err := jobs.NewReconciliationJobFromEnv(handlers.ReconcilePayment)
+	if err != nil {
+		log.Fatalf("failed to configure payment reconciliation job: %v", err)
+	}
+	srv.Go(reconciliation.Start)
 
-	router.Run(":8080") // localhost:8080 -> Container-Port wird durch EVENTSERVICE_PORT gesetzt
+	RegisterRoutes(srv.Router)
 
+	if err := srv.Run(); err != nil {
+		log.Fatalf("payment-service failed: %v", err)
+	}
 }
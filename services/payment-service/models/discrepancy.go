@@ -0,0 +1,118 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
+)
+
+// Discrepancy is a payment whose local status differed from its payment intent at Stripe
+type Discrepancy struct {
+	ID                    int64  `json:"id" example:"1"`
+	PaymentID             int64  `json:"paymentId" example:"1"`
+	OrderID               int64  `json:"orderId" example:"1"`
+	StripePaymentIntentID string `json:"stripePaymentIntentId" example:"pi_1234567890"`
+	// Local payment status before the correction
+	LocalStatus string `json:"localStatus" example:"pending"`
+	// Payment intent status at Stripe
+	ProviderStatus string `json:"providerStatus" example:"succeeded"`
+	// Local payment status after the correction, empty if the correction failed
+	CorrectedStatus *string `json:"correctedStatus,omitempty" example:"succeeded"`
+	// Why the correction or the order update failed
+	Error      *string   `json:"error,omitempty" example:"order-service returned status 500"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// CreateDiscrepancy records a discrepancy found by the reconciliation
+// used in: handlers.ReconcilePayment
+func CreateDiscrepancy(ctx context.Context, d *Discrepancy) error {
+	query := `INSERT INTO payment_discrepancies (payment_id, order_id, stripe_payment_intent_id, local_status, provider_status, corrected_status, error)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          RETURNING id, detected_at`
+	return db.DB.QueryRow(ctx, query, d.PaymentID, d.OrderID, d.StripePaymentIntentID, d.LocalStatus, d.ProviderStatus,
+		d.CorrectedStatus, d.Error).Scan(&d.ID, &d.DetectedAt)
+}
+
+// DiscrepancyFilter narrows and pages the reconciliation report
+type DiscrepancyFilter struct {
+	PaymentID *int64
+	Failed    *bool      // only discrepancies whose correction failed (true) or succeeded (false)
+	From      *time.Time // detected at or after
+	To        *time.Time // detected before
+	Cursor    *pagination.Cursor
+	Limit     int
+}
+
+// DiscrepancyReport is a page of the reconciliation report, newest first
+type DiscrepancyReport struct {
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	// Pass as cursor to fetch the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty" example:"MTc2NzIyNTYwMDAwMDAwMDo0Mg"`
+}
+
+// GetDiscrepancies retrieves a page of the reconciliation report, newest first
+// used in: handlers.GetPaymentDiscrepancies
+func GetDiscrepancies(ctx context.Context, f DiscrepancyFilter) (*DiscrepancyReport, error) {
+	where := []string{"TRUE"}
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.PaymentID != nil {
+		where = append(where, "payment_id = "+arg(*f.PaymentID))
+	}
+	if f.Failed != nil {
+		if *f.Failed {
+			where = append(where, "corrected_status IS NULL")
+		} else {
+			where = append(where, "corrected_status IS NOT NULL")
+		}
+	}
+	if f.From != nil {
+		where = append(where, "detected_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "detected_at < "+arg(*f.To))
+	}
+	if f.Cursor != nil {
+		where = append(where, fmt.Sprintf("(detected_at, id) < (%s, %s)", arg(f.Cursor.CreatedAt), arg(f.Cursor.ID)))
+	}
+
+	query := `SELECT id, payment_id, order_id, stripe_payment_intent_id, local_status, provider_status, corrected_status, error, detected_at
+	          FROM payment_discrepancies
+	          WHERE ` + strings.Join(where, " AND ") + `
+	          ORDER BY detected_at DESC, id DESC
+	          LIMIT ` + arg(f.Limit+1)
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := []Discrepancy{}
+	for rows.Next() {
+		var d Discrepancy
+		err := rows.Scan(&d.ID, &d.PaymentID, &d.OrderID, &d.StripePaymentIntentID, &d.LocalStatus, &d.ProviderStatus,
+			&d.CorrectedStatus, &d.Error, &d.DetectedAt)
+		if err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &DiscrepancyReport{}
+	report.Discrepancies, report.NextCursor = pagination.Next(discrepancies, f.Limit, func(d Discrepancy) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: d.DetectedAt, ID: d.ID}
+	})
+	return report, nil
+}
@@ -1,15 +1,29 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/pagination"
 )
 
+// Cancellation reasons of a payment, passed on to Stripe
+const (
+	CancelReasonAbandoned = "abandoned"             // the payment expired unpaid
+	CancelReasonCustomer  = "requested_by_customer" // the customer cancelled the payment
+)
+
+// ErrPaymentNotPending is returned when a payment cannot be cancelled because it is no longer pending
+var ErrPaymentNotPending = errors.New("payment is not pending")
+
 type Payment struct {
 	ID                    int64      `db:"id" json:"id" swaggerignore:"true"`
 	OrderID               int64      `db:"order_id" json:"orderId" example:"1"`
-	UserID                int64      `db:"user_id" json:"userId" swaggerignore:"true"`
+	UserID                *int64     `db:"user_id" json:"userId,omitempty" swaggerignore:"true"` // nil for guest payments
 	AmountCents           int        `db:"amount_cents" json:"amountCents" example:"5999"`
 	Currency              string     `db:"currency" json:"currency" example:"EUR"`
 	Status                string     `db:"status" json:"status" example:"pending"`
@@ -21,12 +35,12 @@ type Payment struct {
 
 // Create creates a new payment record in the database
 // used in: handlers.CreatePaymentIntent
-func Create(payment *Payment) error {
+func Create(ctx context.Context, payment *Payment) error {
 	query := `INSERT INTO payments (order_id, user_id, amount_cents, currency, status, stripe_payment_intent_id, stripe_client_secret, created_at)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, now())
 	          RETURNING id, created_at`
 
-	err := db.DB.QueryRow(db.Ctx, query,
+	err := db.DB.QueryRow(ctx, query,
 		payment.OrderID,
 		payment.UserID,
 		payment.AmountCents,
@@ -41,13 +55,13 @@ func Create(payment *Payment) error {
 
 // GetByID retrieves a payment by its ID
 // used in: handlers.GetPaymentStatus
-func GetByID(paymentID int64) (*Payment, error) {
+func GetByID(ctx context.Context, paymentID int64) (*Payment, error) {
 	payment := &Payment{}
 	query := `SELECT id, order_id, user_id, amount_cents, currency, status, 
 	          stripe_payment_intent_id, stripe_client_secret, created_at, updated_at
 	          FROM payments WHERE id = $1`
 
-	err := db.DB.QueryRow(db.Ctx, query, paymentID).Scan(
+	err := db.DB.QueryRow(ctx, query, paymentID).Scan(
 		&payment.ID,
 		&payment.OrderID,
 		&payment.UserID,
@@ -69,13 +83,13 @@ func GetByID(paymentID int64) (*Payment, error) {
 
 // GetByOrderID retrieves a payment by order ID
 // used in: handlers.CreatePaymentIntent, order-service integration
-func GetByOrderID(orderID int64) (*Payment, error) {
+func GetByOrderID(ctx context.Context, orderID int64) (*Payment, error) {
 	payment := &Payment{}
 	query := `SELECT id, order_id, user_id, amount_cents, currency, status, 
 	          stripe_payment_intent_id, stripe_client_secret, created_at, updated_at
 	          FROM payments WHERE order_id = $1 ORDER BY created_at DESC LIMIT 1`
 
-	err := db.DB.QueryRow(db.Ctx, query, orderID).Scan(
+	err := db.DB.QueryRow(ctx, query, orderID).Scan(
 		&payment.ID,
 		&payment.OrderID,
 		&payment.UserID,
@@ -97,13 +111,13 @@ func GetByOrderID(orderID int64) (*Payment, error) {
 
 // GetByStripePaymentIntentID retrieves a payment by Stripe Payment Intent ID
 // used in: handlers.WebhookHandler
-func GetByStripePaymentIntentID(paymentIntentID string) (*Payment, error) {
+func GetByStripePaymentIntentID(ctx context.Context, paymentIntentID string) (*Payment, error) {
 	payment := &Payment{}
 	query := `SELECT id, order_id, user_id, amount_cents, currency, status, 
 	          stripe_payment_intent_id, stripe_client_secret, created_at, updated_at
 	          FROM payments WHERE stripe_payment_intent_id = $1`
 
-	err := db.DB.QueryRow(db.Ctx, query, paymentIntentID).Scan(
+	err := db.DB.QueryRow(ctx, query, paymentIntentID).Scan(
 		&payment.ID,
 		&payment.OrderID,
 		&payment.UserID,
@@ -125,20 +139,36 @@ func GetByStripePaymentIntentID(paymentIntentID string) (*Payment, error) {
 
 // UpdateStatus updates the payment status
 // used in: handlers.ConfirmPayment, handlers.WebhookHandler
-func UpdateStatus(paymentID int64, status string) error {
+func UpdateStatus(ctx context.Context, paymentID int64, status string) error {
 	query := `UPDATE payments SET status = $1, updated_at = now() WHERE id = $2`
-	_, err := db.DB.Exec(db.Ctx, query, status, paymentID)
+	_, err := db.DB.Exec(ctx, query, status, paymentID)
 	return err
 }
 
-// GetAllByUserID retrieves all payments for a specific user
-// used in: handlers.GetUserPayments (optional, für Zahlungshistorie)
-func GetAllByUserID(userID int64) ([]Payment, error) {
-	query := `SELECT id, order_id, user_id, amount_cents, currency, status, 
+// CancelPending marks a pending payment as cancelled. It reports false if the payment was no longer pending, e.g.
+// because a webhook completed it in the meantime.
+// used in: handlers.CancelPayment
+func CancelPending(ctx context.Context, paymentID int64) (bool, error) {
+	query := `UPDATE payments SET status = 'cancelled', updated_at = now() WHERE id = $1 AND status = 'pending'`
+	tag, err := db.DB.Exec(ctx, query, paymentID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetStalePendingPayments retrieves up to limit payments still pending that were created before the given time,
+// ordered by id and starting after afterID
+// used in: jobs.PaymentExpiryJob
+func GetStalePendingPayments(ctx context.Context, createdBefore time.Time, afterID int64, limit int) ([]Payment, error) {
+	query := `SELECT id, order_id, user_id, amount_cents, currency, status,
 	          stripe_payment_intent_id, stripe_client_secret, created_at, updated_at
-	          FROM payments WHERE user_id = $1 ORDER BY created_at DESC`
+	          FROM payments
+	          WHERE status = 'pending' AND created_at < $1 AND id > $2
+	          ORDER BY id
+	          LIMIT $3`
 
-	rows, err := db.DB.Query(db.Ctx, query, userID)
+	rows, err := db.DB.Query(ctx, query, createdBefore, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +194,139 @@ func GetAllByUserID(userID int64) ([]Payment, error) {
 		}
 		payments = append(payments, payment)
 	}
+	return payments, rows.Err()
+}
+
+// UpdateStatusFrom changes the status of a payment only if it still has the expected status. It reports false if the
+// status changed in the meantime, e.g. through a webhook.
+// used in: handlers.ReconcilePayment, handlers.InternalCancelOrderPayment
+func UpdateStatusFrom(ctx context.Context, paymentID int64, from, to string) (bool, error) {
+	query := `UPDATE payments SET status = $1, updated_at = now() WHERE id = $2 AND status = $3`
+	tag, err := db.DB.Exec(ctx, query, to, paymentID, from)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetUnsettledPayments retrieves up to limit payments that are pending or processing, have a payment intent and were
+// last changed before the given time, ordered by id and starting after afterID
+// used in: jobs.ReconciliationJob
+func GetUnsettledPayments(ctx context.Context, changedBefore time.Time, afterID int64, limit int) ([]Payment, error) {
+	query := `SELECT id, order_id, user_id, amount_cents, currency, status,
+	          stripe_payment_intent_id, stripe_client_secret, created_at, updated_at
+	          FROM payments
+	          WHERE status IN ('pending', 'processing') AND stripe_payment_intent_id IS NOT NULL
+	            AND COALESCE(updated_at, created_at) < $1 AND id > $2
+	          ORDER BY id
+	          LIMIT $3`
+
+	rows, err := db.DB.Query(ctx, query, changedBefore, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var payments []Payment
+	for rows.Next() {
+		var payment Payment
+		err := rows.Scan(
+			&payment.ID,
+			&payment.OrderID,
+			&payment.UserID,
+			&payment.AmountCents,
+			&payment.Currency,
+			&payment.Status,
+			&payment.StripePaymentIntentID,
+			&payment.StripeClientSecret,
+			&payment.CreatedAt,
+			&payment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
 	return payments, rows.Err()
 }
+
+// PaymentHistoryFilter narrows and pages a user's payment history
+type PaymentHistoryFilter struct {
+	Status string
+	From   *time.Time // created at or after
+	To     *time.Time // created before
+	Cursor *pagination.Cursor
+	Limit  int
+}
+
+// PaymentHistory is a page of a user's payments, newest first
+type PaymentHistory struct {
+	Payments []Payment `json:"payments"`
+	// Pass as cursor to fetch the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty" example:"MTc2NzIyNTYwMDAwMDAwMDo0Mg"`
+}
+
+// GetUserPayments retrieves a page of a user's payments, newest first. Client secrets are not included.
+// used in: handlers.ListPayments
+func GetUserPayments(ctx context.Context, userID int64, f PaymentHistoryFilter) (*PaymentHistory, error) {
+	where := []string{"user_id = $1"}
+	args := []any{userID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.Status != "" {
+		where = append(where, "status = "+arg(f.Status))
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "created_at < "+arg(*f.To))
+	}
+	if f.Cursor != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(f.Cursor.CreatedAt), arg(f.Cursor.ID)))
+	}
+
+	query := `SELECT id, order_id, user_id, amount_cents, currency, status, stripe_payment_intent_id, created_at, updated_at
+	          FROM payments
+	          WHERE ` + strings.Join(where, " AND ") + `
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ` + arg(f.Limit+1)
+
+	rows, err := db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []Payment{}
+	for rows.Next() {
+		var payment Payment
+		err := rows.Scan(
+			&payment.ID,
+			&payment.OrderID,
+			&payment.UserID,
+			&payment.AmountCents,
+			&payment.Currency,
+			&payment.Status,
+			&payment.StripePaymentIntentID,
+			&payment.CreatedAt,
+			&payment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history := &PaymentHistory{}
+	history.Payments, history.NextCursor = pagination.Next(payments, f.Limit, func(p Payment) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+	})
+	return history, nil
+}
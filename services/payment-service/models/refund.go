@@ -0,0 +1,134 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rearatrox/go-ecommerce-backend/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRefundExceedsPayment is returned when a refund would return more than was paid
+var ErrRefundExceedsPayment = errors.New("refund exceeds the remaining paid amount")
+
+type Refund struct {
+	ID             int64      `db:"id" json:"id" example:"1"`
+	PaymentID      int64      `db:"payment_id" json:"paymentId" example:"1"`
+	OrderID        int64      `db:"order_id" json:"orderId" example:"1"`
+	ReturnID       *int64     `db:"return_id" json:"returnId,omitempty" example:"1"`
+	AmountCents    int        `db:"amount_cents" json:"amountCents" example:"2999"`
+	Currency       string     `db:"currency" json:"currency" example:"EUR"`
+	Status         string     `db:"status" json:"status" example:"succeeded"`
+	Reason         *string    `db:"reason" json:"reason,omitempty" example:"return 1"`
+	StripeRefundID *string    `db:"stripe_refund_id" json:"stripeRefundId,omitempty" example:"re_1234567890"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
+}
+
+type CreateRefundRequest struct {
+	OrderID     int64   `json:"orderId" binding:"required" example:"1"`
+	ReturnID    *int64  `json:"returnId" example:"1"`
+	AmountCents int     `json:"amountCents" binding:"required,min=1" example:"2999"`
+	Reason      *string `json:"reason" example:"return 1"`
+}
+
+const refundColumns = `id, payment_id, order_id, return_id, amount_cents, currency, status, reason, stripe_refund_id, created_at, updated_at`
+
+func scanRefund(row pgx.Row, r *Refund) error {
+	return row.Scan(&r.ID, &r.PaymentID, &r.OrderID, &r.ReturnID, &r.AmountCents, &r.Currency, &r.Status,
+		&r.Reason, &r.StripeRefundID, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// CreateRefund stores a pending refund for a payment. The payment row is locked so concurrent refunds
+// cannot return more than was paid; returns ErrRefundExceedsPayment otherwise.
+// used in: handlers.InternalCreateRefund
+func CreateRefund(ctx context.Context, r *Refund) error {
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var paidCents int
+	err = tx.QueryRow(ctx, `SELECT amount_cents FROM payments WHERE id=$1 FOR UPDATE`, r.PaymentID).Scan(&paidCents)
+	if err != nil {
+		return err
+	}
+
+	var refundedCents int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(amount_cents), 0) FROM refunds WHERE payment_id=$1 AND status<>'failed'`, r.PaymentID).Scan(&refundedCents)
+	if err != nil {
+		return err
+	}
+	if refundedCents+r.AmountCents > paidCents {
+		return ErrRefundExceedsPayment
+	}
+
+	query := `INSERT INTO refunds (payment_id, order_id, return_id, amount_cents, currency, status, reason, created_at)
+	          VALUES ($1, $2, $3, $4, $5, 'pending', $6, now())
+	          RETURNING ` + refundColumns
+	err = scanRefund(tx.QueryRow(ctx, query, r.PaymentID, r.OrderID, r.ReturnID, r.AmountCents, r.Currency, r.Reason), r)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetActiveRefundByReturnID retrieves the pending or succeeded refund of a return
+// used in: handlers.InternalCreateRefund
+func GetActiveRefundByReturnID(ctx context.Context, returnID int64) (*Refund, error) {
+	r := &Refund{}
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE return_id=$1 AND status<>'failed'`
+	if err := scanRefund(db.DB.QueryRow(ctx, query, returnID), r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRefundByStripeRefundID retrieves a refund by its Stripe Refund ID
+// used in: handlers.WebhookHandler
+func GetRefundByStripeRefundID(ctx context.Context, stripeRefundID string) (*Refund, error) {
+	r := &Refund{}
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE stripe_refund_id=$1`
+	if err := scanRefund(db.DB.QueryRow(ctx, query, stripeRefundID), r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRefundsByOrderID retrieves all refunds of an order
+// used in: handlers.GetOrderRefunds
+func GetRefundsByOrderID(ctx context.Context, orderID int64) ([]Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE order_id=$1 ORDER BY created_at`
+	rows, err := db.DB.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []Refund{}
+	for rows.Next() {
+		var r Refund
+		if err := scanRefund(rows, &r); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, r)
+	}
+	return refunds, rows.Err()
+}
+
+// UpdateStatus stores the Stripe refund ID and status of a refund
+// used in: handlers.InternalCreateRefund, handlers.WebhookHandler
+func (r *Refund) UpdateStatus(ctx context.Context, status string, stripeRefundID *string) error {
+	query := `UPDATE refunds SET status=$1, stripe_refund_id=COALESCE($2, stripe_refund_id), updated_at=now()
+	          WHERE id=$3
+	          RETURNING stripe_refund_id, updated_at`
+	err := db.DB.QueryRow(ctx, query, status, stripeRefundID, r.ID).Scan(&r.StripeRefundID, &r.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	r.Status = status
+	return nil
+}
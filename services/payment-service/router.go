@@ -3,14 +3,20 @@ package main
 import (
 	"os"
 	"strings"
+	"time"
 
+	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/idempotency"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
 	"rearatrox/go-ecommerce-backend/services/payment-service/handlers"
 
 	docs "rearatrox/go-ecommerce-backend/services/payment-service/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -19,17 +25,14 @@ import (
 const DEFAULT_PORT = "8085"
 
 func RegisterRoutes(router *gin.Engine) {
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
-	// request-logger middleware (adds request-scoped logger into context)
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
 	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
 	router.NoRoute(func(c *gin.Context) {
 		// logger from context
 		log := logger.FromContext(c.Request.Context())
@@ -52,6 +55,21 @@ func RegisterRoutes(router *gin.Engine) {
 	docs.SwaggerInfo.Host = "localhost:" + port
 	docs.SwaggerInfo.BasePath = apiPrefix
 
+	// replays responses of retried requests sent with an Idempotency-Key header
+	idempotent := idempotency.Middleware(idempotency.NewPostgresStore(db.DB))
+
+	// client IPs of guests are taken from X-Forwarded-For only if set by the gateway (TRUSTED_PROXIES)
+	if err := ratelimit.TrustProxiesFromEnv(router, "TRUSTED_PROXIES"); err != nil {
+		panic(err.Error())
+	}
+
+	// per user limit of payment intents and cancellations, guests per client IP (RATE_LIMIT_PAYMENTS)
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		panic(err.Error())
+	}
+	paymentsLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "payments", Limit: ratelimit.Limit{Requests: 20, Period: time.Minute}, Key: ratelimit.ByUser})
+
 	api := router.Group(apiPrefix)
 	{
 		// make sure the swagger UI knows where to fetch the generated spec
@@ -60,19 +78,33 @@ func RegisterRoutes(router *gin.Engine) {
 		// Webhook endpoint (no authentication - verified by Stripe signature)
 		api.POST("/webhooks/stripe", handlers.WebhookHandler)
 
+		// Internal endpoints (service-to-service communication, signed by the calling services named per route)
+		internal := api.Group("/internal")
+		{
+			internal.POST("/refunds", serviceauth.InternalAuth("order-service"), handlers.InternalCreateRefund)
+			internal.POST("/orders/:id/payment/cancel", serviceauth.InternalAuth("order-service"), handlers.InternalCancelOrderPayment)
+		}
+
+		// Guest payments (authorized by the signed order token)
+		api.POST("/guest/payment-intents", paymentsLimit, idempotent, handlers.CreateGuestPaymentIntent)
+		api.POST("/guest/payments/:id/cancel", paymentsLimit, handlers.CancelGuestPayment)
+
 		authenticated := api.Group("/")
 		{
 			authenticated.Use(middleware.Authenticate)
 
 			// Payment endpoints
-			authenticated.POST("/payment-intents", handlers.CreatePaymentIntent)
+			authenticated.POST("/payment-intents", paymentsLimit, idempotent, handlers.CreatePaymentIntent)
+			authenticated.GET("/payments", handlers.ListPayments)
 			authenticated.GET("/payments/:id", handlers.GetPaymentStatus)
+			authenticated.POST("/payments/:id/cancel", paymentsLimit, handlers.CancelMyPayment)
 
 			// admin-only
 			admin := authenticated.Group("/admin")
 			admin.Use(middleware.Authorize("admin"))
 			{
-				// admin endpoints go here
+				admin.GET("/orders/:id/refunds", handlers.GetOrderRefunds)
+				admin.GET("/payments/discrepancies", handlers.GetPaymentDiscrepancies)
 			}
 		}
 
@@ -774,6 +774,62 @@ const docTemplate = `{
                 }
             }
         },
+        "/internal/products/stock/restock": {
+            "post": {
+                "description": "Increase stock when returned items are put back into inventory (used by Order service)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Restock returned items",
+                "parameters": [
+                    {
+                        "description": "Product and quantity to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RestockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/products": {
             "get": {
                 "description": "Get all product information of all products",
@@ -926,7 +982,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CheckStockRequest"
+                            "$ref": "#/definitions/models.CheckStockRequest"
                         }
                     }
                 ],
@@ -934,7 +990,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.CheckStockResponse"
+                            "$ref": "#/definitions/models.CheckStockResponse"
                         }
                     },
                     "400": {
@@ -979,7 +1035,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReduceStockRequest"
+                            "$ref": "#/definitions/models.ReduceStockRequest"
                         }
                     }
                 ],
@@ -1066,7 +1122,28 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "handlers.CheckStockRequest": {
+        "models.Category": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Elektronische Geräte, Zubehör und Gadgets"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Elektronik"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "elektronik"
+                }
+            }
+        },
+        "models.CheckStockRequest": {
             "type": "object",
             "required": [
                 "productId",
@@ -1084,7 +1161,7 @@ const docTemplate = `{
                 }
             }
         },
-        "handlers.CheckStockResponse": {
+        "models.CheckStockResponse": {
             "type": "object",
             "properties": {
                 "available": {
@@ -1105,45 +1182,6 @@ const docTemplate = `{
                 }
             }
         },
-        "handlers.ReduceStockRequest": {
-            "type": "object",
-            "required": [
-                "productId",
-                "quantity"
-            ],
-            "properties": {
-                "productId": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "quantity": {
-                    "type": "integer",
-                    "minimum": 1,
-                    "example": 2
-                }
-            }
-        },
-        "models.Category": {
-            "type": "object",
-            "required": [
-                "name",
-                "slug"
-            ],
-            "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "Elektronische Geräte, Zubehör und Gadgets"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Elektronik"
-                },
-                "slug": {
-                    "type": "string",
-                    "example": "elektronik"
-                }
-            }
-        },
         "models.Product": {
             "type": "object",
             "required": [
@@ -1183,6 +1221,46 @@ const docTemplate = `{
                 "stockQty": {
                     "type": "integer",
                     "example": 25
+                },
+                "weightGrams": {
+                    "type": "integer",
+                    "example": 1800
+                }
+            }
+        },
+        "models.ReduceStockRequest": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "productId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "models.RestockRequest": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "productId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
                 }
             }
         }
@@ -0,0 +1,130 @@
+// Package grpcapi implements the internal gRPC API of the product-service (proto/product/v1/product.proto)
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/pb/productpb"
+	"rearatrox/go-ecommerce-backend/services/product-service/models"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Allowlist names the services allowed to call each method
+var Allowlist = serviceauth.Allowlist{
+	productpb.ProductInternal_CheckStock_FullMethodName:   {"cart-service", "order-service"},
+	productpb.ProductInternal_ReserveStock_FullMethodName: {"order-service"},
+	productpb.ProductInternal_ReduceStock_FullMethodName:  {"order-service"},
+	productpb.ProductInternal_Restock_FullMethodName:      {"order-service"},
+}
+
+// Server serves the stock operations of handlers.CheckStock, handlers.ReduceStock and handlers.RestockStock over gRPC
+type Server struct {
+	productpb.UnimplementedProductInternalServer
+}
+
+// CheckStock reports whether the requested quantity of an active product is in stock
+func (s *Server) CheckStock(ctx context.Context, req *productpb.CheckStockRequest) (*productpb.CheckStockResponse, error) {
+	l := logger.FromContext(ctx)
+	if req.GetQuantity() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be at least 1")
+	}
+
+	available, currentStock, err := models.CheckStockAvailable(ctx, req.GetProductId(), int(req.GetQuantity()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	if err != nil {
+		l.Error("failed to check stock", "productId", req.GetProductId(), "error", err)
+		return nil, status.Error(codes.Internal, "could not check stock")
+	}
+
+	l.Info("stock checked", "productId", req.GetProductId(), "available", available, "requested", req.GetQuantity(), "current", currentStock)
+	return &productpb.CheckStockResponse{
+		ProductId:    req.GetProductId(),
+		Available:    available,
+		RequestedQty: req.GetQuantity(),
+		AvailableQty: int32(currentStock),
+	}, nil
+}
+
+// ReserveStock takes all items of an order out of stock, or none if one of them is short. A repeated reservation for
+// the same order takes nothing.
+func (s *Server) ReserveStock(ctx context.Context, req *productpb.ReserveStockRequest) (*productpb.ReserveStockResponse, error) {
+	l := logger.FromContext(ctx)
+	if req.GetOrderId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "order id is required")
+	}
+
+	items := make([]models.ReduceStockRequest, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		if item.GetQuantity() < 1 {
+			return nil, status.Error(codes.InvalidArgument, "quantity must be at least 1")
+		}
+		items = append(items, models.ReduceStockRequest{ProductID: item.GetProductId(), Quantity: int(item.GetQuantity())})
+	}
+
+	reserved, err := models.ReserveStock(ctx, req.GetOrderId(), items)
+	if err != nil {
+		return nil, stockStatus(l, err, "could not reserve stock")
+	}
+	if !reserved {
+		l.Debug("stock already reserved for order", "order_id", req.GetOrderId())
+		return &productpb.ReserveStockResponse{}, nil
+	}
+
+	l.Info("stock reserved", "order_id", req.GetOrderId(), "items_count", len(items))
+	return &productpb.ReserveStockResponse{}, nil
+}
+
+// ReduceStock takes a single product out of stock
+func (s *Server) ReduceStock(ctx context.Context, req *productpb.StockItem) (*productpb.ReduceStockResponse, error) {
+	l := logger.FromContext(ctx)
+	if req.GetQuantity() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be at least 1")
+	}
+
+	if err := models.ReduceStock(ctx, req.GetProductId(), int(req.GetQuantity())); err != nil {
+		return nil, stockStatus(l, err, "could not reduce stock")
+	}
+
+	l.Info("stock reduced", "productId", req.GetProductId(), "quantity", req.GetQuantity())
+	return &productpb.ReduceStockResponse{}, nil
+}
+
+// Restock puts a single product back into stock
+func (s *Server) Restock(ctx context.Context, req *productpb.StockItem) (*productpb.RestockResponse, error) {
+	l := logger.FromContext(ctx)
+	if req.GetQuantity() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be at least 1")
+	}
+
+	err := models.RestockStock(ctx, req.GetProductId(), int(req.GetQuantity()))
+	if errors.Is(err, models.ErrProductNotFound) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	if err != nil {
+		l.Error("failed to restock", "productId", req.GetProductId(), "error", err)
+		return nil, status.Error(codes.Internal, "could not restock product")
+	}
+
+	l.Info("stock restocked", "productId", req.GetProductId(), "quantity", req.GetQuantity())
+	return &productpb.RestockResponse{}, nil
+}
+
+// stockStatus maps a failed stock reduction to FailedPrecondition for short stock and Internal otherwise
+func stockStatus(l *slog.Logger, err error, message string) error {
+	var stockErr *models.StockError
+	if errors.As(err, &stockErr) {
+		l.Warn("insufficient stock", "productId", stockErr.ProductID, "quantity", stockErr.Requested)
+		return status.Errorf(codes.FailedPrecondition, "insufficient stock or product not found: product %d", stockErr.ProductID)
+	}
+	l.Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -23,7 +23,7 @@ func GetCategories(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetCategories called")
 
-	categories, err := models.GetCategories()
+	categories, err := models.GetCategories(context.Request.Context())
 
 	if err != nil {
 		l.Error("failed to fetch categories", "error", err)
@@ -59,7 +59,7 @@ func GetCategoryByID(context *gin.Context) {
 
 	l.Debug("GetCategoryByID called", "category_id", categoryId)
 
-	category, err := models.GetCategoryByID(categoryId)
+	category, err := models.GetCategoryByID(context.Request.Context(), categoryId)
 	if err != nil {
 		l.Error("failed to fetch category", "category_id", categoryId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch category.", "error": err.Error()})
@@ -87,7 +87,7 @@ func GetCategoryBySlug(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetCategoryBySlug called", "slug", categorySlug)
 
-	category, err := models.GetCategoryBySlug(categorySlug)
+	category, err := models.GetCategoryBySlug(context.Request.Context(), categorySlug)
 	if err != nil {
 		l.Error("failed to fetch category", "slug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch category.", "error": err.Error()})
@@ -124,7 +124,7 @@ func CreateCategory(context *gin.Context) {
 		return
 	}
 
-	err = category.InsertCategory()
+	err = category.InsertCategory(context.Request.Context())
 
 	if err != nil {
 		l.Error("failed to save category", "error", err)
@@ -155,7 +155,7 @@ func UpdateCategory(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("UpdateCategory called", "categorySlug", categorySlug)
 
-	category, err := models.GetCategoryBySlug(categorySlug)
+	category, err := models.GetCategoryBySlug(context.Request.Context(), categorySlug)
 	if err != nil {
 		l.Error("failed to fetch category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch category.", "error": err.Error()})
@@ -178,7 +178,7 @@ func UpdateCategory(context *gin.Context) {
 
 	updatedCategory.ID = category.ID
 
-	err = updatedCategory.UpdateCategory()
+	err = updatedCategory.UpdateCategory(context.Request.Context())
 	if err != nil {
 		l.Error("failed to update category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update category.", "error": err.Error()})
@@ -206,14 +206,14 @@ func DeleteCategoryBySlug(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("DeleteCategory called", "categorySlug", categorySlug)
 
-	deleteCategory, err := models.GetCategoryBySlug(categorySlug)
+	deleteCategory, err := models.GetCategoryBySlug(context.Request.Context(), categorySlug)
 	if err != nil {
 		l.Error("failed to fetch category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch category.", "error": err.Error()})
 		return
 	}
 
-	err = deleteCategory.DeleteCategory()
+	err = deleteCategory.DeleteCategory(context.Request.Context())
 	if err != nil {
 		l.Error("failed to delete category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete category.", "error": err.Error()})
@@ -239,14 +239,14 @@ func GetProductsByCategory(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetProductsByCategory called", "categorySlug", categorySlug)
 
-	category, err := models.GetCategoryBySlug(categorySlug)
+	category, err := models.GetCategoryBySlug(context.Request.Context(), categorySlug)
 	if err != nil {
 		l.Error("failed to fetch category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch category.", "error": err.Error()})
 		return
 	}
 
-	products, err := models.GetProductsByCategory(category.ID)
+	products, err := models.GetProductsByCategory(context.Request.Context(), category.ID)
 	if err != nil {
 		l.Error("failed to fetch products by category", "categorySlug", categorySlug, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch products by category.", "error": err.Error()})
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
 	"rearatrox/go-ecommerce-backend/services/product-service/models"
@@ -24,7 +25,7 @@ func GetProducts(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetProducts called")
 
-	products, err := models.GetProducts()
+	products, err := models.GetProducts(context.Request.Context())
 
 	if err != nil {
 		l.Error("failed to fetch products", "error", err)
@@ -62,7 +63,7 @@ func GetProductByID(context *gin.Context) {
 
 	l.Debug("GetProduct called", "product_id", productId)
 
-	product, err = models.GetProductByID(productId)
+	product, err = models.GetProductByID(context.Request.Context(), productId)
 	if err != nil {
 		l.Error("failed to fetch product", "product_id", productId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
@@ -92,7 +93,7 @@ func GetProductBySKU(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetProduct called", "SKU", productSku)
 
-	product, err := models.GetProductBySKU(productSku)
+	product, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "SKU", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
@@ -122,10 +123,7 @@ func CreateProduct(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("CreateProduct called")
 
-	var requestBody struct {
-		models.Product
-		CategoryIds []int64 `json:"categoryIds,omitempty"`
-	}
+	var requestBody models.CreateProductRequest
 	err := context.ShouldBindJSON(&requestBody)
 
 	if err != nil {
@@ -138,7 +136,7 @@ func CreateProduct(context *gin.Context) {
 	requestBody.Product.CreatorID = userId
 
 	// Produkt anlegen
-	err = requestBody.Product.InsertProduct()
+	err = requestBody.Product.InsertProduct(context.Request.Context())
 
 	if err != nil {
 		l.Error("failed to save product", "error", err)
@@ -148,7 +146,7 @@ func CreateProduct(context *gin.Context) {
 
 	// Kategorien hinzufügen (falls vorhanden)
 	if len(requestBody.CategoryIds) > 0 {
-		err = requestBody.Product.AddCategories(requestBody.CategoryIds)
+		err = requestBody.Product.AddCategories(context.Request.Context(), requestBody.CategoryIds)
 		if err != nil {
 			l.Error("failed to add categories to product", "product_id", requestBody.Product.ID, "error", err)
 			context.JSON(http.StatusInternalServerError, gin.H{"message": "product created but could not add categories.", "error": err.Error()})
@@ -182,7 +180,7 @@ func UpdateProduct(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("UpdateProduct called", "productSku", productSku)
 
-	product, err := models.GetProductBySKU(productSku)
+	product, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
@@ -208,7 +206,7 @@ func UpdateProduct(context *gin.Context) {
 	updatedProduct.CreatorID = product.CreatorID
 	updatedProduct.UpdatorID = userId.(int64)
 
-	err = updatedProduct.UpdateProduct()
+	err = updatedProduct.UpdateProduct(context.Request.Context())
 	if err != nil {
 		l.Error("failed to update product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update product.", "error": err.Error()})
@@ -237,14 +235,14 @@ func DeactivateProductBySKU(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("DeactivateProduct called", "productSku", productSku)
 
-	deactivatedProduct, err := models.GetProductBySKU(productSku)
+	deactivatedProduct, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
 		return
 	}
 
-	err = deactivatedProduct.DeactivateProductBySKU()
+	err = deactivatedProduct.DeactivateProductBySKU(context.Request.Context())
 	if err != nil {
 		l.Error("failed to deactivate product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not deactivate product.", "error": err.Error()})
@@ -273,14 +271,14 @@ func DeleteProductBySKU(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("DeleteProduct called", "productSku", productSku)
 
-	deleteProduct, err := models.GetProductBySKU(productSku)
+	deleteProduct, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
 		return
 	}
 
-	err = deleteProduct.DeleteProductBySKU()
+	err = deleteProduct.DeleteProductBySKU(context.Request.Context())
 	if err != nil {
 		l.Error("failed to delete product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete product.", "error": err.Error()})
@@ -307,14 +305,14 @@ func GetProductCategories(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetProductCategories called", "productSku", productSku)
 
-	product, err := models.GetProductBySKU(productSku)
+	product, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
 		return
 	}
 
-	categories, err := models.GetProductCategories(product.ID)
+	categories, err := models.GetProductCategories(context.Request.Context(), product.ID)
 	if err != nil {
 		l.Error("failed to fetch product categories", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product categories.", "error": err.Error()})
@@ -354,14 +352,14 @@ func AddCategoriesToProduct(context *gin.Context) {
 		return
 	}
 
-	product, err := models.GetProductBySKU(productSku)
+	product, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
 		return
 	}
 
-	err = product.AddCategories(requestBody.CategoryIds)
+	err = product.AddCategories(context.Request.Context(), requestBody.CategoryIds)
 	if err != nil {
 		l.Error("failed to add categories to product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not add categories to product.", "error": err.Error()})
@@ -400,14 +398,14 @@ func RemoveCategoryFromProduct(context *gin.Context) {
 
 	l.Debug("RemoveCategoryFromProduct called", "productSku", productSku, "categoryId", categoryId)
 
-	product, err := models.GetProductBySKU(productSku)
+	product, err := models.GetProductBySKU(context.Request.Context(), productSku)
 	if err != nil {
 		l.Error("failed to fetch product", "productSku", productSku, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch product.", "error": err.Error()})
 		return
 	}
 
-	err = product.RemoveCategory(categoryId)
+	err = product.RemoveCategory(context.Request.Context(), categoryId)
 	if err != nil {
 		l.Error("failed to remove category from product", "productSku", productSku, "categoryId", categoryId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not remove category from product.", "error": err.Error()})
@@ -418,33 +416,21 @@ func RemoveCategoryFromProduct(context *gin.Context) {
 	context.JSON(http.StatusOK, gin.H{"message": "category removed successfully", "productSku": productSku, "categoryId": categoryId})
 }
 
-type CheckStockRequest struct {
-	ProductID int64 `json:"productId" binding:"required" example:"1"`
-	Quantity  int   `json:"quantity" binding:"required,min=1" example:"2"`
-}
-
-type CheckStockResponse struct {
-	Available    bool  `json:"available" example:"true"`
-	RequestedQty int   `json:"requestedQty" example:"2"`
-	AvailableQty int   `json:"availableQty" example:"10"`
-	ProductID    int64 `json:"productId" example:"1"`
-}
-
 // CheckStock godoc
 // @Summary      Check stock availability
 // @Description  Check if enough stock is available for a product (used by Cart/Order services)
 // @Tags         Products
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CheckStockRequest  true  "Product and quantity to check"
-// @Success      200      {object}  CheckStockResponse
+// @Param        request  body      models.CheckStockRequest  true  "Product and quantity to check"
+// @Success      200      {object}  models.CheckStockResponse
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      500      {object}  map[string]interface{}
 // @Router       /products/stock/check [post]
 func CheckStock(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 
-	var req CheckStockRequest
+	var req models.CheckStockRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		l.Error("failed to bind request", "error", err)
 		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
@@ -453,14 +439,14 @@ func CheckStock(context *gin.Context) {
 
 	l.Debug("CheckStock called", "productId", req.ProductID, "quantity", req.Quantity)
 
-	available, currentStock, err := models.CheckStockAvailable(req.ProductID, req.Quantity)
+	available, currentStock, err := models.CheckStockAvailable(context.Request.Context(), req.ProductID, req.Quantity)
 	if err != nil {
 		l.Error("failed to check stock", "productId", req.ProductID, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not check stock.", "error": err.Error()})
 		return
 	}
 
-	response := CheckStockResponse{
+	response := models.CheckStockResponse{
 		Available:    available,
 		RequestedQty: req.Quantity,
 		AvailableQty: currentStock,
@@ -471,18 +457,13 @@ func CheckStock(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
-type ReduceStockRequest struct {
-	ProductID int64 `json:"productId" binding:"required" example:"1"`
-	Quantity  int   `json:"quantity" binding:"required,min=1" example:"2"`
-}
-
 // ReduceStock godoc
 // @Summary      Reduce stock quantity
 // @Description  Reduce stock when an order is confirmed (used by Order service)
 // @Tags         Products
 // @Accept       json
 // @Produce      json
-// @Param        request  body      ReduceStockRequest  true  "Product and quantity to reduce"
+// @Param        request  body      models.ReduceStockRequest  true  "Product and quantity to reduce"
 // @Success      200      {object}  map[string]interface{}
 // @Failure      400      {object}  map[string]interface{}
 // @Failure      409      {object}  map[string]interface{}
@@ -492,7 +473,7 @@ type ReduceStockRequest struct {
 func ReduceStock(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 
-	var req ReduceStockRequest
+	var req models.ReduceStockRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		l.Error("failed to bind request", "error", err)
 		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
@@ -501,7 +482,7 @@ func ReduceStock(context *gin.Context) {
 
 	l.Debug("ReduceStock called", "productId", req.ProductID, "quantity", req.Quantity)
 
-	err := models.ReduceStock(req.ProductID, req.Quantity)
+	err := models.ReduceStock(context.Request.Context(), req.ProductID, req.Quantity)
 	if err != nil {
 		if _, ok := err.(*models.StockError); ok {
 			l.Warn("insufficient stock", "productId", req.ProductID, "quantity", req.Quantity)
@@ -516,3 +497,43 @@ func ReduceStock(context *gin.Context) {
 	l.Info("stock reduced", "productId", req.ProductID, "quantity", req.Quantity)
 	context.JSON(http.StatusOK, gin.H{"message": "stock reduced successfully", "productId": req.ProductID, "quantity": req.Quantity})
 }
+
+// RestockStock godoc
+// @Summary      Restock returned items
+// @Description  Increase stock when returned items are put back into inventory (used by Order service)
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.RestockRequest  true  "Product and quantity to add"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      500      {object}  map[string]interface{}
+// @Router       /internal/products/stock/restock [post]
+func RestockStock(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+
+	var req models.RestockRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		l.Error("failed to bind request", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "invalid request.", "error": err.Error()})
+		return
+	}
+
+	l.Debug("RestockStock called", "productId", req.ProductID, "quantity", req.Quantity)
+
+	err := models.RestockStock(context.Request.Context(), req.ProductID, req.Quantity)
+	if err != nil {
+		if errors.Is(err, models.ErrProductNotFound) {
+			l.Warn("product to restock not found", "productId", req.ProductID)
+			context.JSON(http.StatusNotFound, gin.H{"message": "product not found."})
+			return
+		}
+		l.Error("failed to restock", "productId", req.ProductID, "error", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not restock product.", "error": err.Error()})
+		return
+	}
+
+	l.Info("stock restocked", "productId", req.ProductID, "quantity", req.Quantity)
+	context.JSON(http.StatusOK, gin.H{"message": "stock restocked successfully", "productId": req.ProductID, "quantity": req.Quantity})
+}
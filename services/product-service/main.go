@@ -1,13 +1,12 @@
 package main
 
 import (
-	"io"
 	"log"
 
-	"rearatrox/go-ecommerce-backend/pkg/db"
-	"rearatrox/go-ecommerce-backend/pkg/logger"
-
-	"github.com/gin-gonic/gin"
+	"rearatrox/go-ecommerce-backend/pkg/pb/productpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+	"rearatrox/go-ecommerce-backend/services/product-service/grpcapi"
 )
 
 // @title Event Booking API - Product-Service
@@ -29,19 +28,19 @@ import (
 // @in          header
 // @name        Authorization
 func main() {
-
-	if err := logger.InitFromEnv(); err != nil {
-		log.Fatalf("failed to init logger: %v", err)
+	srv, err := server.New("product-service")
+	if err != nil {
+		log.Fatalf("failed to start product-service: %v", err)
 	}
-	defer logger.Sync()
-
-	db.InitDB()
 
-	gin.DefaultWriter = io.Discard
-	router := gin.Default()
+	// internal API for the other services, the REST /internal endpoints stay available
+	grpcServer := rpc.NewServer(grpcapi.Allowlist)
+	productpb.RegisterProductInternalServer(grpcServer, &grpcapi.Server{})
+	srv.ServeGRPC(grpcServer)
 
-	RegisterRoutes(router)
-
-	router.Run(":8080") // localhost:8080 -> Container-Port wird durch EVENTSERVICE_PORT gesetzt
+	RegisterRoutes(srv.Router)
 
+	if err := srv.Run(); err != nil {
+		log.Fatalf("product-service failed: %v", err)
+	}
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
@@ -17,11 +18,11 @@ type Category struct {
 
 // InsertCategory creates a new category in the database
 // used in: handlers.CreateCategory
-func (c *Category) InsertCategory() error {
+func (c *Category) InsertCategory(ctx context.Context) error {
 	query := `INSERT INTO categories (name, slug, description, created_at)
           VALUES ($1, $2, $3, now())
           RETURNING id, created_at`
-	if err := db.DB.QueryRow(db.Ctx, query, c.Name, c.Slug, c.Description).Scan(&c.ID, &c.CreatedAt); err != nil {
+	if err := db.DB.QueryRow(ctx, query, c.Name, c.Slug, c.Description).Scan(&c.ID, &c.CreatedAt); err != nil {
 		return err
 	}
 	return nil
@@ -29,27 +30,27 @@ func (c *Category) InsertCategory() error {
 
 // UpdateCategory updates an existing category's information
 // used in: handlers.UpdateCategory
-func (c *Category) UpdateCategory() error {
+func (c *Category) UpdateCategory(ctx context.Context) error {
 	query := `UPDATE categories
           SET name=$1, slug=$2, description=$3, updated_at=now()
           WHERE id=$4`
-	_, err := db.DB.Exec(db.Ctx, query, c.Name, c.Slug, c.Description, c.ID)
+	_, err := db.DB.Exec(ctx, query, c.Name, c.Slug, c.Description, c.ID)
 	return err
 }
 
 // DeleteCategory permanently removes a category from the database
 // used in: handlers.DeleteCategoryBySlug
-func (c *Category) DeleteCategory() error {
+func (c *Category) DeleteCategory(ctx context.Context) error {
 	query := `DELETE FROM categories WHERE id=$1`
-	_, err := db.DB.Exec(db.Ctx, query, c.ID)
+	_, err := db.DB.Exec(ctx, query, c.ID)
 	return err
 }
 
 // GetCategories retrieves all categories from the database ordered by name
 // used in: handlers.GetCategories
-func GetCategories() ([]Category, error) {
+func GetCategories(ctx context.Context) ([]Category, error) {
 	query := `SELECT id, name, slug, description, created_at, updated_at FROM categories ORDER BY name`
-	rows, err := db.DB.Query(db.Ctx, query)
+	rows, err := db.DB.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -68,10 +69,10 @@ func GetCategories() ([]Category, error) {
 
 // GetCategoryByID retrieves a category by its numeric ID
 // used in: handlers.GetCategoryByID
-func GetCategoryByID(id int64) (*Category, error) {
+func GetCategoryByID(ctx context.Context, id int64) (*Category, error) {
 	var c Category
 	query := `SELECT id, name, slug, description, created_at, updated_at FROM categories WHERE id=$1`
-	row := db.DB.QueryRow(db.Ctx, query, id)
+	row := db.DB.QueryRow(ctx, query, id)
 	if err := row.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
 		return nil, err
 	}
@@ -80,10 +81,10 @@ func GetCategoryByID(id int64) (*Category, error) {
 
 // GetCategoryBySlug retrieves a category by its URL-friendly slug identifier
 // used in: handlers.GetCategoryBySlug, handlers.UpdateCategory, handlers.DeleteCategoryBySlug, handlers.GetProductsByCategory
-func GetCategoryBySlug(slug string) (*Category, error) {
+func GetCategoryBySlug(ctx context.Context, slug string) (*Category, error) {
 	var c Category
 	query := `SELECT id, name, slug, description, created_at, updated_at FROM categories WHERE slug=$1`
-	row := db.DB.QueryRow(db.Ctx, query, slug)
+	row := db.DB.QueryRow(ctx, query, slug)
 	if err := row.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
 		return nil, err
 	}
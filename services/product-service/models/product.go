@@ -1,11 +1,19 @@
 package models
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"rearatrox/go-ecommerce-backend/pkg/db"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 )
 
+// ErrProductNotFound is returned when a stock update targets a product that does not exist
+var ErrProductNotFound = errors.New("product not found")
+
 type Product struct {
 	ID          int64      `db:"id" json:"id" swaggerignore:"true"`
 	SKU         string     `db:"sku" json:"sku" binding:"required" example:"LAPTOP-001"`
@@ -14,6 +22,7 @@ type Product struct {
 	PriceCents  int        `db:"price_cents" json:"priceCents" binding:"required" example:"149999"`
 	Currency    string     `db:"currency" json:"currency" example:"EUR"`
 	StockQty    int        `db:"stock_qty" json:"stockQty" example:"25"`
+	WeightGrams int        `db:"weight_grams" json:"weightGrams" example:"1800"`
 	Status      string     `db:"status" json:"status" example:"active"`
 	ImageURL    string     `db:"image_url" json:"imageUrl" example:"https://example.com/images/laptop.jpg"`
 	CreatedAt   time.Time  `db:"created_at" json:"createdAt" swaggerignore:"true"`
@@ -22,14 +31,20 @@ type Product struct {
 	UpdatorID   int64      `db:"updator_id" json:"updator_id" swaggerignore:"true"`
 }
 
+// CreateProductRequest is a product with the categories to assign on creation
+type CreateProductRequest struct {
+	Product
+	CategoryIds []int64 `json:"categoryIds,omitempty"`
+}
+
 // InsertProduct creates a new product in the database
 // used in: handlers.CreateProduct
-func (p *Product) InsertProduct() error {
-	query := `INSERT INTO products (sku,name,description,price_cents,stock_qty,image_url,creator_id, created_at)
-          VALUES ($1,$2,$3,$4,$5,$6,$7, now())
+func (p *Product) InsertProduct(ctx context.Context) error {
+	query := `INSERT INTO products (sku,name,description,price_cents,stock_qty,weight_grams,image_url,creator_id, created_at)
+          VALUES ($1,$2,$3,$4,$5,$6,$7,$8, now())
           RETURNING id, status, currency, created_at`
-	if err := db.DB.QueryRow(db.Ctx, query, p.SKU, p.Name, p.Description, p.PriceCents,
-		p.StockQty, p.ImageURL, p.CreatorID).Scan(&p.ID, &p.Status, &p.Currency, &p.CreatedAt); err != nil {
+	if err := db.DB.QueryRow(ctx, query, p.SKU, p.Name, p.Description, p.PriceCents,
+		p.StockQty, p.WeightGrams, p.ImageURL, p.CreatorID).Scan(&p.ID, &p.Status, &p.Currency, &p.CreatedAt); err != nil {
 		return err
 	}
 	return nil
@@ -37,35 +52,35 @@ func (p *Product) InsertProduct() error {
 
 // UpdateProduct updates an existing product's information
 // used in: handlers.UpdateProduct
-func (p *Product) UpdateProduct() error {
+func (p *Product) UpdateProduct(ctx context.Context) error {
 	query := `UPDATE products
-          SET name=$1, description=$2, price_cents=$3, currency=$4, stock_qty=$5, status=$6, image_url=$7, updator_id=$8, updated_at=now()
-          WHERE sku=$9`
-	_, err := db.DB.Exec(db.Ctx, query, p.Name, p.Description, p.PriceCents, p.Currency, p.StockQty, p.Status, p.ImageURL, p.UpdatorID, p.SKU)
+          SET name=$1, description=$2, price_cents=$3, currency=$4, stock_qty=$5, weight_grams=$6, status=$7, image_url=$8, updator_id=$9, updated_at=now()
+          WHERE sku=$10`
+	_, err := db.DB.Exec(ctx, query, p.Name, p.Description, p.PriceCents, p.Currency, p.StockQty, p.WeightGrams, p.Status, p.ImageURL, p.UpdatorID, p.SKU)
 	return err
 }
 
 // DeleteProductBySKU permanently removes a product from the database
 // used in: handlers.DeleteProductBySKU
-func (p *Product) DeleteProductBySKU() error {
+func (p *Product) DeleteProductBySKU(ctx context.Context) error {
 	query := `DELETE FROM products WHERE sku=$1`
-	_, err := db.DB.Exec(db.Ctx, query, p.SKU)
+	_, err := db.DB.Exec(ctx, query, p.SKU)
 	return err
 }
 
 // DeactivateProductBySKU marks a product as inactive without deleting it
 // used in: handlers.DeactivateProductBySKU
-func (p *Product) DeactivateProductBySKU() error {
+func (p *Product) DeactivateProductBySKU(ctx context.Context) error {
 	query := `UPDATE products SET status='inactive' WHERE sku=$1`
-	_, err := db.DB.Exec(db.Ctx, query, p.SKU)
+	_, err := db.DB.Exec(ctx, query, p.SKU)
 	return err
 }
 
 // GetProducts retrieves all products from the database
 // used in: handlers.GetProducts
-func GetProducts() ([]Product, error) {
-	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, status, image_url, creator_id, created_at, updated_at FROM products`
-	rows, err := db.DB.Query(db.Ctx, query)
+func GetProducts(ctx context.Context) ([]Product, error) {
+	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, weight_grams, status, image_url, creator_id, created_at, updated_at FROM products`
+	rows, err := db.DB.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +89,7 @@ func GetProducts() ([]Product, error) {
 	var products []Product
 	for rows.Next() {
 		var p Product
-		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.WeightGrams, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -84,11 +99,11 @@ func GetProducts() ([]Product, error) {
 
 // GetProductByID retrieves a product by its numeric ID
 // used in: handlers.GetProductByID
-func GetProductByID(id int64) (*Product, error) {
+func GetProductByID(ctx context.Context, id int64) (*Product, error) {
 	var p Product
-	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, status, image_url, creator_id, created_at, updated_at FROM products WHERE id=$1`
-	row := db.DB.QueryRow(db.Ctx, query, id)
-	if err := row.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, weight_grams, status, image_url, creator_id, created_at, updated_at FROM products WHERE id=$1`
+	row := db.DB.QueryRow(ctx, query, id)
+	if err := row.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.WeightGrams, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return &p, nil
@@ -96,11 +111,11 @@ func GetProductByID(id int64) (*Product, error) {
 
 // GetProductBySKU retrieves a product by its SKU identifier
 // used in: handlers.GetProductBySKU, handlers.UpdateProduct, handlers.DeactivateProductBySKU, handlers.DeleteProductBySKU, handlers.AddCategoriesToProduct, handlers.RemoveCategoryFromProduct, handlers.GetProductCategories
-func GetProductBySKU(sku string) (*Product, error) {
+func GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
 	var p Product
-	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, status, image_url, creator_id, created_at, updated_at FROM products WHERE sku=$1`
-	row := db.DB.QueryRow(db.Ctx, query, sku)
-	if err := row.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+	query := `SELECT id, sku, name, description, price_cents, currency, stock_qty, weight_grams, status, image_url, creator_id, created_at, updated_at FROM products WHERE sku=$1`
+	row := db.DB.QueryRow(ctx, query, sku)
+	if err := row.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.WeightGrams, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return &p, nil
@@ -108,7 +123,7 @@ func GetProductBySKU(sku string) (*Product, error) {
 
 // AddCategories assigns multiple categories to a product
 // used in: handlers.CreateProduct, handlers.AddCategoriesToProduct
-func (p *Product) AddCategories(categoryIds []int64) error {
+func (p *Product) AddCategories(ctx context.Context, categoryIds []int64) error {
 	if len(categoryIds) == 0 {
 		return nil
 	}
@@ -117,7 +132,7 @@ func (p *Product) AddCategories(categoryIds []int64) error {
 		query := `INSERT INTO product_categories (product_id, category_id, created_at)
 		          VALUES ($1, $2, now())
 		          ON CONFLICT (product_id, category_id) DO NOTHING`
-		_, err := db.DB.Exec(db.Ctx, query, p.ID, categoryId)
+		_, err := db.DB.Exec(ctx, query, p.ID, categoryId)
 		if err != nil {
 			return err
 		}
@@ -127,21 +142,21 @@ func (p *Product) AddCategories(categoryIds []int64) error {
 
 // RemoveCategory removes a category assignment from a product
 // used in: handlers.RemoveCategoryFromProduct
-func (p *Product) RemoveCategory(categoryId int64) error {
+func (p *Product) RemoveCategory(ctx context.Context, categoryId int64) error {
 	query := `DELETE FROM product_categories WHERE product_id=$1 AND category_id=$2`
-	_, err := db.DB.Exec(db.Ctx, query, p.ID, categoryId)
+	_, err := db.DB.Exec(ctx, query, p.ID, categoryId)
 	return err
 }
 
 // GetProductCategories retrieves all categories assigned to a specific product
 // used in: handlers.GetProductCategories
-func GetProductCategories(productId int64) ([]Category, error) {
+func GetProductCategories(ctx context.Context, productId int64) ([]Category, error) {
 	query := `SELECT c.id, c.name, c.slug, c.description, c.created_at, c.updated_at 
 	          FROM categories c
 	          INNER JOIN product_categories pc ON c.id = pc.category_id
 	          WHERE pc.product_id = $1
 	          ORDER BY c.name`
-	rows, err := db.DB.Query(db.Ctx, query, productId)
+	rows, err := db.DB.Query(ctx, query, productId)
 	if err != nil {
 		return nil, err
 	}
@@ -160,13 +175,13 @@ func GetProductCategories(productId int64) ([]Category, error) {
 
 // GetProductsByCategory retrieves all products assigned to a specific category
 // used in: handlers.GetProductsByCategory
-func GetProductsByCategory(categoryId int64) ([]Product, error) {
-	query := `SELECT p.id, p.sku, p.name, p.description, p.price_cents, p.currency, p.stock_qty, p.status, p.image_url, p.creator_id, p.created_at, p.updated_at
+func GetProductsByCategory(ctx context.Context, categoryId int64) ([]Product, error) {
+	query := `SELECT p.id, p.sku, p.name, p.description, p.price_cents, p.currency, p.stock_qty, p.weight_grams, p.status, p.image_url, p.creator_id, p.created_at, p.updated_at
 	          FROM products p
 	          INNER JOIN product_categories pc ON p.id = pc.product_id
 	          WHERE pc.category_id = $1
 	          ORDER BY p.name`
-	rows, err := db.DB.Query(db.Ctx, query, categoryId)
+	rows, err := db.DB.Query(ctx, query, categoryId)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +190,7 @@ func GetProductsByCategory(categoryId int64) ([]Product, error) {
 	var products []Product
 	for rows.Next() {
 		var p Product
-		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Description, &p.PriceCents, &p.Currency, &p.StockQty, &p.WeightGrams, &p.Status, &p.ImageURL, &p.CreatorID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -184,12 +199,12 @@ func GetProductsByCategory(categoryId int64) ([]Product, error) {
 }
 
 // CheckStockAvailable verifies if sufficient stock is available for a product and returns availability status
-// used in: handlers.CheckStock
-func CheckStockAvailable(productID int64, quantity int) (bool, int, error) {
+// used in: handlers.CheckStock, grpcapi.Server.CheckStock
+func CheckStockAvailable(ctx context.Context, productID int64, quantity int) (bool, int, error) {
 	var stockQty int
 	var status string
 	query := `SELECT stock_qty, status FROM products WHERE id=$1`
-	err := db.DB.QueryRow(db.Ctx, query, productID).Scan(&stockQty, &status)
+	err := db.DB.QueryRow(ctx, query, productID).Scan(&stockQty, &status)
 	if err != nil {
 		return false, 0, err
 	}
@@ -203,12 +218,12 @@ func CheckStockAvailable(productID int64, quantity int) (bool, int, error) {
 }
 
 // ReduceStock decreases the stock quantity for a product when an order is confirmed
-// used in: handlers.ReduceStock
-func ReduceStock(productID int64, quantity int) error {
+// used in: handlers.ReduceStock, grpcapi.Server.ReduceStock
+func ReduceStock(ctx context.Context, productID int64, quantity int) error {
 	query := `UPDATE products 
 	          SET stock_qty = stock_qty - $1, updated_at = now()
 	          WHERE id = $2 AND stock_qty >= $1`
-	result, err := db.DB.Exec(db.Ctx, query, quantity, productID)
+	result, err := db.DB.Exec(ctx, query, quantity, productID)
 	if err != nil {
 		return err
 	}
@@ -219,9 +234,95 @@ func ReduceStock(productID int64, quantity int) error {
 		return &StockError{ProductID: productID, Requested: quantity}
 	}
 
+	metrics.StockReductions.Inc()
+	metrics.StockReducedUnits.Add(float64(quantity))
+	return nil
+}
+
+// ReserveStock decreases the stock of all items of an order in one transaction: if one product is short, no stock
+// is taken and a *StockError for that product is returned. Items are updated in product order so concurrent
+// reservations lock the rows in the same order. The reservation is recorded per order in the same transaction, so a
+// repeated call for an order takes nothing and returns false.
+// used in: grpcapi.Server.ReserveStock
+func ReserveStock(ctx context.Context, orderID int64, items []ReduceStockRequest) (reserved bool, err error) {
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b ReduceStockRequest) int { return cmp.Compare(a.ProductID, b.ProductID) })
+
+	tx, err := db.DB.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// a concurrent reservation for the same order waits here until the first one commits or rolls back
+	result, err := tx.Exec(ctx, `INSERT INTO stock_reservations (order_id) VALUES ($1) ON CONFLICT (order_id) DO NOTHING`, orderID)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	for _, item := range items {
+		result, err := tx.Exec(ctx, `UPDATE products
+		          SET stock_qty = stock_qty - $1, updated_at = now()
+		          WHERE id = $2 AND stock_qty >= $1`, item.Quantity, item.ProductID)
+		if err != nil {
+			return false, err
+		}
+		if result.RowsAffected() == 0 {
+			return false, &StockError{ProductID: item.ProductID, Requested: item.Quantity}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	metrics.StockReductions.Inc()
+	for _, item := range items {
+		metrics.StockReducedUnits.Add(float64(item.Quantity))
+	}
+	return true, nil
+}
+
+// RestockStock increases the stock quantity for a product, e.g. for returned items
+// used in: handlers.RestockStock, grpcapi.Server.Restock
+func RestockStock(ctx context.Context, productID int64, quantity int) error {
+	query := `UPDATE products 
+	          SET stock_qty = stock_qty + $1, updated_at = now()
+	          WHERE id = $2`
+	result, err := db.DB.Exec(ctx, query, quantity, productID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
 	return nil
 }
 
+type CheckStockRequest struct {
+	ProductID int64 `json:"productId" binding:"required" example:"1"`
+	Quantity  int   `json:"quantity" binding:"required,min=1" example:"2"`
+}
+
+type CheckStockResponse struct {
+	Available    bool  `json:"available" example:"true"`
+	RequestedQty int   `json:"requestedQty" example:"2"`
+	AvailableQty int   `json:"availableQty" example:"10"`
+	ProductID    int64 `json:"productId" example:"1"`
+}
+
+type ReduceStockRequest struct {
+	ProductID int64 `json:"productId" binding:"required" example:"1"`
+	Quantity  int   `json:"quantity" binding:"required,min=1" example:"2"`
+}
+
+type RestockRequest struct {
+	ProductID int64 `json:"productId" binding:"required" example:"1"`
+	Quantity  int   `json:"quantity" binding:"required,min=1" example:"1"`
+}
+
 // StockError represents an insufficient stock error
 type StockError struct {
 	ProductID int64
@@ -3,15 +3,19 @@ package main
 import (
 	"os"
 	"strings"
+	"time"
 
+	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
 	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
 	"rearatrox/go-ecommerce-backend/services/product-service/handlers"
 
 	docs "rearatrox/go-ecommerce-backend/services/product-service/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,17 +24,14 @@ import (
 const DEFAULT_PORT = "8082"
 
 func RegisterRoutes(router *gin.Engine) {
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
-	// request-logger middleware (adds request-scoped logger into context)
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
 	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
 	router.NoRoute(func(c *gin.Context) {
 		// deinen slog-Logger aus dem Kontext holen
 		log := logger.FromContext(c.Request.Context())
@@ -53,6 +54,19 @@ func RegisterRoutes(router *gin.Engine) {
 	docs.SwaggerInfo.Host = "localhost:" + port
 	docs.SwaggerInfo.BasePath = apiPrefix
 
+	// client IPs are taken from X-Forwarded-For only if set by the gateway (TRUSTED_PROXIES), so callers cannot
+	// switch buckets by sending the header themselves
+	if err := ratelimit.TrustProxiesFromEnv(router, "TRUSTED_PROXIES"); err != nil {
+		panic(err.Error())
+	}
+
+	// per client IP limit of the public stock check (RATE_LIMIT_CHECK_STOCK)
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		panic(err.Error())
+	}
+	checkStockLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "check-stock", Limit: ratelimit.Limit{Requests: 60, Period: time.Minute}, Key: ratelimit.ByIP})
+
 	api := router.Group(apiPrefix)
 	{
 		// make sure the swagger UI knows where to fetch the generated spec
@@ -63,13 +77,13 @@ func RegisterRoutes(router *gin.Engine) {
 		api.GET("/products/:sku/categories", handlers.GetProductCategories)
 
 		// Stock operations (for other services)
-		api.POST("/products/stock/check", handlers.CheckStock)
+		api.POST("/products/stock/check", checkStockLimit, handlers.CheckStock)
 
-		// Internal endpoints (service-to-service communication with secret)
+		// Internal endpoints (service-to-service communication, signed by the calling services named per route)
 		internal := api.Group("/internal")
-		internal.Use(serviceauth.InternalAuth())
 		{
-			internal.POST("/products/stock/reduce", handlers.ReduceStock)
+			internal.POST("/products/stock/reduce", serviceauth.InternalAuth("order-service"), handlers.ReduceStock)
+			internal.POST("/products/stock/restock", serviceauth.InternalAuth("order-service"), handlers.RestockStock)
 		}
 
 		// Category routes (public)
@@ -26,7 +26,7 @@ const docTemplate = `{
     "paths": {
         "/auth/login": {
             "post": {
-                "description": "Authenticate a user and return a JWT token",
+                "description": "Authenticate a user and return a JWT token. If a guest cart token is sent, the guest cart is merged into the user's cart.",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,6 +38,12 @@ const docTemplate = `{
                 ],
                 "summary": "Authenticate user",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guest cart token to merge into the user's cart",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
                     {
                         "description": "User credentials (email + password)",
                         "name": "credentials",
@@ -356,7 +362,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new address for the authenticated user",
+                "description": "Create a new address for the authenticated user. The address is normalized before it is saved; invalid addresses are rejected with the validation result.",
                 "consumes": [
                     "application/json"
                 ],
@@ -400,6 +406,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -410,6 +423,59 @@ const docTemplate = `{
                 }
             }
         },
+        "/users/me/addresses/validate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Normalize and validate an address without saving it. Returns issues per field and suggested corrections.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Addresses"
+                ],
+                "summary": "Validate an address",
+                "parameters": [
+                    {
+                        "description": "Address to validate",
+                        "name": "address",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/addresscheck.Address"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/addresscheck.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/users/me/addresses/{id}": {
             "get": {
                 "security": [
@@ -480,7 +546,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing address of the authenticated user",
+                "description": "Update an existing address of the authenticated user. The address is normalized before it is saved; invalid addresses are rejected with the validation result.",
                 "consumes": [
                     "application/json"
                 ],
@@ -538,6 +604,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -660,6 +733,88 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "addresscheck.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Berlin"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "DE"
+                },
+                "fullName": {
+                    "type": "string",
+                    "example": "Max Mustermann"
+                },
+                "postalCode": {
+                    "type": "string",
+                    "example": "12345"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Musterstraße 123"
+                }
+            }
+        },
+        "addresscheck.Issue": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_postal_code"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "postalCode"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "postal code does not match the format of DE, e.g. 12345"
+                }
+            }
+        },
+        "addresscheck.Result": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Normalized address; store this instead of the input",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/addresscheck.Address"
+                        }
+                    ]
+                },
+                "changed": {
+                    "description": "Fields changed by normalization, e.g. country \"Germany\" became \"DE\"",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "country"
+                    ]
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/addresscheck.Issue"
+                    }
+                },
+                "suggestions": {
+                    "description": "Corrected addresses the customer may choose from",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/addresscheck.Address"
+                    }
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
         "models.Address": {
             "type": "object",
             "required": [
@@ -677,7 +832,7 @@ const docTemplate = `{
                 },
                 "country": {
                     "type": "string",
-                    "example": "Germany"
+                    "example": "DE"
                 },
                 "fullName": {
                     "type": "string",
@@ -0,0 +1,54 @@
+// Package grpcapi implements the internal gRPC API of the user-service (proto/user/v1/user.proto)
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/serviceauth"
+	"rearatrox/go-ecommerce-backend/pkg/pb/userpb"
+	"rearatrox/go-ecommerce-backend/services/user-service/models"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Allowlist names the services allowed to call each method
+var Allowlist = serviceauth.Allowlist{
+	userpb.UserInternal_GetAddress_FullMethodName: {"order-service"},
+}
+
+// Server serves customer data to the other services
+type Server struct {
+	userpb.UnimplementedUserInternalServer
+}
+
+// GetAddress returns a saved address of the user. Addresses of other users are reported as not found, so callers
+// cannot probe for foreign addresses.
+func (s *Server) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.Address, error) {
+	l := logger.FromContext(ctx)
+
+	address, err := models.GetAddressByID(ctx, req.GetAddressId(), req.GetUserId())
+	if errors.Is(err, pgx.ErrNoRows) {
+		l.Warn("address not found", "user_id", req.GetUserId(), "address_id", req.GetAddressId())
+		return nil, status.Error(codes.NotFound, "address not found")
+	}
+	if err != nil {
+		l.Error("failed to get address", "user_id", req.GetUserId(), "address_id", req.GetAddressId(), "error", err)
+		return nil, status.Error(codes.Internal, "could not get address")
+	}
+
+	return &userpb.Address{
+		Id:         address.ID,
+		UserId:     address.UserID,
+		FullName:   address.FullName,
+		Street:     address.Street,
+		PostalCode: address.PostalCode,
+		City:       address.City,
+		Country:    address.Country,
+		Type:       address.Type,
+		IsDefault:  address.IsDefault,
+	}, nil
+}
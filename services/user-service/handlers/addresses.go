@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/addresscheck"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
 	"rearatrox/go-ecommerce-backend/services/user-service/models"
 	"strconv"
@@ -25,7 +26,7 @@ func GetUserAddresses(context *gin.Context) {
 	userId := context.GetInt64("userId")
 	l.Debug("GetUserAddresses called", "user_id", userId)
 
-	addresses, err := models.GetUserAddresses(userId)
+	addresses, err := models.GetUserAddresses(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("failed to fetch user addresses", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch addresses.", "error": err.Error()})
@@ -64,7 +65,7 @@ func GetAddressByID(context *gin.Context) {
 
 	l.Debug("GetAddressByID called", "user_id", userId, "address_id", addressId)
 
-	address, err := models.GetAddressByID(addressId, userId)
+	address, err := models.GetAddressByID(context.Request.Context(), addressId, userId)
 	if err != nil {
 		l.Error("failed to fetch address", "user_id", userId, "address_id", addressId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "address not found.", "error": err.Error()})
@@ -75,9 +76,70 @@ func GetAddressByID(context *gin.Context) {
 	context.JSON(http.StatusOK, address)
 }
 
+// checkAddress runs the local checks and the verifier configured by ADDRESS_VERIFIER. A misconfigured or failing
+// verifier is logged only, the local checks still apply.
+func checkAddress(context *gin.Context, address addresscheck.Address) addresscheck.Result {
+	l := logger.FromContext(context.Request.Context())
+
+	verifier, err := addresscheck.VerifierFromEnv()
+	if err != nil {
+		l.Error("invalid address verifier configuration", "error", err)
+	}
+	validator := addresscheck.Validator{Verifier: verifier}
+	result, err := validator.Validate(context.Request.Context(), address)
+	if err != nil {
+		l.Warn("address verifier unavailable, using local checks only", "error", err)
+	}
+	return result
+}
+
+// validateAddress normalizes an address payload in place. If the address is invalid, the validation result with
+// its issues and suggestions is sent as 422 and false is returned.
+func validateAddress(context *gin.Context, address *models.Address) (addresscheck.Result, bool) {
+	l := logger.FromContext(context.Request.Context())
+
+	result := checkAddress(context, address.Fields())
+	if !result.Valid {
+		l.Warn("invalid address", "issues", result.Issues)
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid address.", "validation": result})
+		return result, false
+	}
+
+	address.SetFields(result.Address)
+	return result, true
+}
+
+// ValidateAddress godoc
+// @Summary      Validate an address
+// @Description  Normalize and validate an address without saving it. Returns issues per field and suggested corrections.
+// @Tags         Addresses
+// @Accept       json
+// @Produce      json
+// @Param        address  body      addresscheck.Address  true  "Address to validate"
+// @Success      200      {object}  addresscheck.Result
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Security     BearerAuth
+// @Router       /users/me/addresses/validate [post]
+func ValidateAddress(context *gin.Context) {
+	l := logger.FromContext(context.Request.Context())
+	l.Debug("ValidateAddress called", "user_id", context.GetInt64("userId"))
+
+	var address addresscheck.Address
+	if err := context.ShouldBindJSON(&address); err != nil {
+		l.Warn("invalid request payload", "error", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "could not parse request data.", "error": err.Error()})
+		return
+	}
+
+	result := checkAddress(context, address)
+	l.Info("validated address", "valid", result.Valid, "issues", len(result.Issues), "suggestions", len(result.Suggestions))
+	context.JSON(http.StatusOK, result)
+}
+
 // CreateAddress godoc
 // @Summary      Create a new address
-// @Description  Create a new address for the authenticated user
+// @Description  Create a new address for the authenticated user. The address is normalized before it is saved; invalid addresses are rejected with the validation result.
 // @Tags         Addresses
 // @Accept       json
 // @Produce      json
@@ -85,6 +147,7 @@ func GetAddressByID(context *gin.Context) {
 // @Success      201    {object}  map[string]interface{}
 // @Failure      400    {object}  map[string]interface{}
 // @Failure      401    {object}  map[string]interface{}
+// @Failure      422    {object}  map[string]interface{}
 // @Failure      500    {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /users/me/addresses [post]
@@ -104,7 +167,12 @@ func CreateAddress(context *gin.Context) {
 
 	address.UserID = userId
 
-	err = address.InsertAddress()
+	validation, ok := validateAddress(context, &address)
+	if !ok {
+		return
+	}
+
+	err = address.InsertAddress(context.Request.Context())
 	if err != nil {
 		l.Error("failed to save address", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create address.", "error": err.Error()})
@@ -112,12 +180,12 @@ func CreateAddress(context *gin.Context) {
 	}
 
 	l.Info("created address", "user_id", userId, "address_id", address.ID)
-	context.JSON(http.StatusCreated, gin.H{"message": "Address created", "address": address})
+	context.JSON(http.StatusCreated, gin.H{"message": "Address created", "address": address, "validation": validation})
 }
 
 // UpdateAddress godoc
 // @Summary      Update an address
-// @Description  Update an existing address of the authenticated user
+// @Description  Update an existing address of the authenticated user. The address is normalized before it is saved; invalid addresses are rejected with the validation result.
 // @Tags         Addresses
 // @Accept       json
 // @Produce      json
@@ -127,6 +195,7 @@ func CreateAddress(context *gin.Context) {
 // @Failure      400    {object}  map[string]interface{}
 // @Failure      401    {object}  map[string]interface{}
 // @Failure      404    {object}  map[string]interface{}
+// @Failure      422    {object}  map[string]interface{}
 // @Failure      500    {object}  map[string]interface{}
 // @Security     BearerAuth
 // @Router       /users/me/addresses/{id} [put]
@@ -145,7 +214,7 @@ func UpdateAddress(context *gin.Context) {
 	l.Debug("UpdateAddress called", "user_id", userId, "address_id", addressId)
 
 	// Check if address exists and belongs to user
-	existingAddress, err := models.GetAddressByID(addressId, userId)
+	existingAddress, err := models.GetAddressByID(context.Request.Context(), addressId, userId)
 	if err != nil {
 		l.Error("failed to fetch address", "user_id", userId, "address_id", addressId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "address not found.", "error": err.Error()})
@@ -163,7 +232,12 @@ func UpdateAddress(context *gin.Context) {
 	updatedAddress.ID = existingAddress.ID
 	updatedAddress.UserID = userId
 
-	err = updatedAddress.UpdateAddress()
+	validation, ok := validateAddress(context, &updatedAddress)
+	if !ok {
+		return
+	}
+
+	err = updatedAddress.UpdateAddress(context.Request.Context())
 	if err != nil {
 		l.Error("failed to update address", "user_id", userId, "address_id", addressId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update address.", "error": err.Error()})
@@ -171,7 +245,7 @@ func UpdateAddress(context *gin.Context) {
 	}
 
 	l.Info("updated address", "user_id", userId, "address_id", addressId)
-	context.JSON(http.StatusOK, gin.H{"message": "updated address successfully", "address": updatedAddress})
+	context.JSON(http.StatusOK, gin.H{"message": "updated address successfully", "address": updatedAddress, "validation": validation})
 }
 
 // DeleteAddress godoc
@@ -203,14 +277,14 @@ func DeleteAddress(context *gin.Context) {
 	l.Debug("DeleteAddress called", "user_id", userId, "address_id", addressId)
 
 	// Check if address exists and belongs to user
-	address, err := models.GetAddressByID(addressId, userId)
+	address, err := models.GetAddressByID(context.Request.Context(), addressId, userId)
 	if err != nil {
 		l.Error("failed to fetch address", "user_id", userId, "address_id", addressId, "error", err)
 		context.JSON(http.StatusNotFound, gin.H{"message": "address not found.", "error": err.Error()})
 		return
 	}
 
-	err = address.DeleteAddress()
+	err = address.DeleteAddress(context.Request.Context())
 	if err != nil {
 		l.Error("failed to delete address", "user_id", userId, "address_id", addressId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not delete address.", "error": err.Error()})
@@ -0,0 +1,12 @@
+package handlers
+
+import "rearatrox/go-ecommerce-backend/pkg/clients"
+
+// cartService merges guest carts on login
+var cartService *clients.CartClient
+
+// UseClients sets the clients of the other services the handlers call; main builds them and calls it before the
+// routes are registered
+func UseClients(cart *clients.CartClient) {
+	cartService = cart
+}
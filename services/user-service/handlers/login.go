@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"rearatrox/go-ecommerce-backend/pkg/guesttoken"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
 	"rearatrox/go-ecommerce-backend/services/user-service/models"
 	"rearatrox/go-ecommerce-backend/services/user-service/utils"
@@ -11,11 +12,12 @@ import (
 
 // Login godoc
 // @Summary      Authenticate user
-// @Description  Authenticate a user and return a JWT token
+// @Description  Authenticate a user and return a JWT token. If a guest cart token is sent, the guest cart is merged into the user's cart.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        credentials  body      models.User  true  "User credentials (email + password)"
+// @Param        X-Cart-Token  header    string       false  "Guest cart token to merge into the user's cart"
+// @Param        credentials   body      models.User  true   "User credentials (email + password)"
 // @Success      200          {object}  map[string]interface{}
 // @Failure      400          {object}  map[string]interface{}
 // @Failure      401          {object}  map[string]interface{}
@@ -36,7 +38,7 @@ func Login(context *gin.Context) {
 	}
 
 	// inside Scan(hash, id, role) --> id + role werden an User-Struct übergeben als Pointer
-	err = user.ValidateCredentials()
+	err = user.ValidateCredentials(context.Request.Context())
 
 	if err != nil {
 		l.Error("login failed", "error", err)
@@ -55,6 +57,21 @@ func Login(context *gin.Context) {
 	//Bearer Token Format
 	token = "Bearer " + token
 
+	response := gin.H{"message": "Login successful", "token": token}
+
+	// Merge the guest cart into the user's cart; a failed merge must not block the login
+	if cartToken := context.GetHeader(guesttoken.CartHeader); cartToken != "" {
+		merged, err := cartService.MergeGuestCart(context.Request.Context(), user.ID, cartToken)
+		if err != nil {
+			l.Warn("could not merge guest cart", "userId", user.ID, "error", err)
+		} else {
+			response["cartMerged"] = true
+			if len(merged.Adjustments) > 0 {
+				response["cartAdjustments"] = merged.Adjustments
+			}
+		}
+	}
+
 	l.Info("Login successful", "token", token, "userId", user.ID, "userRole", user.Role)
-	context.JSON(http.StatusOK, gin.H{"message": "Login successful", "token": token})
+	context.JSON(http.StatusOK, response)
 }
@@ -26,7 +26,7 @@ func Logout(context *gin.Context) {
 	l.Debug("Logout called", "user_id", userId)
 
 	// Get user to increment token version
-	user, err := models.GetUserById(userId)
+	user, err := models.GetUserById(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("failed to get user", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not logout user.", "error": err.Error()})
@@ -34,7 +34,7 @@ func Logout(context *gin.Context) {
 	}
 
 	// Increment token version to invalidate all existing tokens
-	err = user.IncrementTokenVersion()
+	err = user.IncrementTokenVersion(context.Request.Context())
 	if err != nil {
 		l.Error("failed to increment token version", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not logout user.", "error": err.Error()})
@@ -32,7 +32,7 @@ func Signup(context *gin.Context) {
 		return
 	}
 
-	err = user.SaveUser()
+	err = user.SaveUser(context.Request.Context())
 	if err != nil {
 		l.Error("could not create event", "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not create event.", "error": err.Error()})
@@ -23,7 +23,7 @@ func GetUsers(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetUsers called")
 
-	users, err := models.GetUsers()
+	users, err := models.GetUsers(context.Request.Context())
 
 	if err != nil {
 		l.Error("failed to fetch events", "error", err)
@@ -61,7 +61,7 @@ func GetUser(context *gin.Context) {
 		return
 	}
 
-	user, err = models.GetUserById(userId)
+	user, err = models.GetUserById(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("could not fetch event", "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch event.", "error": err.Error()})
@@ -89,7 +89,7 @@ func GetMyProfile(context *gin.Context) {
 	l := logger.FromContext(context.Request.Context())
 	l.Debug("GetMyProfile called", "user_id", userId)
 
-	user, err := models.GetUserById(userId)
+	user, err := models.GetUserById(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("failed to fetch user profile", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch profile.", "error": err.Error()})
@@ -135,7 +135,7 @@ func UpdateMyProfile(context *gin.Context) {
 	}
 
 	// Get existing user
-	user, err := models.GetUserById(userId)
+	user, err := models.GetUserById(context.Request.Context(), userId)
 	if err != nil {
 		l.Error("failed to fetch user", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not fetch user.", "error": err.Error()})
@@ -147,7 +147,7 @@ func UpdateMyProfile(context *gin.Context) {
 	user.LastName = profileUpdate.LastName
 	user.Phone = profileUpdate.Phone
 
-	err = user.UpdateProfile()
+	err = user.UpdateProfile(context.Request.Context())
 	if err != nil {
 		l.Error("failed to update profile", "user_id", userId, "error", err)
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "could not update profile.", "error": err.Error()})
@@ -1,12 +1,13 @@
 package main
 
 import (
-	"io"
 	"log"
-	"rearatrox/go-ecommerce-backend/pkg/db"
-	"rearatrox/go-ecommerce-backend/pkg/logger"
-
-	"github.com/gin-gonic/gin"
+	"rearatrox/go-ecommerce-backend/pkg/clients"
+	"rearatrox/go-ecommerce-backend/pkg/pb/userpb"
+	"rearatrox/go-ecommerce-backend/pkg/rpc"
+	"rearatrox/go-ecommerce-backend/pkg/server"
+	"rearatrox/go-ecommerce-backend/services/user-service/grpcapi"
+	"rearatrox/go-ecommerce-backend/services/user-service/handlers"
 )
 
 // @title Event Booking API - User-Service
@@ -28,21 +29,25 @@ import (
 // @in          header
 // @name        Authorization
 func main() {
-
-	if err := logger.InitFromEnv(); err != nil {
-		log.Fatalf("failed to init logger: %v", err)
+	srv, err := server.New("user-service")
+	if err != nil {
+		log.Fatalf("failed to start user-service: %v", err)
 	}
-	defer logger.Sync()
 
-	db.InitDB()
+	// internal API for the other services, the REST /internal endpoints stay available
+	grpcServer := rpc.NewServer(grpcapi.Allowlist)
+	userpb.RegisterUserInternalServer(grpcServer, &grpcapi.Server{})
+	srv.ServeGRPC(grpcServer)
 
-	gin.DefaultWriter = io.Discard
-	router := gin.Default()
+	// merges the guest cart into the user's cart at login
+	handlers.UseClients(clients.NewCartClientFromEnv())
+	srv.DependsOn("cart-service")
 
-	RegisterRoutes(router)
-
-	router.Run(":8080") // localhost:8080 --> USERSERVICE_PORT mappt dann den Container
+	RegisterRoutes(srv.Router)
 
+	if err := srv.Run(); err != nil {
+		log.Fatalf("user-service failed: %v", err)
+	}
 }
 
 //tmp
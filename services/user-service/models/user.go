@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/services/user-service/utils"
@@ -20,9 +21,9 @@ type User struct {
 
 // GetUsers retrieves all users from the database (admin only)
 // used in: handlers.GetUsers
-func GetUsers() ([]User, error) {
+func GetUsers(ctx context.Context) ([]User, error) {
 	query := `SELECT id, email, password, role, token_version, first_name, last_name, phone FROM users`
-	rows, err := db.DB.Query(db.Ctx, query)
+	rows, err := db.DB.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,10 +42,10 @@ func GetUsers() ([]User, error) {
 
 // GetUserById retrieves a user by their ID
 // used in: handlers.GetUser, handlers.GetMyProfile, handlers.Logout
-func GetUserById(id int64) (*User, error) {
+func GetUserById(ctx context.Context, id int64) (*User, error) {
 	var u User
 	query := `SELECT id, email, password, role, token_version, first_name, last_name, phone FROM users WHERE id=$1`
-	row := db.DB.QueryRow(db.Ctx, query, id)
+	row := db.DB.QueryRow(ctx, query, id)
 	if err := row.Scan(&u.ID, &u.Email, &u.Password, &u.Role, &u.TokenVersion, &u.FirstName, &u.LastName, &u.Phone); err != nil {
 		return nil, err
 	}
@@ -53,9 +54,9 @@ func GetUserById(id int64) (*User, error) {
 
 // ValidateCredentials checks if the provided password matches the stored hash and loads user data
 // used in: handlers.Login
-func (u *User) ValidateCredentials() error {
+func (u *User) ValidateCredentials(ctx context.Context) error {
 	query := `SELECT password, id, role, token_version FROM users WHERE email=$1`
-	row := db.DB.QueryRow(db.Ctx, query, u.Email)
+	row := db.DB.QueryRow(ctx, query, u.Email)
 	var hash []byte
 	if err := row.Scan(&hash, &u.ID, &u.Role, &u.TokenVersion); err != nil {
 		return err
@@ -70,14 +71,14 @@ func (u *User) ValidateCredentials() error {
 
 // SaveUser creates a new user in the database with hashed password
 // used in: handlers.Signup
-func (u *User) SaveUser() error {
+func (u *User) SaveUser(ctx context.Context) error {
 	query := `INSERT INTO users(email, password, first_name, last_name, phone) VALUES ($1, $2, $3, $4, $5) RETURNING id, role`
 	hashedPassword, err := utils.HashPassword(u.Password)
 	if err != nil {
 		return err
 	}
 
-	if err := db.DB.QueryRow(db.Ctx, query, u.Email, hashedPassword, u.FirstName, u.LastName, u.Phone).Scan(&u.ID, &u.Role); err != nil {
+	if err := db.DB.QueryRow(ctx, query, u.Email, hashedPassword, u.FirstName, u.LastName, u.Phone).Scan(&u.ID, &u.Role); err != nil {
 		return err
 	}
 	return nil
@@ -85,21 +86,21 @@ func (u *User) SaveUser() error {
 
 // UpdateProfile updates the user's profile information (firstName, lastName, phone)
 // used in: handlers.UpdateMyProfile
-func (u *User) UpdateProfile() error {
+func (u *User) UpdateProfile(ctx context.Context) error {
 	query := `UPDATE users 
 	          SET first_name=$1, last_name=$2, phone=$3
 	          WHERE id=$4`
-	_, err := db.DB.Exec(db.Ctx, query, u.FirstName, u.LastName, u.Phone, u.ID)
+	_, err := db.DB.Exec(ctx, query, u.FirstName, u.LastName, u.Phone, u.ID)
 	return err
 }
 
 // IncrementTokenVersion increments the token version to invalidate all existing JWT tokens
 // used in: handlers.Logout
-func (u *User) IncrementTokenVersion() error {
+func (u *User) IncrementTokenVersion(ctx context.Context) error {
 	query := `UPDATE users 
 	          SET token_version = token_version + 1 
 	          WHERE id=$1
 	          RETURNING token_version`
-	err := db.DB.QueryRow(db.Ctx, query, u.ID).Scan(&u.TokenVersion)
+	err := db.DB.QueryRow(ctx, query, u.ID).Scan(&u.TokenVersion)
 	return err
 }
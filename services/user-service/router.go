@@ -2,14 +2,18 @@ package main
 
 import (
 	"os"
+	"rearatrox/go-ecommerce-backend/pkg/db"
 	"rearatrox/go-ecommerce-backend/pkg/logger"
+	"rearatrox/go-ecommerce-backend/pkg/metrics"
 	middleware "rearatrox/go-ecommerce-backend/pkg/middleware/auth"
+	"rearatrox/go-ecommerce-backend/pkg/middleware/ratelimit"
+	"rearatrox/go-ecommerce-backend/pkg/tracing"
 	"rearatrox/go-ecommerce-backend/services/user-service/handlers"
 	"strings"
+	"time"
 
 	docs "rearatrox/go-ecommerce-backend/services/user-service/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -18,16 +22,14 @@ import (
 const DEFAULT_PORT = "8081"
 
 func RegisterRoutes(router *gin.Engine) {
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
+	// traces first, so the request logger carries the trace id
+	router.Use(tracing.GinMiddleware())
 	router.Use(logger.GinMiddleware())
+
+	// Prometheus metrics: HTTP requests, outbound calls and business counters; server.New exports the database pool
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", metrics.Handler())
+
 	router.NoRoute(func(c *gin.Context) {
 		// deinen slog-Logger aus dem Kontext holen
 		log := logger.FromContext(c.Request.Context())
@@ -50,11 +52,25 @@ func RegisterRoutes(router *gin.Engine) {
 	docs.SwaggerInfo.Host = "localhost:" + port
 	docs.SwaggerInfo.BasePath = apiPrefix
 
+	// client IPs are taken from X-Forwarded-For only if set by the gateway (TRUSTED_PROXIES), so callers cannot
+	// switch buckets by sending the header themselves
+	if err := ratelimit.TrustProxiesFromEnv(router, "TRUSTED_PROXIES"); err != nil {
+		panic(err.Error())
+	}
+
+	// per client IP limits against credential stuffing and mass signups (RATE_LIMIT_LOGIN, RATE_LIMIT_SIGNUP)
+	limits, err := ratelimit.StoreFromEnv(db.DB)
+	if err != nil {
+		panic(err.Error())
+	}
+	loginLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "login", Limit: ratelimit.Limit{Requests: 10, Period: time.Minute}, Key: ratelimit.ByIP})
+	signupLimit := ratelimit.PolicyFromEnv(limits, ratelimit.Policy{Name: "signup", Limit: ratelimit.Limit{Requests: 5, Period: time.Hour}, Key: ratelimit.ByIP})
+
 	api := router.Group(apiPrefix)
 	{
 		// Public routes
-		api.POST("/auth/signup", handlers.Signup)
-		api.POST("/auth/login", handlers.Login)
+		api.POST("/auth/signup", signupLimit, handlers.Signup)
+		api.POST("/auth/login", loginLimit, handlers.Login)
 
 		// make sure the swagger UI knows where to fetch the generated spec
 		api.GET("/users/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
@@ -74,6 +90,7 @@ func RegisterRoutes(router *gin.Engine) {
 			authenticated.GET("/users/me/addresses", handlers.GetUserAddresses)
 			authenticated.GET("/users/me/addresses/:id", handlers.GetAddressByID)
 			authenticated.POST("/users/me/addresses", handlers.CreateAddress)
+			authenticated.POST("/users/me/addresses/validate", handlers.ValidateAddress)
 			authenticated.PUT("/users/me/addresses/:id", handlers.UpdateAddress)
 			authenticated.DELETE("/users/me/addresses/:id", handlers.DeleteAddress)
 